@@ -0,0 +1,185 @@
+// Command ecsbench runs standardized workloads against the available storage
+// backends and configurations and prints a comparison table, so settings can
+// be chosen based on the shape of a game's workload.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+// --- Benchmark components ---
+
+type position struct {
+	X, Y, Z float64
+}
+
+type velocity struct {
+	Vx, Vy, Vz float64
+}
+
+type mesh struct {
+	ID int
+}
+
+type material struct {
+	ID int
+}
+
+// --- Backends and workloads ---
+
+// backend is a named registry configuration under test.
+type backend struct {
+	name        string
+	newRegistry func() *goecs.Registry
+}
+
+// workload is a standardized benchmark. setup populates the registry outside
+// of the timed section, run is the measured part and returns how many
+// operations it performed.
+type workload struct {
+	name  string
+	setup func(r *goecs.Registry, n int) []goecs.Goent
+	run   func(r *goecs.Registry, entities []goecs.Goent, rng *rand.Rand) int
+}
+
+var backends = []backend{
+	{name: "sparse-set", newRegistry: goecs.NewRegistry},
+	{name: "sparse-set/compact", newRegistry: compactRegistry},
+	{name: "archetype", newRegistry: archetypeRegistry},
+}
+
+func archetypeRegistry() *goecs.Registry {
+	return goecs.NewRegistryWithOptions(goecs.RegistryOptions{Storage: goecs.ArchetypeStorage})
+}
+
+// compactRegistry registers every benchmark component with a small initial
+// capacity and slow growth, trading regrowth for memory.
+func compactRegistry() *goecs.Registry {
+	r := goecs.NewRegistry()
+	policy := goecs.GrowthPolicy{InitialCapacity: 16, GrowthFactor: 1.25}
+	goecs.RegisterComponentWithPolicy[position](r, policy)
+	goecs.RegisterComponentWithPolicy[velocity](r, policy)
+	goecs.RegisterComponentWithPolicy[mesh](r, policy)
+	goecs.RegisterComponentWithPolicy[material](r, policy)
+	return r
+}
+
+var workloads = []workload{
+	{name: "spawn/despawn churn", setup: populate, run: churn},
+	{name: "wide query (4)", setup: populate, run: wideQuery},
+	{name: "narrow query (2)", setup: populate, run: narrowQuery},
+	{name: "random access", setup: populate, run: randomAccess},
+}
+
+// populate creates n entities, every one with position and velocity and
+// every other one also with mesh and material.
+func populate(r *goecs.Registry, n int) []goecs.Goent {
+	entities := make([]goecs.Goent, n)
+	for i := range entities {
+		e := r.CreateEntity()
+		goecs.EmplaceComponent(r, e, position{X: float64(i)})
+		goecs.EmplaceComponent(r, e, velocity{Vx: 1})
+		if i%2 == 0 {
+			goecs.EmplaceComponent(r, e, mesh{ID: i})
+			goecs.EmplaceComponent(r, e, material{ID: i})
+		}
+		entities[i] = e
+	}
+	return entities
+}
+
+// churn despawns a random tenth of the entities and spawns replacements,
+// exercising index recycling as well as component insertion and removal.
+func churn(r *goecs.Registry, entities []goecs.Goent, rng *rand.Rand) int {
+	ops := 0
+	for i := 0; i < len(entities)/10; i++ {
+		slot := rng.Intn(len(entities))
+		r.DestroyEntity(entities[slot])
+		e := r.CreateEntity()
+		goecs.EmplaceComponent(r, e, position{})
+		goecs.EmplaceComponent(r, e, velocity{Vx: 1})
+		entities[slot] = e
+		ops++
+	}
+	return ops
+}
+
+func wideQuery(r *goecs.Registry, _ []goecs.Goent, _ *rand.Rand) int {
+	ops := 0
+	goecs.Iterate4(r, func(e goecs.Goent, p *position, v *velocity, m *mesh, mat *material) {
+		p.X += v.Vx
+		m.ID = mat.ID
+		ops++
+	})
+	return ops
+}
+
+func narrowQuery(r *goecs.Registry, _ []goecs.Goent, _ *rand.Rand) int {
+	ops := 0
+	goecs.Iterate2(r, func(e goecs.Goent, p *position, v *velocity) {
+		p.X += v.Vx
+		ops++
+	})
+	return ops
+}
+
+func randomAccess(r *goecs.Registry, entities []goecs.Goent, rng *rand.Rand) int {
+	for range entities {
+		e := entities[rng.Intn(len(entities))]
+		if p, ok := goecs.GetComponent[position](r, e); ok {
+			p.Y++
+		}
+	}
+	return len(entities)
+}
+
+// --- Runner ---
+
+type result struct {
+	total time.Duration
+	ops   int
+}
+
+func measure(b backend, w workload, n, rounds int, seed int64) result {
+	r := b.newRegistry()
+	entities := w.setup(r, n)
+	rng := rand.New(rand.NewSource(seed))
+
+	var res result
+	for i := 0; i < rounds; i++ {
+		start := time.Now()
+		res.ops += w.run(r, entities, rng)
+		res.total += time.Since(start)
+	}
+	return res
+}
+
+func main() {
+	n := flag.Int("n", 100000, "number of entities per workload")
+	rounds := flag.Int("rounds", 10, "timed rounds per workload")
+	seed := flag.Int64("seed", 1, "random seed for workloads that use randomness")
+	flag.Parse()
+
+	fmt.Printf("ecsbench: %d entities, %d rounds\n\n", *n, *rounds)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "workload\tbackend\ttotal\tops\tns/op\t")
+	for _, w := range workloads {
+		for _, b := range backends {
+			res := measure(b, w, *n, *rounds, *seed)
+			nsPerOp := 0.0
+			if res.ops > 0 {
+				nsPerOp = float64(res.total.Nanoseconds()) / float64(res.ops)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%v\t%d\t%.1f\t\n", w.name, b.name, res.total.Round(time.Microsecond), res.ops, nsPerOp)
+		}
+	}
+	tw.Flush()
+}
@@ -0,0 +1,127 @@
+package goecs
+
+// --- External key aliases ---
+
+// aliasTable is a bidirectional one-to-one map between external keys and
+// entities. Binding a key that is already in use moves it to the new entity,
+// and an entity holds at most one key per table.
+type aliasTable[K comparable] struct {
+	byKey    map[K]Goent
+	byEntity map[Goent]K
+}
+
+func newAliasTable[K comparable]() aliasTable[K] {
+	return aliasTable[K]{
+		byKey:    make(map[K]Goent),
+		byEntity: make(map[Goent]K),
+	}
+}
+
+func (at *aliasTable[K]) set(entity Goent, key K) {
+	if old, exists := at.byEntity[entity]; exists {
+		delete(at.byKey, old)
+	}
+	if owner, exists := at.byKey[key]; exists {
+		delete(at.byEntity, owner)
+	}
+	at.byKey[key] = entity
+	at.byEntity[entity] = key
+}
+
+func (at *aliasTable[K]) lookup(key K) (Goent, bool) {
+	entity, ok := at.byKey[key]
+	return entity, ok
+}
+
+func (at *aliasTable[K]) keyOf(entity Goent) (K, bool) {
+	key, ok := at.byEntity[entity]
+	return key, ok
+}
+
+func (at *aliasTable[K]) remove(entity Goent) {
+	if key, exists := at.byEntity[entity]; exists {
+		delete(at.byKey, key)
+		delete(at.byEntity, entity)
+	}
+}
+
+// SetAlias binds an external string key (database ID, asset path, ...) to an
+// entity, replacing the entity's previous string alias. Destroyed entities
+// are ignored.
+func (r *Registry) SetAlias(entity Goent, key string) {
+	if r.isStale(entity) {
+		return
+	}
+	r.stringAliases.set(entity, key)
+}
+
+// LookupAlias returns the entity bound to an external string key.
+func (r *Registry) LookupAlias(key string) (Goent, bool) {
+	return r.stringAliases.lookup(key)
+}
+
+// AliasOf returns the external string key bound to an entity.
+func (r *Registry) AliasOf(entity Goent) (string, bool) {
+	return r.stringAliases.keyOf(entity)
+}
+
+// RemoveAlias unbinds the external string key of an entity.
+func (r *Registry) RemoveAlias(entity Goent) {
+	r.stringAliases.remove(entity)
+}
+
+// SetAliasID binds an external numeric key (network object ID, ...) to an
+// entity, replacing the entity's previous numeric alias. Destroyed entities
+// are ignored.
+func (r *Registry) SetAliasID(entity Goent, key uint64) {
+	if r.isStale(entity) {
+		return
+	}
+	r.idAliases.set(entity, key)
+}
+
+// LookupAliasID returns the entity bound to an external numeric key.
+func (r *Registry) LookupAliasID(key uint64) (Goent, bool) {
+	return r.idAliases.lookup(key)
+}
+
+// AliasIDOf returns the external numeric key bound to an entity.
+func (r *Registry) AliasIDOf(entity Goent) (uint64, bool) {
+	return r.idAliases.keyOf(entity)
+}
+
+// RemoveAliasID unbinds the external numeric key of an entity.
+func (r *Registry) RemoveAliasID(entity Goent) {
+	r.idAliases.remove(entity)
+}
+
+// --- Entity names ---
+// Names identify well-known entities ("player", "main_camera") for gameplay
+// and tooling code. They live in their own table next to the aliases, so an
+// entity can have a name and an external key at once. Like aliases, names
+// are unique, follow the entity through Save/Load and copies, and are
+// dropped when it is destroyed.
+
+// SetName names an entity, replacing its previous name. A name already held
+// by another entity moves to this one. Destroyed entities are ignored.
+func (r *Registry) SetName(entity Goent, name string) {
+	if r.isStale(entity) {
+		return
+	}
+	r.names.set(entity, name)
+}
+
+// FindByName returns the entity with the given name.
+func (r *Registry) FindByName(name string) (Goent, bool) {
+	return r.names.lookup(name)
+}
+
+// NameOf returns the name of an entity.
+func (r *Registry) NameOf(entity Goent) (string, bool) {
+	return r.names.keyOf(entity)
+}
+
+// ClearName removes the name of an entity.
+func (r *Registry) ClearName(entity Goent) {
+	r.names.remove(entity)
+}
@@ -0,0 +1,109 @@
+package goecs
+
+import "testing"
+
+func TestSetAliasStale(t *testing.T) {
+	tests := []struct {
+		name  string
+		set   func(r *Registry, e Goent, key int)
+		owner func(r *Registry, key int) (Goent, bool)
+	}{
+		{"SetName", func(r *Registry, e Goent, key int) { r.SetName(e, string(rune('a'+key))) },
+			func(r *Registry, key int) (Goent, bool) { return r.FindByName(string(rune('a' + key))) }},
+		{"SetAlias", func(r *Registry, e Goent, key int) { r.SetAlias(e, string(rune('a'+key))) },
+			func(r *Registry, key int) (Goent, bool) { return r.LookupAlias(string(rune('a' + key))) }},
+		{"SetAliasID", func(r *Registry, e Goent, key int) { r.SetAliasID(e, uint64(key)) },
+			func(r *Registry, key int) (Goent, bool) { return r.LookupAliasID(uint64(key)) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			stale := r.CreateEntity()
+			r.DestroyEntity(stale)
+			// the new entity reuses the stale handle's index
+			live := r.CreateEntity()
+			tt.set(r, live, 1)
+
+			tt.set(r, stale, 1)
+			tt.set(r, stale, 2)
+			if e, ok := tt.owner(r, 1); !ok || e != live {
+				t.Errorf("key 1 bound to %v, %v, want %v", e, ok, live)
+			}
+			if e, ok := tt.owner(r, 2); ok {
+				t.Errorf("stale handle bound key 2 to %v", e)
+			}
+		})
+	}
+}
+
+func TestAliases(t *testing.T) {
+	r := NewRegistry()
+	a, b := r.CreateEntity(), r.CreateEntity()
+	r.SetAlias(a, "db:1")
+	r.SetAliasID(a, 100)
+	r.SetAlias(a, "db:2")
+	if _, ok := r.LookupAlias("db:1"); ok {
+		t.Error("replaced alias still resolves")
+	}
+	if key, _ := r.AliasOf(a); key != "db:2" {
+		t.Errorf("AliasOf = %q, want db:2", key)
+	}
+
+	// binding a taken key moves it
+	r.SetAlias(b, "db:2")
+	if e, _ := r.LookupAlias("db:2"); e != b {
+		t.Errorf("db:2 resolves to %d, want %d", e, b)
+	}
+	if _, ok := r.AliasOf(a); ok {
+		t.Error("entity kept the alias that moved away")
+	}
+	if e, _ := r.LookupAliasID(100); e != a {
+		t.Errorf("alias ID resolves to %d, want %d", e, a)
+	}
+
+	r.RemoveAliasID(a)
+	if _, ok := r.LookupAliasID(100); ok {
+		t.Error("alias ID survived RemoveAliasID")
+	}
+	r.DestroyEntity(b)
+	if _, ok := r.LookupAlias("db:2"); ok {
+		t.Error("alias of a destroyed entity still resolves")
+	}
+}
+
+func TestNames(t *testing.T) {
+	r := NewRegistry()
+	player, camera := r.CreateEntity(), r.CreateEntity()
+	r.SetName(player, "player")
+	r.SetName(camera, "camera")
+	r.SetAlias(player, "db:1")
+	if e, ok := r.FindByName("player"); !ok || e != player {
+		t.Errorf("FindByName = %v, %v", e, ok)
+	}
+
+	// renaming frees the old name, names and aliases don't collide
+	r.SetName(player, "hero")
+	if _, ok := r.FindByName("player"); ok {
+		t.Error("old name survived a rename")
+	}
+	if name, _ := r.NameOf(player); name != "hero" {
+		t.Errorf("NameOf = %q, want hero", name)
+	}
+	if key, _ := r.AliasOf(player); key != "db:1" {
+		t.Errorf("renaming changed the alias to %q", key)
+	}
+
+	r.SetName(camera, "hero")
+	if _, ok := r.NameOf(player); ok {
+		t.Error("entity kept the name that moved away")
+	}
+	r.ClearName(camera)
+	if _, ok := r.FindByName("hero"); ok {
+		t.Error("name survived ClearName")
+	}
+	r.SetName(camera, "camera")
+	r.DestroyEntity(camera)
+	if _, ok := r.FindByName("camera"); ok {
+		t.Error("name of a destroyed entity still resolves")
+	}
+}
@@ -0,0 +1,82 @@
+package goecs
+
+import "sort"
+
+// --- Entity annotations ---
+// Annotations are string key/value metadata on entities for editors and
+// tooling: notes, gizmo settings, where an entity was imported from. They
+// are kept apart from the component storages, so no query ever matches or
+// visits them, but they are saved and loaded with the entity, carried along
+// by copies, captured by the Timeline and dropped when it is destroyed.
+
+// Annotate sets an annotation of the entity. Destroyed entities are ignored.
+func (r *Registry) Annotate(entity Goent, key, value string) {
+	if r.isStale(entity) {
+		return
+	}
+	notes, ok := r.annotations[entity]
+	if !ok {
+		notes = make(map[string]string)
+		r.annotations[entity] = notes
+	}
+	notes[key] = value
+}
+
+// Annotation returns an annotation of the entity.
+func (r *Registry) Annotation(entity Goent, key string) (string, bool) {
+	value, ok := r.annotations[entity][key]
+	return value, ok
+}
+
+// Annotations returns a copy of every annotation of the entity.
+func (r *Registry) Annotations(entity Goent) map[string]string {
+	return copyAnnotations(r.annotations[entity])
+}
+
+// RemoveAnnotation removes an annotation of the entity.
+func (r *Registry) RemoveAnnotation(entity Goent, key string) {
+	notes, ok := r.annotations[entity]
+	if !ok {
+		return
+	}
+	delete(notes, key)
+	if len(notes) == 0 {
+		delete(r.annotations, entity)
+	}
+}
+
+// ClearAnnotations removes every annotation of the entity.
+func (r *Registry) ClearAnnotations(entity Goent) {
+	delete(r.annotations, entity)
+}
+
+// AnnotatedEntities returns the entities that have annotations, ordered by ID.
+func (r *Registry) AnnotatedEntities() []Goent {
+	entities := make([]Goent, 0, len(r.annotations))
+	for entity := range r.annotations {
+		entities = append(entities, entity)
+	}
+	sortEntities(entities)
+	return entities
+}
+
+// AnnotationKeys returns the annotation keys of the entity, sorted.
+func (r *Registry) AnnotationKeys(entity Goent) []string {
+	keys := make([]string, 0, len(r.annotations[entity]))
+	for key := range r.annotations[entity] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func copyAnnotations(notes map[string]string) map[string]string {
+	if len(notes) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(notes))
+	for key, value := range notes {
+		out[key] = value
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package goecs
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestAnnotations(t *testing.T) {
+	r := newSaveTarget()
+	door, lamp := r.CreateEntity(), r.CreateEntity()
+	EmplaceComponent(r, door, savePos{X: 1})
+	r.Annotate(door, "source", "level1.tmx")
+	r.Annotate(door, "note", "fix hinge")
+	r.Annotate(lamp, "gizmo", "hidden")
+
+	if v, ok := r.Annotation(door, "source"); !ok || v != "level1.tmx" {
+		t.Errorf("Annotation = %q, %v", v, ok)
+	}
+	if keys := r.AnnotationKeys(door); !slices.Equal(keys, []string{"note", "source"}) {
+		t.Errorf("AnnotationKeys = %v", keys)
+	}
+	notes := r.Annotations(door)
+	notes["note"] = "changed"
+	if v, _ := r.Annotation(door, "note"); v != "fix hinge" {
+		t.Error("Annotations returned the stored map")
+	}
+	// annotations never show up as components
+	r.VisitEntity(lamp, func(typ reflect.Type, _ interface{}) {
+		t.Errorf("annotated entity has a %s component", typ)
+	})
+
+	var buf bytes.Buffer
+	if err := r.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded := newSaveTarget()
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Annotations(door), r.Annotations(door)) {
+		t.Errorf("loaded annotations = %v", loaded.Annotations(door))
+	}
+
+	r.RemoveAnnotation(door, "note")
+	r.ClearAnnotations(door)
+	r.DestroyEntity(lamp)
+	r.Annotate(lamp, "gizmo", "shown")
+	if got := r.AnnotatedEntities(); len(got) != 0 {
+		t.Errorf("AnnotatedEntities = %v, want none", got)
+	}
+}
@@ -0,0 +1,585 @@
+package goecs
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// --- Archetype storage backend ---
+// In archetype mode entities are grouped by their exact set of component
+// types (their archetype). Every archetype stores its components column by
+// column in fixed size chunks, so wide queries walk contiguous memory instead
+// of chasing a pointer per component. Adding or removing a component moves
+// the entity to another archetype, which makes structural changes more
+// expensive than with sparse sets.
+//
+// EmplaceComponent, GetComponent, RemoveComponent, DestroyEntity and the
+// Iterate functions work the same in both modes. Pointers handed out in
+// archetype mode are only valid until the next structural change, since
+// moving an entity or removing another one relocates component values.
+// Helpers that work on sparse set storages directly (views, owning groups,
+// sorting, pruning and the like) only see sparse set storages and find an
+// archetype registry empty.
+
+// StorageMode selects how a registry stores its components.
+type StorageMode int
+
+const (
+	// SparseSetStorage keeps one sparse set per component type. It is the
+	// default and the cheapest for add/remove churn.
+	SparseSetStorage StorageMode = iota
+	// ArchetypeStorage groups entities by component signature into chunks
+	// for cache friendly wide queries.
+	ArchetypeStorage
+)
+
+// archetypeChunkSize is the number of rows in one chunk of a column.
+const archetypeChunkSize = 512
+
+// archColumn is the type-erased interface of one column of an archetype.
+type archColumn interface {
+	// pushFrom appends a copy of row of src, which must hold the same type.
+	pushFrom(src archColumn, row int)
+	// moveRow overwrites row dst with the value in row src.
+	moveRow(dst, src int)
+	// pop drops the last row.
+	pop()
+	// ptr returns a pointer to the value in row, boxed as interface{}.
+	ptr(row int) interface{}
+	// empty returns a new empty column of the same type.
+	empty() archColumn
+}
+
+// chunkedColumn stores the values of one component type of an archetype.
+// Chunks are allocated at full size and never reallocated.
+type chunkedColumn[T any] struct {
+	chunks [][]T
+	n      int
+}
+
+// at returns a pointer to the value in row. A nil column stands for an
+// optional component the archetype doesn't have and yields nil.
+func (c *chunkedColumn[T]) at(row int) *T {
+	if c == nil {
+		return nil
+	}
+	return &c.chunks[row/archetypeChunkSize][row%archetypeChunkSize]
+}
+
+func (c *chunkedColumn[T]) push(v T) {
+	if c.n == len(c.chunks)*archetypeChunkSize {
+		c.chunks = append(c.chunks, make([]T, archetypeChunkSize))
+	}
+	*c.at(c.n) = v
+	c.n++
+}
+
+func (c *chunkedColumn[T]) pushFrom(src archColumn, row int) {
+	c.push(*src.(*chunkedColumn[T]).at(row))
+}
+
+func (c *chunkedColumn[T]) moveRow(dst, src int) {
+	*c.at(dst) = *c.at(src)
+}
+
+func (c *chunkedColumn[T]) pop() {
+	c.n--
+	var zero T
+	*c.at(c.n) = zero
+	// Give back trailing chunks, keeping one spare so a row going back and
+	// forth over a chunk boundary doesn't allocate every time
+	if used := (c.n + archetypeChunkSize - 1) / archetypeChunkSize; len(c.chunks) > used+1 {
+		c.chunks[len(c.chunks)-1] = nil
+		c.chunks = c.chunks[:used+1]
+	}
+}
+
+func (c *chunkedColumn[T]) ptr(row int) interface{} {
+	return c.at(row)
+}
+
+func (c *chunkedColumn[T]) empty() archColumn {
+	return &chunkedColumn[T]{}
+}
+
+// archetype holds every entity with exactly the same component types.
+type archetype struct {
+	types    []reflect.Type
+	columns  map[reflect.Type]archColumn
+	entities []Goent
+	// add and remove cache the archetype reached by adding or removing a type
+	add    map[reflect.Type]*archetype
+	remove map[reflect.Type]*archetype
+}
+
+func (a *archetype) has(t reflect.Type) bool {
+	_, ok := a.columns[t]
+	return ok
+}
+
+// archLocation is where an entity's row lives.
+type archLocation struct {
+	entity Goent
+	arch   *archetype
+	row    int
+}
+
+// archetypeStore is the archetype backend of a registry.
+type archetypeStore struct {
+	typeIDs    map[reflect.Type]int
+	archetypes map[string]*archetype
+	// list keeps archetypes in creation order for deterministic iteration
+	list []*archetype
+	// singles caches the archetypes holding exactly one type, where entities
+	// without components land on their first emplace
+	singles map[reflect.Type]*archetype
+	// locations is indexed by entity index
+	locations []archLocation
+	// ticks holds the change tick per type and entity, read from clock
+	ticks map[reflect.Type]map[Goent]uint64
+	clock *uint64
+}
+
+func newArchetypeStore(clock *uint64) *archetypeStore {
+	return &archetypeStore{
+		typeIDs:    make(map[reflect.Type]int),
+		archetypes: make(map[string]*archetype),
+		singles:    make(map[reflect.Type]*archetype),
+		ticks:      make(map[reflect.Type]map[Goent]uint64),
+		clock:      clock,
+	}
+}
+
+// touch records a change of the entity's t component at the current tick.
+func (as *archetypeStore) touch(entity Goent, t reflect.Type) {
+	ticks, ok := as.ticks[t]
+	if !ok {
+		ticks = make(map[Goent]uint64)
+		as.ticks[t] = ticks
+	}
+	ticks[entity] = *as.clock
+}
+
+// signatureKey builds the map key of an archetype from its type set.
+func (as *archetypeStore) signatureKey(types []reflect.Type) string {
+	ids := make([]int, len(types))
+	for i, t := range types {
+		id, ok := as.typeIDs[t]
+		if !ok {
+			id = len(as.typeIDs)
+			as.typeIDs[t] = id
+		}
+		ids[i] = id
+	}
+	sort.Ints(ids)
+	var sb strings.Builder
+	for _, id := range ids {
+		sb.WriteString(strconv.Itoa(id))
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
+
+// archetypeFor returns the archetype with exactly the given columns, creating
+// it with empty copies of them if needed.
+func (as *archetypeStore) archetypeFor(columns map[reflect.Type]archColumn) *archetype {
+	types := make([]reflect.Type, 0, len(columns))
+	for t := range columns {
+		types = append(types, t)
+	}
+	key := as.signatureKey(types)
+	if a, ok := as.archetypes[key]; ok {
+		return a
+	}
+	a := &archetype{
+		types:   types,
+		columns: make(map[reflect.Type]archColumn, len(columns)),
+		add:     make(map[reflect.Type]*archetype),
+		remove:  make(map[reflect.Type]*archetype),
+	}
+	for t, col := range columns {
+		a.columns[t] = col.empty()
+	}
+	as.archetypes[key] = a
+	as.list = append(as.list, a)
+	return a
+}
+
+// location returns where the entity lives, if it has any components.
+func (as *archetypeStore) location(entity Goent) (archLocation, bool) {
+	index := int(entity.Index())
+	if index >= len(as.locations) {
+		return archLocation{}, false
+	}
+	loc := as.locations[index]
+	if loc.arch == nil || loc.entity != entity {
+		return archLocation{}, false
+	}
+	return loc, true
+}
+
+func (as *archetypeStore) setLocation(entity Goent, a *archetype, row int) {
+	index := int(entity.Index())
+	for index >= len(as.locations) {
+		as.locations = append(as.locations, archLocation{})
+	}
+	as.locations[index] = archLocation{entity: entity, arch: a, row: row}
+}
+
+func (as *archetypeStore) clearLocation(entity Goent) {
+	as.locations[entity.Index()] = archLocation{}
+}
+
+// removeRow takes a row out of an archetype by moving the last row into it.
+func (as *archetypeStore) removeRow(a *archetype, row int) {
+	last := len(a.entities) - 1
+	if row != last {
+		moved := a.entities[last]
+		a.entities[row] = moved
+		for _, col := range a.columns {
+			col.moveRow(row, last)
+		}
+		as.setLocation(moved, a, row)
+	}
+	a.entities = a.entities[:last]
+	for _, col := range a.columns {
+		col.pop()
+	}
+}
+
+// moveEntity copies the entity's shared columns into dst and removes its old
+// row. The caller pushes any column dst has that src doesn't.
+func (as *archetypeStore) moveEntity(entity Goent, src *archetype, row int, dst *archetype) {
+	for t, col := range dst.columns {
+		if from, ok := src.columns[t]; ok {
+			col.pushFrom(from, row)
+		}
+	}
+	dst.entities = append(dst.entities, entity)
+	as.removeRow(src, row)
+	as.setLocation(entity, dst, len(dst.entities)-1)
+}
+
+// archEmplace adds or replaces a T component of the entity.
+func archEmplace[T any](as *archetypeStore, entity Goent, comp T) {
+	key := typeKeyFor[T]()
+	as.touch(entity, key)
+	loc, exists := as.location(entity)
+	if exists && loc.arch.has(key) {
+		*loc.arch.columns[key].(*chunkedColumn[T]).at(loc.row) = comp
+		return
+	}
+
+	var dst *archetype
+	if exists {
+		dst = loc.arch.add[key]
+		if dst == nil {
+			columns := make(map[reflect.Type]archColumn, len(loc.arch.columns)+1)
+			for t, col := range loc.arch.columns {
+				columns[t] = col
+			}
+			columns[key] = &chunkedColumn[T]{}
+			dst = as.archetypeFor(columns)
+			loc.arch.add[key] = dst
+			dst.remove[key] = loc.arch
+		}
+		as.moveEntity(entity, loc.arch, loc.row, dst)
+	} else {
+		dst = as.singles[key]
+		if dst == nil {
+			dst = as.archetypeFor(map[reflect.Type]archColumn{key: &chunkedColumn[T]{}})
+			as.singles[key] = dst
+		}
+		dst.entities = append(dst.entities, entity)
+		as.setLocation(entity, dst, len(dst.entities)-1)
+	}
+	dst.columns[key].(*chunkedColumn[T]).push(comp)
+}
+
+// archGet retrieves a pointer to the entity's T component.
+func archGet[T any](as *archetypeStore, entity Goent) (*T, bool) {
+	loc, exists := as.location(entity)
+	if !exists {
+		return nil, false
+	}
+	col, ok := loc.arch.columns[typeKeyFor[T]()]
+	if !ok {
+		return nil, false
+	}
+	return col.(*chunkedColumn[T]).at(loc.row), true
+}
+
+// get retrieves a pointer to a component by type, boxed as interface{}.
+func (as *archetypeStore) get(entity Goent, t reflect.Type) (interface{}, bool) {
+	loc, exists := as.location(entity)
+	if !exists {
+		return nil, false
+	}
+	col, ok := loc.arch.columns[t]
+	if !ok {
+		return nil, false
+	}
+	return col.ptr(loc.row), true
+}
+
+// has reports whether the entity has a component of type t.
+func (as *archetypeStore) has(entity Goent, t reflect.Type) bool {
+	loc, exists := as.location(entity)
+	return exists && loc.arch.has(t)
+}
+
+// remove takes one component type off the entity.
+func (as *archetypeStore) remove(entity Goent, t reflect.Type) {
+	loc, exists := as.location(entity)
+	if !exists || !loc.arch.has(t) {
+		return
+	}
+	delete(as.ticks[t], entity)
+	if len(loc.arch.columns) == 1 {
+		// That was its last component
+		as.removeRow(loc.arch, loc.row)
+		as.clearLocation(entity)
+		return
+	}
+
+	dst := loc.arch.remove[t]
+	if dst == nil {
+		columns := make(map[reflect.Type]archColumn, len(loc.arch.columns)-1)
+		for ct, col := range loc.arch.columns {
+			if ct != t {
+				columns[ct] = col
+			}
+		}
+		dst = as.archetypeFor(columns)
+		loc.arch.remove[t] = dst
+		dst.add[t] = loc.arch
+	}
+	as.moveEntity(entity, loc.arch, loc.row, dst)
+}
+
+// destroy removes every component of the entity.
+func (as *archetypeStore) destroy(entity Goent) {
+	loc, exists := as.location(entity)
+	if !exists {
+		return
+	}
+	for _, t := range loc.arch.types {
+		delete(as.ticks[t], entity)
+	}
+	as.removeRow(loc.arch, loc.row)
+	as.clearLocation(entity)
+}
+
+// matches reports whether the archetype has all of the types not marked
+// optional, at least one of the types when they all are, and none of the
+// excluded ones.
+func (a *archetype) matches(fs *filterSet, types []reflect.Type) bool {
+	hasAny := false
+	for _, t := range types {
+		if a.has(t) {
+			hasAny = true
+		} else if !fs.isOptional(t) {
+			return false
+		}
+	}
+	if !hasAny {
+		return false
+	}
+	for _, t := range fs.withoutTypes {
+		if a.has(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// each calls fn for every non-empty archetype matching the types and filters.
+func (as *archetypeStore) each(fs *filterSet, types []reflect.Type, fn func(a *archetype)) {
+	for _, a := range as.list {
+		if len(a.entities) > 0 && a.matches(fs, types) {
+			fn(a)
+		}
+	}
+}
+
+// entityMatches reports whether the entity's archetype matches the types and filters.
+func (as *archetypeStore) entityMatches(entity Goent, fs *filterSet, types []reflect.Type) bool {
+	loc, exists := as.location(entity)
+	return exists && loc.arch.matches(fs, types) && !fs.skip(entity)
+}
+
+// iterateReflectiveArchetypes is the archetype mode body of IterateReflective.
+func (r *Registry) iterateReflectiveArchetypes(fVal reflect.Value, fType reflect.Type, fs *filterSet) {
+	compCount := fType.NumIn() - 1
+	types := make([]reflect.Type, compCount)
+	for i := range types {
+		paramType := fType.In(i + 1)
+		if paramType.Kind() == reflect.Ptr {
+			paramType = paramType.Elem()
+		}
+		types[i] = paramType
+	}
+
+	args := make([]reflect.Value, compCount+1)
+	r.archetypes.each(fs, types, func(a *archetype) {
+		for row, entity := range a.entities {
+			if fs.skip(entity) {
+				continue
+			}
+			args[0] = reflect.ValueOf(entity)
+			for i, t := range types {
+				if col, ok := a.columns[t]; ok {
+					args[i+1] = reflect.ValueOf(col.ptr(row))
+				} else {
+					args[i+1] = reflect.Zero(fType.In(i + 1))
+				}
+			}
+			fVal.Call(args)
+		}
+	})
+}
+
+// archColumnOf returns the typed column of T, nil if the archetype lacks it.
+func archColumnOf[T any](a *archetype, key reflect.Type) *chunkedColumn[T] {
+	col, ok := a.columns[key]
+	if !ok {
+		return nil
+	}
+	return col.(*chunkedColumn[T])
+}
+
+// archIterate1 through archIterate8 are the archetype mode bodies of the
+// typed Iterate functions. Missing optional columns are nil and yield nil.
+func archIterate1[T1 any](r *Registry, fs *filterSet, f func(entity Goent, c1 *T1)) {
+	k1 := typeKeyFor[T1]()
+	r.archetypes.each(fs, []reflect.Type{k1}, func(a *archetype) {
+		col1 := archColumnOf[T1](a, k1)
+		for row, entity := range a.entities {
+			if fs.skip(entity) {
+				continue
+			}
+			f(entity, col1.at(row))
+		}
+	})
+}
+
+func archIterate2[T1 any, T2 any](r *Registry, fs *filterSet, f func(entity Goent, c1 *T1, c2 *T2)) {
+	k1, k2 := typeKeyFor[T1](), typeKeyFor[T2]()
+	r.archetypes.each(fs, []reflect.Type{k1, k2}, func(a *archetype) {
+		col1 := archColumnOf[T1](a, k1)
+		col2 := archColumnOf[T2](a, k2)
+		for row, entity := range a.entities {
+			if fs.skip(entity) {
+				continue
+			}
+			f(entity, col1.at(row), col2.at(row))
+		}
+	})
+}
+
+func archIterate3[T1 any, T2 any, T3 any](r *Registry, fs *filterSet, f func(entity Goent, c1 *T1, c2 *T2, c3 *T3)) {
+	k1, k2, k3 := typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3]()
+	r.archetypes.each(fs, []reflect.Type{k1, k2, k3}, func(a *archetype) {
+		col1 := archColumnOf[T1](a, k1)
+		col2 := archColumnOf[T2](a, k2)
+		col3 := archColumnOf[T3](a, k3)
+		for row, entity := range a.entities {
+			if fs.skip(entity) {
+				continue
+			}
+			f(entity, col1.at(row), col2.at(row), col3.at(row))
+		}
+	})
+}
+
+func archIterate4[T1 any, T2 any, T3 any, T4 any](r *Registry, fs *filterSet, f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4)) {
+	k1, k2, k3, k4 := typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3](), typeKeyFor[T4]()
+	r.archetypes.each(fs, []reflect.Type{k1, k2, k3, k4}, func(a *archetype) {
+		col1 := archColumnOf[T1](a, k1)
+		col2 := archColumnOf[T2](a, k2)
+		col3 := archColumnOf[T3](a, k3)
+		col4 := archColumnOf[T4](a, k4)
+		for row, entity := range a.entities {
+			if fs.skip(entity) {
+				continue
+			}
+			f(entity, col1.at(row), col2.at(row), col3.at(row), col4.at(row))
+		}
+	})
+}
+
+func archIterate5[T1 any, T2 any, T3 any, T4 any, T5 any](r *Registry, fs *filterSet, f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4, c5 *T5)) {
+	k1, k2, k3, k4, k5 := typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3](), typeKeyFor[T4](), typeKeyFor[T5]()
+	r.archetypes.each(fs, []reflect.Type{k1, k2, k3, k4, k5}, func(a *archetype) {
+		col1 := archColumnOf[T1](a, k1)
+		col2 := archColumnOf[T2](a, k2)
+		col3 := archColumnOf[T3](a, k3)
+		col4 := archColumnOf[T4](a, k4)
+		col5 := archColumnOf[T5](a, k5)
+		for row, entity := range a.entities {
+			if fs.skip(entity) {
+				continue
+			}
+			f(entity, col1.at(row), col2.at(row), col3.at(row), col4.at(row), col5.at(row))
+		}
+	})
+}
+
+func archIterate6[T1 any, T2 any, T3 any, T4 any, T5 any, T6 any](r *Registry, fs *filterSet, f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4, c5 *T5, c6 *T6)) {
+	k1, k2, k3, k4, k5, k6 := typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3](), typeKeyFor[T4](), typeKeyFor[T5](), typeKeyFor[T6]()
+	r.archetypes.each(fs, []reflect.Type{k1, k2, k3, k4, k5, k6}, func(a *archetype) {
+		col1 := archColumnOf[T1](a, k1)
+		col2 := archColumnOf[T2](a, k2)
+		col3 := archColumnOf[T3](a, k3)
+		col4 := archColumnOf[T4](a, k4)
+		col5 := archColumnOf[T5](a, k5)
+		col6 := archColumnOf[T6](a, k6)
+		for row, entity := range a.entities {
+			if fs.skip(entity) {
+				continue
+			}
+			f(entity, col1.at(row), col2.at(row), col3.at(row), col4.at(row), col5.at(row), col6.at(row))
+		}
+	})
+}
+
+func archIterate7[T1 any, T2 any, T3 any, T4 any, T5 any, T6 any, T7 any](r *Registry, fs *filterSet, f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4, c5 *T5, c6 *T6, c7 *T7)) {
+	k1, k2, k3, k4, k5, k6, k7 := typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3](), typeKeyFor[T4](), typeKeyFor[T5](), typeKeyFor[T6](), typeKeyFor[T7]()
+	r.archetypes.each(fs, []reflect.Type{k1, k2, k3, k4, k5, k6, k7}, func(a *archetype) {
+		col1 := archColumnOf[T1](a, k1)
+		col2 := archColumnOf[T2](a, k2)
+		col3 := archColumnOf[T3](a, k3)
+		col4 := archColumnOf[T4](a, k4)
+		col5 := archColumnOf[T5](a, k5)
+		col6 := archColumnOf[T6](a, k6)
+		col7 := archColumnOf[T7](a, k7)
+		for row, entity := range a.entities {
+			if fs.skip(entity) {
+				continue
+			}
+			f(entity, col1.at(row), col2.at(row), col3.at(row), col4.at(row), col5.at(row), col6.at(row), col7.at(row))
+		}
+	})
+}
+
+func archIterate8[T1 any, T2 any, T3 any, T4 any, T5 any, T6 any, T7 any, T8 any](r *Registry, fs *filterSet, f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4, c5 *T5, c6 *T6, c7 *T7, c8 *T8)) {
+	k1, k2, k3, k4, k5, k6, k7, k8 := typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3](), typeKeyFor[T4](), typeKeyFor[T5](), typeKeyFor[T6](), typeKeyFor[T7](), typeKeyFor[T8]()
+	r.archetypes.each(fs, []reflect.Type{k1, k2, k3, k4, k5, k6, k7, k8}, func(a *archetype) {
+		col1 := archColumnOf[T1](a, k1)
+		col2 := archColumnOf[T2](a, k2)
+		col3 := archColumnOf[T3](a, k3)
+		col4 := archColumnOf[T4](a, k4)
+		col5 := archColumnOf[T5](a, k5)
+		col6 := archColumnOf[T6](a, k6)
+		col7 := archColumnOf[T7](a, k7)
+		col8 := archColumnOf[T8](a, k8)
+		for row, entity := range a.entities {
+			if fs.skip(entity) {
+				continue
+			}
+			f(entity, col1.at(row), col2.at(row), col3.at(row), col4.at(row), col5.at(row), col6.at(row), col7.at(row), col8.at(row))
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package goecs
+
+import "testing"
+
+type archPos struct {
+	X int
+}
+
+type archVel struct {
+	X int
+}
+
+type archTag struct{}
+
+func newArchetypeRegistry() *Registry {
+	return NewRegistryWithOptions(RegistryOptions{Storage: ArchetypeStorage})
+}
+
+func TestArchetypeStructuralChanges(t *testing.T) {
+	r := newArchetypeRegistry()
+	// more entities than fit in one chunk
+	entities := r.CreateEntities(archetypeChunkSize + 100)
+	for i, e := range entities {
+		EmplaceComponent(r, e, archPos{X: i})
+		if i%2 == 0 {
+			EmplaceComponent(r, e, archVel{X: -i})
+		}
+		if i%3 == 0 {
+			EmplaceComponent(r, e, archTag{})
+		}
+	}
+	// move entities between archetypes and punch holes into them
+	for i, e := range entities {
+		switch {
+		case i%5 == 0:
+			r.DestroyEntity(e)
+		case i%7 == 0:
+			RemoveComponent[archVel](r, e)
+		case i%11 == 0:
+			EmplaceComponent(r, e, archPos{X: i * 10})
+		}
+	}
+
+	live, moving := 0, 0
+	for i, e := range entities {
+		if i%5 == 0 {
+			if r.IsAlive(e) || HasComponent[archPos](r, e) {
+				t.Fatalf("destroyed entity %d kept components", i)
+			}
+			continue
+		}
+		live++
+		wantX := i
+		if i%11 == 0 && i%7 != 0 {
+			wantX = i * 10
+		}
+		p, ok := GetComponent[archPos](r, e)
+		if !ok || p.X != wantX {
+			t.Fatalf("entity %d has position %v, %v, want %d", i, p, ok, wantX)
+		}
+		v, ok := GetComponent[archVel](r, e)
+		if hasVel := i%2 == 0 && i%7 != 0; ok != hasVel || (ok && v.X != -i) {
+			t.Fatalf("entity %d has velocity %v, %v", i, v, ok)
+		} else if hasVel {
+			moving++
+		}
+		if HasComponent[archTag](r, e) != (i%3 == 0) {
+			t.Fatalf("entity %d lost its tag", i)
+		}
+	}
+	if Count[archPos](r) != live || Count[archVel](r) != moving {
+		t.Errorf("Count = %d and %d, want %d and %d", Count[archPos](r), Count[archVel](r), live, moving)
+	}
+	n := 0
+	Iterate2(r, func(e Goent, p *archPos, v *archVel) {
+		if p.X != -v.X && p.X != -v.X*10 {
+			t.Errorf("entity %d pairs position %d with velocity %d", e, p.X, v.X)
+		}
+		n++
+	})
+	if n != moving {
+		t.Errorf("Iterate2 visited %d, want %d", n, moving)
+	}
+	n = 0
+	r.IterateReflective(func(e Goent, p *archPos, _ *archTag) { n++ })
+	want := 0
+	for i := range entities {
+		if i%5 != 0 && i%3 == 0 {
+			want++
+		}
+	}
+	if n != want {
+		t.Errorf("IterateReflective visited %d, want %d", n, want)
+	}
+}
@@ -0,0 +1,128 @@
+// Package asset ties asset lifetimes to entity lifetimes. Entities hold
+// assets through Handle components, a Store counts how many handles point at
+// each asset, and Sweep unloads the assets nothing references anymore.
+package asset
+
+import (
+	"sort"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+// Handle references the asset of type T stored under Key, such as a texture
+// path. Handles of different asset types are different component types.
+type Handle[T any] struct {
+	Key string
+}
+
+type entry[T any] struct {
+	asset  T
+	err    error
+	loaded bool
+	refs   int
+}
+
+// Store loads assets of type T on demand and counts their references.
+// It is not safe for concurrent use.
+type Store[T any] struct {
+	load    func(key string) (T, error)
+	unload  func(key string, asset T)
+	entries map[string]*entry[T]
+}
+
+// NewStore creates a store loading assets with load. unload, if set, is
+// called for every asset Sweep drops.
+func NewStore[T any](load func(key string) (T, error), unload func(key string, asset T)) *Store[T] {
+	return &Store[T]{
+		load:    load,
+		unload:  unload,
+		entries: make(map[string]*entry[T]),
+	}
+}
+
+// Attach makes the store count the Handle[T] components of r: attaching a
+// handle adds a reference, replacing it moves the reference to the new key,
+// and removing it or destroying the entity drops it. A store may count the
+// handles of several registries.
+func (s *Store[T]) Attach(r *goecs.Registry) {
+	// held remembers each entity's key, since OnUpdate only sees the new one
+	held := make(map[goecs.Goent]string)
+	goecs.OnAdd(r, func(e goecs.Goent, h *Handle[T]) {
+		held[e] = h.Key
+		s.Retain(h.Key)
+	})
+	goecs.OnUpdate(r, func(e goecs.Goent, h *Handle[T]) {
+		if old := held[e]; old != h.Key {
+			held[e] = h.Key
+			s.Retain(h.Key)
+			s.Release(old)
+		}
+	})
+	goecs.OnRemove(r, func(e goecs.Goent, h *Handle[T]) {
+		s.Release(held[e])
+		delete(held, e)
+	})
+}
+
+// Retain adds a reference to the asset, for holders other than entities.
+func (s *Store[T]) Retain(key string) {
+	s.entry(key).refs++
+}
+
+// Release drops a reference taken with Retain.
+func (s *Store[T]) Release(key string) {
+	if e, ok := s.entries[key]; ok && e.refs > 0 {
+		e.refs--
+	}
+}
+
+func (s *Store[T]) entry(key string) *entry[T] {
+	e, ok := s.entries[key]
+	if !ok {
+		e = &entry[T]{}
+		s.entries[key] = e
+	}
+	return e
+}
+
+// Get returns the asset under key, loading it on first use. A failed load is
+// remembered and reported again until the asset is swept.
+func (s *Store[T]) Get(key string) (T, error) {
+	e := s.entry(key)
+	if !e.loaded {
+		e.asset, e.err = s.load(key)
+		e.loaded = true
+	}
+	return e.asset, e.err
+}
+
+// Resolve returns the asset a handle points at, see Get.
+func (s *Store[T]) Resolve(h Handle[T]) (T, error) {
+	return s.Get(h.Key)
+}
+
+// Refs returns the number of references to the asset.
+func (s *Store[T]) Refs(key string) int {
+	if e, ok := s.entries[key]; ok {
+		return e.refs
+	}
+	return 0
+}
+
+// Sweep unloads every asset without references and returns their keys,
+// sorted. Assets that failed to load are dropped without calling unload.
+func (s *Store[T]) Sweep() []string {
+	var swept []string
+	for key, e := range s.entries {
+		if e.refs > 0 {
+			continue
+		}
+		if e.loaded && e.err == nil && s.unload != nil {
+			s.unload(key, e.asset)
+		}
+		delete(s.entries, key)
+		swept = append(swept, key)
+	}
+	sort.Strings(swept)
+	return swept
+}
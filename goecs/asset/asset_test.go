@@ -0,0 +1,67 @@
+package asset
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+type texture struct {
+	path string
+}
+
+func TestStore(t *testing.T) {
+	var loads, unloads []string
+	s := NewStore(func(key string) (texture, error) {
+		loads = append(loads, key)
+		if key == "missing.png" {
+			return texture{}, errors.New("not found")
+		}
+		return texture{path: key}, nil
+	}, func(key string, tex texture) { unloads = append(unloads, tex.path) })
+	r := goecs.NewRegistry()
+	s.Attach(r)
+
+	a, b := r.CreateEntity(), r.CreateEntity()
+	goecs.EmplaceComponent(r, a, Handle[texture]{Key: "grass.png"})
+	goecs.EmplaceComponent(r, b, Handle[texture]{Key: "grass.png"})
+	if tex, err := s.Resolve(Handle[texture]{Key: "grass.png"}); err != nil || tex.path != "grass.png" {
+		t.Fatalf("Resolve = %v, %v", tex, err)
+	}
+	s.Get("grass.png")
+	if _, err := s.Get("missing.png"); err == nil {
+		t.Error("failed load reported no error")
+	}
+	if !slices.Equal(loads, []string{"grass.png", "missing.png"}) {
+		t.Errorf("loaded %v, want each asset once", loads)
+	}
+
+	// replacing a handle moves its reference
+	goecs.EmplaceComponent(r, b, Handle[texture]{Key: "rock.png"})
+	s.Get("rock.png")
+	if s.Refs("grass.png") != 1 || s.Refs("rock.png") != 1 {
+		t.Errorf("refs = %d grass, %d rock, want 1 each", s.Refs("grass.png"), s.Refs("rock.png"))
+	}
+	if swept := s.Sweep(); !slices.Equal(swept, []string{"missing.png"}) || len(unloads) != 0 {
+		t.Errorf("Sweep = %v (unloaded %v), want only the failed asset", swept, unloads)
+	}
+
+	goecs.RemoveComponent[Handle[texture]](r, a)
+	r.DestroyEntity(b)
+	if swept := s.Sweep(); !slices.Equal(swept, []string{"grass.png", "rock.png"}) || len(unloads) != 2 {
+		t.Errorf("Sweep = %v (unloaded %v), want both textures", swept, unloads)
+	}
+
+	// references held outside entities keep an asset alive
+	s.Retain("ui.png")
+	if swept := s.Sweep(); len(swept) != 0 {
+		t.Errorf("Sweep dropped the retained %v", swept)
+	}
+	s.Release("ui.png")
+	s.Release("ui.png")
+	if s.Refs("ui.png") != 0 || len(s.Sweep()) != 1 {
+		t.Error("released asset wasn't swept")
+	}
+}
@@ -0,0 +1,156 @@
+// Package audio plays sounds for entities through a pluggable backend.
+// AudioEmitter components play a sound for as long as they're attached,
+// one-shot sounds are requested with PlaySound events, and positions come
+// from the entities' physics.Transform. A Mixer runs as a system that hands
+// everything to the Backend once per frame.
+package audio
+
+import (
+	"github.com/Swedeachu/go_ecs/goecs"
+	"github.com/Swedeachu/go_ecs/goecs/physics"
+	"github.com/Swedeachu/go_ecs/goecs/spatial"
+)
+
+// AudioEmitter makes an entity play a sound, starting on the frame after it
+// is attached and stopping when it is removed. A sound without Loop plays
+// once.
+type AudioEmitter struct {
+	Sound  string
+	Volume float64
+	Pitch  float64
+	Loop   bool
+}
+
+// AudioListener marks the entity whose Transform is the ears of the mix,
+// usually the camera or the player. Only the first one found is used.
+type AudioListener struct{}
+
+// PlaySound is an event requesting a one-shot sound. Positional sounds play
+// at the Transform of Entity and are dropped if it has none.
+type PlaySound struct {
+	Sound      string
+	Volume     float64
+	Entity     goecs.Goent
+	Positional bool
+}
+
+// Voice is one sound playing in the backend.
+type Voice struct {
+	Sound  string
+	Volume float64
+	Pitch  float64
+	Loop   bool
+	// Position is only meaningful for positional voices.
+	Position   spatial.Vec2
+	Positional bool
+}
+
+// VoiceID identifies a voice in the backend.
+type VoiceID uint64
+
+// Backend is what an audio engine adapter implements. The Mixer calls it
+// from the game loop only.
+type Backend interface {
+	Play(v Voice) VoiceID
+	// Move updates the position of a positional voice.
+	Move(id VoiceID, position spatial.Vec2)
+	Stop(id VoiceID)
+	SetListener(t physics.Transform)
+}
+
+// Mixer is the system feeding the backend.
+type Mixer struct {
+	backend Backend
+	events  []PlaySound
+	// voices holds the voice of every emitter that was started
+	voices map[goecs.Goent]VoiceID
+}
+
+// NewMixer creates a mixer for the emitters of r.
+func NewMixer(r *goecs.Registry, backend Backend) *Mixer {
+	m := &Mixer{backend: backend, voices: make(map[goecs.Goent]VoiceID)}
+	goecs.OnRemove(r, func(e goecs.Goent, _ *AudioEmitter) {
+		if id, ok := m.voices[e]; ok {
+			m.backend.Stop(id)
+			delete(m.voices, e)
+		}
+	})
+	// a replaced emitter starts over with its new sound
+	goecs.OnUpdate(r, func(e goecs.Goent, _ *AudioEmitter) {
+		if id, ok := m.voices[e]; ok {
+			m.backend.Stop(id)
+			delete(m.voices, e)
+		}
+	})
+	return m
+}
+
+// Send queues an event for the next Flush.
+func (m *Mixer) Send(ev PlaySound) {
+	m.events = append(m.events, ev)
+}
+
+// Play queues a non-positional one-shot sound.
+func (m *Mixer) Play(sound string, volume float64) {
+	m.Send(PlaySound{Sound: sound, Volume: volume})
+}
+
+// PlayAt queues a one-shot sound at the entity's position.
+func (m *Mixer) PlayAt(e goecs.Goent, sound string, volume float64) {
+	m.Send(PlaySound{Sound: sound, Volume: volume, Entity: e, Positional: true})
+}
+
+// Consume queues the PlaySound events among events emitted by scheduled
+// systems, see goecs.Scheduler.Events.
+func (m *Mixer) Consume(events []goecs.QueuedEvent) {
+	for _, ev := range events {
+		if play, ok := ev.Payload.(PlaySound); ok {
+			m.Send(play)
+		}
+	}
+}
+
+// Flush updates the listener, starts new emitters, moves the playing ones
+// and plays the queued events.
+func (m *Mixer) Flush(r *goecs.Registry) {
+	listenerFound := false
+	goecs.Iterate2(r, func(e goecs.Goent, _ *AudioListener, t *physics.Transform) {
+		if !listenerFound {
+			listenerFound = true
+			m.backend.SetListener(*t)
+		}
+	})
+
+	goecs.Iterate1(r, func(e goecs.Goent, em *AudioEmitter) {
+		t, positional := goecs.GetComponent[physics.Transform](r, e)
+		id, playing := m.voices[e]
+		switch {
+		case !playing:
+			v := Voice{Sound: em.Sound, Volume: em.Volume, Pitch: em.Pitch, Loop: em.Loop, Positional: positional}
+			if positional {
+				v.Position = t.Position
+			}
+			m.voices[e] = m.backend.Play(v)
+		case positional:
+			m.backend.Move(id, t.Position)
+		}
+	})
+
+	for _, ev := range m.events {
+		v := Voice{Sound: ev.Sound, Volume: ev.Volume, Pitch: 1}
+		if ev.Positional {
+			t, ok := goecs.GetComponent[physics.Transform](r, ev.Entity)
+			if !ok {
+				continue
+			}
+			v.Position, v.Positional = t.Position, true
+		}
+		m.backend.Play(v)
+	}
+	m.events = m.events[:0]
+}
+
+// Update implements goecs.System.
+func (m *Mixer) Update(w *goecs.World, dt float64) {
+	m.Flush(w.Registry)
+}
@@ -0,0 +1,82 @@
+package audio
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+	"github.com/Swedeachu/go_ecs/goecs/physics"
+	"github.com/Swedeachu/go_ecs/goecs/spatial"
+)
+
+// logBackend records every call as a line of text.
+type logBackend struct {
+	next VoiceID
+	log  []string
+}
+
+func (b *logBackend) Play(v Voice) VoiceID {
+	b.next++
+	if v.Positional {
+		b.log = append(b.log, fmt.Sprintf("play %d %s at %v", b.next, v.Sound, v.Position))
+	} else {
+		b.log = append(b.log, fmt.Sprintf("play %d %s", b.next, v.Sound))
+	}
+	return b.next
+}
+
+func (b *logBackend) Move(id VoiceID, position spatial.Vec2) {
+	b.log = append(b.log, fmt.Sprintf("move %d to %v", id, position))
+}
+
+func (b *logBackend) Stop(id VoiceID) {
+	b.log = append(b.log, fmt.Sprintf("stop %d", id))
+}
+
+func (b *logBackend) SetListener(t physics.Transform) {
+	b.log = append(b.log, fmt.Sprintf("listener at %v", t.Position))
+}
+
+func TestMixer(t *testing.T) {
+	r := goecs.NewRegistry()
+	backend := &logBackend{}
+	m := NewMixer(r, backend)
+	camera, engine, radio, gone := r.CreateEntity(), r.CreateEntity(), r.CreateEntity(), r.CreateEntity()
+	r.DestroyEntity(gone)
+	goecs.EmplaceComponent(r, camera, AudioListener{})
+	goecs.EmplaceComponent(r, camera, physics.Transform{})
+	goecs.EmplaceComponent(r, engine, AudioEmitter{Sound: "engine", Loop: true})
+	goecs.EmplaceComponent(r, engine, physics.Transform{Position: spatial.Vec2{X: 1}})
+	goecs.EmplaceComponent(r, radio, AudioEmitter{Sound: "radio"})
+
+	m.Play("click", 1)
+	m.PlayAt(engine, "honk", 1)
+	m.PlayAt(gone, "lost", 1)
+	m.Consume([]goecs.QueuedEvent{{Payload: PlaySound{Sound: "queued"}}, {Payload: "not a sound"}})
+	m.Flush(r)
+	want := []string{
+		"listener at {0 0}",
+		"play 1 engine at {1 0}",
+		"play 2 radio",
+		"play 3 click",
+		"play 4 honk at {1 0}",
+		"play 5 queued",
+	}
+	if !slices.Equal(backend.log, want) {
+		t.Errorf("first frame = %v, want %v", backend.log, want)
+	}
+
+	// playing emitters follow their entity, replaced ones restart, removed
+	// ones stop
+	backend.log = nil
+	tr, _ := goecs.GetComponent[physics.Transform](r, engine)
+	tr.Position.X = 2
+	goecs.EmplaceComponent(r, radio, AudioEmitter{Sound: "news"})
+	m.Flush(r)
+	goecs.RemoveComponent[AudioEmitter](r, engine)
+	want = []string{"stop 2", "listener at {0 0}", "move 1 to {2 0}", "play 6 news", "stop 1"}
+	if !slices.Equal(backend.log, want) {
+		t.Errorf("second frame = %v, want %v", backend.log, want)
+	}
+}
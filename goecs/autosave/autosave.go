@@ -0,0 +1,344 @@
+// Package autosave periodically saves a world into a ring of slot files and
+// restores the newest one that is intact.
+//
+// Each slot holds a full snapshot (goecs.Registry.Save) followed by the
+// deltas written since (goecs.Registry.WriteDelta), so most autosaves only
+// write what changed. After FullEvery saves the manager moves on to the next
+// slot with a fresh snapshot, overwriting the oldest, so the last Slots
+// chains are kept. Every segment is checksummed: Restore replays a slot up
+// to its first damaged delta, and falls back to the previous slot when the
+// snapshot itself is damaged. Aliases, names and annotations are restored as
+// of the slot's snapshot; deltas only carry entities and components.
+package autosave
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+// FsyncPolicy says when slot files are flushed to stable storage.
+type FsyncPolicy int
+
+const (
+	// FsyncNever leaves flushing to the operating system.
+	FsyncNever FsyncPolicy = iota
+	// FsyncOnRotate syncs every new slot, deltas are left to the OS.
+	FsyncOnRotate
+	// FsyncAlways syncs after every save.
+	FsyncAlways
+)
+
+// Options configures a Manager.
+type Options struct {
+	Dir string
+	// Slots is the number of slot files kept.
+	Slots int
+	// FullEvery is the number of saves per slot, the snapshot included.
+	FullEvery int
+	Fsync     FsyncPolicy
+	// Interval is the time between saves made by Update, in seconds.
+	Interval float64
+}
+
+// Config keys read by OptionsFromConfig and Update.
+const (
+	KeySlots     = "autosave.slots"
+	KeyFullEvery = "autosave.full_every"
+	KeyFsync     = "autosave.fsync"
+	KeyInterval  = "autosave.interval"
+)
+
+// OptionsFromConfig reads the options for saving to dir from a world's
+// config. autosave.fsync is one of never, rotate and always.
+func OptionsFromConfig(c *goecs.Config, dir string) Options {
+	opts := Options{
+		Dir:       dir,
+		Slots:     c.Int(KeySlots, 3),
+		FullEvery: c.Int(KeyFullEvery, 10),
+		Interval:  c.Float(KeyInterval, 60),
+	}
+	switch c.String(KeyFsync, "rotate") {
+	case "never":
+		opts.Fsync = FsyncNever
+	case "always":
+		opts.Fsync = FsyncAlways
+	default:
+		opts.Fsync = FsyncOnRotate
+	}
+	return opts
+}
+
+// ErrNoSave is returned by Restore when no slot holds an intact snapshot.
+var ErrNoSave = errors.New("autosave: no intact save found")
+
+// magic starts every slot file, followed by the slot's sequence number.
+var magic = [8]byte{'G', 'O', 'E', 'C', 'S', 'A', 'V', '1'}
+
+// Manager writes and restores the autosave slots of one directory.
+type Manager struct {
+	opts Options
+	// seq numbers the slots in the order they were started, current is
+	// the slot being appended to
+	seq     uint64
+	current int
+	base    *goecs.Baseline
+	saves   int
+	elapsed float64
+	err     error
+}
+
+// New creates a manager, creating the directory if needed. Existing slots
+// are kept; the first save starts a new slot after the newest of them.
+func New(opts Options) (*Manager, error) {
+	if opts.Slots < 1 {
+		opts.Slots = 1
+	}
+	if opts.FullEvery < 1 {
+		opts.FullEvery = 1
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	m := &Manager{opts: opts, current: -1}
+	for _, s := range m.slots() {
+		if s.seq > m.seq {
+			m.seq, m.current = s.seq, s.index
+		}
+	}
+	return m, nil
+}
+
+// Path returns the file of a slot.
+func (m *Manager) Path(slot int) string {
+	return filepath.Join(m.opts.Dir, fmt.Sprintf("autosave-%d.sav", slot))
+}
+
+// Save writes the registry: a delta to the current slot, or a snapshot to
+// the next slot when the current one is full or there is none yet.
+func (m *Manager) Save(r *goecs.Registry) error {
+	if m.base == nil || m.saves >= m.opts.FullEvery {
+		return m.rotate(r)
+	}
+	var buf bytes.Buffer
+	next, err := r.WriteDelta(&buf, m.base)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(m.Path(m.current), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+	if err := writeSegment(f, buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := m.finish(f, m.opts.Fsync == FsyncAlways); err != nil {
+		return err
+	}
+	m.base = next
+	m.saves++
+	return nil
+}
+
+// rotate starts the next slot with a full snapshot. It is written to a
+// temporary file first, so a crash leaves the old slot intact.
+func (m *Manager) rotate(r *goecs.Registry) error {
+	var buf bytes.Buffer
+	if err := r.Save(&buf); err != nil {
+		return err
+	}
+	base := r.Baseline()
+	slot := (m.current + 1) % m.opts.Slots
+	tmp := m.Path(slot) + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, len(magic)+8)
+	copy(header, magic[:])
+	binary.LittleEndian.PutUint64(header[len(magic):], m.seq+1)
+	if _, err := f.Write(header); err != nil {
+		f.Close()
+		return err
+	}
+	if err := writeSegment(f, buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := m.finish(f, m.opts.Fsync != FsyncNever); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, m.Path(slot)); err != nil {
+		return err
+	}
+	m.seq++
+	m.current = slot
+	m.base = base
+	m.saves = 1
+	return nil
+}
+
+func (m *Manager) finish(f *os.File, sync bool) error {
+	if sync {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// Restored describes what Restore loaded.
+type Restored struct {
+	Slot int
+	// Deltas is the number of deltas replayed on top of the snapshot
+	Deltas int
+	// Truncated is set when the slot ended in a damaged delta, which was
+	// dropped along with everything after it
+	Truncated bool
+	// Skipped lists the newer slots whose snapshot was damaged
+	Skipped []int
+}
+
+// Restore loads the newest intact save into r, which must be empty. The next
+// Save starts a new slot.
+func (m *Manager) Restore(r *goecs.Registry) (Restored, error) {
+	var res Restored
+	slots := m.slots()
+	sort.Slice(slots, func(i, j int) bool { return slots[i].seq > slots[j].seq })
+	for _, s := range slots {
+		segments, truncated, err := readSlot(m.Path(s.index))
+		if err != nil || len(segments) == 0 {
+			res.Skipped = append(res.Skipped, s.index)
+			continue
+		}
+		if err := r.Load(bytes.NewReader(segments[0])); err != nil {
+			return res, err
+		}
+		for _, delta := range segments[1:] {
+			if err := r.ReadDelta(bytes.NewReader(delta)); err != nil {
+				return res, err
+			}
+		}
+		res.Slot, res.Deltas, res.Truncated = s.index, len(segments)-1, truncated
+		m.base = nil
+		return res, nil
+	}
+	return res, ErrNoSave
+}
+
+// Update implements goecs.System, saving every autosave.interval seconds of
+// the world's config (Options.Interval if unset). Errors don't stop the
+// game, they are kept for Err.
+func (m *Manager) Update(w *goecs.World, dt float64) {
+	m.elapsed += dt
+	if m.elapsed < w.Config.Float(KeyInterval, m.opts.Interval) {
+		return
+	}
+	m.elapsed = 0
+	m.err = m.Save(w.Registry)
+}
+
+// Err returns the error of the last save made by Update.
+func (m *Manager) Err() error {
+	return m.err
+}
+
+type slotInfo struct {
+	index int
+	seq   uint64
+}
+
+// slots returns the slots that have a readable header.
+func (m *Manager) slots() []slotInfo {
+	var slots []slotInfo
+	for i := 0; i < m.opts.Slots; i++ {
+		f, err := os.Open(m.Path(i))
+		if err != nil {
+			continue
+		}
+		seq, err := readHeader(f)
+		f.Close()
+		if err == nil {
+			slots = append(slots, slotInfo{index: i, seq: seq})
+		}
+	}
+	return slots
+}
+
+func readHeader(rd io.Reader) (uint64, error) {
+	header := make([]byte, len(magic)+8)
+	if _, err := io.ReadFull(rd, header); err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(header[:len(magic)], magic[:]) {
+		return 0, errors.New("autosave: not a slot file")
+	}
+	return binary.LittleEndian.Uint64(header[len(magic):]), nil
+}
+
+// writeSegment frames a payload with its length and checksum.
+func writeSegment(w io.Writer, payload []byte) error {
+	var frame [8]byte
+	binary.LittleEndian.PutUint32(frame[:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(frame[4:], crc32.ChecksumIEEE(payload))
+	if _, err := w.Write(frame[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// readSlot returns the intact segments of a slot file, stopping at the first
+// damaged or incomplete one, and whether it had to stop early.
+func readSlot(path string) (segments [][]byte, truncated bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+	rd := bufio.NewReader(f)
+	if _, err := readHeader(rd); err != nil {
+		return nil, false, err
+	}
+	left := stat.Size() - int64(len(magic)+8)
+	for {
+		var frame [8]byte
+		n, err := io.ReadFull(rd, frame[:])
+		if err == io.EOF && n == 0 {
+			return segments, false, nil
+		}
+		if err != nil {
+			return segments, true, nil
+		}
+		left -= int64(len(frame))
+		// a damaged length must not make us allocate past the end of the file
+		size := int64(binary.LittleEndian.Uint32(frame[:4]))
+		if size > left {
+			return segments, true, nil
+		}
+		left -= size
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(rd, payload); err != nil {
+			return segments, true, nil
+		}
+		if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(frame[4:]) {
+			return segments, true, nil
+		}
+		segments = append(segments, payload)
+	}
+}
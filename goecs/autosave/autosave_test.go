@@ -0,0 +1,130 @@
+package autosave
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+type score struct {
+	Points int
+}
+
+func newRegistry() *goecs.Registry {
+	r := goecs.NewRegistry()
+	goecs.RegisterComponent[score](r)
+	return r
+}
+
+func points(r *goecs.Registry) []int {
+	var got []int
+	goecs.Iterate1(r, func(e goecs.Goent, s *score) { got = append(got, s.Points) })
+	slices.Sort(got)
+	return got
+}
+
+// play saves four times: a snapshot and a delta in each of two slots.
+func play(t *testing.T, m *Manager) *goecs.Registry {
+	t.Helper()
+	r := newRegistry()
+	for i := 1; i <= 4; i++ {
+		goecs.EmplaceComponent(r, r.CreateEntity(), score{Points: i})
+		r.AdvanceTick()
+		if err := m.Save(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r
+}
+
+func TestRestore(t *testing.T) {
+	m, err := New(Options{Dir: t.TempDir(), Slots: 2, FullEvery: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Restore(newRegistry()); !errors.Is(err, ErrNoSave) {
+		t.Fatalf("Restore of an empty directory = %v, want ErrNoSave", err)
+	}
+	play(t, m)
+
+	r := newRegistry()
+	res, err := m.Restore(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Slot != 1 || res.Deltas != 1 || res.Truncated || len(res.Skipped) != 0 {
+		t.Errorf("Restore = %+v, want slot 1 with one delta", res)
+	}
+	if got := points(r); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("restored points %v", got)
+	}
+
+	// a new manager on the same directory picks up after the newest slot
+	m2, err := New(Options{Dir: m.opts.Dir, Slots: 2, FullEvery: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m2.Save(r); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := m2.Restore(newRegistry()); res.Slot != 0 || res.Deltas != 0 {
+		t.Errorf("Restore after reopening = %+v, want the new snapshot in slot 0", res)
+	}
+}
+
+func TestRestoreDamaged(t *testing.T) {
+	m, err := New(Options{Dir: t.TempDir(), Slots: 2, FullEvery: 2, Fsync: FsyncAlways})
+	if err != nil {
+		t.Fatal(err)
+	}
+	play(t, m)
+
+	// cutting into the last delta drops it
+	info, err := os.Stat(m.Path(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(m.Path(1), info.Size()-3); err != nil {
+		t.Fatal(err)
+	}
+	r := newRegistry()
+	res, err := m.Restore(r)
+	if err != nil || res.Slot != 1 || res.Deltas != 0 || !res.Truncated {
+		t.Errorf("Restore = %+v, %v, want slot 1 truncated", res, err)
+	}
+	if got := points(r); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("restored points %v", got)
+	}
+
+	// a damaged snapshot falls back to the previous slot
+	data, err := os.ReadFile(m.Path(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[30] ^= 0xff
+	if err := os.WriteFile(m.Path(1), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r = newRegistry()
+	res, err = m.Restore(r)
+	if err != nil || res.Slot != 0 || res.Deltas != 1 || !slices.Equal(res.Skipped, []int{1}) {
+		t.Errorf("Restore = %+v, %v, want slot 0 after skipping slot 1", res, err)
+	}
+	if got := points(r); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("restored points %v", got)
+	}
+}
+
+func TestOptionsFromConfig(t *testing.T) {
+	c := goecs.NewConfig()
+	c.Set(KeySlots, "5")
+	c.Set(KeyFsync, "always")
+	opts := OptionsFromConfig(c, "saves")
+	want := Options{Dir: "saves", Slots: 5, FullEvery: 10, Fsync: FsyncAlways, Interval: 60}
+	if opts != want {
+		t.Errorf("OptionsFromConfig = %+v, want %+v", opts, want)
+	}
+}
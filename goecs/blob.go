@@ -0,0 +1,33 @@
+package goecs
+
+// --- Per-entity blob storage ---
+
+// Blob is a fallback component for data that genuinely doesn't fit the typed
+// component model, such as embedded script VM state or opaque third-party
+// handles. Tag is free for the user to describe what Data holds.
+//
+// Blobs live in an ordinary storage of the registry, so everything that walks
+// all storages (DestroyEntity, pruning, whole-registry serialization) handles
+// them like any other component.
+type Blob struct {
+	Tag  string
+	Data []byte
+}
+
+// SetBlob attaches or replaces the blob of an entity. The data is copied so
+// the caller is free to reuse its buffer.
+func (r *Registry) SetBlob(entity Goent, tag string, data []byte) {
+	owned := make([]byte, len(data))
+	copy(owned, data)
+	EmplaceComponent(r, entity, Blob{Tag: tag, Data: owned})
+}
+
+// GetBlob retrieves a pointer to the blob of an entity.
+func (r *Registry) GetBlob(entity Goent) (*Blob, bool) {
+	return GetComponent[Blob](r, entity)
+}
+
+// RemoveBlob detaches the blob of an entity.
+func (r *Registry) RemoveBlob(entity Goent) {
+	RemoveComponent[Blob](r, entity)
+}
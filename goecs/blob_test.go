@@ -0,0 +1,51 @@
+package goecs
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBlob(t *testing.T) {
+	r := NewRegistry()
+	e := r.CreateEntity()
+	data := []byte("vm state")
+	r.SetBlob(e, "lua", data)
+	data[0] = 'X'
+
+	b, ok := r.GetBlob(e)
+	if !ok || b.Tag != "lua" || string(b.Data) != "vm state" {
+		t.Fatalf("GetBlob = %+v, %v, want the copied lua blob", b, ok)
+	}
+	r.SetBlob(e, "js", []byte{1})
+	if b, _ := r.GetBlob(e); b.Tag != "js" || !bytes.Equal(b.Data, []byte{1}) {
+		t.Errorf("blob = %+v after replacing it", b)
+	}
+	r.RemoveBlob(e)
+	if _, ok := r.GetBlob(e); ok {
+		t.Error("blob survived RemoveBlob")
+	}
+
+	r.SetBlob(e, "lua", data)
+	r.DestroyEntity(e)
+	if n := Count[Blob](r); n != 0 {
+		t.Errorf("%d blobs left after DestroyEntity", n)
+	}
+}
+
+func TestBlobSnapshot(t *testing.T) {
+	src := NewRegistry()
+	e := src.CreateEntity()
+	src.SetBlob(e, "lua", []byte("vm state"))
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := NewRegistry()
+	if err := dst.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := dst.GetBlob(e); !ok || b.Tag != "lua" || string(b.Data) != "vm state" {
+		t.Errorf("loaded blob = %+v, %v", b, ok)
+	}
+}
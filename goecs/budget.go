@@ -0,0 +1,83 @@
+package goecs
+
+import (
+	"time"
+)
+
+// --- Budgeted iteration ---
+// Budgeted iteration spreads one pass over a storage across several frames.
+// It stops once the deadline passes and a BudgetCursor remembers where, so
+// the next call resumes there. Entities added or removed between calls can
+// shift positions, so a pass may visit an entity twice or miss one; use it
+// for maintenance work that tolerates that, not for gameplay logic.
+
+// budgetCheckEvery is how many entities are visited between clock reads.
+const budgetCheckEvery = 64
+
+// BudgetCursor is the resume position of a budgeted iteration.
+type BudgetCursor struct {
+	pos int
+}
+
+// Reset restarts the pass from the beginning.
+func (c *BudgetCursor) Reset() {
+	c.pos = 0
+}
+
+// budgetDense walks dense from the cursor until the deadline, reporting
+// whether the pass completed.
+func budgetDense(dense []Goent, cur *BudgetCursor, deadline time.Time, visit func(i int, entity Goent)) bool {
+	for n := 0; cur.pos < len(dense); n++ {
+		if n > 0 && n%budgetCheckEvery == 0 && time.Now().After(deadline) {
+			return false
+		}
+		visit(cur.pos, dense[cur.pos])
+		cur.pos++
+	}
+	cur.pos = 0
+	return true
+}
+
+// IterateBudget1 continues a pass over the T components until the deadline.
+// It reports true when the pass completed, after which the cursor starts
+// over. Only sparse set registries are supported; archetype registries
+// complete the pass in one call.
+func IterateBudget1[T any](r *Registry, cur *BudgetCursor, deadline time.Time, f func(entity Goent, c *T)) bool {
+	if r.archetypes != nil {
+		Iterate1(r, f)
+		return true
+	}
+	s := getStorage[T](r)
+	if s == nil {
+		return true
+	}
+	fs := r.resolveFilters(nil)
+	return budgetDense(s.dense, cur, deadline, func(i int, entity Goent) {
+		if fs.skip(entity) {
+			return
+		}
+		f(entity, s.at(i))
+	})
+}
+
+// IterateBudget2 continues a pass over entities with T1 and T2 until the
+// deadline, driven by the T1 storage. See IterateBudget1.
+func IterateBudget2[T1 any, T2 any](r *Registry, cur *BudgetCursor, deadline time.Time, f func(entity Goent, c1 *T1, c2 *T2)) bool {
+	if r.archetypes != nil {
+		Iterate2(r, f)
+		return true
+	}
+	s1, s2 := getStorage[T1](r), getStorage[T2](r)
+	if s1 == nil || s2 == nil {
+		return true
+	}
+	fs := r.resolveFilters(nil)
+	return budgetDense(s1.dense, cur, deadline, func(i int, entity Goent) {
+		if fs.skip(entity) {
+			return
+		}
+		if c2, ok := s2.Get(entity); ok {
+			f(entity, s1.at(i), c2)
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package goecs
+
+import (
+	"encoding/gob"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strings"
+)
+
+// --- Build info and seeds ---
+// Bug reports and replays are only useful against the code and data that
+// produced them. BuildInfo describes both: the binary's module versions, a
+// hash of the component schema, the tick, and the RNG seeds the game
+// registered with SetSeed. Save embeds it in every snapshot, so a snapshot
+// can be checked with ReadBuildInfo before it is loaded.
+
+// BuildInfo identifies the code and state a registry is running with.
+type BuildInfo struct {
+	// Module and Version are the main module of the binary, GoECS the version
+	// of this package it was built with. They are empty when the binary has
+	// no build information, e.g. under go run.
+	Module    string
+	Version   string
+	GoECS     string
+	GoVersion string
+	// Schema hashes the name and layout of every known component type
+	Schema string
+	Tick   uint64
+	Seeds  map[string]int64
+}
+
+// SetSeed records the seed of a named random source, so replays can recreate
+// it from the snapshot.
+func (r *Registry) SetSeed(name string, seed int64) {
+	if r.seeds == nil {
+		r.seeds = make(map[string]int64)
+	}
+	r.seeds[name] = seed
+}
+
+// Seed returns the seed recorded under the name.
+func (r *Registry) Seed(name string) (int64, bool) {
+	seed, ok := r.seeds[name]
+	return seed, ok
+}
+
+// BuildInfo describes the registry's code and data versions right now.
+func (r *Registry) BuildInfo() BuildInfo {
+	info := BuildInfo{Schema: r.SchemaHash(), Tick: r.tick, Seeds: make(map[string]int64, len(r.seeds))}
+	for name, seed := range r.seeds {
+		info.Seeds[name] = seed
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.Module, info.Version, info.GoVersion = bi.Main.Path, bi.Main.Version, bi.GoVersion
+		for _, dep := range bi.Deps {
+			if dep.Path == "github.com/Swedeachu/go_ecs" {
+				info.GoECS = dep.Version
+			}
+		}
+		if bi.Main.Path == "github.com/Swedeachu/go_ecs" {
+			info.GoECS = bi.Main.Version
+		}
+	}
+	return info
+}
+
+// SchemaHash hashes the names and field layouts of every component type the
+// registry knows, so two registries with the same hash encode components the
+// same way.
+func (r *Registry) SchemaHash() string {
+	h := fnv.New64a()
+	for _, info := range r.sortedComponentTypes() {
+		fmt.Fprintf(h, "%s=%s;", info.name, describeType(info.typ, map[reflect.Type]bool{}))
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// describeType spells out a type's layout down to its basic kinds.
+func describeType(t reflect.Type, seen map[reflect.Type]bool) string {
+	switch t.Kind() {
+	case reflect.Struct:
+		if seen[t] {
+			return t.String()
+		}
+		seen[t] = true
+		fields := make([]string, t.NumField())
+		for i := range fields {
+			f := t.Field(i)
+			fields[i] = f.Name + " " + describeType(f.Type, seen)
+		}
+		return "struct{" + strings.Join(fields, "; ") + "}"
+	case reflect.Pointer:
+		return "*" + describeType(t.Elem(), seen)
+	case reflect.Slice:
+		return "[]" + describeType(t.Elem(), seen)
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), describeType(t.Elem(), seen))
+	case reflect.Map:
+		return "map[" + describeType(t.Key(), seen) + "]" + describeType(t.Elem(), seen)
+	default:
+		return t.Kind().String()
+	}
+}
+
+// Diff lists the fields in which o differs from b, e.g. to warn that a replay
+// was recorded with another build. The tick is not compared.
+func (b BuildInfo) Diff(o BuildInfo) []string {
+	var diffs []string
+	field := func(name, x, y string) {
+		if x != y {
+			diffs = append(diffs, fmt.Sprintf("%s: %q != %q", name, x, y))
+		}
+	}
+	field("Module", b.Module, o.Module)
+	field("Version", b.Version, o.Version)
+	field("GoECS", b.GoECS, o.GoECS)
+	field("GoVersion", b.GoVersion, o.GoVersion)
+	field("Schema", b.Schema, o.Schema)
+
+	names := make(map[string]struct{})
+	for name := range b.Seeds {
+		names[name] = struct{}{}
+	}
+	for name := range o.Seeds {
+		names[name] = struct{}{}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		x, okX := b.Seeds[name]
+		y, okY := o.Seeds[name]
+		if x != y || okX != okY {
+			diffs = append(diffs, fmt.Sprintf("Seeds[%s]: %d != %d", name, x, y))
+		}
+	}
+	return diffs
+}
+
+// ReadBuildInfo returns the build info embedded in a snapshot written by
+// Save, reading only its header.
+func ReadBuildInfo(rd io.Reader) (BuildInfo, error) {
+	var header snapshotHeader
+	if err := gob.NewDecoder(rd).Decode(&header); err != nil {
+		return BuildInfo{}, err
+	}
+	if header.Version != snapshotVersion {
+		return BuildInfo{}, fmt.Errorf("goecs: unsupported snapshot version %d", header.Version)
+	}
+	return header.Build, nil
+}
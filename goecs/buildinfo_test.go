@@ -0,0 +1,74 @@
+package goecs
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+type schemaV1 struct {
+	HP int
+}
+
+type schemaV2 struct {
+	HP    int
+	Armor int
+}
+
+func TestSchemaHash(t *testing.T) {
+	a, b := NewRegistry(), NewRegistry()
+	if a.SchemaHash() != b.SchemaHash() {
+		t.Error("empty registries hash differently")
+	}
+	RegisterNamedComponent[schemaV1](a, "game.Health")
+	RegisterNamedComponent[schemaV1](b, "game.Health")
+	if a.SchemaHash() != b.SchemaHash() {
+		t.Error("the same schema hashes differently")
+	}
+	// the same name with another layout is another schema
+	c := NewRegistry()
+	RegisterNamedComponent[schemaV2](c, "game.Health")
+	if c.SchemaHash() == a.SchemaHash() {
+		t.Error("a changed layout kept the schema hash")
+	}
+}
+
+func TestBuildInfo(t *testing.T) {
+	r := newSaveTarget()
+	r.SetSeed("loot", 42)
+	r.SetSeed("ai", 7)
+	EmplaceComponent(r, r.CreateEntity(), savePos{})
+	r.AdvanceTick()
+	want := r.BuildInfo()
+	if want.Tick != r.Tick() || want.Seeds["loot"] != 42 || want.Schema != r.SchemaHash() {
+		t.Errorf("BuildInfo = %+v", want)
+	}
+
+	var buf bytes.Buffer
+	if err := r.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadBuildInfo(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diffs := want.Diff(got); len(diffs) != 0 || got.Tick != want.Tick {
+		t.Errorf("snapshot build info differs: %v", diffs)
+	}
+	loaded := newSaveTarget()
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if seed, ok := loaded.Seed("ai"); !ok || seed != 7 {
+		t.Errorf("loaded seed = %d, %v, want 7", seed, ok)
+	}
+
+	other := want
+	other.Seeds = map[string]int64{"loot": 43, "weather": 1}
+	other.Schema = "0"
+	diffs := want.Diff(other)
+	wantDiffs := []string{`Schema: "` + want.Schema + `" != "0"`, "Seeds[ai]: 7 != 0", "Seeds[loot]: 42 != 43", "Seeds[weather]: 0 != 1"}
+	if !slices.Equal(diffs, wantDiffs) {
+		t.Errorf("Diff = %v, want %v", diffs, wantDiffs)
+	}
+}
@@ -0,0 +1,103 @@
+// Package camera provides a minimal 2D camera component with screen/world
+// conversions and a query over the entities visible through it, shared by
+// culling, picking and debug draw.
+package camera
+
+import (
+	"math"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+	"github.com/Swedeachu/go_ecs/goecs/spatial"
+)
+
+// Viewport is the area of the screen a camera renders into, in pixels with
+// the origin at the top left and Y pointing down.
+type Viewport struct {
+	X, Y, Width, Height float64
+}
+
+// Center returns the middle of the viewport in screen space.
+func (v Viewport) Center() spatial.Vec2 {
+	return spatial.Vec2{X: v.X + v.Width/2, Y: v.Y + v.Height/2}
+}
+
+// Camera is an orthographic 2D camera component.
+type Camera struct {
+	// Position is the world point shown at the center of the viewport.
+	Position spatial.Vec2
+	// Rotation turns the view counter-clockwise, in radians.
+	Rotation float64
+	// Zoom is the projection scale in pixels per world unit, 0 is treated as 1.
+	Zoom float64
+	// Viewport is where on the screen the camera draws.
+	Viewport Viewport
+}
+
+func (c *Camera) zoom() float64 {
+	if c.Zoom == 0 {
+		return 1
+	}
+	return c.Zoom
+}
+
+// rotate turns v counter-clockwise by angle radians.
+func rotate(v spatial.Vec2, angle float64) spatial.Vec2 {
+	sin, cos := math.Sincos(angle)
+	return spatial.Vec2{X: v.X*cos - v.Y*sin, Y: v.X*sin + v.Y*cos}
+}
+
+// ScreenToWorld converts a pixel position to the world point under it.
+func (c *Camera) ScreenToWorld(p spatial.Vec2) spatial.Vec2 {
+	local := p.Sub(c.Viewport.Center()).Scale(1 / c.zoom())
+	// Screen Y points down, world Y points up
+	local.Y = -local.Y
+	return rotate(local, c.Rotation).Add(c.Position)
+}
+
+// WorldToScreen converts a world point to its pixel position.
+func (c *Camera) WorldToScreen(p spatial.Vec2) spatial.Vec2 {
+	local := rotate(p.Sub(c.Position), -c.Rotation)
+	local.Y = -local.Y
+	return local.Scale(c.zoom()).Add(c.Viewport.Center())
+}
+
+// VisibleRegion returns the world space box that encloses everything the
+// camera can see. With rotation it is the bounding box of the rotated view.
+func (c *Camera) VisibleRegion() spatial.Rect {
+	v := c.Viewport
+	corners := [4]spatial.Vec2{
+		c.ScreenToWorld(spatial.Vec2{X: v.X, Y: v.Y}),
+		c.ScreenToWorld(spatial.Vec2{X: v.X + v.Width, Y: v.Y}),
+		c.ScreenToWorld(spatial.Vec2{X: v.X, Y: v.Y + v.Height}),
+		c.ScreenToWorld(spatial.Vec2{X: v.X + v.Width, Y: v.Y + v.Height}),
+	}
+	region := spatial.Rect{Min: corners[0], Max: corners[0]}
+	for _, p := range corners[1:] {
+		region.Min.X = math.Min(region.Min.X, p.X)
+		region.Min.Y = math.Min(region.Min.Y, p.Y)
+		region.Max.X = math.Max(region.Max.X, p.X)
+		region.Max.Y = math.Max(region.Max.Y, p.Y)
+	}
+	return region
+}
+
+// Find returns the first entity in the registry with a Camera component.
+func Find(r *goecs.Registry) (goecs.Goent, *Camera, bool) {
+	var (
+		found  goecs.Goent
+		cam    *Camera
+		exists bool
+	)
+	goecs.Iterate1(r, func(e goecs.Goent, c *Camera) {
+		if !exists {
+			found, cam, exists = e, c, true
+		}
+	})
+	return found, cam, exists
+}
+
+// QueryVisible calls f for every entity in the index whose bounds intersect
+// the camera's visible region.
+func QueryVisible(cam *Camera, ix *spatial.Index, f func(e goecs.Goent, bounds spatial.Rect)) {
+	ix.Query(cam.VisibleRegion(), f)
+}
@@ -0,0 +1,74 @@
+package camera
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+	"github.com/Swedeachu/go_ecs/goecs/spatial"
+)
+
+func near(a, b spatial.Vec2) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+}
+
+func TestScreenToWorld(t *testing.T) {
+	tests := []struct {
+		name   string
+		cam    Camera
+		screen spatial.Vec2
+		world  spatial.Vec2
+	}{
+		{"center", Camera{Position: spatial.Vec2{X: 5, Y: 5}, Viewport: Viewport{Width: 100, Height: 50}},
+			spatial.Vec2{X: 50, Y: 25}, spatial.Vec2{X: 5, Y: 5}},
+		{"y points up", Camera{Viewport: Viewport{Width: 100, Height: 50}},
+			spatial.Vec2{X: 60, Y: 15}, spatial.Vec2{X: 10, Y: 10}},
+		{"zoom", Camera{Zoom: 2, Viewport: Viewport{X: 10, Width: 100, Height: 50}},
+			spatial.Vec2{X: 80, Y: 25}, spatial.Vec2{X: 10, Y: 0}},
+		{"rotation", Camera{Rotation: math.Pi / 2, Viewport: Viewport{Width: 100, Height: 50}},
+			spatial.Vec2{X: 60, Y: 25}, spatial.Vec2{X: 0, Y: 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cam.ScreenToWorld(tt.screen); !near(got, tt.world) {
+				t.Errorf("ScreenToWorld(%v) = %v, want %v", tt.screen, got, tt.world)
+			}
+			if got := tt.cam.WorldToScreen(tt.world); !near(got, tt.screen) {
+				t.Errorf("WorldToScreen(%v) = %v, want %v", tt.world, got, tt.screen)
+			}
+		})
+	}
+}
+
+func TestQueryVisible(t *testing.T) {
+	r := goecs.NewRegistry()
+	camEntity := r.CreateEntity()
+	goecs.EmplaceComponent(r, camEntity, Camera{Position: spatial.Vec2{X: 10, Y: 10}, Viewport: Viewport{Width: 20, Height: 10}})
+	inside, edge, outside := r.CreateEntity(), r.CreateEntity(), r.CreateEntity()
+	box := func(x, y float64) spatial.Bounds {
+		return spatial.Bounds{Rect: spatial.Rect{Min: spatial.Vec2{X: x, Y: y}, Max: spatial.Vec2{X: x + 1, Y: y + 1}}}
+	}
+	goecs.EmplaceComponent(r, inside, box(10, 10))
+	goecs.EmplaceComponent(r, edge, box(19.5, 14.5))
+	goecs.EmplaceComponent(r, outside, box(30, 10))
+	ix := spatial.NewIndex(4)
+	ix.Rebuild(r)
+
+	found, cam, ok := Find(r)
+	if !ok || found != camEntity {
+		t.Fatalf("Find = %d, %v, want %d", found, ok, camEntity)
+	}
+	if region := cam.VisibleRegion(); !near(region.Min, spatial.Vec2{X: 0, Y: 5}) || !near(region.Max, spatial.Vec2{X: 20, Y: 15}) {
+		t.Errorf("VisibleRegion = %v", region)
+	}
+	var visible []goecs.Goent
+	QueryVisible(cam, ix, func(e goecs.Goent, _ spatial.Rect) { visible = append(visible, e) })
+	slices.Sort(visible)
+	if want := []goecs.Goent{inside, edge}; !slices.Equal(visible, want) {
+		t.Errorf("visible = %v, want %v", visible, want)
+	}
+	if _, _, ok := Find(goecs.NewRegistry()); ok {
+		t.Error("Find reported a camera in an empty registry")
+	}
+}
@@ -0,0 +1,132 @@
+package goecs
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// --- Capacity profiles ---
+// A fresh registry starts with small arrays and grows them as the game fills
+// up, which concentrates allocations and copying in the first minutes of a
+// session. A capacity profile records how big things got: the peak number of
+// components per type and the entity count. Written out at shutdown and
+// passed to NewRegistryWithOptions on the next run, it has every storage
+// sized for the peak the moment it is created:
+//
+//	// at shutdown
+//	r.CapacityProfile().Write(f)
+//
+//	// at startup
+//	profile, err := goecs.ReadCapacityProfile(f)
+//	r := goecs.NewRegistryWithOptions(goecs.RegistryOptions{Capacity: &profile})
+//
+// Types are matched by component name, see RegisterNamedComponent; a type
+// named after its storage was created is sized when the name is registered.
+// Archetype registries record component counts as they are at the time of
+// the call and only pre-size the entity allocator.
+
+// CapacityProfile holds the peak sizes observed in a session.
+type CapacityProfile struct {
+	// Entities is the highest number of entity slots in use at once
+	Entities int `json:"entities"`
+	// Components maps component names to their peak counts
+	Components map[string]int `json:"components"`
+}
+
+// storagePrewarmer is implemented by every SparseSet, for sizing storages
+// from a capacity profile.
+type storagePrewarmer interface {
+	reserve(n int)
+	reserveIndex(size int)
+	peakLen() int
+}
+
+// peakLen returns the most components the storage held at once.
+func (ss *SparseSet[T]) peakLen() int {
+	return ss.peak
+}
+
+// CapacityProfile returns the peak sizes observed since the registry was
+// created.
+func (r *Registry) CapacityProfile() CapacityProfile {
+	p := CapacityProfile{
+		Entities:   len(r.entities.generations),
+		Components: make(map[string]int),
+	}
+	if r.archetypes != nil {
+		for _, a := range r.archetypes.list {
+			for _, t := range a.types {
+				if info, ok := r.componentTypes[t]; ok && len(a.entities) > 0 {
+					p.Components[info.name] += len(a.entities)
+				}
+			}
+		}
+		return p
+	}
+	for t, storage := range r.storages {
+		info, ok := r.componentTypes[t]
+		if !ok {
+			continue
+		}
+		if s, ok := storage.(storagePrewarmer); ok && s.peakLen() > 0 {
+			p.Components[info.name] = s.peakLen()
+		}
+	}
+	return p
+}
+
+// Merge returns the larger of both profiles' sizes per entry, for keeping
+// the peaks of several sessions.
+func (p CapacityProfile) Merge(other CapacityProfile) CapacityProfile {
+	out := CapacityProfile{
+		Entities:   max(p.Entities, other.Entities),
+		Components: make(map[string]int, len(p.Components)),
+	}
+	for name, n := range p.Components {
+		out.Components[name] = n
+	}
+	for name, n := range other.Components {
+		out.Components[name] = max(out.Components[name], n)
+	}
+	return out
+}
+
+// Write encodes the profile as JSON.
+func (p CapacityProfile) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(p)
+}
+
+// ReadCapacityProfile decodes a profile written by CapacityProfile.Write.
+func ReadCapacityProfile(rd io.Reader) (CapacityProfile, error) {
+	var p CapacityProfile
+	err := json.NewDecoder(rd).Decode(&p)
+	return p, err
+}
+
+// applyCapacity pre-sizes the entity allocator from the profile and keeps it
+// for the storages created later.
+func (r *Registry) applyCapacity(p *CapacityProfile) {
+	r.capacity = p
+	if p.Entities > cap(r.entities.generations) {
+		generations := make([]uint32, len(r.entities.generations), p.Entities)
+		copy(generations, r.entities.generations)
+		r.entities.generations = generations
+	}
+}
+
+// prewarm sizes a new storage for its peak in the registry's profile.
+func (r *Registry) prewarm(info *componentInfo) {
+	if r.capacity == nil {
+		return
+	}
+	n := r.capacity.Components[info.name]
+	if n <= 0 {
+		return
+	}
+	s, ok := r.storages[info.typ].(storagePrewarmer)
+	if !ok {
+		return
+	}
+	s.reserve(n)
+	s.reserveIndex(r.capacity.Entities)
+}
@@ -0,0 +1,65 @@
+package goecs
+
+import (
+	"bytes"
+	"maps"
+	"testing"
+)
+
+type capPos struct{ X int }
+type capLoot struct{ Gold int }
+
+func TestCapacityProfile(t *testing.T) {
+	session := NewRegistry()
+	RegisterNamedComponent[capPos](session, "pos")
+	entities := session.CreateEntities(50)
+	for _, e := range entities {
+		EmplaceComponent(session, e, capPos{})
+	}
+	EmplaceComponent(session, entities[0], capLoot{})
+	// the peak survives the entities going away
+	session.DestroyEntities(entities[10:])
+
+	p := session.CapacityProfile()
+	want := map[string]int{"pos": 50, ComponentType[capLoot]().String(): 1}
+	if p.Entities != 50 || !maps.Equal(p.Components, want) {
+		t.Errorf("profile = %+v, want 50 entities and %v", p, want)
+	}
+	var buf bytes.Buffer
+	if err := p.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadCapacityProfile(&buf)
+	if err != nil || read.Entities != p.Entities || !maps.Equal(read.Components, p.Components) {
+		t.Errorf("ReadCapacityProfile = %+v, %v", read, err)
+	}
+
+	merged := read.Merge(CapacityProfile{Entities: 20, Components: map[string]int{"pos": 80, "loot": 5}})
+	if want := map[string]int{"pos": 80, "loot": 5, ComponentType[capLoot]().String(): 1}; merged.Entities != 50 || !maps.Equal(merged.Components, want) {
+		t.Errorf("Merge = %+v", merged)
+	}
+
+	r := NewRegistryWithOptions(RegistryOptions{Capacity: &merged})
+	if cap(r.entities.generations) < 50 {
+		t.Errorf("entity allocator holds %d, want at least 50", cap(r.entities.generations))
+	}
+	// the storage is sized the moment it is created, named types once named
+	RegisterNamedComponent[capPos](r, "pos")
+	e := r.CreateEntity()
+	EmplaceComponent(r, e, capPos{})
+	EmplaceComponent(r, e, capLoot{})
+	RegisterNamedComponent[capLoot](r, "loot")
+	tests := []struct {
+		name string
+		s    SparseSetInterface
+		want int
+	}{
+		{"pos", getStorage[capPos](r), 80},
+		{"loot", getStorage[capLoot](r), 5},
+	}
+	for _, tt := range tests {
+		if c := cap(tt.s.GetDense()); c < tt.want {
+			t.Errorf("%s storage holds %d, want at least %d", tt.name, c, tt.want)
+		}
+	}
+}
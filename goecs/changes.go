@@ -0,0 +1,144 @@
+package goecs
+
+import (
+	"reflect"
+)
+
+// --- Change detection ---
+// Every component slot remembers the registry tick it was last changed at.
+// EmplaceComponent and Patch stamp it, code writing through a component
+// pointer calls MarkChanged. A system iterates with Changed[T](since) to skip
+// untouched entities, where since is what Checkpoint returned at the end of
+// its previous run:
+//
+//	Iterate1(r, syncTransform, Changed[Transform](lastRun))
+//	lastRun = r.Checkpoint()
+//
+// Checkpoint moves the clock on, so a change made after it, by a later system
+// of the same frame or between frames, is newer than the tick it returned and
+// is reported exactly once. Changes a system makes during its own run carry
+// that tick and aren't reported back to it. Ticks start at 1, so a lastRun of
+// 0 sees everything emplaced before the first run.
+//
+// World.Update checkpoints after every system and hands each its since
+// through World.LastRun, Scheduler.Run does the same per stage through
+// SystemQueue.LastRun. World.Update also calls AdvanceTick at the start of
+// every frame for group maintenance; code driving a registry directly calls
+// both itself.
+
+// Tick returns the current change detection tick.
+func (r *Registry) Tick() uint64 {
+	return r.tick
+}
+
+// AdvanceTick moves the registry to the next tick and returns it. Changes
+// made from now on are newer than every tick returned before. Groups run
+// their periodic maintenance, see SortSpatial.
+func (r *Registry) AdvanceTick() uint64 {
+	r.tick++
+	for _, g := range r.groups {
+		g.maintain(r.tick)
+	}
+	return r.tick
+}
+
+// Checkpoint returns the current tick and moves the clock past it, so every
+// change made from now on is newer than the returned tick. Unlike AdvanceTick
+// it doesn't run group maintenance, so it can be called after every system.
+func (r *Registry) Checkpoint() uint64 {
+	tick := r.tick
+	r.tick++
+	return tick
+}
+
+// now returns the tick new changes are stamped with, 0 for a storage that
+// doesn't belong to a registry.
+func (ss *SparseSet[T]) now() uint64 {
+	if ss.clock == nil {
+		return 0
+	}
+	return *ss.clock
+}
+
+// ChangeTick returns the tick the entity's component was last changed at.
+func (ss *SparseSet[T]) ChangeTick(entity Goent) (uint64, bool) {
+	i := ss.slot(entity)
+	if i == invalidIndex {
+		return 0, false
+	}
+	return ss.ticks[i], true
+}
+
+// MarkChanged stamps the entity's component with the current tick.
+func (ss *SparseSet[T]) MarkChanged(entity Goent) {
+	if i := ss.slot(entity); i != invalidIndex {
+		ss.ticks[i] = ss.now()
+	}
+}
+
+// changeTracker is the type-erased side of SparseSet change ticks.
+type changeTracker interface {
+	ChangeTick(entity Goent) (uint64, bool)
+}
+
+// changeTickOf looks a change tick up by type on either backend.
+func (r *Registry) changeTickOf(entity Goent, t reflect.Type) (uint64, bool) {
+	if r.archetypes != nil {
+		tick, ok := r.archetypes.ticks[t][entity]
+		return tick, ok
+	}
+	storage, ok := r.storages[t]
+	if !ok {
+		return 0, false
+	}
+	return storage.(changeTracker).ChangeTick(entity)
+}
+
+// ChangeTick returns the tick the entity's T component was last changed at.
+func ChangeTick[T any](r *Registry, entity Goent) (uint64, bool) {
+	return r.changeTickOf(entity, typeKeyFor[T]())
+}
+
+// MarkChanged stamps the entity's T component with the current tick, for
+// writes made through the component pointer.
+func MarkChanged[T any](r *Registry, entity Goent) {
+	if r.archetypes != nil {
+		if _, ok := archGet[T](r.archetypes, entity); ok {
+			r.archetypes.touch(entity, typeKeyFor[T]())
+		}
+		return
+	}
+	if storage := getStorage[T](r); storage != nil {
+		storage.MarkChanged(entity)
+	}
+}
+
+// Changed only visits entities whose T component changed after the tick
+// since. Entities without a T component are skipped.
+func Changed[T any](since uint64) Filter {
+	return Filter{changed: typeKeyFor[T](), since: since}
+}
+
+// --- Change equality ---
+// By default every Emplace counts as a change, even one writing the value
+// the component already holds, which wakes up Changed filters, OnUpdate
+// hooks and replication for nothing. Registering an equality for a type
+// makes EmplaceComponent and Patch skip writes that don't change anything.
+
+// RegisterEqual makes eq decide whether a write of T changes the component.
+func RegisterEqual[T any](r *Registry, eq func(a, b T) bool) {
+	r.equals[typeKeyFor[T]()] = eq
+}
+
+// RegisterComparable registers == as the equality of T, see RegisterEqual.
+func RegisterComparable[T comparable](r *Registry) {
+	RegisterEqual(r, func(a, b T) bool { return a == b })
+}
+
+// equalFor returns the equality registered for T, or nil.
+func equalFor[T any](r *Registry) func(a, b T) bool {
+	if eq, ok := r.equals[typeKeyFor[T]()]; ok {
+		return eq.(func(a, b T) bool)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package goecs
+
+import (
+	"slices"
+	"testing"
+)
+
+type changeProbe struct {
+	V int
+}
+
+func TestChangedAcrossSystems(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup runs before the first frame
+		setup func(r *Registry, es []Goent)
+		// before and after write in the systems around the observer, per frame
+		before, after func(r *Registry, es []Goent, frame int)
+		// want lists the entity numbers the observer sees per frame
+		want [][]int
+	}{
+		{
+			name:  "emplaced before the first frame",
+			setup: func(r *Registry, es []Goent) { EmplaceComponent(r, es[0], changeProbe{}) },
+			want:  [][]int{{0}, nil},
+		},
+		{
+			name: "written later in the same frame",
+			after: func(r *Registry, es []Goent, frame int) {
+				if frame == 0 {
+					EmplaceComponent(r, es[1], changeProbe{V: 1})
+				}
+			},
+			want: [][]int{nil, {1}, nil},
+		},
+		{
+			name: "written earlier in the same frame",
+			before: func(r *Registry, es []Goent, frame int) {
+				if frame == 1 {
+					EmplaceComponent(r, es[2], changeProbe{V: 2})
+				}
+			},
+			want: [][]int{nil, {2}, nil},
+		},
+		{
+			name:  "marked through the pointer",
+			setup: func(r *Registry, es []Goent) { EmplaceComponent(r, es[0], changeProbe{}) },
+			after: func(r *Registry, es []Goent, frame int) {
+				if frame == 1 {
+					c, _ := GetComponent[changeProbe](r, es[0])
+					c.V++
+					MarkChanged[changeProbe](r, es[0])
+				}
+			},
+			want: [][]int{{0}, nil, {0}, nil},
+		},
+	}
+	for _, storage := range []StorageMode{SparseSetStorage, ArchetypeStorage} {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := NewWorldWithRegistry(NewRegistryWithOptions(RegistryOptions{Storage: storage}))
+				es := w.Registry.CreateEntities(3)
+				if tt.setup != nil {
+					tt.setup(w.Registry, es)
+				}
+				frame := 0
+				var seen []int
+				w.AddSystem(SystemFunc(func(w *World, dt float64) {
+					if tt.before != nil {
+						tt.before(w.Registry, es, frame)
+					}
+				}))
+				w.AddSystem(SystemFunc(func(w *World, dt float64) {
+					Iterate1(w.Registry, func(e Goent, c *changeProbe) {
+						seen = append(seen, slices.Index(es, e))
+					}, Changed[changeProbe](w.LastRun()))
+				}))
+				w.AddSystem(SystemFunc(func(w *World, dt float64) {
+					if tt.after != nil {
+						tt.after(w.Registry, es, frame)
+					}
+				}))
+				for ; frame < len(tt.want); frame++ {
+					seen = nil
+					w.Update(1)
+					slices.Sort(seen)
+					if !slices.Equal(seen, tt.want[frame]) {
+						t.Errorf("storage %v frame %d: saw %v, want %v", storage, frame, seen, tt.want[frame])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestChangedOwnWritesNotReported(t *testing.T) {
+	r := NewRegistry()
+	e := r.CreateEntity()
+	EmplaceComponent(r, e, changeProbe{})
+	var lastRun uint64
+	for run := 0; run < 3; run++ {
+		n := 0
+		Iterate1(r, func(e Goent, c *changeProbe) {
+			n++
+			EmplaceComponent(r, e, changeProbe{V: c.V + 1})
+		}, Changed[changeProbe](lastRun))
+		lastRun = r.Checkpoint()
+		if want := map[bool]int{true: 1, false: 0}[run == 0]; n != want {
+			t.Errorf("run %d saw %d entities, want %d", run, n, want)
+		}
+	}
+}
+
+type equalPath struct {
+	Waypoints []int
+	Cost      int
+}
+
+func TestRegisterEqual(t *testing.T) {
+	r := NewRegistry()
+	RegisterEqual(r, func(a, b equalPath) bool { return a.Cost == b.Cost && slices.Equal(a.Waypoints, b.Waypoints) })
+	RegisterComparable[changeProbe](r)
+	updates := 0
+	OnUpdate(r, func(e Goent, _ *equalPath) { updates++ })
+	e := r.CreateEntity()
+	EmplaceComponent(r, e, equalPath{Waypoints: []int{1, 2}, Cost: 3})
+	EmplaceComponent(r, e, changeProbe{V: 1})
+	r.AdvanceTick()
+	since := r.Tick()
+	r.AdvanceTick()
+
+	tests := []struct {
+		name    string
+		write   func()
+		changed bool
+	}{
+		{"equal emplace", func() { EmplaceComponent(r, e, equalPath{Waypoints: []int{1, 2}, Cost: 3}) }, false},
+		{"equal patch", func() { Patch[equalPath](r, e, "Waypoints", []int{1, 2}) }, false},
+		{"comparable emplace", func() { EmplaceComponent(r, e, changeProbe{V: 1}) }, false},
+		{"different emplace", func() { EmplaceComponent(r, e, equalPath{Waypoints: []int{1}, Cost: 3}) }, true},
+	}
+	for _, tt := range tests {
+		tt.write()
+		tick, _ := ChangeTick[equalPath](r, e)
+		probeTick, _ := ChangeTick[changeProbe](r, e)
+		if changed := tick > since || probeTick > since; changed != tt.changed {
+			t.Errorf("%s: changed = %v, want %v", tt.name, changed, tt.changed)
+		}
+	}
+	if updates != 1 {
+		t.Errorf("OnUpdate fired %d times, want once", updates)
+	}
+	if DirtyFields[equalPath](r, e) != 0 {
+		t.Errorf("equal patch flagged fields %b", DirtyFields[equalPath](r, e))
+	}
+}
@@ -0,0 +1,283 @@
+package goecs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// --- Entity cloning ---
+
+// CloneEntity creates a new entity carrying deep copies of every component of
+// src, emplaced in restore order (see RestoreAfter). References to src inside
+// the copies are pointed at the clone, other entity references are kept. The
+// clone is attached to the parent of src but gets none of its children, and
+// gets a copy of every relationship edge of src. It panics if src was
+// destroyed.
+func (r *Registry) CloneEntity(src Goent) Goent {
+	r.assertWritable()
+	if r.isStale(src) {
+		panic(fmt.Sprintf("goecs: cloning destroyed entity %d", src))
+	}
+	type copied struct {
+		info  *componentInfo
+		value reflect.Value
+	}
+	// copy everything before emplacing, which may move the source components
+	var comps []copied
+	for _, info := range r.restoreOrder() {
+		if isHierarchyType(info.typ) {
+			continue
+		}
+		if comp, ok := r.componentOf(src, info.typ); ok {
+			comps = append(comps, copied{info: info, value: deepCopy(reflect.ValueOf(comp).Elem())})
+		}
+	}
+
+	clone := r.CreateEntity()
+	remap := map[Goent]Goent{src: clone}
+	for _, c := range comps {
+		remapValue(c.value, remap)
+		c.info.emplace(r, clone, c.value)
+	}
+	if parent, ok := GetParent(r, src); ok {
+		SetParent(r, clone, parent)
+	}
+	r.copyRelations(r, remap, true)
+	return clone
+}
+
+// isHierarchyType reports whether t is Parent or Children, which copies must
+// rebuild with SetParent instead of copying.
+func isHierarchyType(t reflect.Type) bool {
+	return t == typeKeyFor[Parent]() || t == typeKeyFor[Children]()
+}
+
+// deepCopy returns a settable copy of v that shares no slices, maps or
+// pointers with it. Unexported fields, channels and functions are copied
+// shallowly.
+func deepCopy(v reflect.Value) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	copyInto(out, v, make(map[copiedPointer]reflect.Value))
+	return out
+}
+
+type copiedPointer struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// copyInto deep copies src into the settable dst. seen maps the pointers
+// copied so far to their copies, so shared and cyclic pointers stay so.
+func copyInto(dst, src reflect.Value, seen map[copiedPointer]reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		key := copiedPointer{addr: src.Pointer(), typ: src.Type()}
+		if p, ok := seen[key]; ok {
+			dst.Set(p)
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		seen[key] = p
+		copyInto(p.Elem(), src.Elem(), seen)
+		dst.Set(p)
+	case reflect.Struct:
+		dst.Set(src)
+		t := src.Type()
+		for i := 0; i < src.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				copyInto(dst.Field(i), src.Field(i), seen)
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			copyInto(dst.Index(i), src.Index(i), seen)
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			copyInto(dst.Index(i), src.Index(i), seen)
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		iter := src.MapRange()
+		for iter.Next() {
+			key := reflect.New(src.Type().Key()).Elem()
+			copyInto(key, iter.Key(), seen)
+			value := reflect.New(src.Type().Elem()).Elem()
+			copyInto(value, iter.Value(), seen)
+			dst.SetMapIndex(key, value)
+		}
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		value := reflect.New(src.Elem().Type()).Elem()
+		copyInto(value, src.Elem(), seen)
+		dst.Set(value)
+	default:
+		dst.Set(src)
+	}
+}
+
+// CopyEntity copies e with all its components from src into a new entity of
+// dst and returns it, see CopyEntities.
+func CopyEntity(dst, src *Registry, e Goent) (Goent, error) {
+	remap, err := CopyEntities(dst, src, []Goent{e})
+	return remap[e], err
+}
+
+// ErrKeyConflict is returned when a copied entity's alias or name is already
+// held by an entity of the destination, or a copied component type's name by
+// another type of the destination.
+var ErrKeyConflict = errors.New("goecs: copied alias or name is taken")
+
+// CopyEntities copies entities with deep copies of all their components from
+// src into new entities of dst, such as from a staging registry into the live
+// world. The returned table maps each copied entity to its new ID; Goent
+// references between the copied entities are rewritten through it, and
+// aliases, names and annotations move along. Parent links and relationship
+// edges are kept between copied entities only; a copy whose parent wasn't
+// copied becomes a root.
+// Destroyed entities are skipped. Types src gave a name with
+// RegisterNamedComponent get the same name in dst. If an alias or name of a
+// copied entity, or the name of a component type, is taken in dst, it fails
+// with ErrKeyConflict before copying anything.
+func CopyEntities(dst, src *Registry, entities []Goent) (map[Goent]Goent, error) {
+	return transferEntities(dst, src, entities, func(Goent) Goent {
+		return dst.CreateEntity()
+	})
+}
+
+// transferEntities copies entities from src into dst under the IDs place
+// hands out for them. Key conflicts are checked before place is called.
+func transferEntities(dst, src *Registry, entities []Goent, place func(entity Goent) Goent) (map[Goent]Goent, error) {
+	dst.assertWritable()
+	type copied struct {
+		info   *componentInfo
+		entity Goent
+		value  reflect.Value
+	}
+	kept := make([]Goent, 0, len(entities))
+	seen := make(map[Goent]struct{}, len(entities))
+	for _, entity := range entities {
+		if src.isStale(entity) {
+			continue
+		}
+		if _, dup := seen[entity]; dup {
+			continue
+		}
+		seen[entity] = struct{}{}
+		kept = append(kept, entity)
+	}
+	if err := typeConflict(dst, src); err != nil {
+		return nil, err
+	}
+	for _, entity := range kept {
+		if err := keyConflict(dst, src, entity); err != nil {
+			return nil, err
+		}
+	}
+	remap := make(map[Goent]Goent, len(kept))
+	for _, entity := range kept {
+		remap[entity] = place(entity)
+	}
+	var comps []copied
+	for _, info := range src.restoreOrder() {
+		if isHierarchyType(info.typ) {
+			continue
+		}
+		for _, entity := range kept {
+			if comp, ok := src.componentOf(entity, info.typ); ok {
+				comps = append(comps, copied{info: info, entity: entity, value: deepCopy(reflect.ValueOf(comp).Elem())})
+			}
+		}
+	}
+
+	for _, info := range src.restoreOrder() {
+		if info.named {
+			info.registerNamed(dst, info.name)
+		}
+	}
+	for _, c := range comps {
+		remapValue(c.value, remap)
+		c.info.emplace(dst, remap[c.entity], c.value)
+	}
+	// relink in each parent's attach order
+	for _, parent := range kept {
+		for _, child := range GetChildren(src, parent) {
+			if created, ok := remap[child]; ok {
+				SetParent(dst, created, remap[parent])
+			}
+		}
+	}
+	src.copyRelations(dst, remap, false)
+	for entity, created := range remap {
+		if key, ok := src.stringAliases.keyOf(entity); ok {
+			dst.stringAliases.set(created, key)
+		}
+		if name, ok := src.names.keyOf(entity); ok {
+			dst.names.set(created, name)
+		}
+		if key, ok := src.idAliases.keyOf(entity); ok {
+			dst.idAliases.set(created, key)
+		}
+		if notes, ok := src.annotations[entity]; ok {
+			dst.annotations[created] = copyAnnotations(notes)
+		}
+	}
+	return remap, nil
+}
+
+// typeConflict returns an error wrapping ErrKeyConflict if a component type
+// of src can't be registered in dst under its src name, because dst holds
+// that name for another type or named the type differently.
+func typeConflict(dst, src *Registry) error {
+	for _, info := range src.restoreOrder() {
+		if isHierarchyType(info.typ) {
+			continue
+		}
+		if !info.named {
+			// unnamed types never clash, dst leaves them out of its name table
+			continue
+		}
+		own, known := dst.componentTypes[info.typ]
+		if known && own.named && own.name != info.name {
+			return fmt.Errorf("%w: %s is named %q, not %q", ErrKeyConflict, info.typ, own.name, info.name)
+		}
+		if other, taken := dst.componentNames[info.name]; taken && other.typ != info.typ {
+			return fmt.Errorf("%w: component name %q of %s belongs to %s", ErrKeyConflict, info.name, info.typ, other.typ)
+		}
+	}
+	return nil
+}
+
+// keyConflict returns an error wrapping ErrKeyConflict if an alias or name
+// of the src entity is held by an entity of dst.
+func keyConflict(dst, src *Registry, entity Goent) error {
+	if key, ok := src.stringAliases.keyOf(entity); ok {
+		if owner, taken := dst.stringAliases.lookup(key); taken {
+			return fmt.Errorf("%w: alias %q of entity %d belongs to %d", ErrKeyConflict, key, entity, owner)
+		}
+	}
+	if name, ok := src.names.keyOf(entity); ok {
+		if owner, taken := dst.names.lookup(name); taken {
+			return fmt.Errorf("%w: name %q of entity %d belongs to %d", ErrKeyConflict, name, entity, owner)
+		}
+	}
+	if key, ok := src.idAliases.keyOf(entity); ok {
+		if owner, taken := dst.idAliases.lookup(key); taken {
+			return fmt.Errorf("%w: alias ID %d of entity %d belongs to %d", ErrKeyConflict, key, entity, owner)
+		}
+	}
+	return nil
+}
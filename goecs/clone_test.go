@@ -0,0 +1,158 @@
+package goecs
+
+import (
+	"errors"
+	"testing"
+)
+
+type cloneProbe struct {
+	V int
+}
+
+func TestCopyEntitiesKeyConflict(t *testing.T) {
+	tests := []struct {
+		name string
+		bind func(r *Registry, e Goent)
+	}{
+		{"alias", func(r *Registry, e Goent) { r.SetAlias(e, "door") }},
+		{"name", func(r *Registry, e Goent) { r.SetName(e, "door") }},
+		{"alias ID", func(r *Registry, e Goent) { r.SetAliasID(e, 7) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := NewRegistry()
+			free, taken := src.CreateEntity(), src.CreateEntity()
+			EmplaceComponent(src, free, cloneProbe{V: 1})
+			EmplaceComponent(src, taken, cloneProbe{V: 2})
+			tt.bind(src, taken)
+
+			dst := NewRegistry()
+			owner := dst.CreateEntity()
+			tt.bind(dst, owner)
+
+			ids, err := CopyEntities(dst, src, []Goent{free, taken})
+			if !errors.Is(err, ErrKeyConflict) || ids != nil {
+				t.Fatalf("CopyEntities = %v, %v, want ErrKeyConflict", ids, err)
+			}
+			if n := Count[cloneProbe](dst); n != 0 || dst.EntityCount() != 1 {
+				t.Errorf("failed copy left %d components and %d entities", n, dst.EntityCount())
+			}
+			if _, err := dst.Merge(src, MergeRemap); !errors.Is(err, ErrKeyConflict) {
+				t.Errorf("Merge = %v, want ErrKeyConflict", err)
+			}
+			if n := dst.EntityCount(); n != 1 {
+				t.Errorf("failed merge left %d entities", n)
+			}
+			// once the owner is gone the key can be copied
+			tt.bind(src, free)
+			dst.DestroyEntity(owner)
+			ids, err = CopyEntities(dst, src, []Goent{free})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c, _ := GetComponent[cloneProbe](dst, ids[free]); c == nil || c.V != 1 {
+				t.Errorf("copy holds %v, want 1", c)
+			}
+		})
+	}
+}
+
+type cloneOther struct {
+	V int
+}
+
+func TestCopyEntitiesTypeNameConflict(t *testing.T) {
+	src := NewRegistry()
+	RegisterNamedComponent[cloneProbe](src, "x")
+	e := src.CreateEntity()
+	EmplaceComponent(src, e, cloneProbe{V: 1})
+
+	dst := NewRegistry()
+	RegisterNamedComponent[cloneOther](dst, "x")
+	ids, err := CopyEntities(dst, src, []Goent{e})
+	if !errors.Is(err, ErrKeyConflict) || ids != nil {
+		t.Fatalf("CopyEntities = %v, %v, want ErrKeyConflict", ids, err)
+	}
+	if n := dst.EntityCount(); n != 0 {
+		t.Errorf("failed copy left %d entities", n)
+	}
+
+	// the same type under another name clashes as well
+	renamed := NewRegistry()
+	RegisterNamedComponent[cloneProbe](renamed, "y")
+	if _, err := CopyEntities(renamed, src, []Goent{e}); !errors.Is(err, ErrKeyConflict) {
+		t.Errorf("CopyEntities into a renamed type = %v, want ErrKeyConflict", err)
+	}
+	if n := renamed.EntityCount(); n != 0 {
+		t.Errorf("failed copy left %d entities", n)
+	}
+}
+
+func TestCopyEntityKeys(t *testing.T) {
+	src := NewRegistry()
+	e := src.CreateEntity()
+	EmplaceComponent(src, e, cloneProbe{V: 3})
+	src.SetAlias(e, "chest")
+	src.SetName(e, "loot")
+	src.SetAliasID(e, 42)
+
+	dst := NewRegistry()
+	copied, err := CopyEntity(dst, src, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := dst.LookupAlias("chest"); got != copied {
+		t.Errorf("alias bound to %d, want %d", got, copied)
+	}
+	if got, _ := dst.FindByName("loot"); got != copied {
+		t.Errorf("name bound to %d, want %d", got, copied)
+	}
+	if got, _ := dst.LookupAliasID(42); got != copied {
+		t.Errorf("alias ID bound to %d, want %d", got, copied)
+	}
+}
+
+type cloneInventory struct {
+	Items  []string
+	Owner  Goent
+	Target Goent
+	Stats  map[string]*int
+}
+
+func TestCloneEntity(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			src, other := r.CreateEntity(), r.CreateEntity()
+			level := 3
+			EmplaceComponent(r, src, cloneProbe{V: 1})
+			EmplaceComponent(r, src, cloneInventory{Items: []string{"sword"}, Owner: src, Target: other, Stats: map[string]*int{"level": &level}})
+
+			clone := r.CloneEntity(src)
+			if clone == src || !r.IsAlive(clone) {
+				t.Fatalf("clone = %d", clone)
+			}
+			if p, _ := GetComponent[cloneProbe](r, clone); p == nil || p.V != 1 {
+				t.Errorf("cloned probe = %v", p)
+			}
+			inv, _ := GetComponent[cloneInventory](r, clone)
+			if inv == nil || inv.Owner != clone || inv.Target != other {
+				t.Fatalf("cloned references = %+v, want owner %d and target %d", inv, clone, other)
+			}
+			inv.Items[0] = "bow"
+			*inv.Stats["level"] = 9
+			orig, _ := GetComponent[cloneInventory](r, src)
+			if orig.Items[0] != "sword" || level != 3 {
+				t.Error("the clone shares memory with its source")
+			}
+
+			r.DestroyEntity(other)
+			defer func() {
+				if recover() == nil {
+					t.Error("cloning a destroyed entity didn't panic")
+				}
+			}()
+			r.CloneEntity(other)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package goecs
+
+// --- Command buffers ---
+// Emplacing or removing components inside an Iterate callback moves entries
+// in the dense arrays being walked. A CommandBuffer records such structural
+// changes instead, and Flush applies them once iteration is done:
+//
+//	var cb CommandBuffer
+//	Iterate1(r, func(e Goent, h *Health) {
+//		if h.HP <= 0 {
+//			cb.Destroy(e)
+//		}
+//	})
+//	cb.Flush(r)
+
+type queuedCommand struct {
+	entity Goent
+	apply  func(r *Registry)
+}
+
+// CommandBuffer records structural changes to apply later, in order. The zero
+// value is ready to use.
+type CommandBuffer struct {
+	commands []queuedCommand
+}
+
+// NewCommandBuffer creates an empty command buffer.
+func NewCommandBuffer() *CommandBuffer {
+	return &CommandBuffer{}
+}
+
+// Defer records an arbitrary change concerning the entity. It is skipped on
+// flush if the entity was destroyed by then.
+func (cb *CommandBuffer) Defer(entity Goent, apply func(r *Registry)) {
+	cb.commands = append(cb.commands, queuedCommand{entity: entity, apply: apply})
+}
+
+// DeferEmplace records an EmplaceComponent.
+func DeferEmplace[T any](cb *CommandBuffer, entity Goent, comp T) {
+	cb.Defer(entity, func(r *Registry) {
+		EmplaceComponent(r, entity, comp)
+	})
+}
+
+// DeferRemove records a RemoveComponent.
+func DeferRemove[T any](cb *CommandBuffer, entity Goent) {
+	cb.Defer(entity, func(r *Registry) {
+		RemoveComponent[T](r, entity)
+	})
+}
+
+// Destroy records a DestroyEntity. Commands recorded for the entity after it
+// are dropped on flush, since its handle is stale by then.
+func (cb *CommandBuffer) Destroy(entity Goent) {
+	cb.Defer(entity, func(r *Registry) {
+		r.DestroyEntity(entity)
+	})
+}
+
+// Append moves the commands of other to the end of cb, leaving other empty.
+func (cb *CommandBuffer) Append(other *CommandBuffer) {
+	cb.commands = append(cb.commands, other.commands...)
+	other.Reset()
+}
+
+// Len returns the number of recorded commands.
+func (cb *CommandBuffer) Len() int {
+	return len(cb.commands)
+}
+
+// Flush applies the recorded commands in order and empties the buffer.
+// Commands for entities destroyed by the time they come up are skipped.
+func (cb *CommandBuffer) Flush(r *Registry) {
+	for _, cmd := range cb.commands {
+		if r.isStale(cmd.entity) {
+			continue
+		}
+		cmd.apply(r)
+	}
+	cb.Reset()
+}
+
+// Reset drops the recorded commands without applying them.
+func (cb *CommandBuffer) Reset() {
+	for i := range cb.commands {
+		cb.commands[i] = queuedCommand{}
+	}
+	cb.commands = cb.commands[:0]
+}
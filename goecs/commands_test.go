@@ -0,0 +1,100 @@
+package goecs
+
+import "testing"
+
+type cmdHealth struct {
+	HP int
+}
+
+type cmdLoot struct {
+	Gold int
+}
+
+func TestCommandBuffer(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(5)
+	for i, e := range entities {
+		EmplaceComponent(r, e, cmdHealth{HP: i - 2})
+		EmplaceComponent(r, e, cmdLoot{Gold: i})
+	}
+
+	var cb CommandBuffer
+	Iterate1(r, func(e Goent, h *cmdHealth) {
+		switch {
+		case h.HP < 0:
+			cb.Destroy(e)
+			// dropped, the handle is stale once the entity is destroyed
+			DeferEmplace(&cb, e, cmdLoot{Gold: 100})
+		case h.HP == 0:
+			DeferRemove[cmdLoot](&cb, e)
+		default:
+			DeferEmplace(&cb, e, cmdLoot{Gold: 10 * h.HP})
+		}
+	})
+	if cb.Len() != 7 || Count[cmdHealth](r) != 5 {
+		t.Fatalf("recorded %d commands with %d entities left, want 7 and 5", cb.Len(), Count[cmdHealth](r))
+	}
+	cb.Flush(r)
+	if cb.Len() != 0 {
+		t.Errorf("%d commands left after Flush", cb.Len())
+	}
+	for i, e := range entities {
+		loot, ok := GetComponent[cmdLoot](r, e)
+		switch {
+		case i < 2:
+			if r.IsAlive(e) {
+				t.Errorf("entity %d survived", i)
+			}
+		case i == 2:
+			if ok {
+				t.Errorf("entity %d kept its loot", i)
+			}
+		default:
+			if !ok || loot.Gold != 10*(i-2) {
+				t.Errorf("entity %d has loot %v, want %d", i, loot, 10*(i-2))
+			}
+		}
+	}
+}
+
+func TestCommandBufferAppendReset(t *testing.T) {
+	r := NewRegistry()
+	e := r.CreateEntity()
+	a, b := NewCommandBuffer(), NewCommandBuffer()
+	DeferEmplace(a, e, cmdHealth{HP: 1})
+	DeferEmplace(b, e, cmdHealth{HP: 2})
+	a.Append(b)
+	if a.Len() != 2 || b.Len() != 0 {
+		t.Fatalf("Append left %d and %d commands, want 2 and 0", a.Len(), b.Len())
+	}
+	a.Flush(r)
+	if h, _ := GetComponent[cmdHealth](r, e); h == nil || h.HP != 2 {
+		t.Errorf("health = %v, want the later command's 2", h)
+	}
+	a.Defer(e, func(r *Registry) { t.Error("reset command was applied") })
+	a.Reset()
+	a.Flush(r)
+}
+
+func TestCommandBufferSkipsDestroyed(t *testing.T) {
+	r := NewRegistry()
+	e, other := r.CreateEntity(), r.CreateEntity()
+	var cb CommandBuffer
+	ran := 0
+	cb.Destroy(e)
+	cb.Defer(e, func(*Registry) { t.Error("command for a destroyed entity ran") })
+	cb.Defer(other, func(*Registry) { ran++ })
+	cb.Flush(r)
+	if ran != 1 || r.IsAlive(e) {
+		t.Errorf("live entity's command ran %d times, destroyed entity alive %v", ran, r.IsAlive(e))
+	}
+
+	// a handle already stale when recorded is skipped too, even after its
+	// index was reused
+	reused := r.CreateEntity()
+	cb.Defer(e, func(*Registry) { t.Error("command for a stale handle ran") })
+	cb.Flush(r)
+	if !r.IsAlive(reused) {
+		t.Error("entity reusing the index was touched")
+	}
+}
@@ -0,0 +1,51 @@
+package goecs
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// --- Concurrent read access ---
+//
+// A registry is not safe for concurrent mutation, but any number of goroutines
+// may read it at the same time as long as nothing writes. Reads never mutate
+// lazily or fill caches behind the caller's back, so the following are safe
+// to call concurrently with each other:
+//
+//	GetComponent, IsAlive, Iterate1 through Iterate8, IterateReflective,
+//	the Each, Len and Contains methods of views and groups, GroupBy
+//
+// Creating a view registers missing storages and counts as a write, so views
+// used during a parallel phase must be created up front. Writing through a
+// component pointer handed to a callback is a write to that component type
+// only; readers of other types are unaffected, keeping systems with disjoint
+// write sets safe to run together. Patch, MarkDirty and MarkChanged likewise
+// only touch the bookkeeping of their own component type.
+
+// ParallelRead runs the functions concurrently and waits for all of them.
+// While they run, the registry rejects structural changes (creating or
+// destroying entities, emplacing or removing components) with a panic, which
+// turns a broken read-only contract into an immediate error rather than a
+// data race.
+func (r *Registry) ParallelRead(fns ...func(r *Registry)) {
+	atomic.AddInt32(&r.readers, 1)
+	defer atomic.AddInt32(&r.readers, -1)
+
+	var wg sync.WaitGroup
+	wg.Add(len(fns))
+	for _, fn := range fns {
+		go func(fn func(r *Registry)) {
+			defer wg.Done()
+			fn(r)
+		}(fn)
+	}
+	wg.Wait()
+}
+
+// assertWritable panics if the registry is inside a parallel read phase,
+// with an error wrapping ErrStorageLocked.
+func (r *Registry) assertWritable() {
+	if err := r.checkWritable(); err != nil {
+		panic(err)
+	}
+}
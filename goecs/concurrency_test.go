@@ -0,0 +1,183 @@
+package goecs
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type readPos struct {
+	X float64
+}
+
+type readVel struct {
+	X float64
+}
+
+type readTeam struct {
+	ID int
+}
+
+func newReadWorld(n int) (*Registry, []Goent) {
+	r := NewRegistry()
+	entities := r.CreateEntities(n)
+	for i, e := range entities {
+		EmplaceComponent(r, e, readPos{X: float64(i)})
+		if i%2 == 0 {
+			EmplaceComponent(r, e, readVel{X: 1})
+		}
+		if i%3 == 0 {
+			EmplaceComponent(r, e, readTeam{ID: i % 4})
+		}
+	}
+	return r, entities
+}
+
+// TestParallelReads runs every documented read concurrently and checks the
+// readers agree. Run with -race to catch hidden writes on the read path.
+func TestParallelReads(t *testing.T) {
+	r, entities := newReadWorld(300)
+	r.Disable(entities[0])
+	view := NewView2[readPos, readVel](r)
+	const want = 149 // even entities minus the disabled one
+
+	reads := map[string]func(r *Registry) int{
+		"View2.Each": func(r *Registry) int {
+			n := 0
+			view.Each(func(Goent, *readPos, *readVel) { n++ })
+			return n
+		},
+		"View2.Len": func(r *Registry) int { return view.Len() },
+		"View2.Contains": func(r *Registry) int {
+			n := 0
+			for _, e := range entities {
+				if view.Contains(e) {
+					n++
+				}
+			}
+			return n
+		},
+		"Iterate2": func(r *Registry) int {
+			n := 0
+			Iterate2(r, func(Goent, *readPos, *readVel) { n++ })
+			return n
+		},
+		"IterateReflective": func(r *Registry) int {
+			n := 0
+			r.IterateReflective(func(Goent, *readPos, *readVel) { n++ })
+			return n
+		},
+		"GetComponent": func(r *Registry) int {
+			n := 0
+			for _, e := range entities {
+				_, ok := GetComponent[readVel](r, e)
+				if ok && r.IsEnabled(e) {
+					n++
+				}
+			}
+			return n
+		},
+		"GroupBy": func(r *Registry) int {
+			groups := GroupBy(r, func(v *readVel) float64 { return v.X })
+			defer ReleaseGroups(groups)
+			return len(groups[1])
+		},
+	}
+	names := make([]string, 0, len(reads))
+	fns := make([]func(r *Registry), 0, len(reads))
+	counts := make([]int, len(reads))
+	for name, read := range reads {
+		i := len(fns)
+		names = append(names, name)
+		fns = append(fns, func(r *Registry) { counts[i] = read(r) })
+	}
+	r.ParallelRead(fns...)
+	for i, n := range counts {
+		if n != want {
+			t.Errorf("%s counted %d entities, want %d", names[i], n, want)
+		}
+	}
+}
+
+func TestParallelReadRejectsStructuralChanges(t *testing.T) {
+	r, entities := newReadWorld(4)
+	changes := map[string]func(r *Registry){
+		"CreateEntity":     func(r *Registry) { r.CreateEntity() },
+		"DestroyEntity":    func(r *Registry) { r.DestroyEntity(entities[1]) },
+		"EmplaceComponent": func(r *Registry) { EmplaceComponent(r, entities[1], readTeam{}) },
+		"RemoveComponent":  func(r *Registry) { RemoveComponent[readPos](r, entities[1]) },
+		"PruneComponents":  func(r *Registry) { r.PruneComponents() },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			var err error
+			r.ParallelRead(func(r *Registry) {
+				defer func() {
+					err, _ = recover().(error)
+				}()
+				change(r)
+			})
+			if !errors.Is(err, ErrStorageLocked) {
+				t.Errorf("panic = %v, want ErrStorageLocked", err)
+			}
+		})
+	}
+	if _, err := EmplaceChecked(r, entities[1], readTeam{ID: 7}); err != nil {
+		t.Errorf("write after the parallel phase: %v", err)
+	}
+}
+
+// TestSchedulerParallelStage runs systems sharing a read type and writing
+// disjoint types in one stage. Run with -race.
+func TestSchedulerParallelStage(t *testing.T) {
+	r, entities := newReadWorld(200)
+	s := NewScheduler(3)
+	defer s.Close()
+	// the systems wait for each other, so they really overlap even on a
+	// single CPU
+	var started sync.WaitGroup
+	started.Add(3)
+	pos, vel, team := ComponentType[readPos](), ComponentType[readVel](), ComponentType[readTeam]()
+	s.Add("move", SystemAccess{Reads: []reflect.Type{pos}, Writes: []reflect.Type{vel}}, func(r *Registry) {
+		started.Done()
+		started.Wait()
+		Iterate2(r, func(e Goent, p *readPos, v *readVel) { v.X = p.X })
+	})
+	s.Add("assign", SystemAccess{Reads: []reflect.Type{pos}, Writes: []reflect.Type{team}}, func(r *Registry) {
+		started.Done()
+		started.Wait()
+		Iterate2(r, func(e Goent, p *readPos, tm *readTeam) {
+			tm.ID = int(p.X)
+			MarkChanged[readTeam](r, e)
+		})
+	})
+	s.AddQueued("spawn", SystemAccess{Reads: []reflect.Type{pos}}, func(r *Registry, q *SystemQueue) {
+		started.Done()
+		started.Wait()
+		Iterate1(r, func(e Goent, p *readPos) {
+			if i := int(p.X); i >= 190 && i%3 != 0 {
+				DeferEmplace(&q.CommandBuffer, e, readTeam{ID: -1})
+			}
+		})
+	})
+	if plan := s.Plan(); len(plan) != 1 {
+		t.Fatalf("plan = %v, want one stage", plan)
+	}
+	s.Run(r)
+
+	for i, e := range entities {
+		if v, ok := GetComponent[readVel](r, e); ok && v.X != float64(i) {
+			t.Errorf("entity %d velocity %v, want %d", i, v.X, i)
+		}
+		tm, ok := GetComponent[readTeam](r, e)
+		switch {
+		case i%3 == 0 && (!ok || tm.ID != i):
+			t.Errorf("entity %d team %v, want %d", i, tm, i)
+		case i%3 != 0 && i >= 190 && (!ok || tm.ID != -1):
+			t.Errorf("entity %d team %v, want the queued one", i, tm)
+		case i%3 != 0 && i < 190 && ok:
+			t.Errorf("entity %d got a team", i)
+		}
+	}
+}
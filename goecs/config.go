@@ -0,0 +1,159 @@
+package goecs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// --- World configuration ---
+// Config holds the tunables and feature flags of a world (gravity, spawn
+// rates, debug switches) in one place. Values are stored as strings and read
+// through typed getters with a default, so a missing or malformed entry never
+// stops a system. Loading the same file again only notifies watchers of the
+// keys whose value changed, which is all a hot-reload needs.
+//
+// The file format is one `key = value` pair per line; blank lines and lines
+// starting with # are ignored.
+
+// ConfigWatcher is notified with the new value of a key that changed.
+type ConfigWatcher func(key, value string)
+
+// Config is safe for concurrent use. Watchers run on the goroutine that made
+// the change, after the lock is released.
+type Config struct {
+	mu       sync.RWMutex
+	values   map[string]string
+	watchers map[string][]ConfigWatcher
+}
+
+// NewConfig creates an empty config.
+func NewConfig() *Config {
+	return &Config{
+		values:   make(map[string]string),
+		watchers: make(map[string][]ConfigWatcher),
+	}
+}
+
+// Watch registers f for changes to key, or to every key when key is "".
+func (c *Config) Watch(key string, f ConfigWatcher) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.watchers[key] = append(c.watchers[key], f)
+}
+
+// Set stores a value and notifies the watchers if it changed.
+func (c *Config) Set(key, value string) {
+	c.mu.Lock()
+	old, existed := c.values[key]
+	if existed && old == value {
+		c.mu.Unlock()
+		return
+	}
+	c.values[key] = value
+	notify := append(append([]ConfigWatcher(nil), c.watchers[key]...), c.watchers[""]...)
+	c.mu.Unlock()
+
+	for _, f := range notify {
+		f(key, value)
+	}
+}
+
+// Lookup returns the raw value of a key.
+func (c *Config) Lookup(key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	v, ok := c.values[key]
+	return v, ok
+}
+
+// String returns the value of key, or def if it isn't set.
+func (c *Config) String(key, def string) string {
+	if v, ok := c.Lookup(key); ok {
+		return v
+	}
+	return def
+}
+
+// Int returns the value of key as an int, or def if it isn't set or isn't a
+// valid integer.
+func (c *Config) Int(key string, def int) int {
+	if v, ok := c.Lookup(key); ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return def
+}
+
+// Float returns the value of key as a float64, or def if it isn't set or
+// isn't a valid number.
+func (c *Config) Float(key string, def float64) float64 {
+	if v, ok := c.Lookup(key); ok {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
+
+// Bool returns the value of key as a bool, or def if it isn't set or isn't
+// a valid boolean.
+func (c *Config) Bool(key string, def bool) bool {
+	if v, ok := c.Lookup(key); ok {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+// Enabled reports whether a feature flag is on. Unset flags are off.
+func (c *Config) Enabled(flag string) bool {
+	return c.Bool(flag, false)
+}
+
+// Load reads `key = value` lines, setting each pair.
+func (c *Config) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return fmt.Errorf("goecs: config line %d: missing '='", line)
+		}
+		c.Set(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+	return scanner.Err()
+}
+
+// LoadFile reads a config file, see Load.
+func (c *Config) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Load(f)
+}
+
+// LoadEnv sets every environment variable starting with prefix, keyed by the
+// rest of its name in lower case: with prefix "GAME_", GAME_GRAVITY sets
+// "gravity".
+func (c *Config) LoadEnv(prefix string) {
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if rest, ok := strings.CutPrefix(name, prefix); ok && rest != "" {
+			c.Set(strings.ToLower(rest), value)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package goecs
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	c := NewConfig()
+	err := c.Load(strings.NewReader(`
+# physics
+gravity = -9.8
+spawn_rate=3
+debug_draw = true
+name = arena = 2
+bad_int = lots
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"Float", c.Float("gravity", 0), -9.8},
+		{"Int", c.Int("spawn_rate", 0), 3},
+		{"Int malformed", c.Int("bad_int", 7), 7},
+		{"Int missing", c.Int("missing", 5), 5},
+		{"Enabled", c.Enabled("debug_draw"), true},
+		{"Enabled unset", c.Enabled("god_mode"), false},
+		{"String", c.String("name", ""), "arena = 2"},
+		{"String missing", c.String("missing", "def"), "def"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if err := c.Load(strings.NewReader("no separator")); err == nil {
+		t.Error("Load accepted a line without '='")
+	}
+}
+
+func TestConfigWatch(t *testing.T) {
+	c := NewConfig()
+	var gravity, all []string
+	c.Watch("gravity", func(key, value string) { gravity = append(gravity, value) })
+	c.Watch("", func(key, value string) { all = append(all, key+"="+value) })
+	c.Set("gravity", "-9.8")
+	c.Set("speed", "2")
+
+	// reloading notifies only the keys whose value changed
+	path := filepath.Join(t.TempDir(), "world.cfg")
+	if err := os.WriteFile(path, []byte("gravity = -9.8\nspeed = 3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(gravity, []string{"-9.8"}) {
+		t.Errorf("gravity watcher saw %v", gravity)
+	}
+	if want := []string{"gravity=-9.8", "speed=2", "speed=3"}; !slices.Equal(all, want) {
+		t.Errorf("global watcher saw %v, want %v", all, want)
+	}
+
+	t.Setenv("GOECSTEST_MAX_PLAYERS", "16")
+	c.LoadEnv("GOECSTEST_")
+	if n := c.Int("max_players", 0); n != 16 {
+		t.Errorf("max_players = %d from the environment, want 16", n)
+	}
+}
@@ -0,0 +1,240 @@
+package goecs
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// --- Delta snapshots ---
+// For replication a sender keeps a Baseline of what the receiver last got
+// and writes only the difference to it: entities created and destroyed since,
+// components removed from surviving entities, and components that are new or
+// whose value changed. WriteDelta with a nil baseline writes the full state.
+//
+// A component counts as changed when its value differs from the baseline
+// copy (reflect.DeepEqual) or it was marked changed after the baseline was
+// taken (see MarkChanged). The baseline copies are shallow, so a component
+// whose slice or map contents are edited in place needs MarkChanged to be
+// sent.
+//
+// The receiver applies deltas with ReadDelta to a replica registry, which
+// takes over the sender's entity IDs and shouldn't create entities of its
+// own. Types are matched by name as in Save/Load, and as there relationship
+// edges aren't sent.
+
+// deltaVersion is bumped whenever the stream layout changes.
+const deltaVersion = 1
+
+// Baseline is a copy of the world state deltas are computed against.
+type Baseline struct {
+	tick       uint64
+	entities   map[Goent]struct{}
+	components map[reflect.Type]map[Goent]reflect.Value
+}
+
+type deltaHeader struct {
+	Version   int
+	Created   []Goent
+	Destroyed []Goent
+}
+
+type deltaRecord struct {
+	// Name is the component type name, "" ends the stream
+	Name string
+	// Entities got the component or a new value for it, Removed lost it
+	Entities []Goent
+	Removed  []Goent
+}
+
+// Baseline captures the current state, for a receiver that already has it.
+func (r *Registry) Baseline() *Baseline {
+	return r.baselineFor(AllDomains)
+}
+
+// baselineFor captures the state the viewer may see. It checkpoints the
+// tick, so a component marked changed after the copy, even within the same
+// frame, is newer than the baseline.
+func (r *Registry) baselineFor(viewer DomainMask) *Baseline {
+	base := &Baseline{
+		tick:       r.Checkpoint(),
+		entities:   make(map[Goent]struct{}),
+		components: make(map[reflect.Type]map[Goent]reflect.Value),
+	}
+	r.eachEntity(func(entity Goent) {
+		if r.VisibleTo(entity, viewer) {
+			base.entities[entity] = struct{}{}
+		}
+	})
+	for _, info := range r.sortedComponentTypes() {
+		for entity := range base.entities {
+			if comp, ok := r.componentOf(entity, info.typ); ok {
+				base.keep(info.typ, entity, comp)
+			}
+		}
+	}
+	return base
+}
+
+// keep stores a copy of the component.
+func (b *Baseline) keep(t reflect.Type, entity Goent, comp interface{}) {
+	values, ok := b.components[t]
+	if !ok {
+		values = make(map[Goent]reflect.Value)
+		b.components[t] = values
+	}
+	value := reflect.New(t).Elem()
+	value.Set(reflect.ValueOf(comp).Elem())
+	values[entity] = value
+}
+
+// WriteDelta writes the difference between base and the current state to w
+// and returns the baseline to diff the next delta against.
+func (r *Registry) WriteDelta(w io.Writer, base *Baseline) (*Baseline, error) {
+	return r.WriteDeltaFor(w, base, AllDomains)
+}
+
+// WriteDeltaFor is WriteDelta for a receiver that may only see what the
+// viewer sees (see Domains). Entities leaving its view are sent as
+// destroyed, entities entering it as created. The baseline passed in must
+// come from an earlier WriteDeltaFor for the same viewer.
+func (r *Registry) WriteDeltaFor(w io.Writer, base *Baseline, viewer DomainMask) (*Baseline, error) {
+	if base == nil {
+		base = &Baseline{}
+	}
+	next := r.baselineFor(viewer)
+
+	header := deltaHeader{Version: deltaVersion}
+	for entity := range next.entities {
+		if _, ok := base.entities[entity]; !ok {
+			header.Created = append(header.Created, entity)
+		}
+	}
+	for entity := range base.entities {
+		if _, ok := next.entities[entity]; !ok {
+			header.Destroyed = append(header.Destroyed, entity)
+		}
+	}
+	sortEntities(header.Created)
+	sortEntities(header.Destroyed)
+
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(header); err != nil {
+		return nil, err
+	}
+	for _, info := range r.sortedComponentTypes() {
+		old, current := base.components[info.typ], next.components[info.typ]
+		record := deltaRecord{Name: info.name}
+		values := reflect.MakeSlice(reflect.SliceOf(info.typ), 0, 0)
+		for entity, value := range current {
+			prev, had := old[entity]
+			if had && reflect.DeepEqual(prev.Interface(), value.Interface()) {
+				if tick, ok := r.changeTickOf(entity, info.typ); !ok || tick <= base.tick {
+					continue
+				}
+			}
+			record.Entities = append(record.Entities, entity)
+		}
+		for entity := range old {
+			_, alive := next.entities[entity]
+			if _, ok := current[entity]; !ok && alive {
+				record.Removed = append(record.Removed, entity)
+			}
+		}
+		if len(record.Entities) == 0 && len(record.Removed) == 0 {
+			continue
+		}
+		if _, err := r.serialName(info); err != nil {
+			return nil, err
+		}
+		sortEntities(record.Entities)
+		sortEntities(record.Removed)
+		for _, entity := range record.Entities {
+			values = reflect.Append(values, current[entity])
+		}
+		if err := enc.Encode(record); err != nil {
+			return nil, err
+		}
+		if isFieldless(info.typ) || len(record.Entities) == 0 {
+			continue
+		}
+		if err := enc.EncodeValue(values); err != nil {
+			return nil, fmt.Errorf("goecs: writing delta of %s: %w", info.name, err)
+		}
+	}
+	if err := enc.Encode(deltaRecord{}); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// ReadDelta applies a delta written by WriteDelta. The whole delta is
+// decoded before anything is applied, so a truncated or corrupt stream
+// returns an error and leaves the registry as it was. Destroyed entities go
+// first, so their indices can be taken over by the created ones, then the
+// component changes in restore order (see RestoreAfter).
+func (r *Registry) ReadDelta(rd io.Reader) error {
+	r.assertWritable()
+	dec := gob.NewDecoder(rd)
+	var header deltaHeader
+	if err := dec.Decode(&header); err != nil {
+		return err
+	}
+	if header.Version != deltaVersion {
+		return fmt.Errorf("goecs: unsupported delta version %d", header.Version)
+	}
+
+	var records []loadedRecord
+	for {
+		var record deltaRecord
+		if err := dec.Decode(&record); err != nil {
+			return err
+		}
+		if record.Name == "" {
+			break
+		}
+		info, ok := r.componentNames[record.Name]
+		if !ok {
+			return fmt.Errorf("%w: delta has %q", ErrTypeNotRegistered, record.Name)
+		}
+		values := reflect.New(reflect.SliceOf(info.typ)).Elem()
+		if isFieldless(info.typ) {
+			values = reflect.MakeSlice(values.Type(), len(record.Entities), len(record.Entities))
+		} else if len(record.Entities) > 0 {
+			if err := dec.DecodeValue(values.Addr()); err != nil {
+				return fmt.Errorf("goecs: reading delta of %s: %w", record.Name, err)
+			}
+		}
+		if values.Len() != len(record.Entities) {
+			return fmt.Errorf("goecs: delta record %s is corrupt", record.Name)
+		}
+		records = append(records, loadedRecord{info: info, entities: record.Entities, values: values, removed: record.Removed})
+	}
+
+	for _, entity := range header.Destroyed {
+		if r.IsAlive(entity) {
+			r.DestroyEntity(entity)
+		}
+	}
+	for _, entity := range header.Created {
+		if !r.IsAlive(entity) {
+			r.entities.claim(entity)
+		}
+	}
+	r.sortRecords(records)
+	for _, record := range records {
+		for _, entity := range record.removed {
+			record.info.remove(r, entity)
+		}
+		for i, entity := range record.entities {
+			record.info.emplace(r, entity, record.values.Index(i))
+		}
+	}
+	return nil
+}
+
+func sortEntities(entities []Goent) {
+	sort.Slice(entities, func(i, j int) bool { return entities[i] < entities[j] })
+}
@@ -0,0 +1,143 @@
+package goecs
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+type deltaPos struct {
+	X, Y int
+}
+
+type deltaInventory struct {
+	Items []string
+}
+
+func newReplica() *Registry {
+	r := NewRegistry()
+	RegisterComponent[deltaPos](r)
+	RegisterComponent[deltaInventory](r)
+	return r
+}
+
+func writeDelta(t *testing.T, r *Registry, base *Baseline) ([]byte, *Baseline) {
+	t.Helper()
+	var buf bytes.Buffer
+	next, err := r.WriteDelta(&buf, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes(), next
+}
+
+// replicaState lists the entities of r with their positions, for comparing
+// registries.
+func replicaState(r *Registry) map[Goent]deltaPos {
+	state := make(map[Goent]deltaPos)
+	r.eachEntity(func(e Goent) {
+		p, _ := GetComponent[deltaPos](r, e)
+		if p == nil {
+			p = &deltaPos{X: -1}
+		}
+		state[e] = *p
+	})
+	return state
+}
+
+func TestDeltaRoundTrip(t *testing.T) {
+	src, replica := newReplica(), newReplica()
+	a, b, c := src.CreateEntity(), src.CreateEntity(), src.CreateEntity()
+	EmplaceComponent(src, a, deltaPos{X: 1})
+	EmplaceComponent(src, b, deltaPos{X: 2})
+	EmplaceComponent(src, b, deltaInventory{})
+	EmplaceComponent(src, c, deltaInventory{Items: []string{"key"}})
+
+	data, base := writeDelta(t, src, nil)
+	if err := replica.ReadDelta(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	EmplaceComponent(src, a, deltaPos{X: 10})
+	RemoveComponent[deltaPos](src, b)
+	src.DestroyEntity(c)
+	d := src.CreateEntity()
+	EmplaceComponent(src, d, deltaPos{Y: 4})
+	data, _ = writeDelta(t, src, base)
+	if err := replica.ReadDelta(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[Goent]deltaPos{a: {X: 10}, b: {X: -1}, d: {Y: 4}}
+	if got := replicaState(replica); len(got) != len(want) || got[a] != want[a] || got[b] != want[b] || got[d] != want[d] {
+		t.Errorf("replica = %v, want %v", got, want)
+	}
+	if HasComponent[deltaInventory](replica, c) {
+		t.Error("replica kept the destroyed entity's inventory")
+	}
+}
+
+func TestReadDeltaTruncated(t *testing.T) {
+	src, replica := newReplica(), newReplica()
+	a := src.CreateEntity()
+	EmplaceComponent(src, a, deltaPos{X: 1})
+	data, base := writeDelta(t, src, nil)
+	if err := replica.ReadDelta(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	before := replicaState(replica)
+
+	src.DestroyEntity(a)
+	for i := 0; i < 3; i++ {
+		e := src.CreateEntity()
+		EmplaceComponent(src, e, deltaPos{X: i})
+		EmplaceComponent(src, e, deltaInventory{Items: []string{"a", "b"}})
+	}
+	data, _ = writeDelta(t, src, base)
+	for n := 0; n < len(data); n++ {
+		if err := replica.ReadDelta(bytes.NewReader(data[:n])); err == nil {
+			t.Fatalf("ReadDelta of %d of %d bytes succeeded", n, len(data))
+		}
+		if got := replicaState(replica); len(got) != len(before) || got[a] != before[a] {
+			t.Fatalf("ReadDelta of %d of %d bytes changed the replica to %v, want %v", n, len(data), got, before)
+		}
+	}
+}
+
+func TestDeltaSameTickChange(t *testing.T) {
+	src, replica := newReplica(), newReplica()
+	e := src.CreateEntity()
+	inv := EmplaceComponent(src, e, deltaInventory{Items: []string{"key"}})
+	data, base := writeDelta(t, src, nil)
+	if err := replica.ReadDelta(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	// edited in place and marked without the frame advancing
+	inv.Items[0] = "sword"
+	MarkChanged[deltaInventory](src, e)
+	data, _ = writeDelta(t, src, base)
+	if err := replica.ReadDelta(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := GetComponent[deltaInventory](replica, e)
+	if got == nil || !slices.Equal(got.Items, []string{"sword"}) {
+		t.Errorf("replica inventory = %v, want [sword]", got)
+	}
+}
+
+func TestDeltaDisabled(t *testing.T) {
+	src := newReplica()
+	e := src.CreateEntity()
+	EmplaceComponent(src, e, deltaPos{X: 1})
+	src.Disable(e)
+	data, _ := writeDelta(t, src, nil)
+
+	replica := newReplica()
+	if err := replica.ReadDelta(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if !HasComponent[Disabled](replica, e) || !HasComponent[deltaPos](replica, e) {
+		t.Error("disabled entity didn't replicate")
+	}
+}
@@ -0,0 +1,116 @@
+package goecs
+
+import (
+	"container/list"
+	"reflect"
+)
+
+// --- Derived components ---
+// A DerivedComponent caches data computed from an entity's components, such
+// as world-space bounds or path distances, for the entities it was last
+// asked about. A value is computed on the first Get and reused until one of
+// the source components changes, as seen by change detection, so writes made
+// through a component pointer need MarkChanged to invalidate it. The least
+// recently used values are evicted once the cache is full.
+//
+//	bounds := NewDerivedComponent(r, 1024, worldBounds,
+//		ComponentType[Transform](), ComponentType[Mesh]())
+//	b, ok := bounds.Get(e)
+
+// DerivedComponent is an LRU cache of values of T derived per entity.
+type DerivedComponent[T any] struct {
+	r        *Registry
+	compute  func(r *Registry, entity Goent) (T, bool)
+	sources  []reflect.Type
+	capacity int
+	// entries indexes lru, which holds the most recently used entry first
+	entries map[Goent]*list.Element
+	lru     *list.List
+}
+
+type derivedEntry[T any] struct {
+	entity Goent
+	value  T
+	// tick is the tick the value was computed at
+	tick uint64
+}
+
+// NewDerivedComponent creates a cache of at most capacity values, computed by
+// compute from the given source component types. compute reports false if
+// the entity has nothing to derive from, which isn't cached.
+func NewDerivedComponent[T any](r *Registry, capacity int, compute func(r *Registry, entity Goent) (T, bool), sources ...reflect.Type) *DerivedComponent[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &DerivedComponent[T]{
+		r:        r,
+		compute:  compute,
+		sources:  sources,
+		capacity: capacity,
+		entries:  make(map[Goent]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// fresh reports whether no source changed since the entry was computed. A
+// change in the tick of the computation can't be ordered against it, so it
+// counts as newer.
+func (d *DerivedComponent[T]) fresh(entry *derivedEntry[T]) bool {
+	for _, t := range d.sources {
+		tick, ok := d.r.changeTickOf(entry.entity, t)
+		if !ok || tick >= entry.tick {
+			return false
+		}
+	}
+	return true
+}
+
+// Get returns the value derived for the entity, computing it if it isn't
+// cached or a source changed since.
+func (d *DerivedComponent[T]) Get(entity Goent) (T, bool) {
+	if el, ok := d.entries[entity]; ok {
+		entry := el.Value.(*derivedEntry[T])
+		if d.fresh(entry) {
+			d.lru.MoveToFront(el)
+			return entry.value, true
+		}
+		d.drop(el)
+	}
+	var zero T
+	if !d.r.IsAlive(entity) {
+		return zero, false
+	}
+	value, ok := d.compute(d.r, entity)
+	if !ok {
+		return zero, false
+	}
+	d.entries[entity] = d.lru.PushFront(&derivedEntry[T]{entity: entity, value: value, tick: d.r.Tick()})
+	if d.lru.Len() > d.capacity {
+		d.drop(d.lru.Back())
+	}
+	return value, true
+}
+
+func (d *DerivedComponent[T]) drop(el *list.Element) {
+	delete(d.entries, el.Value.(*derivedEntry[T]).entity)
+	d.lru.Remove(el)
+}
+
+// Invalidate drops the cached value of the entity, for sources change
+// detection can't see.
+func (d *DerivedComponent[T]) Invalidate(entity Goent) {
+	if el, ok := d.entries[entity]; ok {
+		d.drop(el)
+	}
+}
+
+// Clear drops every cached value.
+func (d *DerivedComponent[T]) Clear() {
+	d.entries = make(map[Goent]*list.Element)
+	d.lru.Init()
+}
+
+// Len returns the number of cached values.
+func (d *DerivedComponent[T]) Len() int {
+	return d.lru.Len()
+}
@@ -0,0 +1,80 @@
+package goecs
+
+import "testing"
+
+type derivedPos struct {
+	X int
+}
+
+type derivedScale struct {
+	K int
+}
+
+func TestDerivedComponent(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(4)
+	for i, e := range entities[:3] {
+		EmplaceComponent(r, e, derivedPos{X: i})
+		EmplaceComponent(r, e, derivedScale{K: 10})
+	}
+	computed := 0
+	d := NewDerivedComponent(r, 2, func(r *Registry, e Goent) (int, bool) {
+		p, ok := GetComponent[derivedPos](r, e)
+		s, ok2 := GetComponent[derivedScale](r, e)
+		if !ok || !ok2 {
+			return 0, false
+		}
+		computed++
+		return p.X * s.K, true
+	}, ComponentType[derivedPos](), ComponentType[derivedScale]())
+	r.AdvanceTick()
+
+	get := func(e Goent, want int) {
+		t.Helper()
+		if v, ok := d.Get(e); !ok || v != want {
+			t.Errorf("Get(%d) = %v, %v, want %d", e, v, ok, want)
+		}
+	}
+	get(entities[1], 10)
+	get(entities[1], 10)
+	if computed != 1 {
+		t.Errorf("computed %d times, want a cached value", computed)
+	}
+
+	// a source change invalidates the value
+	r.AdvanceTick()
+	p, _ := GetComponent[derivedPos](r, entities[1])
+	p.X = 5
+	MarkChanged[derivedPos](r, entities[1])
+	get(entities[1], 50)
+	if computed != 2 {
+		t.Errorf("computed %d times, want a recomputation", computed)
+	}
+
+	// the least recently used value is evicted
+	r.AdvanceTick()
+	get(entities[0], 0)
+	get(entities[1], 50)
+	get(entities[2], 20)
+	if d.Len() != 2 {
+		t.Errorf("Len = %d, want the capacity 2", d.Len())
+	}
+	computed = 0
+	get(entities[1], 50)
+	get(entities[0], 0)
+	if computed != 1 {
+		t.Errorf("recomputed %d values, want only the evicted one", computed)
+	}
+
+	if _, ok := d.Get(entities[3]); ok || d.Len() != 2 {
+		t.Error("entity without sources got a cached value")
+	}
+	d.Invalidate(entities[0])
+	if d.Len() != 1 {
+		t.Errorf("Len = %d after Invalidate", d.Len())
+	}
+	d.Clear()
+	if d.Len() != 0 {
+		t.Errorf("Len = %d after Clear", d.Len())
+	}
+}
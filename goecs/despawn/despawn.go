@@ -0,0 +1,76 @@
+// Package despawn provides an entity budget policy for decorative and
+// poolable entities such as corpses, decals and shell casings: once more of
+// a kind exist than its cap allows, the least recently touched are despawned.
+package despawn
+
+import (
+	"sort"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+// Decorative tags an entity as subject to a despawn policy. Stamp is the time
+// the entity was spawned or last touched, in whatever clock the game uses.
+type Decorative struct {
+	Kind  string
+	Stamp float64
+}
+
+// Mark tags the entity as a decorative entity of the given kind.
+func Mark(r *goecs.Registry, e goecs.Goent, kind string, stamp float64) {
+	goecs.EmplaceComponent(r, e, Decorative{Kind: kind, Stamp: stamp})
+}
+
+// Touch refreshes the entity's stamp, making it the most recently used of its kind.
+func Touch(r *goecs.Registry, e goecs.Goent, stamp float64) {
+	if d, ok := goecs.GetComponent[Decorative](r, e); ok {
+		d.Stamp = stamp
+	}
+}
+
+// Policy caps how many decorative entities of one kind may exist at once.
+type Policy struct {
+	Kind string
+	Cap  int
+	// OnDespawn, when set, is called for every entity right before it is destroyed.
+	OnDespawn func(r *goecs.Registry, e goecs.Goent)
+}
+
+type candidate struct {
+	entity goecs.Goent
+	stamp  float64
+}
+
+// Enforce destroys the oldest entities of the policy's kind until at most Cap
+// remain and returns the despawned entities, oldest first. Ties are broken
+// by entity ID so every run despawns the same entities. It destroys entities
+// directly, so it must not be called from inside an iteration.
+func (p Policy) Enforce(r *goecs.Registry) []goecs.Goent {
+	var tracked []candidate
+	goecs.Iterate1(r, func(e goecs.Goent, d *Decorative) {
+		if d.Kind == p.Kind {
+			tracked = append(tracked, candidate{entity: e, stamp: d.Stamp})
+		}
+	})
+	excess := len(tracked) - p.Cap
+	if excess <= 0 {
+		return nil
+	}
+
+	sort.Slice(tracked, func(i, j int) bool {
+		if tracked[i].stamp != tracked[j].stamp {
+			return tracked[i].stamp < tracked[j].stamp
+		}
+		return tracked[i].entity < tracked[j].entity
+	})
+
+	despawned := make([]goecs.Goent, excess)
+	for i, c := range tracked[:excess] {
+		if p.OnDespawn != nil {
+			p.OnDespawn(r, c.entity)
+		}
+		r.DestroyEntity(c.entity)
+		despawned[i] = c.entity
+	}
+	return despawned
+}
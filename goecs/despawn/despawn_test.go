@@ -0,0 +1,43 @@
+package despawn
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+func TestEnforce(t *testing.T) {
+	r := goecs.NewRegistry()
+	entities := r.CreateEntities(6)
+	for i, e := range entities[:5] {
+		// entities 3 and 4 share a stamp, the lower ID goes first
+		Mark(r, e, "decal", float64(min(i, 3)))
+	}
+	Mark(r, entities[5], "corpse", 0)
+	Touch(r, entities[0], 10)
+
+	var hooked []goecs.Goent
+	p := Policy{Kind: "decal", Cap: 2, OnDespawn: func(r *goecs.Registry, e goecs.Goent) {
+		if !r.IsAlive(e) {
+			t.Errorf("OnDespawn got the destroyed entity %d", e)
+		}
+		hooked = append(hooked, e)
+	}}
+	got := p.Enforce(r)
+	want := []goecs.Goent{entities[1], entities[2], entities[3]}
+	if !slices.Equal(got, want) || !slices.Equal(hooked, want) {
+		t.Fatalf("Enforce despawned %v (hooked %v), want %v", got, hooked, want)
+	}
+	for _, e := range want {
+		if r.IsAlive(e) {
+			t.Errorf("entity %d survived", e)
+		}
+	}
+	if !r.IsAlive(entities[0]) || !r.IsAlive(entities[4]) || !r.IsAlive(entities[5]) {
+		t.Error("Enforce destroyed an entity within the cap or of another kind")
+	}
+	if again := p.Enforce(r); again != nil {
+		t.Errorf("second Enforce despawned %v", again)
+	}
+}
@@ -0,0 +1,207 @@
+package goecs
+
+// --- Batched destruction ---
+
+// DestroyEntities destroys a batch of entities. Instead of removing each
+// entity from every storage in turn, every storage takes out its share of
+// the batch at once: a single compacting pass over its dense array when the
+// batch is a sizable part of it, and plain swap removals otherwise, so no
+// storage costs more than the smaller of its size and the batch. Stale
+// handles and duplicates are skipped.
+func (r *Registry) DestroyEntities(entities []Goent) {
+	r.assertWritable()
+	doomed := make(map[Goent]struct{}, len(entities))
+	live := make([]Goent, 0, len(entities))
+	for _, entity := range entities {
+		if _, dup := doomed[entity]; dup || r.isStale(entity) {
+			continue
+		}
+		doomed[entity] = struct{}{}
+		live = append(live, entity)
+	}
+	for _, entity := range live {
+		r.unlinkHierarchy(entity)
+	}
+
+	if len(r.hooks) > 0 {
+		for _, entity := range live {
+			r.fireRemoveHooks(entity)
+		}
+	}
+	for _, storage := range r.storages {
+		if len(storage.GetDense()) == 0 {
+			continue
+		}
+		if b, ok := storage.(batchRemover); ok {
+			b.removeBatch(doomed, live)
+			continue
+		}
+		for _, entity := range live {
+			storage.Remove(entity)
+		}
+	}
+	if r.archetypes != nil {
+		for _, entity := range live {
+			r.archetypes.destroy(entity)
+		}
+	}
+	r.releaseEntities(live)
+}
+
+// batchRemover is implemented by every SparseSet, for DestroyEntities.
+type batchRemover interface {
+	removeBatch(doomed map[Goent]struct{}, batch []Goent)
+}
+
+// removeBatch removes the components of the batch, whose entities doomed
+// holds as well. Batches that are small next to the storage are swap
+// removed one by one; larger ones, and any batch from a storage that keeps
+// its order, are dropped in one pass that slides the survivors down in
+// order. Group-owned storages always remove one by one, keeping the packed
+// region in step with the group's other storages.
+func (ss *SparseSet[T]) removeBatch(doomed map[Goent]struct{}, batch []Goent) {
+	keepsOrder := ss.ordered || ss.stable
+	if ss.group != nil || (!keepsOrder && len(batch)*4 < len(ss.dense)) {
+		for _, entity := range batch {
+			ss.Remove(entity)
+		}
+		return
+	}
+	kept := 0
+	for i, entity := range ss.dense {
+		if _, ok := doomed[entity]; ok {
+			ss.setSlot(entity.Index(), invalidIndex)
+			continue
+		}
+		if kept != i {
+			ss.dense[kept] = entity
+			ss.ticks[kept] = ss.ticks[i]
+			if ss.tag == nil {
+				ss.components[kept] = ss.components[i]
+			}
+			ss.setSlot(entity.Index(), kept)
+		}
+		kept++
+	}
+	if ss.tag == nil {
+		clear(ss.components[kept:])
+		ss.components = ss.components[:kept]
+	}
+	ss.dense = ss.dense[:kept]
+	ss.ticks = ss.ticks[:kept]
+}
+
+// DestroyWhere1 destroys every entity with a T component matching pred. The
+// matches are collected first and destroyed as one batch after iteration, so
+// pred sees a stable world. It returns the number of destroyed entities.
+func DestroyWhere1[T any](r *Registry, pred func(entity Goent, c *T) bool, filters ...Filter) int {
+	var doomed []Goent
+	Iterate1(r, func(entity Goent, c *T) {
+		if pred(entity, c) {
+			doomed = append(doomed, entity)
+		}
+	}, filters...)
+	r.DestroyEntities(doomed)
+	return len(doomed)
+}
+
+// DestroyWhere2 destroys every entity with T1 and T2 components matching
+// pred, see DestroyWhere1.
+func DestroyWhere2[T1 any, T2 any](r *Registry, pred func(entity Goent, c1 *T1, c2 *T2) bool, filters ...Filter) int {
+	var doomed []Goent
+	Iterate2(r, func(entity Goent, c1 *T1, c2 *T2) {
+		if pred(entity, c1, c2) {
+			doomed = append(doomed, entity)
+		}
+	}, filters...)
+	r.DestroyEntities(doomed)
+	return len(doomed)
+}
+
+// DestroyWhere3 destroys every entity with T1, T2 and T3 components matching
+// pred, see DestroyWhere1.
+func DestroyWhere3[T1 any, T2 any, T3 any](r *Registry, pred func(entity Goent, c1 *T1, c2 *T2, c3 *T3) bool, filters ...Filter) int {
+	var doomed []Goent
+	Iterate3(r, func(entity Goent, c1 *T1, c2 *T2, c3 *T3) {
+		if pred(entity, c1, c2, c3) {
+			doomed = append(doomed, entity)
+		}
+	}, filters...)
+	r.DestroyEntities(doomed)
+	return len(doomed)
+}
+
+// DestroyAll1 destroys every entity with a T component, e.g. all projectiles
+// on a level change. It returns the number of destroyed entities.
+func DestroyAll1[T any](r *Registry, filters ...Filter) int {
+	return DestroyWhere1(r, func(Goent, *T) bool { return true }, filters...)
+}
+
+// DestroyAll2 destroys every entity with T1 and T2 components, see
+// DestroyAll1.
+func DestroyAll2[T1 any, T2 any](r *Registry, filters ...Filter) int {
+	return DestroyWhere2(r, func(Goent, *T1, *T2) bool { return true }, filters...)
+}
+
+// DestroyAll3 destroys every entity with T1, T2 and T3 components, see
+// DestroyAll1.
+func DestroyAll3[T1 any, T2 any, T3 any](r *Registry, filters ...Filter) int {
+	return DestroyWhere3(r, func(Goent, *T1, *T2, *T3) bool { return true }, filters...)
+}
+
+// storageClearer is implemented by every SparseSet, for emptying storages
+// without knowing their type.
+type storageClearer interface {
+	Clear()
+}
+
+// Clear destroys every entity, e.g. to restart a level, while keeping what
+// was set up around them: component types and their storages with their
+// capacity, hooks, groups, resources, seeds and the tick. OnRemove hooks
+// fire as in DestroyEntities. Handles from before stay stale, as after any
+// destroy.
+func (r *Registry) Clear() {
+	r.assertWritable()
+	live := r.entities.live()
+	if len(r.hooks) > 0 {
+		for _, entity := range live {
+			r.fireRemoveHooks(entity)
+		}
+	}
+	for _, storage := range r.storages {
+		if c, ok := storage.(storageClearer); ok {
+			c.Clear()
+		} else {
+			for _, entity := range append([]Goent(nil), storage.GetDense()...) {
+				storage.Remove(entity)
+			}
+		}
+	}
+	if r.archetypes != nil {
+		for _, entity := range live {
+			r.archetypes.destroy(entity)
+		}
+	}
+	for _, table := range r.dirty {
+		clear(table)
+	}
+	for _, s := range r.relations {
+		s.clear()
+	}
+	r.stringAliases = newAliasTable[string]()
+	r.idAliases = newAliasTable[uint64]()
+	r.names = newAliasTable[string]()
+	clear(r.annotations)
+	for _, entity := range live {
+		r.entities.release(entity)
+	}
+}
+
+// Reset is Clear that also forgets every ID handed out, so the next entities
+// get the same IDs as in a fresh registry. Handles from before may then
+// refer to new entities, so nothing may hold on to them.
+func (r *Registry) Reset() {
+	r.Clear()
+	r.entities.generations = r.entities.generations[:0]
+	r.entities.free = r.entities.free[:0]
+}
@@ -0,0 +1,240 @@
+package goecs
+
+import (
+	"slices"
+	"testing"
+)
+
+type destroyHealth struct {
+	HP int
+}
+
+type destroyTag struct{}
+
+type destroyLayer struct {
+	Z int
+}
+
+func TestDestroyEntity(t *testing.T) {
+	r := NewRegistry()
+	e, other := r.CreateEntity(), r.CreateEntity()
+	EmplaceComponent(r, e, destroyHealth{HP: 1})
+	EmplaceComponent(r, e, destroyTag{})
+	EmplaceComponent(r, e, destroyLayer{Z: 2})
+	EmplaceComponent(r, other, destroyHealth{HP: 3})
+	r.SetName(e, "doomed")
+
+	r.DestroyEntity(e)
+	if r.IsAlive(e) || HasComponent[destroyHealth](r, e) || HasComponent[destroyTag](r, e) || HasComponent[destroyLayer](r, e) {
+		t.Error("destroyed entity is alive or kept components")
+	}
+	if _, ok := r.FindByName("doomed"); ok {
+		t.Error("destroyed entity kept its name")
+	}
+	if h, ok := GetComponent[destroyHealth](r, other); !ok || h.HP != 3 {
+		t.Errorf("other entity has %v, %v", h, ok)
+	}
+	// destroying again is a no-op, even once the index is reused
+	reused := r.CreateEntity()
+	EmplaceComponent(r, reused, destroyHealth{HP: 4})
+	r.DestroyEntity(e)
+	if reused.Index() != e.Index() || !r.IsAlive(reused) || !HasComponent[destroyHealth](r, reused) {
+		t.Errorf("stale destroy of %d hit the reused entity %d", e, reused)
+	}
+}
+
+func TestDestroyEntities(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func() *Registry
+		// doomed picks the entities to destroy out of the 40 created
+		doomed func(i int) bool
+	}{
+		{"few", NewRegistry, func(i int) bool { return i == 3 || i == 17 }},
+		{"most", NewRegistry, func(i int) bool { return i%5 != 0 }},
+		{"all", NewRegistry, func(i int) bool { return true }},
+		{"map indexed", func() *Registry {
+			r := NewRegistry()
+			RegisterComponentWithPolicy[destroyHealth](r, GrowthPolicy{UpgradeAt: 1000})
+			return r
+		}, func(i int) bool { return i%2 == 0 }},
+		{"deterministic", func() *Registry {
+			return NewRegistryWithOptions(RegistryOptions{Deterministic: true})
+		}, func(i int) bool { return i%3 == 0 }},
+		{"preserve order", func() *Registry {
+			r := NewRegistry()
+			PreserveOrder[destroyLayer](r)
+			return r
+		}, func(i int) bool { return i%3 == 1 }},
+		{"owned group", func() *Registry {
+			r := NewRegistry()
+			NewGroup2[destroyHealth, destroyLayer](r)
+			return r
+		}, func(i int) bool { return i%2 == 1 }},
+		{"archetypes", func() *Registry {
+			return NewRegistryWithOptions(RegistryOptions{Storage: ArchetypeStorage})
+		}, func(i int) bool { return i%4 != 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.setup()
+			entities := r.CreateEntities(40)
+			var doomed, survivors []Goent
+			for i, e := range entities {
+				EmplaceComponent(r, e, destroyHealth{HP: i})
+				if i%2 == 0 {
+					EmplaceComponent(r, e, destroyTag{})
+				}
+				if i%3 != 2 {
+					EmplaceComponent(r, e, destroyLayer{Z: i})
+				}
+				if tt.doomed(i) {
+					doomed = append(doomed, e)
+				} else {
+					survivors = append(survivors, e)
+				}
+			}
+			var layered []Goent
+			Iterate1(r, func(e Goent, _ *destroyLayer) { layered = append(layered, e) })
+
+			// duplicates and stale handles are skipped
+			stale := r.CreateEntity()
+			r.DestroyEntity(stale)
+			r.DestroyEntities(append(append([]Goent{stale}, doomed...), doomed...))
+
+			for _, e := range doomed {
+				if r.IsAlive(e) || HasComponent[destroyHealth](r, e) || HasComponent[destroyTag](r, e) || HasComponent[destroyLayer](r, e) {
+					t.Fatalf("entity %d survived or kept components", e)
+				}
+			}
+			for _, e := range survivors {
+				i := slices.Index(entities, e)
+				h, ok := GetComponent[destroyHealth](r, e)
+				if !r.IsAlive(e) || !ok || h.HP != i || HasComponent[destroyTag](r, e) != (i%2 == 0) {
+					t.Fatalf("survivor %d lost its components", e)
+				}
+				if l, ok := GetComponent[destroyLayer](r, e); ok != (i%3 != 2) || (ok && l.Z != i) {
+					t.Fatalf("survivor %d has layer %v, %v", e, l, ok)
+				}
+			}
+			if got := Count[destroyHealth](r); got != len(survivors) {
+				t.Errorf("%d health components left, want %d", got, len(survivors))
+			}
+			if tt.name == "deterministic" || tt.name == "preserve order" {
+				var want, got []Goent
+				for _, e := range layered {
+					if !slices.Contains(doomed, e) {
+						want = append(want, e)
+					}
+				}
+				Iterate1(r, func(e Goent, _ *destroyLayer) { got = append(got, e) })
+				if !slices.Equal(got, want) {
+					t.Errorf("layer order = %v, want %v", got, want)
+				}
+			}
+			// every index comes back exactly once
+			recycled := r.CreateEntities(len(doomed) + 1)
+			seen := make(map[uint32]bool)
+			for _, e := range recycled {
+				if seen[e.Index()] {
+					t.Fatalf("index %d handed out twice", e.Index())
+				}
+				seen[e.Index()] = true
+			}
+		})
+	}
+}
+
+func TestDestroyEntitiesGroup(t *testing.T) {
+	r := NewRegistry()
+	g := NewGroup2[destroyHealth, destroyLayer](r)
+	entities := r.CreateEntities(20)
+	for i, e := range entities {
+		EmplaceComponent(r, e, destroyHealth{HP: i})
+		if i%4 != 0 {
+			EmplaceComponent(r, e, destroyLayer{Z: i})
+		}
+	}
+	r.DestroyEntities(entities[:12])
+	var members []Goent
+	g.Each(func(e Goent, h *destroyHealth, l *destroyLayer) {
+		if h.HP != l.Z {
+			t.Errorf("entity %d pairs HP %d with Z %d", e, h.HP, l.Z)
+		}
+		members = append(members, e)
+	})
+	slices.Sort(members)
+	want := []Goent{entities[13], entities[14], entities[15], entities[17], entities[18], entities[19]}
+	if !slices.Equal(members, want) || g.Len() != len(want) {
+		t.Errorf("group = %v (Len %d), want %v", members, g.Len(), want)
+	}
+}
+
+func TestDestroyWhere(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(10)
+	for i, e := range entities {
+		EmplaceComponent(r, e, destroyHealth{HP: i})
+		EmplaceComponent(r, e, destroyLayer{Z: i % 3})
+	}
+	r.Disable(entities[0])
+	n := DestroyWhere2(r, func(e Goent, h *destroyHealth, l *destroyLayer) bool {
+		return h.HP < 5 && l.Z == 0
+	})
+	// entity 0 is disabled and entity 3 the only other match
+	if n != 1 || r.IsAlive(entities[3]) || !r.IsAlive(entities[0]) {
+		t.Errorf("DestroyWhere2 destroyed %d, entity 3 alive %v, entity 0 alive %v", n, r.IsAlive(entities[3]), r.IsAlive(entities[0]))
+	}
+	if n := DestroyAll1[destroyHealth](r, IncludeDisabled()); n != 9 || Count[destroyLayer](r) != 0 {
+		t.Errorf("DestroyAll1 destroyed %d, %d layers left", n, Count[destroyLayer](r))
+	}
+}
+
+func TestClear(t *testing.T) {
+	for _, reset := range []bool{false, true} {
+		r := NewRegistry()
+		entities := r.CreateEntities(5)
+		for i, e := range entities {
+			EmplaceComponent(r, e, destroyHealth{HP: i})
+		}
+		EmplaceComponent(r, entities[2], destroyTag{})
+		r.SetName(entities[0], "hero")
+		SetResource(r, destroyLayer{Z: 7})
+		tick := r.AdvanceTick()
+		removed := 0
+		OnRemove(r, func(Goent, *destroyHealth) { removed++ })
+
+		if reset {
+			r.Reset()
+		} else {
+			r.Clear()
+		}
+		if r.EntityCount() != 0 || Count[destroyHealth](r) != 0 || Count[destroyTag](r) != 0 {
+			t.Errorf("reset %v: %d entities, %d healths left", reset, r.EntityCount(), Count[destroyHealth](r))
+		}
+		if removed != 5 {
+			t.Errorf("reset %v: OnRemove fired %d times, want 5", reset, removed)
+		}
+		if _, ok := r.FindByName("hero"); ok {
+			t.Errorf("reset %v: name survived", reset)
+		}
+		for _, e := range entities {
+			if r.IsAlive(e) {
+				t.Errorf("reset %v: entity %d still alive", reset, e)
+			}
+		}
+		// the setup around the entities is kept
+		if l, ok := GetResource[destroyLayer](r); !ok || l.Z != 7 || r.Tick() != tick {
+			t.Errorf("reset %v: resource %v, %v and tick %d", reset, l, ok, r.Tick())
+		}
+		// only Reset hands out the same IDs again
+		e := r.CreateEntity()
+		EmplaceComponent(r, e, destroyHealth{HP: 9})
+		if (e == entities[0]) != reset {
+			t.Errorf("reset %v: new entity %d, first old one %d", reset, e, entities[0])
+		}
+		if h, ok := GetComponent[destroyHealth](r, e); !ok || h.HP != 9 {
+			t.Errorf("reset %v: new entity has %v, %v", reset, h, ok)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package goecs
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+)
+
+// --- Per-field dirty masks for replication ---
+// Replicated components track which of their fields changed, so deltas can
+// encode only the changed fields of changed components. Fields take part when
+// tagged `ecs:"replicate"`; a type without any tagged field replicates all of
+// its exported fields. Bit i of a DirtyMask is the i-th replicated field in
+// declaration order, which caps a type at 64 replicated fields.
+
+// DirtyMask is the set of changed replicated fields of one component.
+type DirtyMask uint64
+
+// fieldInfo is one replicated field of a component type.
+type fieldInfo struct {
+	name  string
+	index int
+	kind  reflect.Kind
+}
+
+// fieldLayout lists the replicated fields of a type, bit order.
+type fieldLayout struct {
+	fields []fieldInfo
+	byName map[string]int
+}
+
+// fieldLayouts caches layouts per type, they only depend on the type itself.
+var fieldLayouts sync.Map
+
+func layoutFor(t reflect.Type) *fieldLayout {
+	if cached, ok := fieldLayouts.Load(t); ok {
+		return cached.(*fieldLayout)
+	}
+	layout := &fieldLayout{byName: make(map[string]int)}
+	if t.Kind() == reflect.Struct {
+		tagged := false
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).Tag.Get("ecs") == "replicate" {
+				tagged = true
+				break
+			}
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() || (tagged && f.Tag.Get("ecs") != "replicate") {
+				continue
+			}
+			if len(layout.fields) == 64 {
+				panic("goecs: more than 64 replicated fields in " + t.String())
+			}
+			layout.byName[f.Name] = len(layout.fields)
+			layout.fields = append(layout.fields, fieldInfo{name: f.Name, index: i, kind: f.Type.Kind()})
+		}
+	}
+	actual, _ := fieldLayouts.LoadOrStore(t, layout)
+	return actual.(*fieldLayout)
+}
+
+// FieldMask returns the mask bits of the named replicated fields of T.
+// Unknown names are ignored.
+func FieldMask[T any](fields ...string) DirtyMask {
+	layout := layoutFor(typeKeyFor[T]())
+	var mask DirtyMask
+	for _, name := range fields {
+		if bit, ok := layout.byName[name]; ok {
+			mask |= 1 << bit
+		}
+	}
+	return mask
+}
+
+// dirtyTable returns the masks of a type. Tables are created along with the
+// type's registration, so systems writing disjoint types only touch their
+// own tables and may flag fields in parallel (see Scheduler). A type that was
+// never stored gets its table here, which is a structural change.
+func (r *Registry) dirtyTable(t reflect.Type) map[Goent]DirtyMask {
+	table, ok := r.dirty[t]
+	if !ok {
+		r.assertWritable()
+		table = make(map[Goent]DirtyMask)
+		r.dirty[t] = table
+	}
+	return table
+}
+
+// MarkDirty flags fields of the entity's T component as changed, for code
+// that writes through the component pointer directly.
+func MarkDirty[T any](r *Registry, entity Goent, fields ...string) {
+	if mask := FieldMask[T](fields...); mask != 0 {
+		r.dirtyTable(typeKeyFor[T]())[entity] |= mask
+	}
+}
+
+// MarkAllDirty flags every replicated field of the entity's T component, e.g.
+// right after it was emplaced.
+func MarkAllDirty[T any](r *Registry, entity Goent) {
+	n := len(layoutFor(typeKeyFor[T]()).fields)
+	if n == 0 {
+		return
+	}
+	r.dirtyTable(typeKeyFor[T]())[entity] |= DirtyMask(math.MaxUint64 >> (64 - n))
+}
+
+// Patch sets one replicated field of the entity's T component, flags it
+// dirty and stamps its change tick. Writing the value it already holds
+// doesn't flag anything; for fields that can't be compared with == that
+// takes an equality registered with RegisterEqual.
+func Patch[T any](r *Registry, entity Goent, field string, value interface{}) error {
+	comp, err := Lookup[T](r, entity)
+	if err != nil {
+		return err
+	}
+	layout := layoutFor(typeKeyFor[T]())
+	bit, ok := layout.byName[field]
+	if !ok {
+		return fmt.Errorf("goecs: %s has no replicated field %q", typeKeyFor[T](), field)
+	}
+
+	target := reflect.ValueOf(comp).Elem().Field(layout.fields[bit].index)
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		switch target.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+			v = reflect.Zero(target.Type())
+		default:
+			return fmt.Errorf("goecs: cannot assign nil to %s.%s", typeKeyFor[T](), field)
+		}
+	}
+	if !v.Type().AssignableTo(target.Type()) {
+		if !v.Type().ConvertibleTo(target.Type()) {
+			return fmt.Errorf("goecs: cannot assign %s to %s.%s", v.Type(), typeKeyFor[T](), field)
+		}
+		v = v.Convert(target.Type())
+	}
+	if v.Type().Comparable() && target.Interface() == v.Interface() {
+		return nil
+	}
+	if eq := equalFor[T](r); eq != nil {
+		old := *comp
+		target.Set(v)
+		if eq(old, *comp) {
+			return nil
+		}
+	}
+	target.Set(v)
+	r.dirtyTable(typeKeyFor[T]())[entity] |= 1 << bit
+	MarkChanged[T](r, entity)
+	return nil
+}
+
+// DirtyFields returns the changed fields of the entity's T component.
+func DirtyFields[T any](r *Registry, entity Goent) DirtyMask {
+	return r.dirty[typeKeyFor[T]()][entity]
+}
+
+// EachDirty calls f for every entity whose T component has changed fields.
+func EachDirty[T any](r *Registry, f func(entity Goent, c *T, mask DirtyMask)) {
+	for entity, mask := range r.dirty[typeKeyFor[T]()] {
+		if comp, ok := GetComponent[T](r, entity); ok && mask != 0 {
+			f(entity, comp, mask)
+		}
+	}
+}
+
+// ClearDirty resets the masks of every T component, typically once a delta
+// has been sent. The table itself stays, see dirtyTable.
+func ClearDirty[T any](r *Registry) {
+	clear(r.dirty[typeKeyFor[T]()])
+}
+
+// forgetDirty drops every mask of a destroyed entity.
+func (r *Registry) forgetDirty(entity Goent) {
+	for _, table := range r.dirty {
+		delete(table, entity)
+	}
+}
+
+// AppendDelta encodes the masked fields of the component onto buf: the mask
+// as a uvarint, then each field in bit order. Supported field kinds are
+// bools, integers, floats and strings.
+func AppendDelta[T any](buf []byte, comp *T, mask DirtyMask) ([]byte, error) {
+	layout := layoutFor(typeKeyFor[T]())
+	v := reflect.ValueOf(comp).Elem()
+	buf = binary.AppendUvarint(buf, uint64(mask))
+	for bit, f := range layout.fields {
+		if mask&(1<<bit) == 0 {
+			continue
+		}
+		field := v.Field(f.index)
+		switch f.kind {
+		case reflect.Bool:
+			b := byte(0)
+			if field.Bool() {
+				b = 1
+			}
+			buf = append(buf, b)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			buf = binary.AppendVarint(buf, field.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			buf = binary.AppendUvarint(buf, field.Uint())
+		case reflect.Float32:
+			buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(field.Float())))
+		case reflect.Float64:
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(field.Float()))
+		case reflect.String:
+			buf = binary.AppendUvarint(buf, uint64(field.Len()))
+			buf = append(buf, field.String()...)
+		default:
+			return buf, fmt.Errorf("goecs: field %s of kind %s can't be delta encoded", f.name, f.kind)
+		}
+	}
+	return buf, nil
+}
+
+// errShort is returned by ApplyDelta for data that ends early or holds
+// lengths running past its end.
+var errShort = errors.New("goecs: truncated delta")
+
+// ApplyDelta decodes a delta written by AppendDelta into comp, returning the
+// mask it carried and the number of bytes consumed. Data that ends early or
+// is corrupt yields an error, never a panic, so it is safe on network input.
+func ApplyDelta[T any](comp *T, data []byte) (DirtyMask, int, error) {
+	layout := layoutFor(typeKeyFor[T]())
+	v := reflect.ValueOf(comp).Elem()
+
+	raw, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, 0, errShort
+	}
+	mask, pos := DirtyMask(raw), n
+	for bit, f := range layout.fields {
+		if mask&(1<<bit) == 0 {
+			continue
+		}
+		field := v.Field(f.index)
+		switch f.kind {
+		case reflect.Bool:
+			if pos >= len(data) {
+				return mask, pos, errShort
+			}
+			field.SetBool(data[pos] != 0)
+			pos++
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			x, n := binary.Varint(data[pos:])
+			if n <= 0 {
+				return mask, pos, errShort
+			}
+			field.SetInt(x)
+			pos += n
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			x, n := binary.Uvarint(data[pos:])
+			if n <= 0 {
+				return mask, pos, errShort
+			}
+			field.SetUint(x)
+			pos += n
+		case reflect.Float32:
+			if pos+4 > len(data) {
+				return mask, pos, errShort
+			}
+			field.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(data[pos:]))))
+			pos += 4
+		case reflect.Float64:
+			if pos+8 > len(data) {
+				return mask, pos, errShort
+			}
+			field.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(data[pos:])))
+			pos += 8
+		case reflect.String:
+			size, n := binary.Uvarint(data[pos:])
+			if n <= 0 || size > uint64(len(data)-pos-n) {
+				return mask, pos, errShort
+			}
+			pos += n
+			field.SetString(string(data[pos : pos+int(size)]))
+			pos += int(size)
+		default:
+			return mask, pos, fmt.Errorf("goecs: field %s of kind %s can't be delta decoded", f.name, f.kind)
+		}
+	}
+	return mask, pos, nil
+}
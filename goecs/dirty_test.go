@@ -0,0 +1,180 @@
+package goecs
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type deltaProbe struct {
+	Alive bool
+	HP    int
+	Ammo  uint16
+	Speed float32
+	X     float64
+	Name  string
+	Tags  []string
+	Owner *Goent
+}
+
+type dirtyOther struct {
+	HP int
+}
+
+func TestApplyDeltaRoundTrip(t *testing.T) {
+	src := deltaProbe{Alive: true, HP: -7, Ammo: 300, Speed: 1.5, X: -2.25, Name: "orc"}
+	mask := FieldMask[deltaProbe]("Alive", "HP", "Ammo", "Speed", "X", "Name")
+	data, err := AppendDelta(nil, &src, mask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dst deltaProbe
+	got, n, err := ApplyDelta(&dst, data)
+	if err != nil || got != mask || n != len(data) {
+		t.Fatalf("ApplyDelta = %b, %d, %v, want %b, %d, nil", got, n, err, mask, len(data))
+	}
+	if dst.Alive != src.Alive || dst.HP != src.HP || dst.Ammo != src.Ammo || dst.Speed != src.Speed || dst.X != src.X || dst.Name != src.Name {
+		t.Errorf("decoded %+v, want %+v", dst, src)
+	}
+}
+
+func TestApplyDeltaCorrupt(t *testing.T) {
+	mask := func(fields ...string) []byte {
+		return binary.AppendUvarint(nil, uint64(FieldMask[deltaProbe](fields...)))
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"unterminated mask", []byte{0x80}},
+		{"missing bool", mask("Alive")},
+		{"missing int", mask("HP")},
+		{"unterminated int", append(mask("HP"), 0x80)},
+		{"missing uint", mask("Ammo")},
+		{"short float32", append(mask("Speed"), 1, 2, 3)},
+		{"short float64", append(mask("X"), 1, 2, 3, 4, 5, 6, 7)},
+		{"missing string length", mask("Name")},
+		{"string past end", append(binary.AppendUvarint(mask("Name"), 4), 'o', 'r')},
+		{"huge string length", binary.AppendUvarint(mask("Name"), math.MaxUint64-1)},
+		{"string length wrapping int", binary.AppendUvarint(mask("Name"), 1<<63)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst deltaProbe
+			_, n, err := ApplyDelta(&dst, tt.data)
+			if !errors.Is(err, errShort) {
+				t.Errorf("err = %v, want a truncated delta error", err)
+			}
+			if n > len(tt.data) {
+				t.Errorf("consumed %d bytes of %d", n, len(tt.data))
+			}
+		})
+	}
+}
+
+func TestApplyDeltaUnsupportedKind(t *testing.T) {
+	data := binary.AppendUvarint(nil, uint64(FieldMask[deltaProbe]("Tags")))
+	var dst deltaProbe
+	if _, _, err := ApplyDelta(&dst, data); err == nil || errors.Is(err, errShort) {
+		t.Errorf("err = %v, want an unsupported kind error", err)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	owner := Goent(3)
+	tests := []struct {
+		name    string
+		field   string
+		value   interface{}
+		wantErr bool
+		dirty   bool
+		check   func(p *deltaProbe) bool
+	}{
+		{"assignable", "HP", 5, false, true, func(p *deltaProbe) bool { return p.HP == 5 }},
+		{"convertible", "Speed", 2.5, false, true, func(p *deltaProbe) bool { return p.Speed == 2.5 }},
+		{"unchanged", "HP", 1, false, false, func(p *deltaProbe) bool { return p.HP == 1 }},
+		{"nil slice", "Tags", nil, false, true, func(p *deltaProbe) bool { return p.Tags == nil }},
+		{"nil pointer", "Owner", nil, false, true, func(p *deltaProbe) bool { return p.Owner == nil }},
+		{"nil int", "HP", nil, true, false, func(p *deltaProbe) bool { return p.HP == 1 }},
+		{"wrong type", "Name", 4.5, true, false, func(p *deltaProbe) bool { return p.Name == "orc" }},
+		{"unknown field", "Mana", 1, true, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			e := r.CreateEntity()
+			p := EmplaceComponent(r, e, deltaProbe{HP: 1, Name: "orc", Tags: []string{"a"}, Owner: &owner})
+			err := Patch[deltaProbe](r, e, tt.field, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if dirty := DirtyFields[deltaProbe](r, e) != 0; dirty != tt.dirty {
+				t.Errorf("dirty = %v, want %v", dirty, tt.dirty)
+			}
+			if tt.check != nil && !tt.check(p) {
+				t.Errorf("component is %+v after the patch", *p)
+			}
+		})
+	}
+}
+
+func TestPatchMissing(t *testing.T) {
+	r := NewRegistry()
+	e := r.CreateEntity()
+	if err := Patch[deltaProbe](r, e, "HP", 1); !errors.Is(err, ErrComponentMissing) {
+		t.Errorf("err = %v, want ErrComponentMissing", err)
+	}
+	r.DestroyEntity(e)
+	if err := Patch[deltaProbe](r, e, "HP", 1); !errors.Is(err, ErrEntityNotAlive) {
+		t.Errorf("err = %v, want ErrEntityNotAlive", err)
+	}
+}
+
+// TestPatchParallelSystems patches two types from systems with disjoint
+// Writes, which the scheduler runs in one stage. Run with -race.
+func TestPatchParallelSystems(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(64)
+	for _, e := range entities {
+		EmplaceComponent(r, e, deltaProbe{})
+		EmplaceComponent(r, e, dirtyOther{})
+	}
+	s := NewScheduler(2)
+	defer s.Close()
+	// both systems wait for each other, so they run on separate workers
+	// even on a single CPU
+	var started sync.WaitGroup
+	started.Add(2)
+	s.Add("probe", SystemAccess{Writes: []reflect.Type{ComponentType[deltaProbe]()}}, func(r *Registry) {
+		started.Done()
+		started.Wait()
+		for i, e := range entities {
+			if err := Patch[deltaProbe](r, e, "HP", i+1); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+	s.Add("other", SystemAccess{Writes: []reflect.Type{ComponentType[dirtyOther]()}}, func(r *Registry) {
+		started.Done()
+		started.Wait()
+		for i, e := range entities {
+			if err := Patch[dirtyOther](r, e, "HP", i+1); err != nil {
+				t.Error(err)
+			}
+		}
+		ClearDirty[dirtyOther](r)
+	})
+	if plan := s.Plan(); len(plan) != 1 {
+		t.Fatalf("plan = %v, want both systems in one stage", plan)
+	}
+	s.Run(r)
+	for _, e := range entities {
+		if DirtyFields[deltaProbe](r, e) != FieldMask[deltaProbe]("HP") || DirtyFields[dirtyOther](r, e) != 0 {
+			t.Fatalf("entity %d masks = %b, %b", e, DirtyFields[deltaProbe](r, e), DirtyFields[dirtyOther](r, e))
+		}
+	}
+}
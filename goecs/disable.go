@@ -0,0 +1,33 @@
+package goecs
+
+// --- Disabled entities ---
+// Disabling an entity takes it out of iteration while it keeps all its
+// components, for paused enemies or pooled objects waiting to be reused.
+// It is a Disabled tag under the hood, which every iteration (views,
+// IterateReflective, groups and budgeted passes included) excludes as if
+// Without[Disabled]() had been passed. IncludeDisabled() opts out, which is
+// also needed to iterate Disabled itself. Direct lookups such as
+// GetComponent aren't affected.
+
+// Disabled tags a disabled entity, see Registry.Disable.
+type Disabled struct{}
+
+// Disable excludes the entity from iteration until Enable.
+func (r *Registry) Disable(entity Goent) {
+	EmplaceComponent(r, entity, Disabled{})
+}
+
+// Enable makes a disabled entity visible to iteration again.
+func (r *Registry) Enable(entity Goent) {
+	RemoveComponent[Disabled](r, entity)
+}
+
+// IsEnabled reports whether the entity is alive and not disabled.
+func (r *Registry) IsEnabled(entity Goent) bool {
+	return r.IsAlive(entity) && !HasComponent[Disabled](r, entity)
+}
+
+// IncludeDisabled makes an iteration visit disabled entities too.
+func IncludeDisabled() Filter {
+	return Filter{includeDisabled: true}
+}
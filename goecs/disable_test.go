@@ -0,0 +1,78 @@
+package goecs
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDisable(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			entities := newIterWorld(r, 6)
+			r.Disable(entities[1])
+			r.Disable(entities[4])
+			r.Disable(entities[4])
+			r.Enable(entities[4])
+			if r.IsEnabled(entities[1]) || !r.IsEnabled(entities[4]) {
+				t.Fatal("IsEnabled doesn't follow Disable and Enable")
+			}
+
+			collect := func(each func(f func(e Goent, a *iterA))) []int {
+				var got []int
+				each(func(e Goent, a *iterA) { got = append(got, a.V) })
+				return sortedValues(got)
+			}
+			tests := []struct {
+				name string
+				each func(f func(e Goent, a *iterA))
+				want []int
+			}{
+				{"Iterate1", func(f func(Goent, *iterA)) { Iterate1(r, f) }, []int{0, 2, 3, 4, 5}},
+				{"IncludeDisabled", func(f func(Goent, *iterA)) { Iterate1(r, f, IncludeDisabled()) }, []int{0, 1, 2, 3, 4, 5}},
+				{"View", func(f func(Goent, *iterA)) { NewView1[iterA](r).Each(f) }, []int{0, 2, 3, 4, 5}},
+				{"IterateBudget1", func(f func(Goent, *iterA)) {
+					var cur BudgetCursor
+					IterateBudget1(r, &cur, time.Now().Add(time.Hour), f)
+				}, []int{0, 2, 3, 4, 5}},
+			}
+			for _, tt := range tests {
+				if got := collect(tt.each); !slices.Equal(got, tt.want) {
+					t.Errorf("%s visited %v, want %v", tt.name, got, tt.want)
+				}
+			}
+
+			// direct lookups still see disabled entities
+			if a, ok := GetComponent[iterA](r, entities[1]); !ok || a.V != 1 {
+				t.Errorf("GetComponent of a disabled entity = %v, %v", a, ok)
+			}
+			var disabled []Goent
+			Iterate1(r, func(e Goent, _ *Disabled) { disabled = append(disabled, e) }, IncludeDisabled())
+			if !slices.Equal(disabled, entities[1:2]) {
+				t.Errorf("disabled entities = %v", disabled)
+			}
+		})
+	}
+}
+
+func TestDisableDuringIteration(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(3)
+	for i, e := range entities {
+		EmplaceComponent(r, e, iterA{V: i})
+	}
+	// the Disabled storage exists but is empty when the pass starts
+	r.Disable(entities[0])
+	r.Enable(entities[0])
+	var seen []Goent
+	Iterate1(r, func(e Goent, _ *iterA) {
+		seen = append(seen, e)
+		if e == entities[0] {
+			r.Disable(entities[2])
+		}
+	})
+	if len(seen) != 2 || slices.Contains(seen, entities[2]) {
+		t.Errorf("visited %v, want the entity disabled mid-pass skipped", seen)
+	}
+}
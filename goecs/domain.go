@@ -0,0 +1,74 @@
+package goecs
+
+// --- Visibility domains ---
+// One registry can hold the state of several parties that mustn't see each
+// other's secrets, such as the hands of the players of a card game. An entity
+// is restricted to the domains set in its Domains component; entities
+// without one are public. A viewer is the mask of domains it belongs to and
+// sees the public entities plus those sharing a domain with it. The
+// restriction applies where state leaves the server: iteration with the
+// InDomain filter, deltas written with WriteDeltaFor, dirty fields walked
+// with EachDirtyFor, and timelines inspected through InspectAs or HandlerFor.
+//
+//	r.SetDomains(card, goecs.Domain(player))
+//	goecs.Iterate1(r, draw, goecs.InDomain(goecs.Domain(player)))
+
+// DomainMask is a set of up to 64 visibility domains.
+type DomainMask uint64
+
+// AllDomains is the viewer that sees every entity, e.g. the server itself.
+const AllDomains = ^DomainMask(0)
+
+// Domain returns the mask holding just domain i, which must be below 64.
+func Domain(i int) DomainMask {
+	return 1 << uint(i)
+}
+
+// Domains restricts an entity to the domains in Mask. An empty mask hides the
+// entity from every viewer but AllDomains.
+type Domains struct {
+	Mask DomainMask
+}
+
+// SetDomains restricts the entity to the domains in mask.
+func (r *Registry) SetDomains(entity Goent, mask DomainMask) {
+	EmplaceComponent(r, entity, Domains{Mask: mask})
+}
+
+// MakePublic lifts the entity's restriction, making it visible to everyone.
+func (r *Registry) MakePublic(entity Goent) {
+	RemoveComponent[Domains](r, entity)
+}
+
+// DomainsOf returns the entity's domains, or false if it is public.
+func (r *Registry) DomainsOf(entity Goent) (DomainMask, bool) {
+	d, ok := GetComponent[Domains](r, entity)
+	if !ok {
+		return 0, false
+	}
+	return d.Mask, true
+}
+
+// VisibleTo reports whether the viewer may see the entity.
+func (r *Registry) VisibleTo(entity Goent, viewer DomainMask) bool {
+	mask, restricted := r.DomainsOf(entity)
+	return visible(mask, restricted, viewer)
+}
+
+func visible(mask DomainMask, restricted bool, viewer DomainMask) bool {
+	return !restricted || viewer == AllDomains || mask&viewer != 0
+}
+
+// InDomain skips the entities the viewer may not see.
+func InDomain(viewer DomainMask) Filter {
+	return Filter{viewer: viewer, hasViewer: true}
+}
+
+// EachDirtyFor is EachDirty restricted to the entities the viewer may see.
+func EachDirtyFor[T any](r *Registry, viewer DomainMask, f func(entity Goent, c *T, mask DirtyMask)) {
+	EachDirty(r, func(entity Goent, c *T, mask DirtyMask) {
+		if r.VisibleTo(entity, viewer) {
+			f(entity, c, mask)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package goecs
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestDomains(t *testing.T) {
+	r := NewRegistry()
+	public, alice, bob, shared, hidden := r.CreateEntity(), r.CreateEntity(), r.CreateEntity(), r.CreateEntity(), r.CreateEntity()
+	for i, e := range []Goent{public, alice, bob, shared, hidden} {
+		EmplaceComponent(r, e, deltaPos{X: i})
+	}
+	r.SetDomains(alice, Domain(0))
+	r.SetDomains(bob, Domain(1))
+	r.SetDomains(shared, Domain(0)|Domain(1))
+	r.SetDomains(hidden, 0)
+
+	tests := []struct {
+		name   string
+		viewer DomainMask
+		want   []Goent
+	}{
+		{"alice", Domain(0), []Goent{public, alice, shared}},
+		{"bob", Domain(1), []Goent{public, bob, shared}},
+		{"spectator", 0, []Goent{public}},
+		{"server", AllDomains, []Goent{public, alice, bob, shared, hidden}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen []Goent
+			Iterate1(r, func(e Goent, _ *deltaPos) {
+				seen = append(seen, e)
+				if !r.VisibleTo(e, tt.viewer) {
+					t.Errorf("InDomain visited %d, which VisibleTo hides", e)
+				}
+			}, InDomain(tt.viewer))
+			sortEntities(seen)
+			if !slices.Equal(seen, tt.want) {
+				t.Errorf("visited %v, want %v", seen, tt.want)
+			}
+		})
+	}
+
+	r.MakePublic(bob)
+	if _, restricted := r.DomainsOf(bob); restricted || !r.VisibleTo(bob, Domain(0)) {
+		t.Error("MakePublic left the entity restricted")
+	}
+	if mask, ok := r.DomainsOf(shared); !ok || mask != Domain(0)|Domain(1) {
+		t.Errorf("DomainsOf(shared) = %b, %v", mask, ok)
+	}
+}
+
+func TestWriteDeltaFor(t *testing.T) {
+	src := newReplica()
+	public, secret := src.CreateEntity(), src.CreateEntity()
+	EmplaceComponent(src, public, deltaPos{X: 1})
+	EmplaceComponent(src, secret, deltaPos{X: 2})
+	src.SetDomains(secret, Domain(1))
+
+	replica := newReplica()
+	RegisterComponent[Domains](replica)
+	read := func(base *Baseline) *Baseline {
+		t.Helper()
+		var buf bytes.Buffer
+		next, err := src.WriteDeltaFor(&buf, base, Domain(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := replica.ReadDelta(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return next
+	}
+
+	base := read(nil)
+	if got := replicaState(replica); len(got) != 1 || got[public] != (deltaPos{X: 1}) {
+		t.Errorf("replica = %v, want only the public entity", got)
+	}
+	// sharing the entity brings it into view, revoking it takes it out again
+	src.SetDomains(secret, Domain(0)|Domain(1))
+	base = read(base)
+	if p, ok := GetComponent[deltaPos](replica, secret); !ok || p.X != 2 {
+		t.Errorf("shared entity arrived as %v, %v", p, ok)
+	}
+	src.SetDomains(secret, Domain(1))
+	read(base)
+	if replica.IsAlive(secret) {
+		t.Error("entity that left the view is still on the replica")
+	}
+}
+
+func TestInspectAs(t *testing.T) {
+	r := NewRegistry()
+	e := r.CreateEntity()
+	EmplaceComponent(r, e, timelinePos{X: 1})
+	r.SetDomains(e, Domain(2))
+	tl := NewTimeline(1, 2)
+	tl.Tick(r)
+
+	if _, ok := tl.InspectAs(0, e, Domain(0)); ok {
+		t.Error("InspectAs showed the entity to a viewer outside its domain")
+	}
+	if comps, ok := tl.InspectAs(0, e, Domain(2)); !ok || comps["goecs.timelinePos"] != (timelinePos{X: 1}) {
+		t.Errorf("InspectAs = %v, %v for a viewer in the domain", comps, ok)
+	}
+}
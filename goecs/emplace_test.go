@@ -0,0 +1,139 @@
+package goecs
+
+import "testing"
+
+type emplaceInventory struct {
+	Slots int
+}
+
+func TestGetOrEmplace(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			added := 0
+			OnAdd(r, func(e Goent, _ *emplaceInventory) { added++ })
+			e := r.CreateEntity()
+
+			inv := GetOrEmplace(r, e, emplaceInventory{Slots: 4})
+			if inv == nil || inv.Slots != 4 {
+				t.Fatalf("GetOrEmplace on a new entity = %v", inv)
+			}
+			inv.Slots = 10
+			if again := GetOrEmplace(r, e, emplaceInventory{Slots: 4}); again.Slots != 10 {
+				t.Errorf("GetOrEmplace replaced the stored component with %v", again)
+			}
+			if added != 1 {
+				t.Errorf("OnAdd fired %d times, want once", added)
+			}
+
+			r.DestroyEntity(e)
+			if c := GetOrEmplace(r, e, emplaceInventory{}); c != nil || added != 1 {
+				t.Errorf("GetOrEmplace on a destroyed entity = %v", c)
+			}
+		})
+	}
+}
+
+func TestTryEmplace(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			e := r.CreateEntity()
+			if !TryEmplace(r, e, emplaceInventory{Slots: 1}) {
+				t.Error("TryEmplace on an entity without the component failed")
+			}
+			if TryEmplace(r, e, emplaceInventory{Slots: 2}) {
+				t.Error("TryEmplace reported overwriting an existing component")
+			}
+			if c, _ := GetComponent[emplaceInventory](r, e); c.Slots != 1 {
+				t.Errorf("TryEmplace overwrote the component with %v", c)
+			}
+			r.DestroyEntity(e)
+			if TryEmplace(r, e, emplaceInventory{}) {
+				t.Error("TryEmplace on a destroyed entity succeeded")
+			}
+		})
+	}
+}
+
+func TestEmplaceReturnsStored(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			e := r.CreateEntity()
+			c := EmplaceComponent(r, e, emplaceInventory{Slots: 1})
+			c.Slots = 8
+			if got, _ := GetComponent[emplaceInventory](r, e); got != c || got.Slots != 8 {
+				t.Errorf("stored component = %v, want the returned pointer %p", got, c)
+			}
+			// replacing returns the updated component
+			if c := EmplaceComponent(r, e, emplaceInventory{Slots: 2}); c == nil || c.Slots != 2 {
+				t.Errorf("replacing Emplace returned %v", c)
+			}
+			r.DestroyEntity(e)
+			if c := EmplaceComponent(r, e, emplaceInventory{}); c != nil {
+				t.Errorf("Emplace on a destroyed entity returned %v", c)
+			}
+		})
+	}
+}
+
+func TestEmplaceBatch(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			entities := r.CreateEntities(5)
+			EmplaceComponent(r, entities[0], emplaceInventory{Slots: -1})
+			r.DestroyEntity(entities[2])
+			comps := []emplaceInventory{{0}, {1}, {2}, {3}, {4}}
+			batch := append([]Goent(nil), entities...)
+			EmplaceBatch(r, batch, comps)
+
+			if batch[2] != entities[2] {
+				t.Error("EmplaceBatch changed the caller's slice")
+			}
+			for i, e := range entities {
+				c, ok := GetComponent[emplaceInventory](r, e)
+				if i == 2 {
+					if ok {
+						t.Error("destroyed entity got a component")
+					}
+					continue
+				}
+				if !ok || c.Slots != i {
+					t.Errorf("entity %d holds %v, want %d", i, c, i)
+				}
+			}
+			if n := Count[emplaceInventory](r); n != 4 {
+				t.Errorf("Count = %d, want 4", n)
+			}
+
+			defer func() {
+				if recover() == nil {
+					t.Error("mismatched lengths didn't panic")
+				}
+			}()
+			EmplaceBatch(r, entities, comps[:1])
+		})
+	}
+}
+
+func TestEmplaceBatchUpgrade(t *testing.T) {
+	r := NewRegistry()
+	s := RegisterComponentWithPolicy[emplaceInventory](r, RareComponentPolicy(2))
+	entities := r.CreateEntities(10)
+	comps := make([]emplaceInventory, len(entities))
+	for i := range comps {
+		comps[i].Slots = i
+	}
+	EmplaceBatch(r, entities, comps)
+
+	if s.index != nil {
+		t.Fatal("storage of 10 components didn't upgrade to a sparse array")
+	}
+	for i, e := range entities {
+		if c, _ := GetComponent[emplaceInventory](r, e); c == nil || c.Slots != i {
+			t.Errorf("entity %d holds %v, want %d", i, c, i)
+		}
+	}
+}
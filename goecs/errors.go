@@ -0,0 +1,118 @@
+package goecs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+)
+
+// --- Errors ---
+// Failures callers may want to handle come as sentinel errors, wrapped with
+// details, so they can branch with errors.Is:
+//
+//	if _, err := goecs.Lookup[Health](r, e); errors.Is(err, goecs.ErrEntityNotAlive) {
+//		...
+//	}
+//
+// The core API panics on misuse and reports absence with ok results, which
+// stays the fast path. Lookup and the Checked functions are the error
+// returning counterparts, for code such as scripting bridges and tools that
+// would rather not recover panics. The panic raised for a structural change
+// during a parallel read is an error wrapping ErrStorageLocked as well. Errors
+// specific to one feature are declared next to it: ErrRegistryNotEmpty,
+// ErrIDConflict, ErrKeyConflict and ErrAccessDenied.
+
+var (
+	// ErrEntityNotAlive is returned for handles of destroyed entities.
+	ErrEntityNotAlive = errors.New("goecs: entity is not alive")
+	// ErrComponentMissing is returned when an entity lacks the component.
+	ErrComponentMissing = errors.New("goecs: entity has no such component")
+	// ErrTypeNotRegistered is returned for component names no type was
+	// registered under.
+	ErrTypeNotRegistered = errors.New("goecs: component type not registered")
+	// ErrStorageLocked is returned for structural changes while a parallel
+	// read phase holds the registry.
+	ErrStorageLocked = errors.New("goecs: storage is locked")
+)
+
+// checkWritable returns an error wrapping ErrStorageLocked inside a parallel
+// read phase.
+func (r *Registry) checkWritable() error {
+	if atomic.LoadInt32(&r.readers) != 0 {
+		return fmt.Errorf("%w: structural change during a parallel read phase", ErrStorageLocked)
+	}
+	return nil
+}
+
+// checkAlive returns an error wrapping ErrEntityNotAlive unless the handle
+// refers to a live entity of this registry.
+func (r *Registry) checkAlive(entity Goent) error {
+	if !r.IsAlive(entity) {
+		return fmt.Errorf("%w: entity %d", ErrEntityNotAlive, entity)
+	}
+	return nil
+}
+
+// Lookup is GetComponent returning why the component can't be had.
+func Lookup[T any](r *Registry, entity Goent) (*T, error) {
+	if err := r.checkAlive(entity); err != nil {
+		return nil, err
+	}
+	comp, ok := GetComponent[T](r, entity)
+	if !ok {
+		return nil, fmt.Errorf("%w: entity %d has no %s", ErrComponentMissing, entity, typeKeyFor[T]())
+	}
+	return comp, nil
+}
+
+// EmplaceChecked is EmplaceComponent returning an error instead of
+// panicking or returning nil.
+func EmplaceChecked[T any](r *Registry, entity Goent, comp T) (*T, error) {
+	if err := r.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := r.checkAlive(entity); err != nil {
+		return nil, err
+	}
+	return EmplaceComponent(r, entity, comp), nil
+}
+
+// RemoveChecked is RemoveComponent returning an error instead of panicking
+// or doing nothing.
+func RemoveChecked[T any](r *Registry, entity Goent) error {
+	if err := r.checkWritable(); err != nil {
+		return err
+	}
+	if err := r.checkAlive(entity); err != nil {
+		return err
+	}
+	if !HasComponent[T](r, entity) {
+		return fmt.Errorf("%w: entity %d has no %s", ErrComponentMissing, entity, typeKeyFor[T]())
+	}
+	RemoveComponent[T](r, entity)
+	return nil
+}
+
+// DestroyChecked is DestroyEntity returning an error instead of panicking
+// or doing nothing.
+func (r *Registry) DestroyChecked(entity Goent) error {
+	if err := r.checkWritable(); err != nil {
+		return err
+	}
+	if err := r.checkAlive(entity); err != nil {
+		return err
+	}
+	r.DestroyEntity(entity)
+	return nil
+}
+
+// ComponentTypeNamed returns the component type registered under name with
+// RegisterNamedComponent.
+func (r *Registry) ComponentTypeNamed(name string) (reflect.Type, error) {
+	info, ok := r.namedComponent(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrTypeNotRegistered, name)
+	}
+	return info.typ, nil
+}
@@ -0,0 +1,56 @@
+package goecs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type saveProbe struct {
+	HP int
+}
+
+type saveTagProbe struct{}
+
+func TestLoadUnknownType(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(r *Registry, e Goent)
+	}{
+		{"component", func(r *Registry, e Goent) {
+			RegisterNamedComponent[saveProbe](r, "saveProbe")
+			EmplaceComponent(r, e, saveProbe{HP: 3})
+		}},
+		{"tag", func(r *Registry, e Goent) {
+			RegisterNamedComponent[saveTagProbe](r, "saveTagProbe")
+			EmplaceComponent(r, e, saveTagProbe{})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := NewRegistry()
+			tt.build(src, src.CreateEntity())
+			var buf bytes.Buffer
+			if err := src.Save(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if err := NewRegistry().Load(&buf); !errors.Is(err, ErrTypeNotRegistered) {
+				t.Errorf("Load err = %v, want ErrTypeNotRegistered", err)
+			}
+		})
+	}
+}
+
+func TestCheckedNotAlive(t *testing.T) {
+	r := NewRegistry()
+	destroyed := r.CreateEntity()
+	r.DestroyEntity(destroyed)
+	for _, entity := range []Goent{destroyed, Goent(1000)} {
+		if _, err := Lookup[saveProbe](r, entity); !errors.Is(err, ErrEntityNotAlive) {
+			t.Errorf("Lookup(%d) err = %v, want ErrEntityNotAlive", entity, err)
+		}
+		if _, err := EmplaceChecked(r, entity, saveProbe{}); !errors.Is(err, ErrEntityNotAlive) {
+			t.Errorf("EmplaceChecked(%d) err = %v, want ErrEntityNotAlive", entity, err)
+		}
+	}
+}
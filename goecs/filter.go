@@ -0,0 +1,127 @@
+package goecs
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// --- Iteration filters ---
+
+// Filter narrows down which entities an iteration visits beyond the
+// component types it asks for. Filters are passed after the callback, e.g.
+// Iterate2(r, f, Without[Frozen]()).
+type Filter struct {
+	without  reflect.Type
+	optional reflect.Type
+	changed  reflect.Type
+	since    uint64
+	// includeDisabled turns off the implicit Without[Disabled]
+	includeDisabled bool
+	// viewer restricts the iteration to what it may see, see InDomain
+	viewer    DomainMask
+	hasViewer bool
+}
+
+// Without skips entities that have a T component.
+func Without[T any]() Filter {
+	return Filter{without: typeKeyFor[T]()}
+}
+
+// Optional marks the iteration's T parameter as optional. Entities lacking
+// T are still visited and the callback receives nil for it. At least one
+// parameter should stay required, otherwise every entity that has any of the
+// parameters is visited.
+func Optional[T any]() Filter {
+	return Filter{optional: typeKeyFor[T]()}
+}
+
+// filterSet is the form of a filter list resolved against a registry once
+// per iteration, so the per-entity check doesn't touch the storage map.
+type filterSet struct {
+	without []SparseSetInterface
+	// disabled is the Disabled storage unless IncludeDisabled was given,
+	// probed only while it holds any entity
+	disabled *SparseSet[Disabled]
+	// withoutTypes is used by the archetype backend to skip whole archetypes
+	withoutTypes []reflect.Type
+	optional     []reflect.Type
+	changed      []changedCheck
+	registry     *Registry
+	// probe counts the candidates while MeasureQuery runs
+	probe *QueryCost
+	// viewers are the InDomain viewers, an entity must be visible to all
+	viewers []DomainMask
+}
+
+// changedCheck is a resolved Changed filter.
+type changedCheck struct {
+	typ   reflect.Type
+	since uint64
+}
+
+func (r *Registry) resolveFilters(filters []Filter) filterSet {
+	var fs filterSet
+	includeDisabled := false
+	for _, filter := range filters {
+		includeDisabled = includeDisabled || filter.includeDisabled
+		if filter.without != nil {
+			fs.withoutTypes = append(fs.withoutTypes, filter.without)
+			// A type nobody has registered can't exclude anything
+			if storage, exists := r.storages[filter.without]; exists {
+				fs.without = append(fs.without, storage)
+			}
+		}
+		if filter.optional != nil {
+			fs.optional = append(fs.optional, filter.optional)
+		}
+		if filter.changed != nil {
+			fs.changed = append(fs.changed, changedCheck{typ: filter.changed, since: filter.since})
+		}
+		if filter.hasViewer {
+			fs.viewers = append(fs.viewers, filter.viewer)
+		}
+	}
+	if !includeDisabled {
+		fs.withoutTypes = append(fs.withoutTypes, typeKeyFor[Disabled]())
+		fs.disabled = getStorage[Disabled](r)
+	}
+	fs.registry = r
+	fs.probe = r.probe
+	return fs
+}
+
+// isOptional reports whether the component type was marked Optional.
+func (fs *filterSet) isOptional(t reflect.Type) bool {
+	for _, optional := range fs.optional {
+		if optional == t {
+			return true
+		}
+	}
+	return false
+}
+
+// skip reports whether the filters reject the entity.
+func (fs *filterSet) skip(entity Goent) bool {
+	if fs.probe != nil {
+		atomic.AddInt64(&fs.probe.Candidates, 1)
+	}
+	if fs.disabled != nil && fs.disabled.Len() > 0 && fs.disabled.Contains(entity) {
+		return true
+	}
+	for _, storage := range fs.without {
+		if _, ok := storage.GetComponent(entity); ok {
+			return true
+		}
+	}
+	for _, check := range fs.changed {
+		if tick, ok := fs.registry.changeTickOf(entity, check.typ); !ok || tick <= check.since {
+			return true
+		}
+	}
+	for _, viewer := range fs.viewers {
+		if !fs.registry.VisibleTo(entity, viewer) {
+			return true
+		}
+	}
+	return false
+}
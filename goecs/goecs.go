@@ -1,23 +1,147 @@
 package goecs
 
 import (
+	"fmt"
 	"reflect"
+	"slices"
 )
 
 // --- Entity ID definitions ---
 
 // Goent is a typedef for uint64, used for entity IDs. This makes it easier
 // to see what is supposed to be an entity key.
+//
+// The low 32 bits hold the entity index and the high 32 bits its generation.
+// Destroyed indices are recycled with a bumped generation, so a stale handle
+// to a destroyed entity never matches the entity that reuses its index.
 type Goent uint64
 
-// nextEntity is a simple global counter to generate unique entity IDs.
-var nextEntity Goent = 0
+const entityIndexBits = 32
+const entityIndexMask = 1<<entityIndexBits - 1
 
-// CreateEntity returns a new unique entity ID.
+// makeGoent packs an index and generation into an entity ID.
+func makeGoent(index, generation uint32) Goent {
+	return Goent(generation)<<entityIndexBits | Goent(index)
+}
+
+// Index returns the slot of the entity, which is what storages are keyed by.
+func (e Goent) Index() uint32 {
+	return uint32(e & entityIndexMask)
+}
+
+// Generation returns how many times the entity's index has been recycled.
+func (e Goent) Generation() uint32 {
+	return uint32(e >> entityIndexBits)
+}
+
+// entityAllocator hands out entity IDs and recycles destroyed ones through a free-list.
+type entityAllocator struct {
+	// generations holds the current generation of every index handed out so far
+	generations []uint32
+	free        []uint32
+}
+
+func (a *entityAllocator) create() Goent {
+	if n := len(a.free); n > 0 {
+		index := a.free[n-1]
+		a.free = a.free[:n-1]
+		return makeGoent(index, a.generations[index])
+	}
+	index := uint32(len(a.generations))
+	a.generations = append(a.generations, 0)
+	return makeGoent(index, 0)
+}
+
+// createMany hands out n IDs, recycled ones first, growing the generations
+// at most once.
+func (a *entityAllocator) createMany(n int) []Goent {
+	out := make([]Goent, 0, n)
+	for len(out) < n && len(a.free) > 0 {
+		out = append(out, a.create())
+	}
+	if fresh := n - len(out); fresh > 0 {
+		start := uint32(len(a.generations))
+		a.generations = append(a.generations, make([]uint32, fresh)...)
+		for i := 0; i < fresh; i++ {
+			out = append(out, makeGoent(start+uint32(i), 0))
+		}
+	}
+	return out
+}
+
+// known reports whether the entity's index was handed out by this allocator.
+func (a *entityAllocator) known(e Goent) bool {
+	return int(e.Index()) < len(a.generations)
+}
+
+// alive reports whether the handle's generation still matches its index.
+func (a *entityAllocator) alive(e Goent) bool {
+	index := e.Index()
+	return int(index) < len(a.generations) && a.generations[index] == e.Generation()
+}
+
+// release bumps the generation of a live entity and queues its index for reuse.
+func (a *entityAllocator) release(e Goent) bool {
+	if !a.alive(e) {
+		return false
+	}
+	index := e.Index()
+	a.generations[index]++
+	a.free = append(a.free, index)
+	return true
+}
+
+// releaseMany is release for a batch of live entities, growing the free
+// list at most once.
+func (a *entityAllocator) releaseMany(entities []Goent) {
+	a.free = slices.Grow(a.free, len(entities))
+	for _, e := range entities {
+		a.release(e)
+	}
+}
+
+// live returns every live entity, in index order.
+func (a *entityAllocator) live() []Goent {
+	free := make([]bool, len(a.generations))
+	for _, index := range a.free {
+		free[index] = true
+	}
+	var out []Goent
+	for index, gen := range a.generations {
+		if !free[index] {
+			out = append(out, makeGoent(uint32(index), gen))
+		}
+	}
+	return out
+}
+
+// claim hands out exactly e, for mirroring the IDs of another allocator. The
+// index must not be alive. Indices skipped on the way are queued for reuse.
+func (a *entityAllocator) claim(e Goent) {
+	index := e.Index()
+	for uint32(len(a.generations)) <= index {
+		a.free = append(a.free, uint32(len(a.generations)))
+		a.generations = append(a.generations, 0)
+	}
+	a.generations[index] = e.Generation()
+	for i, f := range a.free {
+		if f == index {
+			a.free = append(a.free[:i], a.free[i+1:]...)
+			break
+		}
+	}
+}
+
+// defaultEntities is the package-level allocator behind the CreateEntity shim.
+var defaultEntities = &entityAllocator{}
+
+// CreateEntity returns a new unique entity ID from a package-level allocator
+// that no registry tracks, so registries can't detect stale handles to it.
+//
+// Deprecated: use Registry.CreateEntity, which gives each registry its own
+// ID range. Don't mix the two on the same registry, their indices collide.
 func CreateEntity() Goent {
-	id := nextEntity
-	nextEntity++
-	return id
+	return defaultEntities.create()
 }
 
 // --- ECS core ---
@@ -25,86 +149,303 @@ func CreateEntity() Goent {
 const invalidIndex = -1
 const alignment = 256
 
-func nextAlignedCapacity(n int) int {
-	if n%alignment == 0 {
-		return n
-	}
-	return ((n / alignment) + 1) * alignment
-}
-
 // SparseSetInterface is a non–generic interface used for reflection-based iteration.
 type SparseSetInterface interface {
 	GetComponent(entity Goent) (interface{}, bool)
 	GetDense() []Goent
+	Remove(entity Goent)
 }
 
 // SparseSet stores a dense array of entity IDs and their corresponding component pointers.
+// The sparse array is keyed by entity index, while the dense array keeps the
+// full ID so handles with an outdated generation are rejected.
 type SparseSet[T any] struct {
 	dense      []Goent
 	components []*T
 	sparse     []int
+	policy     GrowthPolicy
+	// group is the owning group keeping this storage's front packed, if any
+	group *ownedGroup
+	// index replaces sparse while the storage is small, see
+	// GrowthPolicy.UpgradeAt
+	index map[uint32]int
+	// ticks holds the change tick of each dense slot, read from clock
+	ticks []uint64
+	clock *uint64
+	// tag is the one shared value of a zero-size type such as Dead{}, whose
+	// storage keeps no components slice at all, see at
+	tag *T
+	// peak is the most components the storage held at once
+	peak int
+	// ordered keeps dense sorted by entity index, see
+	// RegistryOptions.Deterministic
+	ordered bool
+	// stable removes by shifting, keeping insertion order, see
+	// PreserveOrder
+	stable bool
 }
 
-// NewSparseSet creates a new SparseSet with a default aligned capacity.
+// NewSparseSet creates a new SparseSet with the default growth policy.
 func NewSparseSet[T any]() *SparseSet[T] {
-	sparse := make([]int, alignment)
-	for i := range sparse {
-		sparse[i] = invalidIndex
+	return NewSparseSetWithPolicy[T](DefaultGrowthPolicy())
+}
+
+// NewSparseSetWithPolicy creates a new SparseSet that allocates and grows
+// according to the given policy.
+func NewSparseSetWithPolicy[T any](policy GrowthPolicy) *SparseSet[T] {
+	policy = policy.sanitized()
+	ss := &SparseSet[T]{
+		dense:      make([]Goent, 0, policy.InitialCapacity),
+		components: make([]*T, 0, policy.InitialCapacity),
+		ticks:      make([]uint64, 0, policy.InitialCapacity),
+		policy:     policy,
+	}
+	if t := typeKeyFor[T](); t != nil && t.Size() == 0 {
+		ss.tag = new(T)
+		ss.components = nil
+	}
+	if policy.UpgradeAt > 0 {
+		ss.index = make(map[uint32]int)
+		return ss
 	}
-	return &SparseSet[T]{
-		dense:      make([]Goent, 0, alignment),
-		components: make([]*T, 0, alignment),
-		sparse:     sparse,
+	ss.sparse = make([]int, policy.InitialCapacity)
+	for i := range ss.sparse {
+		ss.sparse[i] = invalidIndex
+	}
+	return ss
+}
+
+// at returns the component in dense slot i. Tags all share one value, there
+// is nothing to tell them apart.
+func (ss *SparseSet[T]) at(i int) *T {
+	if ss.tag != nil {
+		return ss.tag
 	}
+	return ss.components[i]
 }
 
-// Emplace inserts or updates a component for an entity.
-func (ss *SparseSet[T]) Emplace(entity Goent, comp T) {
-	if int(entity) >= len(ss.sparse) {
-		newSize := nextAlignedCapacity(int(entity) + 1)
-		newSparse := make([]int, newSize)
-		for i := range newSparse {
-			newSparse[i] = invalidIndex
+// slot returns the dense index of the entity, or invalidIndex if the entity
+// is not stored or the stored handle has a different generation.
+func (ss *SparseSet[T]) slot(entity Goent) int {
+	i := ss.lookup(entity.Index())
+	if i == invalidIndex || ss.dense[i] != entity {
+		return invalidIndex
+	}
+	return i
+}
+
+// lookup returns the dense index stored for an entity index, whatever its
+// generation, or invalidIndex.
+func (ss *SparseSet[T]) lookup(index uint32) int {
+	if ss.index != nil {
+		if i, ok := ss.index[index]; ok {
+			return i
 		}
-		copy(newSparse, ss.sparse)
-		ss.sparse = newSparse
+		return invalidIndex
 	}
+	if int(index) >= len(ss.sparse) {
+		return invalidIndex
+	}
+	return ss.sparse[index]
+}
 
-	if ss.sparse[int(entity)] != invalidIndex {
-		*ss.components[ss.sparse[int(entity)]] = comp
-		return
+// setSlot points an entity index at a dense index, or at nothing with
+// invalidIndex. The sparse array must already cover the index.
+func (ss *SparseSet[T]) setSlot(index uint32, i int) {
+	if ss.index == nil {
+		ss.sparse[index] = i
+	} else if i == invalidIndex {
+		delete(ss.index, index)
+	} else {
+		ss.index[index] = i
+	}
+}
+
+// Emplace inserts or updates a component for an entity and returns the
+// stored component. A handle older than the one already stored at its index
+// is stale and gets ignored, returning nil.
+func (ss *SparseSet[T]) Emplace(entity Goent, comp T) *T {
+	index := entity.Index()
+	if ss.index == nil {
+		ss.growSparse(int(index) + 1)
+	}
+
+	if i := ss.lookup(index); i != invalidIndex {
+		stored := ss.dense[i]
+		if entity.Generation() < stored.Generation() {
+			return nil
+		}
+		if stored == entity {
+			c := ss.at(i)
+			*c = comp
+			ss.ticks[i] = ss.now()
+			return c
+		}
+		// A newer entity taking over a leftover slot, drop the old one first
+		ss.Remove(stored)
+	}
+	return ss.push(entity, comp)
+}
+
+// GetOrEmplace returns the entity's component, inserting def first if it has
+// none, with a single sparse lookup. It reports whether def was inserted; a
+// stale handle gets nil.
+func (ss *SparseSet[T]) GetOrEmplace(entity Goent, def T) (*T, bool) {
+	index := entity.Index()
+	if ss.index == nil {
+		ss.growSparse(int(index) + 1)
+	}
+
+	if i := ss.lookup(index); i != invalidIndex {
+		stored := ss.dense[i]
+		if stored == entity {
+			return ss.at(i), false
+		}
+		if entity.Generation() < stored.Generation() {
+			return nil, false
+		}
+		ss.Remove(stored)
 	}
+	return ss.push(entity, def), true
+}
 
-	index := len(ss.dense)
+// push appends a component for an entity that has none and returns it.
+func (ss *SparseSet[T]) push(entity Goent, comp T) *T {
+	return ss.insert(entity, &comp)
+}
+
+// insert appends c for the entity, which must not be in the set.
+func (ss *SparseSet[T]) insert(entity Goent, c *T) *T {
+	i := len(ss.dense)
+	ss.reserve(i + 1)
 	ss.dense = append(ss.dense, entity)
-	ss.components = append(ss.components, &comp)
-	ss.sparse[int(entity)] = index
+	if ss.tag == nil {
+		ss.components = append(ss.components, c)
+	} else {
+		c = ss.tag
+	}
+	ss.ticks = append(ss.ticks, ss.now())
+	ss.setSlot(entity.Index(), i)
+	if ss.ordered {
+		ss.settle(i)
+	}
+	ss.peak = max(ss.peak, len(ss.dense))
+	if ss.index != nil && len(ss.dense) > ss.policy.UpgradeAt {
+		ss.upgrade()
+	}
+
+	if ss.group != nil {
+		ss.group.onAdd(entity)
+	}
+	return c
+}
+
+// EmplaceBatch emplaces comps[i] for entities[i], like Emplace in a loop, but
+// grows the arrays once and stores the new components in one allocation.
+func (ss *SparseSet[T]) EmplaceBatch(entities []Goent, comps []T) {
+	size := 0
+	for _, entity := range entities {
+		size = max(size, int(entity.Index())+1)
+	}
+	// Upgrade up front, insert would otherwise do it partway through with a
+	// sparse array sized for the entities stored so far
+	if ss.index != nil && len(ss.dense)+len(entities) > ss.policy.UpgradeAt {
+		ss.upgrade()
+	}
+	ss.reserveIndex(size)
+	ss.reserve(len(ss.dense) + len(entities))
+	var block []T
+	if ss.tag == nil {
+		block = make([]T, 0, len(entities))
+	}
+	for n, entity := range entities {
+		index := entity.Index()
+		if i := ss.lookup(index); i != invalidIndex {
+			stored := ss.dense[i]
+			if entity.Generation() < stored.Generation() {
+				continue
+			}
+			if stored == entity {
+				*ss.at(i) = comps[n]
+				ss.ticks[i] = ss.now()
+				continue
+			}
+			ss.Remove(stored)
+		}
+		if block == nil {
+			ss.push(entity, comps[n])
+			continue
+		}
+		block = append(block, comps[n])
+		ss.insert(entity, &block[len(block)-1])
+	}
 }
 
 // Get retrieves a pointer to the component.
 func (ss *SparseSet[T]) Get(entity Goent) (*T, bool) {
-	if int(entity) >= len(ss.sparse) || ss.sparse[int(entity)] == invalidIndex {
+	i := ss.slot(entity)
+	if i == invalidIndex {
 		return nil, false
 	}
-	return ss.components[ss.sparse[int(entity)]], true
+	return ss.at(i), true
+}
+
+// Contains reports whether the entity has a component in the set.
+func (ss *SparseSet[T]) Contains(entity Goent) bool {
+	return ss.slot(entity) != invalidIndex
+}
+
+// Len returns the number of components in the set.
+func (ss *SparseSet[T]) Len() int {
+	return len(ss.dense)
 }
 
 // Remove deletes a component for an entity.
 func (ss *SparseSet[T]) Remove(entity Goent) {
-	if int(entity) >= len(ss.sparse) || ss.sparse[int(entity)] == invalidIndex {
+	if ss.slot(entity) == invalidIndex {
+		return
+	}
+	if ss.group != nil {
+		// Move it out of the packed group region before the swap below
+		ss.group.onRemove(entity)
+	}
+	index := ss.slot(entity)
+	if ss.ordered || ss.stable {
+		ss.removeOrdered(index)
 		return
 	}
-	index := ss.sparse[int(entity)]
 	lastIndex := len(ss.dense) - 1
 	lastEntity := ss.dense[lastIndex]
 
 	ss.dense[index] = lastEntity
-	ss.components[index] = ss.components[lastIndex]
-	ss.sparse[int(lastEntity)] = index
-
+	ss.ticks[index] = ss.ticks[lastIndex]
+	ss.setSlot(lastEntity.Index(), index)
 	ss.dense = ss.dense[:lastIndex]
-	ss.components = ss.components[:lastIndex]
-	ss.sparse[int(entity)] = invalidIndex
+	if ss.tag == nil {
+		ss.components[index] = ss.components[lastIndex]
+		ss.components = ss.components[:lastIndex]
+	}
+	ss.ticks = ss.ticks[:lastIndex]
+	ss.setSlot(entity.Index(), invalidIndex)
+}
+
+// Clear removes every component, keeping the capacity of the arrays.
+func (ss *SparseSet[T]) Clear() {
+	if ss.index != nil {
+		clear(ss.index)
+	} else {
+		for _, entity := range ss.dense {
+			ss.sparse[entity.Index()] = invalidIndex
+		}
+	}
+	if ss.group != nil {
+		// no entity has this type now, so none is left in the group
+		ss.group.size = 0
+	}
+	clear(ss.components)
+	ss.components = ss.components[:0]
+	ss.dense = ss.dense[:0]
+	ss.ticks = ss.ticks[:0]
 }
 
 // GetComponent implements SparseSetInterface.
@@ -121,11 +462,104 @@ func (ss *SparseSet[T]) GetDense() []Goent {
 type Registry struct {
 	// Use reflect.Type instead of string for keys
 	storages map[reflect.Type]SparseSetInterface
+	// entities allocates this registry's IDs and tracks which generations are alive
+	entities entityAllocator
+	// identities holds the component types whose presence means an entity exists
+	identities map[reflect.Type]struct{}
+	// archetypes is the archetype backend, nil in sparse set mode
+	archetypes *archetypeStore
+	// readers counts the running ParallelRead phases, which lock out writes
+	readers int32
+	// groups are the owning groups created on this registry
+	groups []*ownedGroup
+	// interpolators holds the blend functions registered per component type
+	interpolators map[reflect.Type]interpolator
+	// dirty holds the changed replicated fields per component type and entity
+	dirty map[reflect.Type]map[Goent]DirtyMask
+	// migrations are the component conversions waiting for Migrate
+	migrations []migration
+	// hooks holds the lifecycle hooks per component type
+	hooks map[reflect.Type]hookSet
+	// tick is the change detection clock, see Checkpoint
+	tick uint64
+	// componentTypes and componentNames index every component type stored so
+	// far, for serialization
+	componentTypes map[reflect.Type]*componentInfo
+	componentNames map[string]*componentInfo
+	// ranks caches restoreRanks until the type table changes
+	ranks map[*componentInfo]int
+	// relations holds the relationship edges per relationship type
+	relations map[reflect.Type]relationSet
+	// external key aliases, cleaned up when an entity is destroyed
+	stringAliases aliasTable[string]
+	idAliases     aliasTable[uint64]
+	// names index the entities named with SetName
+	names aliasTable[string]
+	// resources holds the singletons set with SetResource
+	resources map[reflect.Type]interface{}
+	// annotations holds the tooling metadata set with Annotate
+	annotations map[Goent]map[string]string
+	// seeds are the RNG seeds recorded with SetSeed, see BuildInfo
+	seeds map[string]int64
+	// equals holds the func(a, b T) bool registered with RegisterEqual
+	equals map[reflect.Type]interface{}
+	// probe is set while MeasureQuery runs
+	probe *QueryCost
+	// capacity sizes new storages, see RegistryOptions.Capacity
+	capacity *CapacityProfile
+	// ordered is set by RegistryOptions.Deterministic
+	ordered bool
 }
 
 // NewRegistry creates a new ECS registry.
 func NewRegistry() *Registry {
-	return &Registry{storages: make(map[reflect.Type]SparseSetInterface)}
+	r := &Registry{
+		storages:       make(map[reflect.Type]SparseSetInterface),
+		identities:     make(map[reflect.Type]struct{}),
+		interpolators:  make(map[reflect.Type]interpolator),
+		dirty:          make(map[reflect.Type]map[Goent]DirtyMask),
+		hooks:          make(map[reflect.Type]hookSet),
+		componentTypes: make(map[reflect.Type]*componentInfo),
+		componentNames: make(map[string]*componentInfo),
+		relations:      make(map[reflect.Type]relationSet),
+		resources:      make(map[reflect.Type]interface{}),
+		annotations:    make(map[Goent]map[string]string),
+		equals:         make(map[reflect.Type]interface{}),
+		stringAliases:  newAliasTable[string](),
+		idAliases:      newAliasTable[uint64](),
+		names:          newAliasTable[string](),
+		tick:           1,
+	}
+	r.noteBuiltins()
+	return r
+}
+
+// RegistryOptions configures a registry created with NewRegistryWithOptions.
+type RegistryOptions struct {
+	// Storage selects the component storage backend.
+	Storage StorageMode
+	// Capacity, when set, pre-sizes storages for the peaks of an earlier
+	// session, see CapacityProfile.
+	Capacity *CapacityProfile
+	// Deterministic keeps storages ordered by entity index, so iteration
+	// order doesn't depend on history. Sparse set storage only.
+	Deterministic bool
+}
+
+// NewRegistryWithOptions creates a new ECS registry with the given options.
+func NewRegistryWithOptions(opts RegistryOptions) *Registry {
+	r := NewRegistry()
+	if opts.Storage == ArchetypeStorage {
+		if opts.Deterministic {
+			panic("goecs: deterministic order needs sparse set storage")
+		}
+		r.archetypes = newArchetypeStore(&r.tick)
+	}
+	r.ordered = opts.Deterministic
+	if opts.Capacity != nil {
+		r.applyCapacity(opts.Capacity)
+	}
+	return r
 }
 
 // typeKeyFor generates a reflection type key for a component type.
@@ -137,26 +571,154 @@ func typeKeyFor[T any]() reflect.Type {
 // RegisterComponent registers a new component type. EmplaceComponent does
 // this same logic if needed.
 func RegisterComponent[T any](r *Registry) *SparseSet[T] {
+	return RegisterComponentWithPolicy[T](r, DefaultGrowthPolicy())
+}
+
+// RegisterComponentWithPolicy registers a new component type whose storage
+// allocates and grows according to the given policy.
+func RegisterComponentWithPolicy[T any](r *Registry, policy GrowthPolicy) *SparseSet[T] {
+	r.assertWritable()
 	key := typeKeyFor[T]()
-	set := NewSparseSet[T]()
+	set := NewSparseSetWithPolicy[T](policy)
+	set.clock = &r.tick
+	set.ordered = r.ordered
 	r.storages[key] = set
+	r.prewarm(noteComponent[T](r))
 	return set
 }
 
-// EmplaceComponent adds or replaces a component by entity id.
-func EmplaceComponent[T any](r *Registry, entity Goent, comp T) {
-	key := typeKeyFor[T]()
-	storageInterface, exists := r.storages[key]
-	if !exists {
-		storageInterface = NewSparseSet[T]()
-		r.storages[key] = storageInterface
+// EmplaceComponent adds or replaces a component by entity id and returns the
+// stored component, so callers can finish initializing it without another
+// lookup. Handles of destroyed entities are rejected and get nil.
+//
+// With the archetype backend the pointer is only valid until the entity's
+// component set changes, as with GetComponent. Writing a value equal to the
+// stored one under RegisterEqual changes nothing, not even the change tick.
+func EmplaceComponent[T any](r *Registry, entity Goent, comp T) *T {
+	r.assertWritable()
+	if r.isStale(entity) {
+		return nil
 	}
-	storage := storageInterface.(*SparseSet[T])
-	storage.Emplace(entity, comp)
+	if eq := equalFor[T](r); eq != nil {
+		if old, ok := GetComponent[T](r, entity); ok && eq(*old, comp) {
+			return old
+		}
+	}
+	hooks := hooksFor[T](r)
+	replacing := false
+	if hooks != nil {
+		_, replacing = GetComponent[T](r, entity)
+	}
+	var c *T
+	if r.archetypes != nil {
+		if _, known := r.componentTypes[typeKeyFor[T]()]; !known {
+			noteComponent[T](r)
+		}
+		archEmplace(r.archetypes, entity, comp)
+		c, _ = archGet[T](r.archetypes, entity)
+	} else {
+		c = ensureStorage[T](r).Emplace(entity, comp)
+	}
+	if hooks == nil || c == nil {
+		return c
+	}
+	if replacing {
+		hooks.fire(hooks.update, entity, c)
+	} else {
+		hooks.fire(hooks.add, entity, c)
+	}
+	if r.archetypes != nil {
+		// a hook changing the entity's components moves it to another archetype
+		c, _ = archGet[T](r.archetypes, entity)
+	}
+	return c
+}
+
+// EmplaceBatch emplaces comps[i] for entities[i], skipping destroyed
+// entities. It is much faster than EmplaceComponent in a loop for spawning
+// many entities: the storage is grown once and the new components share one
+// allocation. Types with hooks or an equality registered, and the archetype
+// backend, still go through EmplaceComponent one entity at a time.
+func EmplaceBatch[T any](r *Registry, entities []Goent, comps []T) {
+	r.assertWritable()
+	if len(entities) != len(comps) {
+		panic(fmt.Sprintf("goecs: EmplaceBatch got %d entities and %d components", len(entities), len(comps)))
+	}
+	if r.archetypes != nil || hooksFor[T](r) != nil || equalFor[T](r) != nil {
+		for i, entity := range entities {
+			EmplaceComponent(r, entity, comps[i])
+		}
+		return
+	}
+	for i, entity := range entities {
+		if r.isStale(entity) {
+			// filter into copies, the caller's slices stay untouched
+			live := append([]Goent(nil), entities[:i]...)
+			kept := append([]T(nil), comps[:i]...)
+			for j := i + 1; j < len(entities); j++ {
+				if !r.isStale(entities[j]) {
+					live = append(live, entities[j])
+					kept = append(kept, comps[j])
+				}
+			}
+			entities, comps = live, kept
+			break
+		}
+	}
+	ensureStorage[T](r).EmplaceBatch(entities, comps)
+}
+
+// GetOrEmplace returns the entity's T component, emplacing def first if it
+// has none. It returns nil for a destroyed entity.
+func GetOrEmplace[T any](r *Registry, entity Goent, def T) *T {
+	r.assertWritable()
+	if r.isStale(entity) {
+		return nil
+	}
+	if r.archetypes != nil {
+		if c, ok := archGet[T](r.archetypes, entity); ok {
+			return c
+		}
+		return EmplaceComponent(r, entity, def)
+	}
+	c, added := ensureStorage[T](r).GetOrEmplace(entity, def)
+	if added {
+		if hooks := hooksFor[T](r); hooks != nil {
+			hooks.fire(hooks.add, entity, c)
+		}
+	}
+	return c
+}
+
+// TryEmplace adds the component only if the entity doesn't have a T yet and
+// reports whether it did, so two systems initializing the same type notice
+// each other instead of one silently overwriting the other.
+func TryEmplace[T any](r *Registry, entity Goent, comp T) bool {
+	r.assertWritable()
+	if r.isStale(entity) {
+		return false
+	}
+	if r.archetypes != nil {
+		if HasComponent[T](r, entity) {
+			return false
+		}
+		EmplaceComponent(r, entity, comp)
+		return true
+	}
+	c, added := ensureStorage[T](r).GetOrEmplace(entity, comp)
+	if added {
+		if hooks := hooksFor[T](r); hooks != nil {
+			hooks.fire(hooks.add, entity, c)
+		}
+	}
+	return added
 }
 
 // GetComponent retrieves a pointer to a component.
 func GetComponent[T any](r *Registry, entity Goent) (*T, bool) {
+	if r.archetypes != nil {
+		return archGet[T](r.archetypes, entity)
+	}
 	key := typeKeyFor[T]()
 	storageInterface, exists := r.storages[key]
 	if !exists {
@@ -166,17 +728,213 @@ func GetComponent[T any](r *Registry, entity Goent) (*T, bool) {
 	return storage.Get(entity)
 }
 
+// HasComponent reports whether the entity has a T component, without
+// fetching it.
+func HasComponent[T any](r *Registry, entity Goent) bool {
+	if r.archetypes != nil {
+		return r.archetypes.has(entity, typeKeyFor[T]())
+	}
+	if storage, exists := r.storages[typeKeyFor[T]()]; exists {
+		return storage.(*SparseSet[T]).Contains(entity)
+	}
+	return false
+}
+
 // RemoveComponent removes a component by entity id.
 func RemoveComponent[T any](r *Registry, entity Goent) {
+	r.assertWritable()
 	key := typeKeyFor[T]()
+	r.fireRemoveHook(entity, key)
+	if r.archetypes != nil {
+		r.archetypes.remove(entity, key)
+		return
+	}
 	if storageInterface, exists := r.storages[key]; exists {
 		storage := storageInterface.(*SparseSet[T])
 		storage.Remove(entity)
 	}
 }
 
+// ClearComponent removes the T component of every entity, e.g. the event
+// components of a frame, in one pass over the storage instead of one
+// removal per entity. OnRemove hooks fire for every component first.
+func ClearComponent[T any](r *Registry) {
+	r.assertWritable()
+	key := typeKeyFor[T]()
+	if r.archetypes != nil {
+		var owners []Goent
+		r.archetypes.each(&filterSet{}, []reflect.Type{key}, func(a *archetype) {
+			owners = append(owners, a.entities...)
+		})
+		for _, entity := range owners {
+			RemoveComponent[T](r, entity)
+		}
+		return
+	}
+	storage := getStorage[T](r)
+	if storage == nil {
+		return
+	}
+	if _, hooked := r.hooks[key]; hooked {
+		for _, entity := range append([]Goent(nil), storage.dense...) {
+			r.fireRemoveHook(entity, key)
+		}
+	}
+	storage.Clear()
+}
+
+// SortStorage reorders the T storage so iteration visits the components in
+// the order given by less, e.g. render state by depth and material. The
+// components stay where they are; only the dense order and sparse indices
+// change. Entities that compare equal keep their relative order. It panics
+// on archetype and deterministic registries and for storages owned by a
+// group, whose order the group maintains.
+func SortStorage[T any](r *Registry, less func(a, b *T) bool) {
+	r.assertWritable()
+	if r.archetypes != nil {
+		panic("goecs: SortStorage needs sparse set storage")
+	}
+	if s := getStorage[T](r); s != nil {
+		s.Sort(less)
+	}
+}
+
+// Sort reorders the storage by less, see SortStorage.
+func (ss *SparseSet[T]) Sort(less func(a, b *T) bool) {
+	if ss.group != nil {
+		panic("goecs: can't sort a storage owned by a group")
+	}
+	if ss.ordered {
+		panic("goecs: can't sort a storage of a deterministic registry")
+	}
+	if ss.tag != nil || len(ss.dense) < 2 {
+		return
+	}
+	order := slices.Clone(ss.dense)
+	slices.SortStableFunc(order, func(a, b Goent) int {
+		ca, cb := ss.at(ss.slot(a)), ss.at(ss.slot(b))
+		switch {
+		case less(ca, cb):
+			return -1
+		case less(cb, ca):
+			return 1
+		}
+		return 0
+	})
+	for i, entity := range order {
+		ss.swapSlots(ss.slot(entity), i)
+	}
+}
+
+// CreateEntity returns a new unique entity ID from this registry's own ID
+// range, reusing the index of a destroyed entity when one is available.
+func (r *Registry) CreateEntity() Goent {
+	r.assertWritable()
+	return r.entities.create()
+}
+
+// CreateEntities returns n new entities. The sparse arrays of every storage
+// are grown to cover them in one go, so spawning thousands of entities
+// doesn't regrow them over and over as the components are emplaced.
+func (r *Registry) CreateEntities(n int) []Goent {
+	r.assertWritable()
+	if n <= 0 {
+		return nil
+	}
+	entities := r.entities.createMany(n)
+	size := 0
+	for _, entity := range entities {
+		size = max(size, int(entity.Index())+1)
+	}
+	for _, storage := range r.storages {
+		if g, ok := storage.(sparseGrower); ok {
+			g.reserveIndex(size)
+		}
+	}
+	return entities
+}
+
+// EntityCount returns the number of live entities, with or without
+// components.
+func (r *Registry) EntityCount() int {
+	return len(r.entities.generations) - len(r.entities.free)
+}
+
+// Count returns the number of entities with a T component, disabled ones
+// included, without iterating them.
+func Count[T any](r *Registry) int {
+	if r.archetypes != nil {
+		n := 0
+		r.archetypes.each(&filterSet{}, []reflect.Type{typeKeyFor[T]()}, func(a *archetype) {
+			n += len(a.entities)
+		})
+		return n
+	}
+	if storage := getStorage[T](r); storage != nil {
+		return storage.Len()
+	}
+	return 0
+}
+
+// IsAlive reports whether the entity was created by this registry and not destroyed since.
+func (r *Registry) IsAlive(entity Goent) bool {
+	return r.entities.alive(entity)
+}
+
+// isStale reports whether the handle refers to a destroyed incarnation of an
+// index this registry handed out. IDs from outside the registry's range, such
+// as the ones from the package-level CreateEntity, are accepted as they are.
+func (r *Registry) isStale(entity Goent) bool {
+	return r.entities.known(entity) && !r.entities.alive(entity)
+}
+
+// DestroyEntity removes the entity from every registered storage, so
+// teardown is a single call that can't leak components. The index is then
+// recycled with a new generation, making the handle stale.
+func (r *Registry) DestroyEntity(entity Goent) {
+	r.assertWritable()
+	if r.isStale(entity) {
+		return
+	}
+	r.unlinkHierarchy(entity)
+	r.fireRemoveHooks(entity)
+	for _, storage := range r.storages {
+		storage.Remove(entity)
+	}
+	if r.archetypes != nil {
+		r.archetypes.destroy(entity)
+	}
+	r.releaseEntity(entity)
+}
+
+// releaseEntity drops the per-entity side tables of a destroyed entity and
+// recycles its index.
+func (r *Registry) releaseEntity(entity Goent) {
+	r.forgetDirty(entity)
+	r.forgetRelations(entity)
+	r.stringAliases.remove(entity)
+	r.names.remove(entity)
+	r.idAliases.remove(entity)
+	delete(r.annotations, entity)
+	r.entities.release(entity)
+}
+
+// releaseEntities is releaseEntity for a batch, recycling all indices with
+// one allocator call.
+func (r *Registry) releaseEntities(entities []Goent) {
+	for _, entity := range entities {
+		r.forgetDirty(entity)
+		r.forgetRelations(entity)
+		r.stringAliases.remove(entity)
+		r.names.remove(entity)
+		r.idAliases.remove(entity)
+		delete(r.annotations, entity)
+	}
+	r.entities.releaseMany(entities)
+}
+
 // IterateReflective uses reflection for iteration. It is much slower but flexible.
-func (r *Registry) IterateReflective(f interface{}) {
+func (r *Registry) IterateReflective(f interface{}, filters ...Filter) {
 	fVal := reflect.ValueOf(f)
 	fType := fVal.Type()
 
@@ -189,44 +947,74 @@ func (r *Registry) IterateReflective(f interface{}) {
 		panic("Iterate function must have at least one component parameter")
 	}
 
-	// Figure out storages for each parameter
+	fs := r.resolveFilters(filters)
+	if r.archetypes != nil {
+		r.iterateReflectiveArchetypes(fVal, fType, &fs)
+		return
+	}
+
+	// Figure out storages for each parameter, optional ones may stay nil
 	storages := make([]SparseSetInterface, compCount)
+	optional := make([]bool, compCount)
 	for i := 0; i < compCount; i++ {
 		paramType := fType.In(i + 1)
 		if paramType.Kind() == reflect.Ptr {
 			paramType = paramType.Elem()
 		}
+		optional[i] = fs.isOptional(paramType)
 		storage, exists := r.storages[paramType]
-		if !exists {
-			// If any storage is missing, there's nothing to iterate
+		if !exists && !optional[i] {
+			// If any required storage is missing, there's nothing to iterate
 			return
 		}
 		storages[i] = storage
 	}
 
-	// Pick the smallest dense array to drive iteration
-	baseIndex := 0
-	minLen := len(storages[0].GetDense())
-	for i := 1; i < compCount; i++ {
-		l := len(storages[i].GetDense())
-		if l < minLen {
+	// Pick the smallest required dense array to drive iteration
+	var baseDense []Goent
+	baseIndex := -1
+	for i, storage := range storages {
+		if optional[i] {
+			continue
+		}
+		if baseIndex == -1 || len(storage.GetDense()) < len(baseDense) {
 			baseIndex = i
-			minLen = l
+			baseDense = storage.GetDense()
 		}
 	}
-	baseDense := storages[baseIndex].GetDense()
+	if baseIndex == -1 {
+		// Every parameter is optional, so any entity with one of them matches
+		var lists [][]Goent
+		for _, storage := range storages {
+			if storage != nil {
+				lists = append(lists, storage.GetDense())
+			}
+		}
+		baseDense = unionDense(lists...)
+	}
 
 	// Pre-allocate the call arguments
 	args := make([]reflect.Value, compCount+1)
 
 	// Iterate over entities in the base storage
 	for _, entity := range baseDense {
+		if fs.skip(entity) {
+			continue
+		}
 		args[0] = reflect.ValueOf(entity)
 		valid := true
 
 		for i, storage := range storages {
-			comp, ok := storage.GetComponent(entity)
+			var comp interface{}
+			ok := false
+			if storage != nil {
+				comp, ok = storage.GetComponent(entity)
+			}
 			if !ok {
+				if optional[i] {
+					args[i+1] = reflect.Zero(fType.In(i + 1))
+					continue
+				}
 				valid = false
 				break
 			}
@@ -241,7 +1029,7 @@ func (r *Registry) IterateReflective(f interface{}) {
 }
 
 // --- Typed (non-reflective) iteration helpers ---
-// Goes up to 4 supported arguments. For more, consider codegen or a better pattern.
+// Goes from 1 up to 8 supported arguments. For more, use IterateReflective or a better pattern.
 
 // iterateDense is a helper to loop over a dense slice.
 func iterateDense(dense []Goent, f func(entity Goent)) {
@@ -250,6 +1038,90 @@ func iterateDense(dense []Goent, f func(entity Goent)) {
 	}
 }
 
+// column is one component parameter of a typed iteration, resolved once per call.
+type column[T any] struct {
+	storage  *SparseSet[T]
+	optional bool
+}
+
+// newColumn resolves the storage of T. It reports false when T is required
+// but has no storage, meaning nothing can match.
+func newColumn[T any](r *Registry, fs *filterSet) (column[T], bool) {
+	c := column[T]{storage: getStorage[T](r), optional: fs.isOptional(typeKeyFor[T]())}
+	return c, c.storage != nil || c.optional
+}
+
+// get returns the entity's component. A missing optional component yields
+// nil while still reporting ok, so the entity isn't filtered out.
+func (c column[T]) get(entity Goent) (*T, bool) {
+	if c.storage != nil {
+		if comp, ok := c.storage.Get(entity); ok {
+			return comp, true
+		}
+	}
+	return nil, c.optional
+}
+
+// driver returns the dense array the column can drive iteration from.
+// Optional columns can't, since entities lacking them still match.
+func (c column[T]) driver() ([]Goent, bool) {
+	if c.optional {
+		if c.storage == nil {
+			return nil, false
+		}
+		return c.storage.dense, false
+	}
+	return c.storage.dense, true
+}
+
+// denseDriver is implemented by every column regardless of its component type.
+type denseDriver interface {
+	driver() ([]Goent, bool)
+}
+
+// driverDense picks the smallest dense array among the required columns.
+// With every column optional it walks the union of all of them instead.
+func driverDense(columns ...denseDriver) []Goent {
+	var base []Goent
+	found := false
+	var optionals [][]Goent
+	for _, c := range columns {
+		dense, required := c.driver()
+		if !required {
+			if dense != nil {
+				optionals = append(optionals, dense)
+			}
+			continue
+		}
+		if !found || len(dense) < len(base) {
+			base = dense
+			found = true
+		}
+	}
+	if found {
+		return base
+	}
+	return unionDense(optionals...)
+}
+
+// unionDense returns every entity that appears in any of the lists, once.
+func unionDense(lists ...[]Goent) []Goent {
+	if len(lists) == 1 {
+		return lists[0]
+	}
+	seen := make(map[Goent]struct{})
+	var union []Goent
+	for _, list := range lists {
+		for _, entity := range list {
+			if _, ok := seen[entity]; !ok {
+				seen[entity] = struct{}{}
+				union = append(union, entity)
+			}
+		}
+	}
+	return union
+}
+
 // getStorage returns the typed storage for a component type from the registry.
 func getStorage[T any](r *Registry) *SparseSet[T] {
 	key := typeKeyFor[T]()
@@ -260,23 +1132,66 @@ func getStorage[T any](r *Registry) *SparseSet[T] {
 	return storageInterface.(*SparseSet[T])
 }
 
+// ensureStorage returns the typed storage for a component type, creating it
+// with the default growth policy if it doesn't exist yet.
+func ensureStorage[T any](r *Registry) *SparseSet[T] {
+	key := typeKeyFor[T]()
+	storageInterface, exists := r.storages[key]
+	if !exists {
+		set := NewSparseSet[T]()
+		set.clock = &r.tick
+		set.ordered = r.ordered
+		r.storages[key] = set
+		r.prewarm(noteComponent[T](r))
+		return set
+	}
+	return storageInterface.(*SparseSet[T])
+}
+
+// Iterate1 iterates over entities that have a T component. It walks the dense
+// arrays directly, making it the fastest path; the only sparse lookups are
+// the ones filters need, and the Disabled check while any entity is disabled.
+func Iterate1[T any](r *Registry, f func(entity Goent, c *T), filters ...Filter) {
+	fs := r.resolveFilters(filters)
+	if r.archetypes != nil {
+		archIterate1(r, &fs, f)
+		return
+	}
+	s := getStorage[T](r)
+	if s == nil {
+		return
+	}
+
+	for i, entity := range s.dense {
+		if fs.skip(entity) {
+			continue
+		}
+		f(entity, s.at(i))
+	}
+}
+
 // Iterate2 iterates over entities that have both T1 and T2 components.
-func Iterate2[T1 any, T2 any](r *Registry, f func(entity Goent, c1 *T1, c2 *T2)) {
-	s1 := getStorage[T1](r)
-	s2 := getStorage[T2](r)
-	if s1 == nil || s2 == nil {
+func Iterate2[T1 any, T2 any](r *Registry, f func(entity Goent, c1 *T1, c2 *T2), filters ...Filter) {
+	fs := r.resolveFilters(filters)
+	if r.archetypes != nil {
+		archIterate2(r, &fs, f)
+		return
+	}
+	s1, ok1 := newColumn[T1](r, &fs)
+	s2, ok2 := newColumn[T2](r, &fs)
+	if !ok1 || !ok2 {
 		return
 	}
 
 	// Decide which dense array is smaller
-	baseDense := s1.dense
-	if len(s2.dense) < len(baseDense) {
-		baseDense = s2.dense
-	}
+	baseDense := driverDense(s1, s2)
 
 	iterateDense(baseDense, func(entity Goent) {
-		c1, ok1 := s1.Get(entity)
-		c2, ok2 := s2.Get(entity)
+		if fs.skip(entity) {
+			return
+		}
+		c1, ok1 := s1.get(entity)
+		c2, ok2 := s2.get(entity)
 		if ok1 && ok2 {
 			f(entity, c1, c2)
 		}
@@ -284,27 +1199,29 @@ func Iterate2[T1 any, T2 any](r *Registry, f func(entity Goent, c1 *T1, c2 *T2))
 }
 
 // Iterate3 iterates over entities that have T1, T2, and T3 components.
-func Iterate3[T1 any, T2 any, T3 any](r *Registry, f func(entity Goent, c1 *T1, c2 *T2, c3 *T3)) {
-	s1 := getStorage[T1](r)
-	s2 := getStorage[T2](r)
-	s3 := getStorage[T3](r)
-	if s1 == nil || s2 == nil || s3 == nil {
+func Iterate3[T1 any, T2 any, T3 any](r *Registry, f func(entity Goent, c1 *T1, c2 *T2, c3 *T3), filters ...Filter) {
+	fs := r.resolveFilters(filters)
+	if r.archetypes != nil {
+		archIterate3(r, &fs, f)
+		return
+	}
+	s1, ok1 := newColumn[T1](r, &fs)
+	s2, ok2 := newColumn[T2](r, &fs)
+	s3, ok3 := newColumn[T3](r, &fs)
+	if !ok1 || !ok2 || !ok3 {
 		return
 	}
 
 	// Decide which dense array is smaller
-	baseDense := s1.dense
-	if len(s2.dense) < len(baseDense) {
-		baseDense = s2.dense
-	}
-	if len(s3.dense) < len(baseDense) {
-		baseDense = s3.dense
-	}
+	baseDense := driverDense(s1, s2, s3)
 
 	iterateDense(baseDense, func(entity Goent) {
-		c1, ok1 := s1.Get(entity)
-		c2, ok2 := s2.Get(entity)
-		c3, ok3 := s3.Get(entity)
+		if fs.skip(entity) {
+			return
+		}
+		c1, ok1 := s1.get(entity)
+		c2, ok2 := s2.get(entity)
+		c3, ok3 := s3.get(entity)
 		if ok1 && ok2 && ok3 {
 			f(entity, c1, c2, c3)
 		}
@@ -312,34 +1229,181 @@ func Iterate3[T1 any, T2 any, T3 any](r *Registry, f func(entity Goent, c1 *T1,
 }
 
 // Iterate4 iterates over entities that have T1, T2, T3, and T4 components.
-func Iterate4[T1 any, T2 any, T3 any, T4 any](r *Registry, f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4)) {
-	s1 := getStorage[T1](r)
-	s2 := getStorage[T2](r)
-	s3 := getStorage[T3](r)
-	s4 := getStorage[T4](r)
-	if s1 == nil || s2 == nil || s3 == nil || s4 == nil {
+func Iterate4[T1 any, T2 any, T3 any, T4 any](r *Registry, f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4), filters ...Filter) {
+	fs := r.resolveFilters(filters)
+	if r.archetypes != nil {
+		archIterate4(r, &fs, f)
+		return
+	}
+	s1, ok1 := newColumn[T1](r, &fs)
+	s2, ok2 := newColumn[T2](r, &fs)
+	s3, ok3 := newColumn[T3](r, &fs)
+	s4, ok4 := newColumn[T4](r, &fs)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
 		return
 	}
 
 	// Decide which dense array is smaller
-	baseDense := s1.dense
-	if len(s2.dense) < len(baseDense) {
-		baseDense = s2.dense
+	baseDense := driverDense(s1, s2, s3, s4)
+
+	iterateDense(baseDense, func(entity Goent) {
+		if fs.skip(entity) {
+			return
+		}
+		c1, ok1 := s1.get(entity)
+		c2, ok2 := s2.get(entity)
+		c3, ok3 := s3.get(entity)
+		c4, ok4 := s4.get(entity)
+		if ok1 && ok2 && ok3 && ok4 {
+			f(entity, c1, c2, c3, c4)
+		}
+	})
+}
+
+// Iterate5 iterates over entities that have T1, T2, T3, T4, and T5 components.
+func Iterate5[T1 any, T2 any, T3 any, T4 any, T5 any](r *Registry, f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4, c5 *T5), filters ...Filter) {
+	fs := r.resolveFilters(filters)
+	if r.archetypes != nil {
+		archIterate5(r, &fs, f)
+		return
 	}
-	if len(s3.dense) < len(baseDense) {
-		baseDense = s3.dense
+	s1, ok1 := newColumn[T1](r, &fs)
+	s2, ok2 := newColumn[T2](r, &fs)
+	s3, ok3 := newColumn[T3](r, &fs)
+	s4, ok4 := newColumn[T4](r, &fs)
+	s5, ok5 := newColumn[T5](r, &fs)
+	if !ok1 || !ok2 || !ok3 || !ok4 || !ok5 {
+		return
 	}
-	if len(s4.dense) < len(baseDense) {
-		baseDense = s4.dense
+
+	// Decide which dense array is smaller
+	baseDense := driverDense(s1, s2, s3, s4, s5)
+
+	iterateDense(baseDense, func(entity Goent) {
+		if fs.skip(entity) {
+			return
+		}
+		c1, ok1 := s1.get(entity)
+		c2, ok2 := s2.get(entity)
+		c3, ok3 := s3.get(entity)
+		c4, ok4 := s4.get(entity)
+		c5, ok5 := s5.get(entity)
+		if ok1 && ok2 && ok3 && ok4 && ok5 {
+			f(entity, c1, c2, c3, c4, c5)
+		}
+	})
+}
+
+// Iterate6 iterates over entities that have T1, T2, T3, T4, T5, and T6 components.
+func Iterate6[T1 any, T2 any, T3 any, T4 any, T5 any, T6 any](r *Registry, f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4, c5 *T5, c6 *T6), filters ...Filter) {
+	fs := r.resolveFilters(filters)
+	if r.archetypes != nil {
+		archIterate6(r, &fs, f)
+		return
 	}
+	s1, ok1 := newColumn[T1](r, &fs)
+	s2, ok2 := newColumn[T2](r, &fs)
+	s3, ok3 := newColumn[T3](r, &fs)
+	s4, ok4 := newColumn[T4](r, &fs)
+	s5, ok5 := newColumn[T5](r, &fs)
+	s6, ok6 := newColumn[T6](r, &fs)
+	if !ok1 || !ok2 || !ok3 || !ok4 || !ok5 || !ok6 {
+		return
+	}
+
+	// Decide which dense array is smaller
+	baseDense := driverDense(s1, s2, s3, s4, s5, s6)
 
 	iterateDense(baseDense, func(entity Goent) {
-		c1, ok1 := s1.Get(entity)
-		c2, ok2 := s2.Get(entity)
-		c3, ok3 := s3.Get(entity)
-		c4, ok4 := s4.Get(entity)
-		if ok1 && ok2 && ok3 && ok4 {
-			f(entity, c1, c2, c3, c4)
+		if fs.skip(entity) {
+			return
+		}
+		c1, ok1 := s1.get(entity)
+		c2, ok2 := s2.get(entity)
+		c3, ok3 := s3.get(entity)
+		c4, ok4 := s4.get(entity)
+		c5, ok5 := s5.get(entity)
+		c6, ok6 := s6.get(entity)
+		if ok1 && ok2 && ok3 && ok4 && ok5 && ok6 {
+			f(entity, c1, c2, c3, c4, c5, c6)
+		}
+	})
+}
+
+// Iterate7 iterates over entities that have T1, T2, T3, T4, T5, T6, and T7 components.
+func Iterate7[T1 any, T2 any, T3 any, T4 any, T5 any, T6 any, T7 any](r *Registry, f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4, c5 *T5, c6 *T6, c7 *T7), filters ...Filter) {
+	fs := r.resolveFilters(filters)
+	if r.archetypes != nil {
+		archIterate7(r, &fs, f)
+		return
+	}
+	s1, ok1 := newColumn[T1](r, &fs)
+	s2, ok2 := newColumn[T2](r, &fs)
+	s3, ok3 := newColumn[T3](r, &fs)
+	s4, ok4 := newColumn[T4](r, &fs)
+	s5, ok5 := newColumn[T5](r, &fs)
+	s6, ok6 := newColumn[T6](r, &fs)
+	s7, ok7 := newColumn[T7](r, &fs)
+	if !ok1 || !ok2 || !ok3 || !ok4 || !ok5 || !ok6 || !ok7 {
+		return
+	}
+
+	// Decide which dense array is smaller
+	baseDense := driverDense(s1, s2, s3, s4, s5, s6, s7)
+
+	iterateDense(baseDense, func(entity Goent) {
+		if fs.skip(entity) {
+			return
+		}
+		c1, ok1 := s1.get(entity)
+		c2, ok2 := s2.get(entity)
+		c3, ok3 := s3.get(entity)
+		c4, ok4 := s4.get(entity)
+		c5, ok5 := s5.get(entity)
+		c6, ok6 := s6.get(entity)
+		c7, ok7 := s7.get(entity)
+		if ok1 && ok2 && ok3 && ok4 && ok5 && ok6 && ok7 {
+			f(entity, c1, c2, c3, c4, c5, c6, c7)
+		}
+	})
+}
+
+// Iterate8 iterates over entities that have T1, T2, T3, T4, T5, T6, T7, and T8 components.
+func Iterate8[T1 any, T2 any, T3 any, T4 any, T5 any, T6 any, T7 any, T8 any](r *Registry, f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4, c5 *T5, c6 *T6, c7 *T7, c8 *T8), filters ...Filter) {
+	fs := r.resolveFilters(filters)
+	if r.archetypes != nil {
+		archIterate8(r, &fs, f)
+		return
+	}
+	s1, ok1 := newColumn[T1](r, &fs)
+	s2, ok2 := newColumn[T2](r, &fs)
+	s3, ok3 := newColumn[T3](r, &fs)
+	s4, ok4 := newColumn[T4](r, &fs)
+	s5, ok5 := newColumn[T5](r, &fs)
+	s6, ok6 := newColumn[T6](r, &fs)
+	s7, ok7 := newColumn[T7](r, &fs)
+	s8, ok8 := newColumn[T8](r, &fs)
+	if !ok1 || !ok2 || !ok3 || !ok4 || !ok5 || !ok6 || !ok7 || !ok8 {
+		return
+	}
+
+	// Decide which dense array is smaller
+	baseDense := driverDense(s1, s2, s3, s4, s5, s6, s7, s8)
+
+	iterateDense(baseDense, func(entity Goent) {
+		if fs.skip(entity) {
+			return
+		}
+		c1, ok1 := s1.get(entity)
+		c2, ok2 := s2.get(entity)
+		c3, ok3 := s3.get(entity)
+		c4, ok4 := s4.get(entity)
+		c5, ok5 := s5.get(entity)
+		c6, ok6 := s6.get(entity)
+		c7, ok7 := s7.get(entity)
+		c8, ok8 := s8.get(entity)
+		if ok1 && ok2 && ok3 && ok4 && ok5 && ok6 && ok7 && ok8 {
+			f(entity, c1, c2, c3, c4, c5, c6, c7, c8)
 		}
 	})
 }
@@ -0,0 +1,266 @@
+package goecs
+
+import (
+	"slices"
+	"testing"
+)
+
+type entityProbe struct {
+	V int
+}
+
+func TestGenerationalIDs(t *testing.T) {
+	r := NewRegistry()
+	e := r.CreateEntity()
+	EmplaceComponent(r, e, entityProbe{V: 1})
+	r.DestroyEntity(e)
+
+	reused := r.CreateEntity()
+	if reused.Index() != e.Index() || reused.Generation() != e.Generation()+1 {
+		t.Fatalf("reused %d (index %d, generation %d) after destroying %d", reused, reused.Index(), reused.Generation(), e)
+	}
+	if r.IsAlive(e) || !r.IsAlive(reused) {
+		t.Errorf("IsAlive(stale) = %v, IsAlive(reused) = %v", r.IsAlive(e), r.IsAlive(reused))
+	}
+	EmplaceComponent(r, reused, entityProbe{V: 2})
+	if _, ok := GetComponent[entityProbe](r, e); ok {
+		t.Error("stale handle reads the reused entity's component")
+	}
+	RemoveComponent[entityProbe](r, e)
+	if c, ok := GetComponent[entityProbe](r, reused); !ok || c.V != 2 {
+		t.Errorf("stale RemoveComponent hit the reused entity: %v, %v", c, ok)
+	}
+	if id := makeGoent(7, 3); id.Index() != 7 || id.Generation() != 3 {
+		t.Errorf("makeGoent(7, 3) unpacks to %d, %d", id.Index(), id.Generation())
+	}
+}
+
+func TestRegistryEntityRanges(t *testing.T) {
+	a, b := NewRegistry(), NewRegistry()
+	ea := a.CreateEntities(3)
+	eb := b.CreateEntity()
+	if eb != ea[0] {
+		t.Errorf("second registry started at %d, want %d", eb, ea[0])
+	}
+	if !b.IsAlive(eb) || b.IsAlive(ea[2]) {
+		t.Error("registry tracks entities of another registry")
+	}
+	if a.EntityCount() != 3 || b.EntityCount() != 1 {
+		t.Errorf("EntityCount = %d and %d, want 3 and 1", a.EntityCount(), b.EntityCount())
+	}
+	a.DestroyEntity(ea[1])
+	if a.EntityCount() != 2 {
+		t.Errorf("EntityCount = %d after a destroy, want 2", a.EntityCount())
+	}
+	seen := map[Goent]bool{}
+	for _, e := range append(a.CreateEntities(4), ea[0], ea[2]) {
+		if seen[e] {
+			t.Fatalf("entity %d handed out twice", e)
+		}
+		seen[e] = true
+	}
+}
+
+func TestHasComponent(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			if HasComponent[entityProbe](r, r.CreateEntity()) {
+				t.Error("HasComponent before the type was ever stored")
+			}
+			e, other := r.CreateEntity(), r.CreateEntity()
+			EmplaceComponent(r, e, entityProbe{})
+			if !HasComponent[entityProbe](r, e) || HasComponent[entityProbe](r, other) {
+				t.Error("HasComponent doesn't match the stored components")
+			}
+			r.DestroyEntity(e)
+			reused := r.CreateEntity()
+			if HasComponent[entityProbe](r, e) || HasComponent[entityProbe](r, reused) {
+				t.Error("HasComponent sees the component of a destroyed entity")
+			}
+		})
+	}
+
+	s := NewSparseSet[entityProbe]()
+	s.Emplace(makeGoent(3, 1), entityProbe{})
+	tests := []struct {
+		entity Goent
+		want   bool
+	}{
+		{makeGoent(3, 1), true},
+		{makeGoent(3, 0), false},
+		{makeGoent(4, 1), false},
+		{makeGoent(1000, 0), false},
+	}
+	for _, tt := range tests {
+		if got := s.Contains(tt.entity); got != tt.want {
+			t.Errorf("Contains(%d) = %v, want %v", tt.entity, got, tt.want)
+		}
+	}
+}
+
+func TestCreateEntities(t *testing.T) {
+	r := NewRegistry()
+	s := RegisterComponent[entityProbe](r)
+	if got := r.CreateEntities(0); got != nil {
+		t.Errorf("CreateEntities(0) = %v", got)
+	}
+	recycled := r.CreateEntities(3)
+	r.DestroyEntity(recycled[1])
+
+	entities := r.CreateEntities(1000)
+	seen := map[Goent]bool{}
+	for _, e := range entities {
+		if seen[e] || !r.IsAlive(e) {
+			t.Fatalf("entity %d is a duplicate or not alive", e)
+		}
+		seen[e] = true
+	}
+	if !slices.ContainsFunc(entities, func(e Goent) bool { return e.Index() == recycled[1].Index() }) {
+		t.Error("destroyed index wasn't reused")
+	}
+	// the sparse array already covers the new entities
+	size := len(s.sparse)
+	for _, e := range entities {
+		EmplaceComponent(r, e, entityProbe{})
+	}
+	if len(s.sparse) != size || size < int(entities[len(entities)-1].Index())+1 {
+		t.Errorf("sparse array grew from %d to %d while emplacing", size, len(s.sparse))
+	}
+}
+
+func TestClearComponent(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			entities := newIterWorld(r, 10)
+			var removed []int
+			OnRemove(r, func(e Goent, c *iterB) {
+				if !HasComponent[iterB](r, e) {
+					t.Errorf("OnRemove for entity %d fired after the removal", e)
+				}
+				removed = append(removed, c.V)
+			})
+
+			ClearComponent[iterB](r)
+			if n := Count[iterB](r); n != 0 {
+				t.Errorf("Count = %d after ClearComponent", n)
+			}
+			if want := multiples(10, 2); !slices.Equal(sortedValues(removed), want) {
+				t.Errorf("OnRemove saw %v, want %v", removed, want)
+			}
+			// the entities and their other components stay
+			for i, e := range entities {
+				a, ok := GetComponent[iterA](r, e)
+				if !r.IsAlive(e) || !ok || a.V != i || HasComponent[iterB](r, e) {
+					t.Errorf("entity %d: alive %v, iterA %v, %v", e, r.IsAlive(e), a, ok)
+				}
+			}
+			// the storage takes new components
+			EmplaceComponent(r, entities[3], iterB{V: 3})
+			if c, ok := GetComponent[iterB](r, entities[3]); !ok || c.V != 3 || Count[iterB](r) != 1 {
+				t.Errorf("after ClearComponent, emplaced iterB = %v, %v", c, ok)
+			}
+			// a type never used is a no-op
+			ClearComponent[entityProbe](r)
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			if Count[iterA](r) != 0 || r.EntityCount() != 0 {
+				t.Errorf("empty registry counts %d components and %d entities", Count[iterA](r), r.EntityCount())
+			}
+			entities := newIterWorld(r, 12)
+			r.CreateEntity()
+			// disabled entities count, destroyed ones don't
+			r.Disable(entities[2])
+			r.DestroyEntity(entities[3])
+			r.DestroyEntity(entities[4])
+			tests := []struct {
+				name      string
+				got, want int
+			}{
+				{"iterA", Count[iterA](r), 10},
+				{"iterB", Count[iterB](r), 5},
+				{"iterC", Count[iterC](r), 3},
+				{"unused", Count[entityProbe](r), 0},
+				{"entities", r.EntityCount(), 11},
+			}
+			for _, tt := range tests {
+				if tt.got != tt.want {
+					t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.want)
+				}
+			}
+		})
+	}
+
+	r := NewRegistry()
+	newIterWorld(r, 6)
+	if s := getStorage[iterB](r); s.Len() != 3 || s.Len() != len(s.GetDense()) {
+		t.Errorf("SparseSet.Len = %d for %d dense entries", s.Len(), len(s.GetDense()))
+	}
+}
+
+type sortDepth struct {
+	Z, Seq int
+}
+
+func TestSortStorage(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(8)
+	for i, e := range entities {
+		EmplaceComponent(r, e, sortDepth{Z: (i * 5) % 4, Seq: i})
+	}
+	SortStorage(r, func(a, b *sortDepth) bool { return a.Z < b.Z })
+
+	// equal depths keep their creation order
+	var got []int
+	Iterate1(r, func(e Goent, d *sortDepth) {
+		if d.Seq != int(e.Index()) {
+			t.Errorf("entity %d reads the component of entity %d", e, d.Seq)
+		}
+		got = append(got, d.Seq)
+	})
+	if want := []int{0, 4, 1, 5, 2, 6, 3, 7}; !slices.Equal(got, want) {
+		t.Errorf("sorted order = %v, want %v", got, want)
+	}
+	if d, _ := GetComponent[sortDepth](r, entities[6]); d.Seq != 6 {
+		t.Errorf("lookup after sorting found %v", d)
+	}
+	// a type without storage and a zero-size type are left alone
+	SortStorage(r, func(a, b *entityProbe) bool { return a.V < b.V })
+	EmplaceComponent(r, entities[0], destroyTag{})
+	SortStorage(r, func(a, b *destroyTag) bool { return false })
+
+	tests := []struct {
+		name  string
+		setup func() *Registry
+	}{
+		{"group owned", func() *Registry {
+			r := NewRegistry()
+			NewGroup2[sortDepth, entityProbe](r)
+			return r
+		}},
+		{"deterministic", func() *Registry {
+			r := NewRegistryWithOptions(RegistryOptions{Deterministic: true})
+			EmplaceComponent(r, r.CreateEntity(), sortDepth{})
+			return r
+		}},
+		{"archetypes", func() *Registry { return NewRegistryWithOptions(RegistryOptions{Storage: ArchetypeStorage}) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.setup()
+			defer func() {
+				if recover() == nil {
+					t.Error("SortStorage didn't panic")
+				}
+			}()
+			SortStorage(r, func(a, b *sortDepth) bool { return a.Z < b.Z })
+		})
+	}
+}
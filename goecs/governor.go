@@ -0,0 +1,117 @@
+package goecs
+
+import (
+	"strings"
+	"time"
+)
+
+// --- Frame budget governor ---
+// A governor keeps a scheduler within a frame budget under load spikes. It
+// measures what every system costs (as a moving average) and, once the frame
+// would run over budget, skips the systems marked deferrable for this frame,
+// logging what it deferred. A system is never deferred more than MaxDeferrals
+// frames in a row, so deferred work still makes progress.
+//
+// Deferrable systems that walk many entities can also time-slice themselves:
+// IterateBudget1 and IterateBudget2 stop at Governor.Deadline and resume
+// where they left off on the next frame.
+
+// Governor enforces a frame budget on a scheduler, see SetGovernor.
+type Governor struct {
+	Budget time.Duration
+	// MaxDeferrals caps how many consecutive frames a system can be deferred,
+	// 0 means no cap.
+	MaxDeferrals int
+	// Logf reports deferred systems when set, e.g. log.Printf.
+	Logf func(format string, args ...interface{})
+
+	frameStart time.Time
+	deferred   []string
+}
+
+// NewGovernor creates a governor with the given budget, deferring a system at
+// most 8 frames in a row.
+func NewGovernor(budget time.Duration) *Governor {
+	return &Governor{Budget: budget, MaxDeferrals: 8}
+}
+
+// SetGovernor attaches a governor to the scheduler, nil detaches it.
+func (s *Scheduler) SetGovernor(g *Governor) {
+	s.governor = g
+}
+
+// MarkDeferrable lets the governor skip the named system on frames that run
+// over budget. It reports false if no system has that name.
+func (s *Scheduler) MarkDeferrable(name string) bool {
+	found := false
+	for _, sys := range s.systems {
+		if sys.name == name {
+			sys.deferrable = true
+			found = true
+		}
+	}
+	return found
+}
+
+// Cost returns the measured average cost of the named system, 0 before a
+// governor has timed it.
+func (s *Scheduler) Cost(name string) time.Duration {
+	for _, sys := range s.systems {
+		if sys.name == name {
+			return sys.cost
+		}
+	}
+	return 0
+}
+
+// Deadline returns when the current frame's budget runs out.
+func (g *Governor) Deadline() time.Time {
+	return g.frameStart.Add(g.Budget)
+}
+
+// Deferred returns the systems deferred during the last frame.
+func (g *Governor) Deferred() []string {
+	return g.deferred
+}
+
+func (g *Governor) beginFrame() {
+	g.frameStart = time.Now()
+	g.deferred = g.deferred[:0]
+}
+
+func (g *Governor) endFrame() {
+	if len(g.deferred) > 0 && g.Logf != nil {
+		g.Logf("goecs: frame took %v of a %v budget, deferred %s",
+			time.Since(g.frameStart), g.Budget, strings.Join(g.deferred, ", "))
+	}
+}
+
+// admit returns the systems of the stage that fit the remaining budget.
+func (g *Governor) admit(systems []*scheduledSystem, stage []int) []int {
+	admitted := make([]int, 0, len(stage))
+	elapsed := time.Since(g.frameStart)
+	for _, id := range stage {
+		sys := systems[id]
+		canDefer := g.MaxDeferrals == 0 || sys.deferrals < g.MaxDeferrals
+		if sys.deferrable && canDefer && elapsed+sys.cost > g.Budget {
+			sys.deferrals++
+			g.deferred = append(g.deferred, sys.name)
+			continue
+		}
+		sys.deferrals = 0
+		admitted = append(admitted, id)
+	}
+	return admitted
+}
+
+// record folds the last run of the systems into their average cost.
+func (g *Governor) record(systems []*scheduledSystem, stage []int) {
+	for _, id := range stage {
+		sys := systems[id]
+		if sys.cost == 0 {
+			sys.cost = sys.lastCost
+		} else {
+			sys.cost = (sys.cost*7 + sys.lastCost) / 8
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package goecs
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGovernorDefers(t *testing.T) {
+	r := NewRegistry()
+	s := NewScheduler(1)
+	defer s.Close()
+	pos := []reflect.Type{ComponentType[schedPos]()}
+	s.Add("physics", SystemAccess{Writes: pos}, func(r *Registry) { time.Sleep(2 * time.Millisecond) })
+	fx := 0
+	s.Add("fx", SystemAccess{Writes: pos}, func(r *Registry) { fx++ })
+	if !s.MarkDeferrable("fx") || s.MarkDeferrable("missing") {
+		t.Fatal("MarkDeferrable didn't match system names")
+	}
+
+	g := NewGovernor(time.Millisecond)
+	g.MaxDeferrals = 2
+	var logs []string
+	g.Logf = func(format string, args ...interface{}) { logs = append(logs, fmt.Sprintf(format, args...)) }
+	s.SetGovernor(g)
+
+	// deferred twice in a row, then forced to run
+	var ran []int
+	for frame := 0; frame < 3; frame++ {
+		s.Run(r)
+		ran = append(ran, fx)
+	}
+	if !slices.Equal(ran, []int{0, 0, 1}) {
+		t.Errorf("fx run count per frame = %v, want [0 0 1]", ran)
+	}
+	if len(logs) != 2 {
+		t.Errorf("logged %d deferrals, want 2: %v", len(logs), logs)
+	}
+	if s.Cost("physics") < 2*time.Millisecond {
+		t.Errorf("Cost(physics) = %v, want at least 2ms", s.Cost("physics"))
+	}
+
+	// within budget nothing is deferred
+	g.Budget = time.Second
+	s.Run(r)
+	if fx != 2 || len(g.Deferred()) != 0 {
+		t.Errorf("fx ran %d times, deferred %v under a generous budget", fx, g.Deferred())
+	}
+}
+
+func TestIterateBudget(t *testing.T) {
+	r := NewRegistry()
+	entities := newIterWorld(r, 200)
+	var cur BudgetCursor
+	seen := map[Goent]int{}
+	visit := func(e Goent, a *iterA) { seen[e]++ }
+
+	// an expired deadline still makes progress each call
+	passes := 0
+	for !IterateBudget1(r, &cur, time.Now().Add(-time.Second), visit) {
+		passes++
+	}
+	if passes == 0 || len(seen) != len(entities) {
+		t.Errorf("expired budget took %d extra calls and visited %d entities", passes, len(seen))
+	}
+	for e, n := range seen {
+		if n != 1 {
+			t.Errorf("entity %d visited %d times", e, n)
+		}
+	}
+
+	// a completed pass restarts from the beginning
+	if !IterateBudget1(r, &cur, time.Now().Add(time.Hour), visit) {
+		t.Error("pass with an open deadline didn't complete")
+	}
+	for e, n := range seen {
+		if n != 2 {
+			t.Errorf("entity %d visited %d times after two passes", e, n)
+		}
+	}
+}
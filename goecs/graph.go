@@ -0,0 +1,202 @@
+package goecs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// --- Entity graph export ---
+// Graph collects the structure of a scene, the hierarchy and every
+// relationship edge, for visualizing with GraphViz (WriteDOT) or web tooling
+// (WriteJSON). Edges whose target was destroyed are kept and flagged, which
+// makes orphaned subtrees stand out, and Cycles finds loops among the
+// relationships.
+
+// GraphOptions selects what Graph includes.
+type GraphOptions struct {
+	// Components lists the component type names of every node.
+	Components bool
+	// AllEntities adds the entities without any edge as well.
+	AllEntities bool
+}
+
+// GraphNode is an entity in the graph.
+type GraphNode struct {
+	Entity Goent `json:"entity"`
+	// Label is the entity's name or string alias, if it has one
+	Label      string   `json:"label,omitempty"`
+	Components []string `json:"components,omitempty"`
+}
+
+// GraphEdge points the way the stored reference does: from a child to its
+// parent, and from the source of a relationship to its target.
+type GraphEdge struct {
+	From Goent `json:"from"`
+	To   Goent `json:"to"`
+	// Kind is "Parent" for the hierarchy and the relationship type otherwise
+	Kind string `json:"kind"`
+	// Dangling is set when To was destroyed
+	Dangling bool `json:"dangling,omitempty"`
+}
+
+// Graph is a snapshot of the entity graph, nodes and edges sorted.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// Graph captures the hierarchy and relationship edges of the registry.
+func (r *Registry) Graph(opts GraphOptions) Graph {
+	var g Graph
+	nodes := make(map[Goent]struct{})
+	addEdge := func(from, to Goent, kind string) {
+		g.Edges = append(g.Edges, GraphEdge{From: from, To: to, Kind: kind, Dangling: !r.IsAlive(to)})
+		nodes[from] = struct{}{}
+		nodes[to] = struct{}{}
+	}
+	Iterate1(r, func(child Goent, p *Parent) {
+		addEdge(child, p.Entity, "Parent")
+	}, IncludeDisabled())
+	for t, s := range r.relations {
+		kind := t.String()
+		s.edges(func(source, target Goent) {
+			addEdge(source, target, kind)
+		})
+	}
+	if opts.AllEntities {
+		r.eachEntity(func(entity Goent) {
+			nodes[entity] = struct{}{}
+		})
+	}
+
+	for entity := range nodes {
+		node := GraphNode{Entity: entity}
+		if name, ok := r.names.keyOf(entity); ok {
+			node.Label = name
+		} else if alias, ok := r.stringAliases.keyOf(entity); ok {
+			node.Label = alias
+		}
+		if opts.Components {
+			r.VisitEntity(entity, func(t reflect.Type, _ interface{}) {
+				name, _ := r.ComponentName(t)
+				node.Components = append(node.Components, name)
+			})
+		}
+		g.Nodes = append(g.Nodes, node)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].Entity < g.Nodes[j].Entity })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.To < b.To
+	})
+	return g
+}
+
+// Cycles returns the groups of entities that reach each other through the
+// edges, each sorted, e.g. two units targeting each other. The hierarchy
+// can't form cycles, so they are always made of relationships.
+func (g Graph) Cycles() [][]Goent {
+	adj := make(map[Goent][]Goent)
+	for _, e := range g.Edges {
+		adj[e.From] = append(adj[e.From], e.To)
+	}
+	// Tarjan's strongly connected components
+	index := make(map[Goent]int)
+	low := make(map[Goent]int)
+	onStack := make(map[Goent]bool)
+	var stack []Goent
+	var cycles [][]Goent
+	var visit func(v Goent)
+	visit = func(v Goent) {
+		index[v] = len(index)
+		low[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+		selfLoop := false
+		for _, w := range adj[v] {
+			if w == v {
+				selfLoop = true
+			}
+			if _, seen := index[w]; !seen {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var scc []Goent
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			scc = append(scc, w)
+			if w == v {
+				break
+			}
+		}
+		if len(scc) > 1 || selfLoop {
+			sortEntities(scc)
+			cycles = append(cycles, scc)
+		}
+	}
+	for _, e := range g.Edges {
+		if _, seen := index[e.From]; !seen {
+			visit(e.From)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// WriteJSON writes the graph as JSON.
+func (g Graph) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(g)
+}
+
+// WriteDOT writes the graph in the GraphViz DOT language. Dangling edges are
+// drawn dashed and red.
+func (g Graph) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph entities {\n\tnode [shape=box];\n")
+	for _, n := range g.Nodes {
+		label := fmt.Sprintf("#%d", n.Entity)
+		if n.Label != "" {
+			label = n.Label + "\n" + label
+		}
+		if len(n.Components) > 0 {
+			label += "\n" + strings.Join(n.Components, "\n")
+		}
+		fmt.Fprintf(&sb, "\t%d [label=%s];\n", n.Entity, dotQuote(label))
+	}
+	for _, e := range g.Edges {
+		style := ""
+		if e.Dangling {
+			style = ", style=dashed, color=red"
+		}
+		fmt.Fprintf(&sb, "\t%d -> %d [label=%s%s];\n", e.From, e.To, dotQuote(e.Kind), style)
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// dotQuote quotes s as a DOT string, with newlines as line breaks.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,77 @@
+package goecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type graphProbe struct{}
+
+func TestGraph(t *testing.T) {
+	r := NewRegistry()
+	root, child, a, b, loner := r.CreateEntity(), r.CreateEntity(), r.CreateEntity(), r.CreateEntity(), r.CreateEntity()
+	EmplaceComponent(r, loner, graphProbe{})
+	SetParent(r, child, root)
+	r.SetName(root, "scene")
+	// a and b target each other
+	Relate(r, a, b, relTargets{})
+	Relate(r, b, a, relTargets{})
+	// the parent of an orphan was destroyed
+	gone, orphan := r.CreateEntity(), r.CreateEntity()
+	r.DestroyEntity(gone)
+	EmplaceComponent(r, orphan, Parent{Entity: gone})
+
+	g := r.Graph(GraphOptions{})
+	kind := ComponentType[relTargets]().String()
+	wantEdges := []GraphEdge{
+		{From: child, To: root, Kind: "Parent"},
+		{From: a, To: b, Kind: kind},
+		{From: b, To: a, Kind: kind},
+		{From: orphan, To: gone, Kind: "Parent", Dangling: true},
+	}
+	if !slices.Equal(g.Edges, wantEdges) {
+		t.Errorf("edges = %v, want %v", g.Edges, wantEdges)
+	}
+	var nodes []Goent
+	for _, n := range g.Nodes {
+		nodes = append(nodes, n.Entity)
+		if n.Entity == root && n.Label != "scene" {
+			t.Errorf("root labeled %q", n.Label)
+		}
+	}
+	if want := []Goent{root, child, a, b, gone, orphan}; !slices.Equal(nodes, want) {
+		t.Errorf("nodes = %v, want %v", nodes, want)
+	}
+	if cycles := g.Cycles(); !reflect.DeepEqual(cycles, [][]Goent{{a, b}}) {
+		t.Errorf("Cycles() = %v, want [[%d %d]]", cycles, a, b)
+	}
+
+	all := r.Graph(GraphOptions{Components: true, AllEntities: true})
+	i := slices.IndexFunc(all.Nodes, func(n GraphNode) bool { return n.Entity == loner })
+	if len(all.Nodes) != 7 || i < 0 || !slices.Equal(all.Nodes[i].Components, []string{"goecs.graphProbe"}) {
+		t.Errorf("with all entities, nodes = %+v", all.Nodes)
+	}
+
+	var buf bytes.Buffer
+	if err := g.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Graph
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || !reflect.DeepEqual(decoded, g) {
+		t.Errorf("JSON round trip = %+v, %v", decoded, err)
+	}
+	buf.Reset()
+	if err := g.WriteDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	for _, want := range []string{"digraph entities {", `[label="scene\n#`, `[label="Parent", style=dashed, color=red];`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT output lacks %q:\n%s", want, dot)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package goecs
+
+// --- Owning groups ---
+// An owning group keeps the entities that have all of its component types
+// packed at the front of every owned storage, in the same order. Iterating
+// the group is then a straight parallel walk of contiguous slices. The
+// storages stay in lockstep as components come and go, and each storage can
+// only be owned by one group. Archetype registries already store their
+// entities packed and don't support groups.
+
+// groupStorage is the type-erased view of a SparseSet an owning group needs.
+type groupStorage interface {
+	slot(entity Goent) int
+	swapSlots(i, j int)
+	owned() bool
+	preservesOrder() bool
+	setGroup(g *ownedGroup)
+	GetDense() []Goent
+}
+
+// ownedGroup tracks the packed region shared by its storages.
+type ownedGroup struct {
+	registry *Registry
+	storages []groupStorage
+	// size is the number of entities packed at the front of every storage
+	size int
+	// sortPeriod is the tick interval of the spatial sort, 0 when off
+	sortPeriod uint64
+	// position reads the position of the member at a dense index
+	position func(i int) (float64, float64)
+}
+
+// swapSlots exchanges two dense entries and fixes up their sparse indices.
+func (ss *SparseSet[T]) swapSlots(i, j int) {
+	if i == j {
+		return
+	}
+	ss.dense[i], ss.dense[j] = ss.dense[j], ss.dense[i]
+	if ss.tag == nil {
+		ss.components[i], ss.components[j] = ss.components[j], ss.components[i]
+	}
+	ss.ticks[i], ss.ticks[j] = ss.ticks[j], ss.ticks[i]
+	ss.setSlot(ss.dense[i].Index(), i)
+	ss.setSlot(ss.dense[j].Index(), j)
+}
+
+// owned reports whether a group owns the storage.
+func (ss *SparseSet[T]) owned() bool {
+	return ss.group != nil
+}
+
+// preservesOrder reports whether the storage was set to PreserveOrder.
+func (ss *SparseSet[T]) preservesOrder() bool {
+	return ss.stable
+}
+
+// setGroup hands the storage to an owning group. newOwnedGroup checks that
+// it may first.
+func (ss *SparseSet[T]) setGroup(g *ownedGroup) {
+	ss.group = g
+}
+
+func (r *Registry) newOwnedGroup(storages ...groupStorage) *ownedGroup {
+	if r.archetypes != nil {
+		panic("goecs: owning groups need sparse set storage")
+	}
+	if r.ordered {
+		panic("goecs: owning groups can't be used on a deterministic registry")
+	}
+	// Check every storage before claiming any, so a failed group leaves
+	// none of them owned
+	for i, s := range storages {
+		if s.preservesOrder() {
+			panic("goecs: a storage preserving its order can't be owned by a group")
+		}
+		if s.owned() {
+			panic("goecs: component type is already owned by another group")
+		}
+		for _, other := range storages[:i] {
+			if other == s {
+				panic("goecs: a group can't own the same component type twice")
+			}
+		}
+	}
+	g := &ownedGroup{registry: r, storages: storages}
+	for _, s := range storages {
+		s.setGroup(g)
+	}
+	r.groups = append(r.groups, g)
+
+	// Pack the entities that already have every owned type
+	smallest := storages[0].GetDense()
+	for _, s := range storages[1:] {
+		if len(s.GetDense()) < len(smallest) {
+			smallest = s.GetDense()
+		}
+	}
+	candidates := make([]Goent, len(smallest))
+	copy(candidates, smallest)
+	for _, entity := range candidates {
+		g.onAdd(entity)
+	}
+	return g
+}
+
+// isMember reports whether the entity sits in the packed region.
+func (g *ownedGroup) isMember(entity Goent) bool {
+	i := g.storages[0].slot(entity)
+	return i != invalidIndex && i < g.size
+}
+
+// onAdd packs the entity if it now has every owned type.
+func (g *ownedGroup) onAdd(entity Goent) {
+	if g.isMember(entity) {
+		return
+	}
+	for _, s := range g.storages {
+		if s.slot(entity) == invalidIndex {
+			return
+		}
+	}
+	for _, s := range g.storages {
+		s.swapSlots(s.slot(entity), g.size)
+	}
+	g.size++
+}
+
+// onRemove moves a member just past the packed region, where the regular
+// swap-with-last removal can take it out without disturbing the group.
+func (g *ownedGroup) onRemove(entity Goent) {
+	if !g.isMember(entity) {
+		return
+	}
+	g.size--
+	for _, s := range g.storages {
+		s.swapSlots(s.slot(entity), g.size)
+	}
+}
+
+// Group2 is an owning group over the T1 and T2 storages.
+type Group2[T1 any, T2 any] struct {
+	group *ownedGroup
+	s1    *SparseSet[T1]
+	s2    *SparseSet[T2]
+}
+
+// NewGroup2 creates an owning group over T1 and T2, packing the entities
+// that already have all of them. It panics if a type is owned by another group
+// or preserves its order.
+func NewGroup2[T1 any, T2 any](r *Registry) *Group2[T1, T2] {
+	g := &Group2[T1, T2]{
+		s1: ensureStorage[T1](r),
+		s2: ensureStorage[T2](r),
+	}
+	g.group = r.newOwnedGroup(g.s1, g.s2)
+	return g
+}
+
+// Len returns the number of entities in the group.
+func (g *Group2[T1, T2]) Len() int {
+	return g.group.size
+}
+
+// SortSpatial orders the group by Morton code of the position of its first
+// owned component type implementing Spatial, now and then every period ticks
+// as the registry advances. A period of 0 turns the sorting off.
+func (g *Group2[T1, T2]) SortSpatial(period uint64) {
+	g.group.setSpatial(period, spatialAt(g.s1), spatialAt(g.s2))
+}
+
+// Each walks the packed front of the owned storages in lockstep, without
+// any sparse lookups unless some entities are disabled.
+func (g *Group2[T1, T2]) Each(f func(entity Goent, c1 *T1, c2 *T2)) {
+	size := g.group.size
+	fs := g.group.registry.resolveFilters(nil)
+	for i, entity := range g.s1.dense[:size] {
+		if fs.skip(entity) {
+			continue
+		}
+		f(entity, g.s1.at(i), g.s2.at(i))
+	}
+}
+
+// Group3 is an owning group over the T1, T2, and T3 storages.
+type Group3[T1 any, T2 any, T3 any] struct {
+	group *ownedGroup
+	s1    *SparseSet[T1]
+	s2    *SparseSet[T2]
+	s3    *SparseSet[T3]
+}
+
+// NewGroup3 creates an owning group over T1, T2, and T3, packing the entities
+// that already have all of them. It panics if a type is owned by another group
+// or preserves its order.
+func NewGroup3[T1 any, T2 any, T3 any](r *Registry) *Group3[T1, T2, T3] {
+	g := &Group3[T1, T2, T3]{
+		s1: ensureStorage[T1](r),
+		s2: ensureStorage[T2](r),
+		s3: ensureStorage[T3](r),
+	}
+	g.group = r.newOwnedGroup(g.s1, g.s2, g.s3)
+	return g
+}
+
+// Len returns the number of entities in the group.
+func (g *Group3[T1, T2, T3]) Len() int {
+	return g.group.size
+}
+
+// SortSpatial orders the group by Morton code of the position of its first
+// owned component type implementing Spatial, now and then every period ticks
+// as the registry advances. A period of 0 turns the sorting off.
+func (g *Group3[T1, T2, T3]) SortSpatial(period uint64) {
+	g.group.setSpatial(period, spatialAt(g.s1), spatialAt(g.s2), spatialAt(g.s3))
+}
+
+// Each walks the packed front of the owned storages in lockstep, without
+// any sparse lookups unless some entities are disabled.
+func (g *Group3[T1, T2, T3]) Each(f func(entity Goent, c1 *T1, c2 *T2, c3 *T3)) {
+	size := g.group.size
+	fs := g.group.registry.resolveFilters(nil)
+	for i, entity := range g.s1.dense[:size] {
+		if fs.skip(entity) {
+			continue
+		}
+		f(entity, g.s1.at(i), g.s2.at(i), g.s3.at(i))
+	}
+}
+
+// Group4 is an owning group over the T1, T2, T3, and T4 storages.
+type Group4[T1 any, T2 any, T3 any, T4 any] struct {
+	group *ownedGroup
+	s1    *SparseSet[T1]
+	s2    *SparseSet[T2]
+	s3    *SparseSet[T3]
+	s4    *SparseSet[T4]
+}
+
+// NewGroup4 creates an owning group over T1, T2, T3, and T4, packing the entities
+// that already have all of them. It panics if a type is owned by another group
+// or preserves its order.
+func NewGroup4[T1 any, T2 any, T3 any, T4 any](r *Registry) *Group4[T1, T2, T3, T4] {
+	g := &Group4[T1, T2, T3, T4]{
+		s1: ensureStorage[T1](r),
+		s2: ensureStorage[T2](r),
+		s3: ensureStorage[T3](r),
+		s4: ensureStorage[T4](r),
+	}
+	g.group = r.newOwnedGroup(g.s1, g.s2, g.s3, g.s4)
+	return g
+}
+
+// Len returns the number of entities in the group.
+func (g *Group4[T1, T2, T3, T4]) Len() int {
+	return g.group.size
+}
+
+// SortSpatial orders the group by Morton code of the position of its first
+// owned component type implementing Spatial, now and then every period ticks
+// as the registry advances. A period of 0 turns the sorting off.
+func (g *Group4[T1, T2, T3, T4]) SortSpatial(period uint64) {
+	g.group.setSpatial(period, spatialAt(g.s1), spatialAt(g.s2), spatialAt(g.s3), spatialAt(g.s4))
+}
+
+// Each walks the packed front of the owned storages in lockstep, without
+// any sparse lookups unless some entities are disabled.
+func (g *Group4[T1, T2, T3, T4]) Each(f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4)) {
+	size := g.group.size
+	fs := g.group.registry.resolveFilters(nil)
+	for i, entity := range g.s1.dense[:size] {
+		if fs.skip(entity) {
+			continue
+		}
+		f(entity, g.s1.at(i), g.s2.at(i), g.s3.at(i), g.s4.at(i))
+	}
+}
@@ -0,0 +1,48 @@
+package goecs
+
+import "testing"
+
+type groupProbeA struct{ V int }
+type groupProbeB struct{ V int }
+
+func TestNewGroupRejectedLeavesStoragesFree(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(r *Registry)
+	}{
+		{"second preserves order", func(r *Registry) { PreserveOrder[groupProbeB](r) }},
+		{"second owned", func(r *Registry) { NewGroup2[groupProbeB, Parent](r) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			tt.setup(r)
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Error("NewGroup2 didn't panic")
+					}
+				}()
+				NewGroup2[groupProbeA, groupProbeB](r)
+			}()
+			if getStorage[groupProbeA](r).owned() {
+				t.Error("the rejected group still owns the first storage")
+			}
+			// The first storage must still be usable on its own
+			PreserveOrder[groupProbeA](r)
+		})
+	}
+}
+
+func TestNewGroupSameTypeTwice(t *testing.T) {
+	r := NewRegistry()
+	defer func() {
+		if recover() == nil {
+			t.Error("NewGroup2 over one type didn't panic")
+		}
+		if getStorage[groupProbeA](r).owned() {
+			t.Error("the rejected group still owns the storage")
+		}
+	}()
+	NewGroup2[groupProbeA, groupProbeA](r)
+}
@@ -0,0 +1,65 @@
+package goecs
+
+import (
+	"reflect"
+	"sync"
+)
+
+// --- Group-by queries ---
+
+// groupBuffer is the pooled state behind a GroupBy result. spare keeps the
+// emptied entity slices of a released result so their capacity is reused.
+type groupBuffer[K comparable] struct {
+	groups map[K][]Goent
+	spare  [][]Goent
+}
+
+// groupPools holds one *sync.Pool per key type, keyed by the map's reflect.Type.
+var groupPools sync.Map
+
+func groupPoolFor[K comparable]() *sync.Pool {
+	key := reflect.TypeOf(map[K][]Goent(nil))
+	if pool, ok := groupPools.Load(key); ok {
+		return pool.(*sync.Pool)
+	}
+	pool, _ := groupPools.LoadOrStore(key, &sync.Pool{})
+	return pool.(*sync.Pool)
+}
+
+// GroupBy buckets every entity with a T component by the key computed from
+// that component, e.g. render batches by material ID or AI by faction.
+// Disabled entities are left out, like in any other iteration.
+// The result comes from a pool; hand it back with ReleaseGroups once the
+// frame is done with it so the next call doesn't allocate again.
+func GroupBy[T any, K comparable](r *Registry, key func(*T) K) map[K][]Goent {
+	buf, _ := groupPoolFor[K]().Get().(*groupBuffer[K])
+	if buf == nil {
+		buf = &groupBuffer[K]{groups: make(map[K][]Goent)}
+	}
+
+	Iterate1(r, func(entity Goent, c *T) {
+		k := key(c)
+		group, exists := buf.groups[k]
+		if !exists && len(buf.spare) > 0 {
+			last := len(buf.spare) - 1
+			group = buf.spare[last]
+			buf.spare = buf.spare[:last]
+		}
+		buf.groups[k] = append(group, entity)
+	})
+	return buf.groups
+}
+
+// ReleaseGroups returns a GroupBy result to the pool. The map and its slices
+// must not be used after this call.
+func ReleaseGroups[K comparable](groups map[K][]Goent) {
+	if groups == nil {
+		return
+	}
+	buf := &groupBuffer[K]{groups: groups}
+	for k, group := range groups {
+		buf.spare = append(buf.spare, group[:0])
+		delete(groups, k)
+	}
+	groupPoolFor[K]().Put(buf)
+}
@@ -0,0 +1,46 @@
+package goecs
+
+import (
+	"slices"
+	"testing"
+)
+
+type groupByFaction struct {
+	ID int
+}
+
+func TestGroupBy(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(9)
+	for i, e := range entities {
+		EmplaceComponent(r, e, groupByFaction{ID: i % 3})
+	}
+	r.Disable(entities[0])
+
+	groups := GroupBy(r, func(f *groupByFaction) int { return f.ID })
+	want := map[int][]Goent{
+		0: {entities[3], entities[6]},
+		1: {entities[1], entities[4], entities[7]},
+		2: {entities[2], entities[5], entities[8]},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("got %d groups, want %d", len(groups), len(want))
+	}
+	for k, members := range want {
+		got := slices.Clone(groups[k])
+		slices.Sort(got)
+		if !slices.Equal(got, members) {
+			t.Errorf("group %d = %v, want %v", k, got, members)
+		}
+	}
+	ReleaseGroups(groups)
+
+	// a released result is handed out again empty
+	RemoveComponent[groupByFaction](r, entities[1])
+	groups = GroupBy(r, func(f *groupByFaction) int { return f.ID % 2 })
+	defer ReleaseGroups(groups)
+	if len(groups) != 2 || len(groups[0]) != 5 || len(groups[1]) != 2 {
+		t.Errorf("groups = %v, want 5 even and 2 odd", groups)
+	}
+	ReleaseGroups[int](nil)
+}
@@ -0,0 +1,136 @@
+package goecs
+
+import (
+	"math"
+	"slices"
+)
+
+// --- Spatial group order ---
+// Physics and AI systems walking a group often look at each entity's
+// neighbours too. Sorting the packed region by the Morton (Z-order) code of
+// the entities' positions keeps entities that are close in space close in
+// memory, so those lookups mostly hit cache lines the walk just loaded.
+// Entities move, so the order decays; SortSpatial re-sorts every few ticks
+// as part of AdvanceTick. The group reads positions from the first owned
+// component type implementing Spatial:
+//
+//	func (p *Position) SpatialPosition() (float64, float64) { return p.X, p.Y }
+//
+//	g := goecs.NewGroup2[Position, Velocity](r)
+//	g.SortSpatial(30)
+
+// Spatial is implemented by position components a group can be sorted by.
+type Spatial interface {
+	SpatialPosition() (x, y float64)
+}
+
+// spatialAt returns the position reader of a storage whose component type
+// implements Spatial, or nil.
+func spatialAt[T any](s *SparseSet[T]) func(i int) (float64, float64) {
+	if _, ok := any((*T)(nil)).(Spatial); !ok {
+		return nil
+	}
+	return func(i int) (float64, float64) {
+		return any(s.at(i)).(Spatial).SpatialPosition()
+	}
+}
+
+// setSpatial turns the periodic spatial sort on, using the first non-nil
+// position reader, or off when period is 0.
+func (g *ownedGroup) setSpatial(period uint64, readers ...func(i int) (float64, float64)) {
+	g.sortPeriod = period
+	g.position = nil
+	if period == 0 {
+		return
+	}
+	for _, read := range readers {
+		if read != nil {
+			g.position = read
+			break
+		}
+	}
+	if g.position == nil {
+		panic("goecs: SortSpatial needs an owned component type implementing Spatial")
+	}
+	g.sortSpatial()
+}
+
+// maintain runs the group's periodic work for the tick.
+func (g *ownedGroup) maintain(tick uint64) {
+	if g.sortPeriod != 0 && tick%g.sortPeriod == 0 {
+		g.sortSpatial()
+	}
+}
+
+// sortSpatial orders the packed region by the Morton codes of the members'
+// positions, scaled to the bounding box of the group.
+func (g *ownedGroup) sortSpatial() {
+	if g.size < 2 {
+		return
+	}
+	type keyed struct {
+		entity Goent
+		code   uint64
+	}
+	xs := make([]float64, g.size)
+	ys := make([]float64, g.size)
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for i := range g.size {
+		x, y := g.position(i)
+		xs[i], ys[i] = x, y
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+	dense := g.storages[0].GetDense()
+	members := make([]keyed, g.size)
+	for i := range members {
+		members[i] = keyed{
+			entity: dense[i],
+			code:   interleave(quantize(xs[i], minX, maxX), quantize(ys[i], minY, maxY)),
+		}
+	}
+	slices.SortStableFunc(members, func(a, b keyed) int {
+		switch {
+		case a.code < b.code:
+			return -1
+		case a.code > b.code:
+			return 1
+		}
+		return 0
+	})
+	// Move each member into place; the storages stay in lockstep
+	for i, m := range members {
+		from := g.storages[0].slot(m.entity)
+		for _, s := range g.storages {
+			s.swapSlots(from, i)
+		}
+	}
+}
+
+// quantize maps v from [lo, hi] onto the 32 bit grid. NaNs land on 0.
+func quantize(v, lo, hi float64) uint32 {
+	if !(hi > lo) || !(v > lo) {
+		return 0
+	}
+	if v >= hi {
+		return math.MaxUint32
+	}
+	return uint32((v - lo) / (hi - lo) * math.MaxUint32)
+}
+
+// interleave returns the Morton code of x and y, x in the even bits.
+func interleave(x, y uint32) uint64 {
+	return spread(x) | spread(y)<<1
+}
+
+// spread moves the bits of v to the even bit positions.
+func spread(v uint32) uint64 {
+	x := uint64(v)
+	x = (x | x<<16) & 0x0000ffff0000ffff
+	x = (x | x<<8) & 0x00ff00ff00ff00ff
+	x = (x | x<<4) & 0x0f0f0f0f0f0f0f0f
+	x = (x | x<<2) & 0x3333333333333333
+	x = (x | x<<1) & 0x5555555555555555
+	return x
+}
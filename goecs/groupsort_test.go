@@ -0,0 +1,110 @@
+package goecs
+
+import (
+	"math"
+	"testing"
+)
+
+type sortPos struct {
+	X, Y float64
+}
+
+func (p *sortPos) SpatialPosition() (float64, float64) { return p.X, p.Y }
+
+type sortVel struct {
+	ID int
+}
+
+func TestInterleave(t *testing.T) {
+	tests := []struct {
+		x, y uint32
+		want uint64
+	}{
+		{0, 0, 0},
+		{1, 0, 1},
+		{0, 1, 2},
+		{3, 3, 15},
+		{4, 1, 18},
+		{math.MaxUint32, 0, 0x5555555555555555},
+		{math.MaxUint32, math.MaxUint32, math.MaxUint64},
+	}
+	for _, tt := range tests {
+		if got := interleave(tt.x, tt.y); got != tt.want {
+			t.Errorf("interleave(%d, %d) = %#x, want %#x", tt.x, tt.y, got, tt.want)
+		}
+	}
+	if quantize(math.NaN(), 0, 1) != 0 || quantize(5, 0, 0) != 0 || quantize(2, 0, 1) != math.MaxUint32 {
+		t.Error("quantize doesn't clamp")
+	}
+}
+
+// mortonOrdered reports whether the group visits its members by
+// non-decreasing Morton code, and that each still has its own velocity.
+func mortonOrdered(t *testing.T, g *Group2[sortPos, sortVel], ids map[Goent]int) bool {
+	t.Helper()
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	g.Each(func(_ Goent, p *sortPos, _ *sortVel) {
+		minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	})
+	ordered := true
+	var last uint64
+	g.Each(func(e Goent, p *sortPos, v *sortVel) {
+		if v.ID != ids[e] {
+			t.Errorf("entity %d carries velocity %d, want %d", e, v.ID, ids[e])
+		}
+		code := interleave(quantize(p.X, minX, maxX), quantize(p.Y, minY, maxY))
+		ordered = ordered && code >= last
+		last = code
+	})
+	return ordered
+}
+
+func TestSortSpatial(t *testing.T) {
+	r := NewRegistry()
+	g := NewGroup2[sortPos, sortVel](r)
+	ids := map[Goent]int{}
+	// scattered over an 8x8 grid, created in an order far from Z-order
+	for i := range 40 {
+		e := r.CreateEntity()
+		EmplaceComponent(r, e, sortPos{X: float64(i * 5 % 8), Y: float64(i * 3 % 8)})
+		EmplaceComponent(r, e, sortVel{ID: i})
+		ids[e] = i
+	}
+	// a non-member keeps its component
+	loner := r.CreateEntity()
+	EmplaceComponent(r, loner, sortPos{X: -100})
+
+	if mortonOrdered(t, g, ids) {
+		t.Fatal("the unsorted group is already in Morton order")
+	}
+	g.SortSpatial(2)
+	if !mortonOrdered(t, g, ids) {
+		t.Error("SortSpatial didn't order the group")
+	}
+	if p, _ := GetComponent[sortPos](r, loner); p.X != -100 || g.Len() != 40 {
+		t.Errorf("non-member at %v, group of %d", p, g.Len())
+	}
+
+	// moving the members decays the order until the next period
+	for r.Tick()%2 != 0 {
+		r.AdvanceTick()
+	}
+	g.Each(func(_ Goent, p *sortPos, _ *sortVel) { p.X, p.Y = p.Y, 7-p.X })
+	r.AdvanceTick()
+	if mortonOrdered(t, g, ids) {
+		t.Error("the group was re-sorted off period")
+	}
+	r.AdvanceTick()
+	if !mortonOrdered(t, g, ids) {
+		t.Error("the group wasn't re-sorted on period")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("SortSpatial without a Spatial type didn't panic")
+		}
+	}()
+	NewGroup2[groupProbeA, groupProbeB](r).SortSpatial(1)
+}
@@ -0,0 +1,131 @@
+package goecs
+
+// --- Storage growth policy ---
+
+// GrowthPolicy controls how a storage allocates and grows its arrays, so rare
+// components don't waste memory and huge ones don't regrow constantly.
+type GrowthPolicy struct {
+	// InitialCapacity is the number of slots reserved when the storage is created.
+	InitialCapacity int
+	// GrowthFactor multiplies the capacity whenever the storage runs out of
+	// room. Values of 1 or less fall back to the default factor.
+	GrowthFactor float64
+	// MaxGrowth caps how many slots a single grow may add, 0 means unbounded.
+	MaxGrowth int
+	// UpgradeAt, when positive, keeps the storage indexed by a map instead
+	// of a sparse array sized by the highest entity index, until it holds
+	// more than UpgradeAt components. It then switches to the sparse array
+	// for good. Lookups are slower before the switch, but a rare component
+	// costs memory only for the entities that have it.
+	UpgradeAt int
+}
+
+// DefaultGrowthPolicy returns the policy used by storages registered without one.
+func DefaultGrowthPolicy() GrowthPolicy {
+	return GrowthPolicy{
+		InitialCapacity: alignment,
+		GrowthFactor:    2,
+	}
+}
+
+// RareComponentPolicy returns a policy for components few entities have:
+// nothing is reserved up front and the storage stays map indexed up to
+// upgradeAt components.
+func RareComponentPolicy(upgradeAt int) GrowthPolicy {
+	return GrowthPolicy{
+		GrowthFactor: DefaultGrowthPolicy().GrowthFactor,
+		UpgradeAt:    upgradeAt,
+	}
+}
+
+// sanitized replaces out of range fields with their defaults.
+func (p GrowthPolicy) sanitized() GrowthPolicy {
+	if p.InitialCapacity < 0 {
+		p.InitialCapacity = 0
+	}
+	if p.GrowthFactor <= 1 {
+		p.GrowthFactor = DefaultGrowthPolicy().GrowthFactor
+	}
+	if p.MaxGrowth < 0 {
+		p.MaxGrowth = 0
+	}
+	if p.UpgradeAt < 0 {
+		p.UpgradeAt = 0
+	}
+	return p
+}
+
+// next returns the capacity to grow to from current so that at least needed slots fit.
+func (p GrowthPolicy) next(current, needed int) int {
+	grown := int(float64(current) * p.GrowthFactor)
+	if p.MaxGrowth > 0 && grown-current > p.MaxGrowth {
+		grown = current + p.MaxGrowth
+	}
+	if grown < needed {
+		grown = needed
+	}
+	return grown
+}
+
+// growSparse makes sure the sparse array can be indexed by entity indices below size.
+func (ss *SparseSet[T]) growSparse(size int) {
+	if size <= len(ss.sparse) {
+		return
+	}
+	newSparse := make([]int, ss.policy.next(len(ss.sparse), size))
+	for i := range newSparse {
+		newSparse[i] = invalidIndex
+	}
+	copy(newSparse, ss.sparse)
+	ss.sparse = newSparse
+}
+
+// sparseGrower is implemented by every SparseSet, for growing storages
+// without knowing their type.
+type sparseGrower interface {
+	reserveIndex(size int)
+}
+
+// reserveIndex grows the sparse array to cover size indices. Map indexed
+// storages don't need it.
+func (ss *SparseSet[T]) reserveIndex(size int) {
+	if ss.index == nil {
+		ss.growSparse(size)
+	}
+}
+
+// upgrade moves a map indexed storage over to a sparse array.
+func (ss *SparseSet[T]) upgrade() {
+	size := 0
+	for index := range ss.index {
+		size = max(size, int(index)+1)
+	}
+	indices := ss.index
+	ss.index = nil
+	ss.growSparse(size)
+	for index, i := range indices {
+		ss.sparse[index] = i
+	}
+}
+
+// reserve makes sure the dense arrays can hold n entries without reallocating.
+func (ss *SparseSet[T]) reserve(n int) {
+	if n <= cap(ss.dense) {
+		return
+	}
+	newCap := ss.policy.next(cap(ss.dense), n)
+
+	dense := make([]Goent, len(ss.dense), newCap)
+	copy(dense, ss.dense)
+	ss.dense = dense
+
+	if ss.tag == nil {
+		components := make([]*T, len(ss.components), newCap)
+		copy(components, ss.components)
+		ss.components = components
+	}
+
+	ticks := make([]uint64, len(ss.ticks), newCap)
+	copy(ticks, ss.ticks)
+	ss.ticks = ticks
+}
@@ -0,0 +1,83 @@
+package goecs
+
+import "testing"
+
+type growthProbe struct {
+	V int
+}
+
+func TestGrowthPolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        GrowthPolicy
+		current, need int
+		want          int
+	}{
+		{"doubles", GrowthPolicy{GrowthFactor: 2}, 100, 101, 200},
+		{"capped", GrowthPolicy{GrowthFactor: 2, MaxGrowth: 10}, 100, 101, 110},
+		{"at least needed", GrowthPolicy{GrowthFactor: 1.5, MaxGrowth: 10}, 100, 500, 500},
+		{"bad factor", GrowthPolicy{GrowthFactor: 0.5}.sanitized(), 10, 11, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.next(tt.current, tt.need); got != tt.want {
+				t.Errorf("next(%d, %d) = %d, want %d", tt.current, tt.need, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRareComponentPolicy(t *testing.T) {
+	r := NewRegistry()
+	s := RegisterComponentWithPolicy[growthProbe](r, RareComponentPolicy(4))
+	entities := r.CreateEntities(10000)
+	far := entities[len(entities)-1]
+	EmplaceComponent(r, far, growthProbe{V: 1})
+	if s.index == nil || len(s.sparse) != 0 || cap(s.dense) != 1 {
+		t.Fatalf("rare storage allocated a sparse array of %d and %d dense slots", len(s.sparse), cap(s.dense))
+	}
+	for i, e := range entities[:5] {
+		EmplaceComponent(r, e, growthProbe{V: i})
+	}
+	if s.index != nil || len(s.sparse) < int(far.Index())+1 {
+		t.Fatalf("storage of %d components didn't upgrade to a sparse array", s.Len())
+	}
+	if c, ok := GetComponent[growthProbe](r, far); !ok || c.V != 1 {
+		t.Errorf("component lost across the upgrade: %v, %v", c, ok)
+	}
+	for i, e := range entities[:5] {
+		if c, _ := GetComponent[growthProbe](r, e); c == nil || c.V != i {
+			t.Errorf("entity %d holds %v, want %d", i, c, i)
+		}
+	}
+}
+
+func TestRareComponentRemove(t *testing.T) {
+	r := NewRegistry()
+	s := RegisterComponentWithPolicy[growthProbe](r, RareComponentPolicy(8))
+	entities := r.CreateEntities(4)
+	for i, e := range entities {
+		EmplaceComponent(r, e, growthProbe{V: i})
+	}
+	RemoveComponent[growthProbe](r, entities[0])
+	r.DestroyEntity(entities[1])
+	reused := r.CreateEntity()
+	if reused.Index() != entities[1].Index() {
+		t.Fatalf("entity index %d wasn't reused", entities[1].Index())
+	}
+	EmplaceComponent(r, entities[1], growthProbe{V: 100})
+
+	if s.index == nil || len(s.index) != 2 || s.Len() != 2 {
+		t.Fatalf("map index holds %v for %d components, want 2", s.index, s.Len())
+	}
+	for _, e := range []Goent{entities[0], entities[1], reused} {
+		if HasComponent[growthProbe](r, e) {
+			t.Errorf("entity %d still has the component", e)
+		}
+	}
+	for i, e := range entities[2:] {
+		if c, _ := GetComponent[growthProbe](r, e); c == nil || c.V != i+2 {
+			t.Errorf("entity %d holds %v after the swap, want %d", i+2, c, i+2)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package goecs
+
+// --- Stable per-entity hashes ---
+// Visual variation (animation offsets, color jitter) should come out the same
+// in replays and on every networked client, so it can't use math/rand. These
+// hashes are pure functions of an entity's identity instead.
+
+// mix64 is the splitmix64 finalizer, a cheap bijective bit mixer.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// StableHash returns a well mixed 64-bit hash of the entity handle. It is the
+// same in every run and on every peer that allocated the same handle.
+func StableHash(entity Goent) uint64 {
+	return mix64(uint64(entity))
+}
+
+// StableHash hashes the entity's ID alias (its GUID) when it has one, which
+// stays the same across save/load and between peers, and the handle otherwise.
+func (r *Registry) StableHash(entity Goent) uint64 {
+	if guid, ok := r.idAliases.keyOf(entity); ok {
+		return mix64(guid)
+	}
+	return StableHash(entity)
+}
+
+// StableFloat derives a value in [0, 1) from a stable hash and a salt, so one
+// entity can draw several independent variations (salt 0 for the animation
+// offset, salt 1 for the tint, ...).
+func StableFloat(hash uint64, salt uint64) float64 {
+	return float64(mix64(hash^mix64(salt))>>11) / (1 << 53)
+}
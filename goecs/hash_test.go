@@ -0,0 +1,42 @@
+package goecs
+
+import "testing"
+
+func TestStableHash(t *testing.T) {
+	a, b := makeGoent(1, 0), makeGoent(2, 0)
+	if StableHash(a) != StableHash(a) || StableHash(a) == StableHash(b) {
+		t.Error("StableHash isn't a stable, distinguishing hash")
+	}
+	if StableHash(a) == StableHash(makeGoent(1, 1)) {
+		t.Error("StableHash ignores the generation")
+	}
+
+	// entities with the same GUID hash the same in different registries
+	r1, r2 := NewRegistry(), NewRegistry()
+	r2.CreateEntities(5)
+	e1, e2 := r1.CreateEntity(), r2.CreateEntity()
+	r1.SetAliasID(e1, 42)
+	r2.SetAliasID(e2, 42)
+	if r1.StableHash(e1) != r2.StableHash(e2) {
+		t.Error("entities with the same GUID hash differently")
+	}
+	if other := r1.CreateEntity(); r1.StableHash(other) != StableHash(other) {
+		t.Error("entity without a GUID doesn't hash its handle")
+	}
+
+	h := StableHash(a)
+	sum := 0.0
+	for salt := uint64(0); salt < 1000; salt++ {
+		f := StableFloat(h, salt)
+		if f < 0 || f >= 1 {
+			t.Fatalf("StableFloat = %v, want [0, 1)", f)
+		}
+		sum += f
+	}
+	if StableFloat(h, 0) == StableFloat(h, 1) || StableFloat(h, 3) != StableFloat(h, 3) {
+		t.Error("salts don't give stable, independent values")
+	}
+	if mean := sum / 1000; mean < 0.45 || mean > 0.55 {
+		t.Errorf("StableFloat averages %v over 1000 salts", mean)
+	}
+}
@@ -0,0 +1,155 @@
+package goecs
+
+import "fmt"
+
+// --- Parent/child hierarchy ---
+// Scene-graph relations are stored as a Parent component on the child and a
+// Children component on the parent. Both are kept in sync by SetParent and
+// RemoveParent, which are the only way they should be changed. Destroying
+// an entity detaches it from its parent and orphans its children;
+// DestroyRecursive takes the whole subtree down instead.
+
+// Parent points at the entity's parent.
+type Parent struct {
+	Entity Goent
+}
+
+// Children lists an entity's children in the order they were attached.
+type Children struct {
+	Entities []Goent
+}
+
+// SetParent attaches child to parent, detaching it from its previous parent
+// first. It panics if parent is child or one of its descendants.
+func SetParent(r *Registry, child, parent Goent) {
+	r.assertWritable()
+	if r.isStale(child) || r.isStale(parent) {
+		return
+	}
+	for e, ok := parent, true; ok; e, ok = GetParent(r, e) {
+		if e == child {
+			panic(fmt.Sprintf("goecs: making %d the parent of %d would create a cycle", parent, child))
+		}
+	}
+	detachFromParent(r, child)
+	EmplaceComponent(r, child, Parent{Entity: parent})
+	if c, ok := GetComponent[Children](r, parent); ok {
+		c.Entities = append(c.Entities, child)
+		MarkChanged[Children](r, parent)
+	} else {
+		EmplaceComponent(r, parent, Children{Entities: []Goent{child}})
+	}
+}
+
+// RemoveParent detaches child from its parent, making it a root.
+func RemoveParent(r *Registry, child Goent) {
+	r.assertWritable()
+	if detachFromParent(r, child) {
+		RemoveComponent[Parent](r, child)
+	}
+}
+
+// detachFromParent drops child from its parent's Children, removing the
+// component once it is empty. It reports whether child had a parent.
+func detachFromParent(r *Registry, child Goent) bool {
+	p, ok := GetComponent[Parent](r, child)
+	if !ok {
+		return false
+	}
+	parent := p.Entity
+	c, ok := GetComponent[Children](r, parent)
+	if !ok {
+		return true
+	}
+	for i, e := range c.Entities {
+		if e == child {
+			c.Entities = append(c.Entities[:i], c.Entities[i+1:]...)
+			break
+		}
+	}
+	if len(c.Entities) == 0 {
+		RemoveComponent[Children](r, parent)
+	} else {
+		MarkChanged[Children](r, parent)
+	}
+	return true
+}
+
+// GetParent returns the parent of the entity.
+func GetParent(r *Registry, child Goent) (Goent, bool) {
+	p, ok := GetComponent[Parent](r, child)
+	if !ok {
+		return 0, false
+	}
+	return p.Entity, true
+}
+
+// GetChildren returns the children of the entity in attach order. The slice
+// belongs to the registry and must not be modified.
+func GetChildren(r *Registry, parent Goent) []Goent {
+	c, ok := GetComponent[Children](r, parent)
+	if !ok {
+		return nil
+	}
+	return c.Entities
+}
+
+// IterateChildren calls f for every direct child of parent. f must not change
+// the hierarchy; collect the changes and apply them afterwards.
+func IterateChildren(r *Registry, parent Goent, f func(child Goent)) {
+	for _, child := range GetChildren(r, parent) {
+		f(child)
+	}
+}
+
+// IterateDescendants calls f for every descendant of root, parents before
+// their children, with depth 1 for direct children.
+func IterateDescendants(r *Registry, root Goent, f func(entity Goent, depth int)) {
+	var walk func(parent Goent, depth int)
+	walk = func(parent Goent, depth int) {
+		for _, child := range GetChildren(r, parent) {
+			f(child, depth)
+			walk(child, depth+1)
+		}
+	}
+	walk(root, 1)
+}
+
+// DestroyRecursive destroys the entity together with all its descendants.
+func (r *Registry) DestroyRecursive(entity Goent) {
+	if r.isStale(entity) {
+		return
+	}
+	doomed := []Goent{entity}
+	IterateDescendants(r, entity, func(e Goent, _ int) {
+		doomed = append(doomed, e)
+	})
+	r.DestroyEntities(doomed)
+}
+
+// hierarchyStored reports whether Parent or Children components were ever
+// stored, however they got there, so destroys only pay for the hierarchy
+// when it is used.
+func (r *Registry) hierarchyStored() bool {
+	parent, children := typeKeyFor[Parent](), typeKeyFor[Children]()
+	if r.archetypes != nil {
+		_, p := r.archetypes.typeIDs[parent]
+		_, c := r.archetypes.typeIDs[children]
+		return p || c
+	}
+	_, p := r.storages[parent]
+	_, c := r.storages[children]
+	return p || c
+}
+
+// unlinkHierarchy detaches an entity about to be destroyed from its parent
+// and orphans its children.
+func (r *Registry) unlinkHierarchy(entity Goent) {
+	if !r.hierarchyStored() {
+		return
+	}
+	detachFromParent(r, entity)
+	for _, child := range append([]Goent(nil), GetChildren(r, entity)...) {
+		RemoveComponent[Parent](r, child)
+	}
+}
@@ -0,0 +1,117 @@
+package goecs
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestHierarchyStaysLinked(t *testing.T) {
+	tests := []struct {
+		name string
+		// build returns the registry, a parent and its child
+		build func(t *testing.T) (*Registry, Goent, Goent)
+	}{
+		{"SetParent", func(t *testing.T) (*Registry, Goent, Goent) {
+			r := NewRegistry()
+			parent, child := r.CreateEntity(), r.CreateEntity()
+			SetParent(r, child, parent)
+			return r, parent, child
+		}},
+		{"EmplaceComponent", func(t *testing.T) (*Registry, Goent, Goent) {
+			r := NewRegistry()
+			parent, child := r.CreateEntity(), r.CreateEntity()
+			EmplaceComponent(r, child, Parent{Entity: parent})
+			EmplaceComponent(r, parent, Children{Entities: []Goent{child}})
+			return r, parent, child
+		}},
+		{"EmplaceComponent archetypes", func(t *testing.T) (*Registry, Goent, Goent) {
+			r := NewRegistryWithOptions(RegistryOptions{Storage: ArchetypeStorage})
+			parent, child := r.CreateEntity(), r.CreateEntity()
+			EmplaceComponent(r, child, Parent{Entity: parent})
+			EmplaceComponent(r, parent, Children{Entities: []Goent{child}})
+			return r, parent, child
+		}},
+		{"Load", func(t *testing.T) (*Registry, Goent, Goent) {
+			src := NewRegistry()
+			parent, child := src.CreateEntity(), src.CreateEntity()
+			SetParent(src, child, parent)
+			var buf bytes.Buffer
+			if err := src.Save(&buf); err != nil {
+				t.Fatal(err)
+			}
+			r := NewRegistry()
+			RegisterComponent[Parent](r)
+			RegisterComponent[Children](r)
+			if err := r.Load(&buf); err != nil {
+				t.Fatal(err)
+			}
+			return r, parent, child
+		}},
+		{"CopyEntities", func(t *testing.T) (*Registry, Goent, Goent) {
+			src := NewRegistry()
+			parent, child := src.CreateEntity(), src.CreateEntity()
+			SetParent(src, child, parent)
+			r := NewRegistry()
+			ids, err := CopyEntities(r, src, []Goent{parent, child})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return r, ids[parent], ids[child]
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, parent, child := tt.build(t)
+			if got := GetChildren(r, parent); !slices.Equal(got, []Goent{child}) {
+				t.Fatalf("children before destroy = %v, want [%d]", got, child)
+			}
+			r.DestroyEntity(child)
+			if got := GetChildren(r, parent); len(got) != 0 {
+				t.Errorf("children after destroying the child = %v, want none", got)
+			}
+		})
+	}
+}
+
+func TestCloneEntityHierarchy(t *testing.T) {
+	r := NewRegistry()
+	root, node, leaf := r.CreateEntity(), r.CreateEntity(), r.CreateEntity()
+	SetParent(r, node, root)
+	SetParent(r, leaf, node)
+
+	clone := r.CloneEntity(node)
+	if got := GetChildren(r, clone); len(got) != 0 {
+		t.Errorf("clone children = %v, want none", got)
+	}
+	if p, ok := GetParent(r, clone); !ok || p != root {
+		t.Errorf("clone parent = %d, %v, want %d", p, ok, root)
+	}
+	if got := GetChildren(r, root); !slices.Equal(got, []Goent{node, clone}) {
+		t.Errorf("root children = %v, want [%d %d]", got, node, clone)
+	}
+
+	r.DestroyRecursive(clone)
+	if !r.IsAlive(leaf) {
+		t.Error("destroying the clone took the original's child down")
+	}
+	if got := GetChildren(r, root); !slices.Equal(got, []Goent{node}) {
+		t.Errorf("root children after destroy = %v, want [%d]", got, node)
+	}
+}
+
+func TestCopyEntitiesDropsOutsideParent(t *testing.T) {
+	src := NewRegistry()
+	root, child := src.CreateEntity(), src.CreateEntity()
+	SetParent(src, child, root)
+
+	dst := NewRegistry()
+	dst.CreateEntities(3)
+	ids, err := CopyEntities(dst, src, []Goent{child})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := GetParent(dst, ids[child]); ok {
+		t.Error("copy kept a parent that wasn't copied")
+	}
+}
@@ -0,0 +1,106 @@
+package goecs
+
+import (
+	"reflect"
+)
+
+// --- Component lifecycle hooks ---
+// Hooks let code react when a component type is attached, replaced or
+// detached, e.g. to register a body with the physics engine. OnAdd and
+// OnUpdate fire after the value is stored, OnRemove fires while the
+// component is still attached, so every callback sees a valid pointer.
+// Removing the same component from inside its own OnRemove is not allowed.
+
+// hookSet is the type-erased side of componentHooks, used where components
+// are removed without knowing their type.
+type hookSet interface {
+	fireRemove(entity Goent, comp interface{})
+}
+
+type componentHooks[T any] struct {
+	add    []func(entity Goent, c *T)
+	update []func(entity Goent, c *T)
+	remove []func(entity Goent, c *T)
+}
+
+func (h *componentHooks[T]) fire(fns []func(entity Goent, c *T), entity Goent, c *T) {
+	for _, fn := range fns {
+		fn(entity, c)
+	}
+}
+
+func (h *componentHooks[T]) fireRemove(entity Goent, comp interface{}) {
+	h.fire(h.remove, entity, comp.(*T))
+}
+
+// hooksFor returns the hooks of T, or nil when none are registered.
+func hooksFor[T any](r *Registry) *componentHooks[T] {
+	if len(r.hooks) == 0 {
+		return nil
+	}
+	h, ok := r.hooks[typeKeyFor[T]()]
+	if !ok {
+		return nil
+	}
+	return h.(*componentHooks[T])
+}
+
+func ensureHooks[T any](r *Registry) *componentHooks[T] {
+	if h := hooksFor[T](r); h != nil {
+		return h
+	}
+	h := &componentHooks[T]{}
+	r.hooks[typeKeyFor[T]()] = h
+	return h
+}
+
+// OnAdd registers f to run whenever a T component is attached to an entity
+// that didn't have one.
+func OnAdd[T any](r *Registry, f func(entity Goent, c *T)) {
+	h := ensureHooks[T](r)
+	h.add = append(h.add, f)
+}
+
+// OnUpdate registers f to run whenever an existing T component is replaced
+// through EmplaceComponent.
+func OnUpdate[T any](r *Registry, f func(entity Goent, c *T)) {
+	h := ensureHooks[T](r)
+	h.update = append(h.update, f)
+}
+
+// OnRemove registers f to run whenever a T component is detached, including
+// when its entity is destroyed.
+func OnRemove[T any](r *Registry, f func(entity Goent, c *T)) {
+	h := ensureHooks[T](r)
+	h.remove = append(h.remove, f)
+}
+
+// componentOf retrieves a component pointer by type on either backend.
+func (r *Registry) componentOf(entity Goent, t reflect.Type) (interface{}, bool) {
+	if r.archetypes != nil {
+		return r.archetypes.get(entity, t)
+	}
+	storage, ok := r.storages[t]
+	if !ok {
+		return nil, false
+	}
+	return storage.GetComponent(entity)
+}
+
+// fireRemoveHook runs the OnRemove hooks of one type if the entity has it.
+func (r *Registry) fireRemoveHook(entity Goent, t reflect.Type) {
+	h, ok := r.hooks[t]
+	if !ok {
+		return
+	}
+	if comp, ok := r.componentOf(entity, t); ok {
+		h.fireRemove(entity, comp)
+	}
+}
+
+// fireRemoveHooks runs the OnRemove hooks of every type the entity has.
+func (r *Registry) fireRemoveHooks(entity Goent) {
+	for t := range r.hooks {
+		r.fireRemoveHook(entity, t)
+	}
+}
@@ -0,0 +1,50 @@
+package goecs
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+type hookBody struct {
+	Mass int
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			var log []string
+			record := func(event string) func(Goent, *hookBody) {
+				return func(e Goent, c *hookBody) {
+					if c == nil {
+						t.Errorf("%s hook of entity %d got nil", event, e)
+						return
+					}
+					log = append(log, fmt.Sprintf("%s %d %d", event, e.Index(), c.Mass))
+				}
+			}
+			OnAdd(r, record("add"))
+			OnUpdate(r, record("update"))
+			OnRemove(r, record("remove"))
+			OnRemove(r, func(e Goent, c *hookBody) {
+				if !HasComponent[hookBody](r, e) {
+					t.Errorf("entity %d lost the component before OnRemove", e)
+				}
+			})
+
+			a, b := r.CreateEntity(), r.CreateEntity()
+			EmplaceComponent(r, a, hookBody{Mass: 1})
+			EmplaceComponent(r, a, hookBody{Mass: 2})
+			EmplaceComponent(r, b, hookBody{Mass: 3})
+			RemoveComponent[hookBody](r, a)
+			RemoveComponent[hookBody](r, a)
+			r.DestroyEntity(b)
+
+			want := []string{"add 0 1", "update 0 2", "add 1 3", "remove 0 2", "remove 1 3"}
+			if !slices.Equal(log, want) {
+				t.Errorf("hooks fired %v, want %v", log, want)
+			}
+		})
+	}
+}
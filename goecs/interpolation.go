@@ -0,0 +1,95 @@
+package goecs
+
+import (
+	"reflect"
+)
+
+// --- Component interpolation registry ---
+// Component types register how to blend two of their values once, and every
+// feature that blends state (interpolation buffers, render interpolation,
+// tweening) looks the functions up here instead of needing its own glue.
+
+// LerpFunc blends from a to b, t going from 0 (a) to 1 (b).
+type LerpFunc[T any] func(a, b T, t float64) T
+
+// ExtrapolateFunc predicts a value t steps past cur, given the value one step
+// before it.
+type ExtrapolateFunc[T any] func(prev, cur T, t float64) T
+
+// interpolator is the type-erased entry of one component type.
+type interpolator struct {
+	// typed holds the *typedInterpolator[T] for typed lookups
+	typed interface{}
+	lerp  func(a, b interface{}, t float64) interface{}
+	extra func(prev, cur interface{}, t float64) interface{}
+}
+
+type typedInterpolator[T any] struct {
+	lerp  LerpFunc[T]
+	extra ExtrapolateFunc[T]
+}
+
+// RegisterInterpolation registers the blend functions of T. A nil extrapolate
+// falls back to lerping past the end, Lerp(prev, cur, 1+t).
+func RegisterInterpolation[T any](r *Registry, lerp LerpFunc[T], extrapolate ExtrapolateFunc[T]) {
+	if extrapolate == nil {
+		extrapolate = func(prev, cur T, t float64) T {
+			return lerp(prev, cur, 1+t)
+		}
+	}
+	typed := &typedInterpolator[T]{lerp: lerp, extra: extrapolate}
+	r.interpolators[typeKeyFor[T]()] = interpolator{
+		typed: typed,
+		lerp: func(a, b interface{}, t float64) interface{} {
+			return lerp(a.(T), b.(T), t)
+		},
+		extra: func(prev, cur interface{}, t float64) interface{} {
+			return extrapolate(prev.(T), cur.(T), t)
+		},
+	}
+}
+
+// HasInterpolation reports whether blend functions are registered for the type.
+func (r *Registry) HasInterpolation(t reflect.Type) bool {
+	_, ok := r.interpolators[t]
+	return ok
+}
+
+// Lerp blends two T values with the registered function. It reports false,
+// returning b, when T has no registration.
+func Lerp[T any](r *Registry, a, b T, t float64) (T, bool) {
+	entry, ok := r.interpolators[typeKeyFor[T]()]
+	if !ok {
+		return b, false
+	}
+	return entry.typed.(*typedInterpolator[T]).lerp(a, b, t), true
+}
+
+// Extrapolate predicts a T value with the registered function. It reports
+// false, returning cur, when T has no registration.
+func Extrapolate[T any](r *Registry, prev, cur T, t float64) (T, bool) {
+	entry, ok := r.interpolators[typeKeyFor[T]()]
+	if !ok {
+		return cur, false
+	}
+	return entry.typed.(*typedInterpolator[T]).extra(prev, cur, t), true
+}
+
+// LerpValue is the type-erased Lerp for features that handle components
+// generically. a and b must be values (not pointers) of the given type.
+func (r *Registry) LerpValue(typ reflect.Type, a, b interface{}, t float64) (interface{}, bool) {
+	entry, ok := r.interpolators[typ]
+	if !ok {
+		return b, false
+	}
+	return entry.lerp(a, b, t), true
+}
+
+// ExtrapolateValue is the type-erased Extrapolate.
+func (r *Registry) ExtrapolateValue(typ reflect.Type, prev, cur interface{}, t float64) (interface{}, bool) {
+	entry, ok := r.interpolators[typ]
+	if !ok {
+		return cur, false
+	}
+	return entry.extra(prev, cur, t), true
+}
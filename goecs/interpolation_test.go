@@ -0,0 +1,53 @@
+package goecs
+
+import "testing"
+
+type lerpPos struct {
+	X float64
+}
+
+type lerpAngle struct {
+	Deg float64
+}
+
+func TestInterpolation(t *testing.T) {
+	r := NewRegistry()
+	RegisterInterpolation(r, func(a, b lerpPos, t float64) lerpPos {
+		return lerpPos{X: a.X + (b.X-a.X)*t}
+	}, nil)
+	RegisterInterpolation(r, func(a, b lerpAngle, t float64) lerpAngle {
+		return lerpAngle{Deg: a.Deg + (b.Deg-a.Deg)*t}
+	}, func(prev, cur lerpAngle, t float64) lerpAngle {
+		// clamps instead of predicting past the last value
+		return cur
+	})
+
+	if got, ok := Lerp(r, lerpPos{X: 0}, lerpPos{X: 10}, 0.25); !ok || got.X != 2.5 {
+		t.Errorf("Lerp = %v, %v, want 2.5", got, ok)
+	}
+	if got, ok := Extrapolate(r, lerpPos{X: 0}, lerpPos{X: 10}, 0.5); !ok || got.X != 15 {
+		t.Errorf("default Extrapolate = %v, %v, want 15", got, ok)
+	}
+	if got, _ := Extrapolate(r, lerpAngle{Deg: 0}, lerpAngle{Deg: 90}, 0.5); got.Deg != 90 {
+		t.Errorf("custom Extrapolate = %v, want 90", got)
+	}
+
+	typ := ComponentType[lerpPos]()
+	if !r.HasInterpolation(typ) || r.HasInterpolation(ComponentType[entityProbe]()) {
+		t.Error("HasInterpolation disagrees with the registrations")
+	}
+	if got, ok := r.LerpValue(typ, lerpPos{X: 2}, lerpPos{X: 4}, 0.5); !ok || got != (lerpPos{X: 3}) {
+		t.Errorf("LerpValue = %v, %v, want {3}", got, ok)
+	}
+	if got, ok := r.ExtrapolateValue(typ, lerpPos{X: 2}, lerpPos{X: 4}, 1); !ok || got != (lerpPos{X: 6}) {
+		t.Errorf("ExtrapolateValue = %v, %v, want {6}", got, ok)
+	}
+
+	// unregistered types report false and keep the latest value
+	if got, ok := Lerp(r, entityProbe{V: 1}, entityProbe{V: 2}, 0.5); ok || got.V != 2 {
+		t.Errorf("unregistered Lerp = %v, %v", got, ok)
+	}
+	if got, ok := r.ExtrapolateValue(ComponentType[entityProbe](), entityProbe{V: 1}, entityProbe{V: 2}, 1); ok || got != (entityProbe{V: 2}) {
+		t.Errorf("unregistered ExtrapolateValue = %v, %v", got, ok)
+	}
+}
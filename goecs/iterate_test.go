@@ -0,0 +1,182 @@
+package goecs
+
+import (
+	"slices"
+	"testing"
+)
+
+type iterA struct{ V int }
+type iterB struct{ V int }
+type iterC struct{ V int }
+type iterD struct{ V int }
+type iterE struct{ V int }
+type iterF struct{ V int }
+type iterG struct{ V int }
+type iterH struct{ V int }
+
+// iterBackends builds the iteration tests' registries for each storage
+// backend.
+var iterBackends = []struct {
+	name string
+	new  func() *Registry
+}{
+	{"sparse sets", NewRegistry},
+	{"archetypes", func() *Registry { return NewRegistryWithOptions(RegistryOptions{Storage: ArchetypeStorage}) }},
+}
+
+// newIterWorld creates n entities where entity i has iterA and, for each
+// further type k (B=1, C=2, ...), the type when i is divisible by k+1.
+func newIterWorld(r *Registry, n int) []Goent {
+	entities := r.CreateEntities(n)
+	for i, e := range entities {
+		EmplaceComponent(r, e, iterA{V: i})
+		emplaceIf := func(k int, emplace func()) {
+			if i%(k+1) == 0 {
+				emplace()
+			}
+		}
+		emplaceIf(1, func() { EmplaceComponent(r, e, iterB{V: i}) })
+		emplaceIf(2, func() { EmplaceComponent(r, e, iterC{V: i}) })
+		emplaceIf(3, func() { EmplaceComponent(r, e, iterD{V: i}) })
+		emplaceIf(4, func() { EmplaceComponent(r, e, iterE{V: i}) })
+		emplaceIf(5, func() { EmplaceComponent(r, e, iterF{V: i}) })
+		emplaceIf(6, func() { EmplaceComponent(r, e, iterG{V: i}) })
+		emplaceIf(7, func() { EmplaceComponent(r, e, iterH{V: i}) })
+	}
+	return entities
+}
+
+// sortedValues sorts the component values a callback collected.
+func sortedValues(values []int) []int {
+	slices.Sort(values)
+	return values
+}
+
+// multiples returns the numbers below n divisible by every divisor.
+func multiples(n int, divisors ...int) []int {
+	var out []int
+	for i := 0; i < n; i++ {
+		ok := true
+		for _, d := range divisors {
+			ok = ok && i%d == 0
+		}
+		if ok {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func TestIterate1(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			newIterWorld(r, 30)
+			var got []int
+			Iterate1(r, func(e Goent, c *iterC) {
+				if c.V != int(e.Index()) {
+					t.Errorf("entity %d got component %d", e, c.V)
+				}
+				got = append(got, c.V)
+			})
+			if want := multiples(30, 3); !slices.Equal(sortedValues(got), want) {
+				t.Errorf("visited %v, want %v", got, want)
+			}
+			Iterate1(r, func(Goent, *entityProbe) { t.Error("visited an unregistered type") })
+		})
+	}
+}
+
+func TestIterateArities(t *testing.T) {
+	const n = 900
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			newIterWorld(r, n)
+			var got5, got6, got7, got8 []int
+			Iterate5(r, func(e Goent, a *iterA, b *iterB, c *iterC, d *iterD, e5 *iterE) {
+				if a.V != b.V || b.V != c.V || c.V != d.V || d.V != e5.V {
+					t.Errorf("entity %d mixes components of different entities", e)
+				}
+				got5 = append(got5, a.V)
+			})
+			Iterate6(r, func(e Goent, a *iterA, b *iterB, c *iterC, d *iterD, e5 *iterE, f *iterF) {
+				got6 = append(got6, f.V)
+			})
+			Iterate7(r, func(e Goent, a *iterA, b *iterB, c *iterC, d *iterD, e5 *iterE, f *iterF, g *iterG) {
+				got7 = append(got7, g.V)
+			})
+			Iterate8(r, func(e Goent, a *iterA, b *iterB, c *iterC, d *iterD, e5 *iterE, f *iterF, g *iterG, h *iterH) {
+				if a.V != h.V {
+					t.Errorf("entity %d mixes components of different entities", e)
+				}
+				got8 = append(got8, h.V)
+			})
+			for _, tt := range []struct {
+				arity     int
+				got, want []int
+			}{
+				{5, got5, multiples(n, 2, 3, 4, 5)},
+				{6, got6, multiples(n, 2, 3, 4, 5, 6)},
+				{7, got7, multiples(n, 2, 3, 4, 5, 6, 7)},
+				{8, got8, multiples(n, 2, 3, 4, 5, 6, 7, 8)},
+			} {
+				if !slices.Equal(sortedValues(tt.got), tt.want) {
+					t.Errorf("Iterate%d visited %v, want %v", tt.arity, tt.got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithout(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			newIterWorld(r, 30)
+			var got1, got2 []int
+			Iterate1(r, func(e Goent, c *iterB) { got1 = append(got1, c.V) }, Without[iterC]())
+			Iterate2(r, func(e Goent, a *iterA, c *iterB) { got2 = append(got2, a.V) }, Without[iterC](), Without[iterF]())
+			// every multiple of 6 has iterC, so the second filter drops nothing more
+			var want []int
+			for _, i := range multiples(30, 2) {
+				if i%3 != 0 {
+					want = append(want, i)
+				}
+			}
+			if !slices.Equal(sortedValues(got1), want) {
+				t.Errorf("Iterate1 visited %v, want %v", got1, want)
+			}
+			if !slices.Equal(sortedValues(got2), want) {
+				t.Errorf("Iterate2 visited %v, want %v", got2, want)
+			}
+			n := 0
+			Iterate1(r, func(Goent, *iterA) { n++ }, Without[entityProbe]())
+			if n != 30 {
+				t.Errorf("Without an unregistered type visited %d, want 30", n)
+			}
+		})
+	}
+}
+
+func TestOptional(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			newIterWorld(r, 12)
+			var visited []int
+			Iterate3(r, func(e Goent, b *iterB, c *iterC, d *iterD) {
+				visited = append(visited, b.V)
+				if (c != nil) != (b.V%3 == 0) || (d != nil) != (b.V%4 == 0) {
+					t.Errorf("entity %d got iterC %v and iterD %v", e, c, d)
+				}
+				if c != nil && c.V != b.V {
+					t.Errorf("entity %d got another entity's iterC", e)
+				}
+			}, Optional[iterC](), Optional[iterD]())
+			if want := multiples(12, 2); !slices.Equal(sortedValues(visited), want) {
+				t.Errorf("visited %v, want %v", visited, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package goecs
+
+// --- Value joins ---
+// A join pairs entities whose components agree on a key, like projectiles
+// and players of the same team:
+//
+//	pairs := Join(r,
+//		func(p *Projectile) int { return p.TeamID },
+//		func(p *Player) int { return p.TeamID })
+//
+// The B side is bucketed by key first, as GroupBy does, so the join costs
+// one pass over each storage instead of a nested scan.
+
+// EntityPair is one match of a join.
+type EntityPair struct {
+	A, B Goent
+}
+
+// JoinEach calls f for every pair of an entity with an A and a different
+// entity with a B whose keys are equal. Pairs come in A iteration order.
+// Structural changes inside f must be deferred, see CommandBuffer.
+func JoinEach[A any, B any, K comparable](r *Registry, keyA func(*A) K, keyB func(*B) K, f func(a Goent, ca *A, b Goent, cb *B)) {
+	index := make(map[K][]Goent)
+	Iterate1(r, func(entity Goent, c *B) {
+		k := keyB(c)
+		index[k] = append(index[k], entity)
+	})
+	if len(index) == 0 {
+		return
+	}
+	Iterate1(r, func(a Goent, ca *A) {
+		for _, b := range index[keyA(ca)] {
+			if b == a {
+				continue
+			}
+			if cb, ok := GetComponent[B](r, b); ok {
+				f(a, ca, b, cb)
+			}
+		}
+	})
+}
+
+// Join returns every pair JoinEach would visit.
+func Join[A any, B any, K comparable](r *Registry, keyA func(*A) K, keyB func(*B) K) []EntityPair {
+	var pairs []EntityPair
+	JoinEach(r, keyA, keyB, func(a Goent, _ *A, b Goent, _ *B) {
+		pairs = append(pairs, EntityPair{A: a, B: b})
+	})
+	return pairs
+}
@@ -0,0 +1,47 @@
+package goecs
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+)
+
+type joinProjectile struct {
+	Team int
+}
+
+type joinPlayer struct {
+	Team int
+}
+
+func TestJoin(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			players := r.CreateEntities(3)
+			for i, e := range players {
+				EmplaceComponent(r, e, joinPlayer{Team: i % 2})
+			}
+			shots := r.CreateEntities(3)
+			for i, e := range shots {
+				EmplaceComponent(r, e, joinProjectile{Team: i})
+			}
+			// an entity never pairs with itself
+			EmplaceComponent(r, players[1], joinProjectile{Team: 1})
+
+			pairs := Join(r,
+				func(p *joinProjectile) int { return p.Team },
+				func(p *joinPlayer) int { return p.Team })
+			want := []EntityPair{
+				{shots[0], players[0]}, {shots[0], players[2]},
+				{shots[1], players[1]},
+			}
+			slices.SortFunc(pairs, func(x, y EntityPair) int {
+				return cmp.Or(cmp.Compare(x.A, y.A), cmp.Compare(x.B, y.B))
+			})
+			if !slices.Equal(pairs, want) {
+				t.Errorf("Join = %v, want %v", pairs, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package goecs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// --- JSON export/import ---
+// For tooling and debugging a registry can be written as JSON, each entity
+// listing its component values keyed by type name:
+//
+//	{"entities": [{"id": 3, "components": {"game.Transform": {"X": 1}}}]}
+//
+// Component types opt in by getting a stable name with RegisterNamedComponent.
+// Types without one are left out.
+
+type jsonEntity struct {
+	ID         Goent                      `json:"id"`
+	Components map[string]json.RawMessage `json:"components"`
+}
+
+type jsonWorld struct {
+	Entities []jsonEntity `json:"entities"`
+}
+
+// RegisterJSONComponent opts T into JSON export and import under name.
+//
+// Deprecated: use RegisterNamedComponent, which names the type for every
+// serialization feature at once.
+func RegisterJSONComponent[T any](r *Registry, name string) {
+	RegisterNamedComponent[T](r, name)
+}
+
+// MarshalJSON exports every entity that has at least one opted-in component.
+func (r *Registry) MarshalJSON() ([]byte, error) {
+	var infos []*componentInfo
+	for _, info := range r.sortedComponentTypes() {
+		if info.named {
+			infos = append(infos, info)
+		}
+	}
+
+	world := jsonWorld{Entities: []jsonEntity{}}
+	for _, entity := range r.FilteredEntities(SnapshotFilter{}) {
+		out := jsonEntity{ID: entity, Components: make(map[string]json.RawMessage)}
+		for _, info := range infos {
+			comp, ok := r.componentOf(entity, info.typ)
+			if !ok {
+				continue
+			}
+			raw, err := json.Marshal(comp)
+			if err != nil {
+				return nil, fmt.Errorf("goecs: exporting %s: %w", info.name, err)
+			}
+			out.Components[info.name] = raw
+		}
+		if len(out.Components) > 0 {
+			world.Entities = append(world.Entities, out)
+		}
+	}
+	return json.Marshal(world)
+}
+
+// ImportJSON creates a new entity for every entity in the document and
+// emplaces its components. It returns the entity each document ID was given;
+// Goent references inside the components are rewritten the same way (see
+// RemapEntities). Unknown component names fail the import before anything is
+// created. Components are emplaced type by type in restore order (see
+// RestoreAfter).
+func (r *Registry) ImportJSON(data []byte) (map[Goent]Goent, error) {
+	var world jsonWorld
+	if err := json.Unmarshal(data, &world); err != nil {
+		return nil, err
+	}
+
+	// decode everything first so a bad document doesn't leave half an import
+	type decoded struct {
+		entity Goent
+		info   *componentInfo
+		value  reflect.Value
+	}
+	var comps []decoded
+	for _, entity := range world.Entities {
+		for name, raw := range entity.Components {
+			info, ok := r.namedComponent(name)
+			if !ok {
+				return nil, fmt.Errorf("%w: JSON component %q", ErrTypeNotRegistered, name)
+			}
+			value, err := info.decodeJSON(raw)
+			if err != nil {
+				return nil, fmt.Errorf("goecs: importing %s of entity %d: %w", name, entity.ID, err)
+			}
+			comps = append(comps, decoded{entity: entity.ID, info: info, value: value})
+		}
+	}
+
+	ids := make(map[Goent]Goent, len(world.Entities))
+	for _, entity := range world.Entities {
+		ids[entity.ID] = r.CreateEntity()
+	}
+	ranks := r.restoreRanks()
+	sort.SliceStable(comps, func(i, j int) bool { return ranks[comps[i].info] < ranks[comps[j].info] })
+	for _, c := range comps {
+		value := reflect.New(c.value.Type()).Elem()
+		value.Set(c.value)
+		remapValue(value, ids)
+		c.info.emplace(r, ids[c.entity], value)
+	}
+	return ids, nil
+}
@@ -0,0 +1,88 @@
+package goecs
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type jsonPos struct {
+	X, Y int
+}
+
+type jsonFollow struct {
+	Target Goent
+}
+
+type jsonScratch struct {
+	N int
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	src := NewRegistry()
+	RegisterNamedComponent[jsonPos](src, "test.Pos")
+	RegisterJSONComponent[jsonFollow](src, "test.Follow")
+	leader, follower, scratch := src.CreateEntity(), src.CreateEntity(), src.CreateEntity()
+	EmplaceComponent(src, leader, jsonPos{X: 1, Y: 2})
+	EmplaceComponent(src, follower, jsonFollow{Target: leader})
+	EmplaceComponent(src, follower, jsonScratch{N: 9})
+	EmplaceComponent(src, scratch, jsonScratch{N: 9})
+
+	data, err := json.Marshal(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc jsonWorld
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Entities) != 2 || len(doc.Entities[1].Components) != 1 {
+		t.Errorf("export holds unnamed components: %s", data)
+	}
+
+	dst := NewRegistry()
+	RegisterNamedComponent[jsonPos](dst, "test.Pos")
+	RegisterNamedComponent[jsonFollow](dst, "test.Follow")
+	dst.CreateEntities(3)
+	ids, err := dst.ImportJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("imported %d entities, want 2", len(ids))
+	}
+	if p, ok := GetComponent[jsonPos](dst, ids[leader]); !ok || *p != (jsonPos{X: 1, Y: 2}) {
+		t.Errorf("leader has %v, %v", p, ok)
+	}
+	if f, ok := GetComponent[jsonFollow](dst, ids[follower]); !ok || f.Target != ids[leader] {
+		t.Errorf("follower targets %v, want the imported leader %d", f, ids[leader])
+	}
+	if HasComponent[jsonScratch](dst, ids[follower]) {
+		t.Error("unnamed component was imported")
+	}
+}
+
+func TestImportJSONErrors(t *testing.T) {
+	r := NewRegistry()
+	RegisterNamedComponent[jsonPos](r, "test.Pos")
+	tests := []struct {
+		name string
+		doc  string
+		want error
+	}{
+		{"unknown name", `{"entities": [{"id": 1, "components": {"test.Pos": {"X": 1}}}, {"id": 2, "components": {"test.Missing": {}}}]}`, ErrTypeNotRegistered},
+		{"bad value", `{"entities": [{"id": 1, "components": {"test.Pos": {"X": "far"}}}]}`, nil},
+		{"malformed", `{"entities": [`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := r.ImportJSON([]byte(tt.doc))
+			if err == nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("ImportJSON = %v, want %v", err, tt.want)
+			}
+			if n := r.EntityCount(); n != 0 {
+				t.Errorf("failed import left %d entities", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package level
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type ldtkProject struct {
+	DefaultGridSize int         `json:"defaultGridSize"`
+	Levels          []ldtkLevel `json:"levels"`
+}
+
+type ldtkLevel struct {
+	Identifier string `json:"identifier"`
+	PxWid      int    `json:"pxWid"`
+	PxHei      int    `json:"pxHei"`
+	// Layers is null when the level is saved in a separate file
+	Layers []ldtkLayer `json:"layerInstances"`
+}
+
+type ldtkLayer struct {
+	Identifier     string       `json:"__identifier"`
+	Type           string       `json:"__type"`
+	GridSize       int          `json:"__gridSize"`
+	CWid           int          `json:"__cWid"`
+	OffsetX        float64      `json:"__pxTotalOffsetX"`
+	OffsetY        float64      `json:"__pxTotalOffsetY"`
+	IntGrid        []int        `json:"intGridCsv"`
+	GridTiles      []ldtkTile   `json:"gridTiles"`
+	AutoLayerTiles []ldtkTile   `json:"autoLayerTiles"`
+	Entities       []ldtkEntity `json:"entityInstances"`
+}
+
+type ldtkTile struct {
+	Px [2]int `json:"px"`
+	T  int    `json:"t"`
+	// F holds the flips, bit 0 for X and bit 1 for Y
+	F int `json:"f"`
+}
+
+type ldtkEntity struct {
+	Identifier string      `json:"__identifier"`
+	IID        string      `json:"iid"`
+	Px         [2]float64  `json:"px"`
+	Width      float64     `json:"width"`
+	Height     float64     `json:"height"`
+	Fields     []ldtkField `json:"fieldInstances"`
+}
+
+type ldtkField struct {
+	Identifier string      `json:"__identifier"`
+	Value      interface{} `json:"__value"`
+}
+
+// ParseLDtk parses an LDtk project (.ldtk) into one Level per level it holds,
+// skipping levels saved in separate files. Entity instances become objects
+// typed by their identifier, with their fields as properties. Tile and auto
+// layers yield their tiles, IntGrid layers a tile per non-zero cell with the
+// cell value as ID.
+func ParseLDtk(rd io.Reader) ([]*Level, error) {
+	var p ldtkProject
+	if err := json.NewDecoder(rd).Decode(&p); err != nil {
+		return nil, err
+	}
+	var levels []*Level
+	for _, l := range p.Levels {
+		if l.Layers == nil {
+			continue
+		}
+		lvl := &Level{Name: l.Identifier, TileWidth: p.DefaultGridSize, TileHeight: p.DefaultGridSize}
+		if p.DefaultGridSize > 0 {
+			lvl.Width, lvl.Height = l.PxWid/p.DefaultGridSize, l.PxHei/p.DefaultGridSize
+		}
+		// LDtk lists layers top first
+		for i := len(l.Layers) - 1; i >= 0; i-- {
+			lvl.Layers = append(lvl.Layers, ldtkToLayer(l.Layers[i]))
+		}
+		levels = append(levels, lvl)
+	}
+	return levels, nil
+}
+
+func ldtkToLayer(l ldtkLayer) Layer {
+	layer := Layer{Name: l.Identifier}
+	switch l.Type {
+	case "Entities":
+		for _, e := range l.Entities {
+			obj := Object{
+				Layer:      l.Identifier,
+				ID:         e.IID,
+				Type:       e.Identifier,
+				X:          e.Px[0] + l.OffsetX,
+				Y:          e.Px[1] + l.OffsetY,
+				Width:      e.Width,
+				Height:     e.Height,
+				Properties: make(map[string]interface{}, len(e.Fields)),
+			}
+			for _, f := range e.Fields {
+				obj.Properties[f.Identifier] = f.Value
+			}
+			layer.Objects = append(layer.Objects, obj)
+		}
+	case "IntGrid":
+		if l.CWid <= 0 {
+			break
+		}
+		for i, v := range l.IntGrid {
+			if v != 0 {
+				layer.Tiles = append(layer.Tiles, Tile{Layer: l.Identifier, X: i % l.CWid, Y: i / l.CWid, ID: v})
+			}
+		}
+	case "Tiles", "AutoLayer":
+		if l.GridSize <= 0 {
+			break
+		}
+		for _, tiles := range [][]ldtkTile{l.GridTiles, l.AutoLayerTiles} {
+			for _, t := range tiles {
+				layer.Tiles = append(layer.Tiles, Tile{
+					Layer: l.Identifier,
+					X:     t.Px[0] / l.GridSize,
+					Y:     t.Px[1] / l.GridSize,
+					ID:    t.T,
+					FlipX: t.F&1 != 0,
+					FlipY: t.F&2 != 0,
+				})
+			}
+		}
+	}
+	return layer
+}
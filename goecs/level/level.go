@@ -0,0 +1,181 @@
+// Package level imports levels made in 2D level editors into a registry.
+// Tiled maps (TMX and JSON) and LDtk projects are parsed into one Level
+// model, and an Importer turns its objects and tiles into entities through
+// factories registered per object type and tile layer:
+//
+//	im := level.NewImporter()
+//	im.Register("Spawn", func(r *goecs.Registry, e goecs.Goent, o level.Object) error {
+//		goecs.EmplaceComponent(r, e, Transform{X: o.X, Y: o.Y})
+//		goecs.EmplaceComponent(r, e, Spawner{Wave: o.Int("wave", 1)})
+//		return nil
+//	})
+//	lvl, err := level.ParseTMX(file)
+//	...
+//	entities, err := im.Import(r, lvl)
+package level
+
+import (
+	"fmt"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+// Level is an editor level in a format independent form.
+type Level struct {
+	Name string
+	// Width and Height are the size in tiles
+	Width, Height         int
+	TileWidth, TileHeight int
+	// Layers are in drawing order, bottom first
+	Layers []Layer
+}
+
+// Layer is a tile or object layer. Layers inside Tiled groups are flattened,
+// their names joined with slashes.
+type Layer struct {
+	Name    string
+	Objects []Object
+	Tiles   []Tile
+}
+
+// Object is a placed object (Tiled) or entity instance (LDtk). Positions and
+// sizes are in pixels.
+type Object struct {
+	Layer string
+	// ID is the editor's ID, unique within the level (Tiled) or project (LDtk)
+	ID   string
+	Type string
+	Name string
+	X, Y float64
+	// Width and Height are zero for point objects
+	Width, Height float64
+	// Rotation is in degrees, clockwise
+	Rotation float64
+	// Properties holds custom properties: numbers as float64, booleans as
+	// bool and everything else as the format decoded it, mostly strings
+	Properties map[string]interface{}
+}
+
+// String returns a string property, or def if it is missing or no string.
+func (o Object) String(key, def string) string {
+	if s, ok := o.Properties[key].(string); ok {
+		return s
+	}
+	return def
+}
+
+// Float returns a number property, or def if it is missing or no number.
+func (o Object) Float(key string, def float64) float64 {
+	if f, ok := o.Properties[key].(float64); ok {
+		return f
+	}
+	return def
+}
+
+// Int returns a number property truncated to an int, or def.
+func (o Object) Int(key string, def int) int {
+	if f, ok := o.Properties[key].(float64); ok {
+		return int(f)
+	}
+	return def
+}
+
+// Bool returns a boolean property, or def if it is missing or no boolean.
+func (o Object) Bool(key string, def bool) bool {
+	if b, ok := o.Properties[key].(bool); ok {
+		return b
+	}
+	return def
+}
+
+// Tile is one non-empty cell of a tile layer.
+type Tile struct {
+	Layer string
+	// X and Y are the cell, counted from the top left
+	X, Y int
+	// ID is the tile's global ID (Tiled) or tileset tile ID (LDtk), with
+	// the flip flags cleared
+	ID                     int
+	FlipX, FlipY, FlipDiag bool
+}
+
+// Factory adds the components of an object's entity.
+type Factory func(r *goecs.Registry, e goecs.Goent, o Object) error
+
+// TileFactory adds the components of a tile's entity.
+type TileFactory func(r *goecs.Registry, e goecs.Goent, t Tile) error
+
+// Importer maps the objects and tiles of levels to entities.
+type Importer struct {
+	objects map[string]Factory
+	tiles   map[string]TileFactory
+	// Default, when set, imports objects of types without a factory, which
+	// are skipped otherwise
+	Default Factory
+}
+
+// NewImporter creates an importer without any factories.
+func NewImporter() *Importer {
+	return &Importer{
+		objects: make(map[string]Factory),
+		tiles:   make(map[string]TileFactory),
+	}
+}
+
+// Register sets the factory of an object type.
+func (im *Importer) Register(objectType string, f Factory) {
+	im.objects[objectType] = f
+}
+
+// RegisterTiles sets the factory of the tiles of a layer. Tile layers
+// without one are skipped, so huge layers that are only drawn don't become
+// entities.
+func (im *Importer) RegisterTiles(layer string, f TileFactory) {
+	im.tiles[layer] = f
+}
+
+// Import creates an entity per object and tile that has a factory, layer by
+// layer, and returns them in creation order. Each entity is annotated with
+// its layer under "level.layer", and objects with their name under
+// "level.name". If a factory fails, the entities created so far are
+// destroyed again and the error is returned.
+func (im *Importer) Import(r *goecs.Registry, lvl *Level) ([]goecs.Goent, error) {
+	var created []goecs.Goent
+	fail := func(err error) ([]goecs.Goent, error) {
+		r.DestroyEntities(created)
+		return nil, err
+	}
+	for _, layer := range lvl.Layers {
+		for _, o := range layer.Objects {
+			f, ok := im.objects[o.Type]
+			if !ok {
+				f = im.Default
+			}
+			if f == nil {
+				continue
+			}
+			e := r.CreateEntity()
+			created = append(created, e)
+			r.Annotate(e, "level.layer", layer.Name)
+			if o.Name != "" {
+				r.Annotate(e, "level.name", o.Name)
+			}
+			if err := f(r, e, o); err != nil {
+				return fail(fmt.Errorf("level: object %s (%s) on layer %q: %w", o.ID, o.Type, layer.Name, err))
+			}
+		}
+		tf, ok := im.tiles[layer.Name]
+		if !ok {
+			continue
+		}
+		for _, t := range layer.Tiles {
+			e := r.CreateEntity()
+			created = append(created, e)
+			r.Annotate(e, "level.layer", layer.Name)
+			if err := tf(r, e, t); err != nil {
+				return fail(fmt.Errorf("level: tile %d,%d on layer %q: %w", t.X, t.Y, layer.Name, err))
+			}
+		}
+	}
+	return created, nil
+}
@@ -0,0 +1,222 @@
+package level
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+const testTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<map width="3" height="2" tilewidth="16" tileheight="16">
+ <group name="world">
+  <layer name="ground" width="3" height="2">
+   <data encoding="csv">
+0,2147483650,0,
+4,0,0
+   </data>
+  </layer>
+ </group>
+ <objectgroup name="things">
+  <object id="7" name="boss" type="Spawn" x="32" y="16" rotation="90">
+   <properties>
+    <property name="wave" type="int" value="3"/>
+    <property name="elite" type="bool" value="true"/>
+    <property name="taunt">Come closer</property>
+   </properties>
+  </object>
+ </objectgroup>
+</map>`
+
+const testTiledJSON = `{
+	"width": 4, "height": 4, "tilewidth": 8, "tileheight": 8,
+	"layers": [
+		{"name": "sky", "type": "tilelayer", "width": 2, "data": [1, 0, 0, 3]},
+		{"name": "far", "type": "tilelayer", "chunks": [{"x": 16, "y": -16, "width": 2, "data": [0, 5]}]},
+		{"name": "items", "type": "objectgroup", "objects": [
+			{"id": 2, "class": "Chest", "x": 4, "y": 8, "width": 8, "height": 8,
+			 "properties": [{"name": "gold", "type": "int", "value": 50}]}
+		]}
+	]
+}`
+
+const testLDtk = `{
+	"defaultGridSize": 16,
+	"levels": [
+		{"identifier": "Level_0", "pxWid": 32, "pxHei": 32, "layerInstances": [
+			{"__identifier": "Entities", "__type": "Entities", "__pxTotalOffsetX": 2, "__pxTotalOffsetY": 0,
+			 "entityInstances": [{"__identifier": "Door", "iid": "a1", "px": [16, 0], "width": 16, "height": 32,
+			  "fieldInstances": [{"__identifier": "locked", "__value": true}]}]},
+			{"__identifier": "Walls", "__type": "IntGrid", "__cWid": 2, "intGridCsv": [0, 1, 2, 0]},
+			{"__identifier": "Ground", "__type": "Tiles", "__gridSize": 16, "gridTiles": [{"px": [16, 16], "t": 5, "f": 3}]}
+		]},
+		{"identifier": "Level_1", "pxWid": 32, "pxHei": 32, "layerInstances": null}
+	]
+}`
+
+func layerNames(lvl *Level) []string {
+	var names []string
+	for _, l := range lvl.Layers {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+func TestParseTMX(t *testing.T) {
+	lvl, err := ParseTMX(strings.NewReader(testTMX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lvl.Width != 3 || lvl.Height != 2 || lvl.TileWidth != 16 {
+		t.Errorf("map is %dx%d of %d pixel tiles", lvl.Width, lvl.Height, lvl.TileWidth)
+	}
+	if names := layerNames(lvl); !slices.Equal(names, []string{"world/ground", "things"}) {
+		t.Fatalf("layers = %v", names)
+	}
+	want := []Tile{
+		{Layer: "world/ground", X: 1, Y: 0, ID: 2, FlipX: true},
+		{Layer: "world/ground", X: 0, Y: 1, ID: 4},
+	}
+	if tiles := lvl.Layers[0].Tiles; !slices.Equal(tiles, want) {
+		t.Errorf("tiles = %v, want %v", tiles, want)
+	}
+	o := lvl.Layers[1].Objects[0]
+	if o.ID != "7" || o.Type != "Spawn" || o.Name != "boss" || o.X != 32 || o.Rotation != 90 {
+		t.Errorf("object = %+v", o)
+	}
+	if o.Int("wave", 0) != 3 || !o.Bool("elite", false) || o.String("taunt", "") != "Come closer" {
+		t.Errorf("properties = %v", o.Properties)
+	}
+	if o.Float("missing", 1.5) != 1.5 || o.Int("taunt", -1) != -1 {
+		t.Error("missing or mistyped property didn't give the default")
+	}
+
+	bad := strings.Replace(testTMX, `value="3"`, `value="three"`, 1)
+	if _, err := ParseTMX(strings.NewReader(bad)); err == nil {
+		t.Error("ParseTMX accepted a malformed int property")
+	}
+}
+
+func TestParseTiledJSON(t *testing.T) {
+	lvl, err := ParseTiledJSON(strings.NewReader(testTiledJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := layerNames(lvl); !slices.Equal(names, []string{"sky", "far", "items"}) {
+		t.Fatalf("layers = %v", names)
+	}
+	tests := []struct {
+		name string
+		got  []Tile
+		want []Tile
+	}{
+		{"sky", lvl.Layers[0].Tiles, []Tile{{Layer: "sky", ID: 1}, {Layer: "sky", X: 1, Y: 1, ID: 3}}},
+		// chunks of infinite maps are offset by their position
+		{"far", lvl.Layers[1].Tiles, []Tile{{Layer: "far", X: 17, Y: -16, ID: 5}}},
+	}
+	for _, tt := range tests {
+		if !slices.Equal(tt.got, tt.want) {
+			t.Errorf("%s tiles = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	// objects without a type go by their class
+	if o := lvl.Layers[2].Objects[0]; o.Type != "Chest" || o.Int("gold", 0) != 50 || o.Height != 8 {
+		t.Errorf("object = %+v", o)
+	}
+
+	bad := strings.Replace(testTiledJSON, `"width": 2, "data": [1, 0, 0, 3]`, `"width": 2, "encoding": "base64", "compression": "zstd", "data": "AQAAAA=="`, 1)
+	if _, err := ParseTiledJSON(strings.NewReader(bad)); err == nil {
+		t.Error("ParseTiledJSON accepted an unsupported compression")
+	}
+}
+
+func TestParseLDtk(t *testing.T) {
+	levels, err := ParseLDtk(strings.NewReader(testLDtk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Level_1 is saved in a separate file
+	if len(levels) != 1 {
+		t.Fatalf("parsed %d levels, want 1", len(levels))
+	}
+	lvl := levels[0]
+	if lvl.Name != "Level_0" || lvl.Width != 2 || lvl.Height != 2 {
+		t.Errorf("level %q is %dx%d", lvl.Name, lvl.Width, lvl.Height)
+	}
+	// layers come bottom first
+	if names := layerNames(lvl); !slices.Equal(names, []string{"Ground", "Walls", "Entities"}) {
+		t.Fatalf("layers = %v", names)
+	}
+	if want := []Tile{{Layer: "Ground", X: 1, Y: 1, ID: 5, FlipX: true, FlipY: true}}; !slices.Equal(lvl.Layers[0].Tiles, want) {
+		t.Errorf("Ground tiles = %v, want %v", lvl.Layers[0].Tiles, want)
+	}
+	if want := []Tile{{Layer: "Walls", X: 1, ID: 1}, {Layer: "Walls", Y: 1, ID: 2}}; !slices.Equal(lvl.Layers[1].Tiles, want) {
+		t.Errorf("Walls tiles = %v, want %v", lvl.Layers[1].Tiles, want)
+	}
+	o := lvl.Layers[2].Objects[0]
+	if o.ID != "a1" || o.Type != "Door" || o.X != 18 || o.Height != 32 || !o.Bool("locked", false) {
+		t.Errorf("object = %+v", o)
+	}
+}
+
+type levelPos struct {
+	X, Y float64
+}
+
+type levelTile struct {
+	ID int
+}
+
+func TestImport(t *testing.T) {
+	lvl, err := ParseTMX(strings.NewReader(testTMX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lvl.Layers[1].Objects = append(lvl.Layers[1].Objects, Object{Layer: "things", ID: "8", Type: "Decor"})
+
+	im := NewImporter()
+	im.Register("Spawn", func(r *goecs.Registry, e goecs.Goent, o Object) error {
+		goecs.EmplaceComponent(r, e, levelPos{X: o.X, Y: o.Y})
+		return nil
+	})
+	im.RegisterTiles("world/ground", func(r *goecs.Registry, e goecs.Goent, tile Tile) error {
+		goecs.EmplaceComponent(r, e, levelTile{ID: tile.ID})
+		return nil
+	})
+
+	r := goecs.NewRegistry()
+	entities, err := im.Import(r, lvl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the Decor object has no factory and is skipped
+	if len(entities) != 3 {
+		t.Fatalf("imported %d entities, want 3", len(entities))
+	}
+	if tile, _ := goecs.GetComponent[levelTile](r, entities[0]); tile == nil || tile.ID != 2 {
+		t.Errorf("first tile entity has %v", tile)
+	}
+	boss := entities[2]
+	if p, _ := goecs.GetComponent[levelPos](r, boss); p == nil || *p != (levelPos{X: 32, Y: 16}) {
+		t.Errorf("boss at %v", p)
+	}
+	if layer, _ := r.Annotation(boss, "level.layer"); layer != "things" {
+		t.Errorf("boss annotated with layer %q", layer)
+	}
+	if name, _ := r.Annotation(boss, "level.name"); name != "boss" {
+		t.Errorf("boss annotated with name %q", name)
+	}
+
+	// a failing factory undoes the import
+	errNoDecor := errors.New("no decor")
+	im.Default = func(*goecs.Registry, goecs.Goent, Object) error { return errNoDecor }
+	r = goecs.NewRegistry()
+	if _, err := im.Import(r, lvl); !errors.Is(err, errNoDecor) {
+		t.Errorf("Import = %v, want %v", err, errNoDecor)
+	}
+	if n := r.EntityCount(); n != 0 {
+		t.Errorf("failed import left %d entities", n)
+	}
+}
@@ -0,0 +1,404 @@
+package level
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Tiled stores the flip flags of a tile in the top bits of its global ID.
+const (
+	flipX    = 0x80000000
+	flipY    = 0x40000000
+	flipDiag = 0x20000000
+	// rotated hexagonal tiles, not exposed
+	flipHex = 0x10000000
+	gidMask = ^uint32(flipX | flipY | flipDiag | flipHex)
+)
+
+// appendTiles adds the non-empty cells of a row-major GID array, offset by
+// the position of the chunk it came from.
+func appendTiles(tiles []Tile, layer string, gids []uint32, width, offsetX, offsetY int) []Tile {
+	if width <= 0 {
+		return tiles
+	}
+	for i, gid := range gids {
+		if gid&gidMask == 0 {
+			continue
+		}
+		tiles = append(tiles, Tile{
+			Layer:    layer,
+			X:        offsetX + i%width,
+			Y:        offsetY + i/width,
+			ID:       int(gid & gidMask),
+			FlipX:    gid&flipX != 0,
+			FlipY:    gid&flipY != 0,
+			FlipDiag: gid&flipDiag != 0,
+		})
+	}
+	return tiles
+}
+
+// decodeGIDs decodes tile data stored as CSV or as base64 of little endian
+// IDs, optionally zlib or gzip compressed.
+func decodeGIDs(encoding, compression, text string) ([]uint32, error) {
+	switch encoding {
+	case "csv":
+		var gids []uint32
+		for _, field := range strings.Split(text, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("level: bad tile ID %q", field)
+			}
+			gids = append(gids, uint32(gid))
+		}
+		return gids, nil
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+		if err != nil {
+			return nil, err
+		}
+		var rd io.Reader = bytes.NewReader(raw)
+		switch compression {
+		case "":
+		case "zlib":
+			if rd, err = zlib.NewReader(rd); err != nil {
+				return nil, err
+			}
+		case "gzip":
+			if rd, err = gzip.NewReader(rd); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("level: unsupported tile data compression %q", compression)
+		}
+		if raw, err = io.ReadAll(rd); err != nil {
+			return nil, err
+		}
+		if len(raw)%4 != 0 {
+			return nil, fmt.Errorf("level: tile data of %d bytes isn't a list of IDs", len(raw))
+		}
+		gids := make([]uint32, len(raw)/4)
+		for i := range gids {
+			gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+		}
+		return gids, nil
+	default:
+		return nil, fmt.Errorf("level: unsupported tile data encoding %q", encoding)
+	}
+}
+
+func joinLayer(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "/" + name
+}
+
+// --- Tiled JSON ---
+
+type tiledMap struct {
+	Width      int          `json:"width"`
+	Height     int          `json:"height"`
+	TileWidth  int          `json:"tilewidth"`
+	TileHeight int          `json:"tileheight"`
+	Layers     []tiledLayer `json:"layers"`
+}
+
+type tiledLayer struct {
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	Width       int             `json:"width"`
+	Data        json.RawMessage `json:"data"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Chunks      []tiledChunk    `json:"chunks"`
+	Objects     []tiledObject   `json:"objects"`
+	Layers      []tiledLayer    `json:"layers"`
+}
+
+type tiledChunk struct {
+	X     int             `json:"x"`
+	Y     int             `json:"y"`
+	Width int             `json:"width"`
+	Data  json.RawMessage `json:"data"`
+}
+
+type tiledObject struct {
+	ID         int             `json:"id"`
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Class      string          `json:"class"`
+	X          float64         `json:"x"`
+	Y          float64         `json:"y"`
+	Width      float64         `json:"width"`
+	Height     float64         `json:"height"`
+	Rotation   float64         `json:"rotation"`
+	Properties []tiledProperty `json:"properties"`
+}
+
+type tiledProperty struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+// ParseTiledJSON parses a map saved by Tiled as JSON (.tmj). Infinite maps
+// are supported, tilesets are not read; tiles keep their global IDs.
+func ParseTiledJSON(rd io.Reader) (*Level, error) {
+	var m tiledMap
+	if err := json.NewDecoder(rd).Decode(&m); err != nil {
+		return nil, err
+	}
+	lvl := &Level{Width: m.Width, Height: m.Height, TileWidth: m.TileWidth, TileHeight: m.TileHeight}
+	if err := addTiledLayers(lvl, "", m.Layers); err != nil {
+		return nil, err
+	}
+	return lvl, nil
+}
+
+func addTiledLayers(lvl *Level, parent string, layers []tiledLayer) error {
+	for _, l := range layers {
+		name := joinLayer(parent, l.Name)
+		switch l.Type {
+		case "group":
+			if err := addTiledLayers(lvl, name, l.Layers); err != nil {
+				return err
+			}
+		case "tilelayer":
+			layer := Layer{Name: name}
+			if len(l.Chunks) == 0 {
+				gids, err := tiledJSONData(l.Data, l.Encoding, l.Compression)
+				if err != nil {
+					return fmt.Errorf("level: layer %q: %w", name, err)
+				}
+				layer.Tiles = appendTiles(layer.Tiles, name, gids, l.Width, 0, 0)
+			}
+			for _, c := range l.Chunks {
+				gids, err := tiledJSONData(c.Data, l.Encoding, l.Compression)
+				if err != nil {
+					return fmt.Errorf("level: layer %q: %w", name, err)
+				}
+				layer.Tiles = appendTiles(layer.Tiles, name, gids, c.Width, c.X, c.Y)
+			}
+			lvl.Layers = append(lvl.Layers, layer)
+		case "objectgroup":
+			layer := Layer{Name: name}
+			for _, o := range l.Objects {
+				obj := Object{
+					Layer:      name,
+					ID:         strconv.Itoa(o.ID),
+					Type:       o.Type,
+					Name:       o.Name,
+					X:          o.X,
+					Y:          o.Y,
+					Width:      o.Width,
+					Height:     o.Height,
+					Rotation:   o.Rotation,
+					Properties: make(map[string]interface{}, len(o.Properties)),
+				}
+				if obj.Type == "" {
+					obj.Type = o.Class
+				}
+				for _, p := range o.Properties {
+					obj.Properties[p.Name] = p.Value
+				}
+				layer.Objects = append(layer.Objects, obj)
+			}
+			lvl.Layers = append(lvl.Layers, layer)
+		}
+	}
+	return nil
+}
+
+// tiledJSONData decodes tile data, a JSON array of IDs or a base64 string.
+func tiledJSONData(data json.RawMessage, encoding, compression string) ([]uint32, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if encoding == "base64" {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return nil, err
+		}
+		return decodeGIDs(encoding, compression, text)
+	}
+	var gids []uint32
+	err := json.Unmarshal(data, &gids)
+	return gids, err
+}
+
+// --- Tiled TMX ---
+
+type tmxMap struct {
+	Width      int       `xml:"width,attr"`
+	Height     int       `xml:"height,attr"`
+	TileWidth  int       `xml:"tilewidth,attr"`
+	TileHeight int       `xml:"tileheight,attr"`
+	Items      []tmxItem `xml:",any"`
+}
+
+// tmxItem is any child element of a map or group. Layers, object groups and
+// groups are told apart by the element name, keeping their order.
+type tmxItem struct {
+	XMLName xml.Name
+	Name    string      `xml:"name,attr"`
+	Width   int         `xml:"width,attr"`
+	Data    *tmxData    `xml:"data"`
+	Objects []tmxObject `xml:"object"`
+	Items   []tmxItem   `xml:",any"`
+}
+
+type tmxData struct {
+	Encoding    string     `xml:"encoding,attr"`
+	Compression string     `xml:"compression,attr"`
+	Text        string     `xml:",chardata"`
+	Tiles       []tmxTile  `xml:"tile"`
+	Chunks      []tmxChunk `xml:"chunk"`
+}
+
+type tmxChunk struct {
+	X     int       `xml:"x,attr"`
+	Y     int       `xml:"y,attr"`
+	Width int       `xml:"width,attr"`
+	Text  string    `xml:",chardata"`
+	Tiles []tmxTile `xml:"tile"`
+}
+
+type tmxTile struct {
+	GID uint32 `xml:"gid,attr"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	Rotation   float64       `xml:"rotation,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Type  string `xml:"type,attr"`
+	Value string `xml:"value,attr"`
+	// Text holds multi-line string values
+	Text string `xml:",chardata"`
+}
+
+// value converts the property to the types ParseTiledJSON yields.
+func (p tmxProperty) value() (interface{}, error) {
+	text := p.Value
+	if text == "" {
+		text = p.Text
+	}
+	switch p.Type {
+	case "int", "float", "object":
+		return strconv.ParseFloat(text, 64)
+	case "bool":
+		return strconv.ParseBool(text)
+	default:
+		return text, nil
+	}
+}
+
+// ParseTMX parses a map saved by Tiled as XML (.tmx), see ParseTiledJSON.
+func ParseTMX(rd io.Reader) (*Level, error) {
+	var m tmxMap
+	if err := xml.NewDecoder(rd).Decode(&m); err != nil {
+		return nil, err
+	}
+	lvl := &Level{Width: m.Width, Height: m.Height, TileWidth: m.TileWidth, TileHeight: m.TileHeight}
+	if err := addTMXItems(lvl, "", m.Items); err != nil {
+		return nil, err
+	}
+	return lvl, nil
+}
+
+func addTMXItems(lvl *Level, parent string, items []tmxItem) error {
+	for _, item := range items {
+		name := joinLayer(parent, item.Name)
+		switch item.XMLName.Local {
+		case "group":
+			if err := addTMXItems(lvl, name, item.Items); err != nil {
+				return err
+			}
+		case "layer":
+			layer := Layer{Name: name}
+			if d := item.Data; d != nil {
+				if len(d.Chunks) == 0 {
+					gids, err := tmxGIDs(d.Encoding, d.Compression, d.Text, d.Tiles)
+					if err != nil {
+						return fmt.Errorf("level: layer %q: %w", name, err)
+					}
+					layer.Tiles = appendTiles(layer.Tiles, name, gids, item.Width, 0, 0)
+				}
+				for _, c := range d.Chunks {
+					gids, err := tmxGIDs(d.Encoding, d.Compression, c.Text, c.Tiles)
+					if err != nil {
+						return fmt.Errorf("level: layer %q: %w", name, err)
+					}
+					layer.Tiles = appendTiles(layer.Tiles, name, gids, c.Width, c.X, c.Y)
+				}
+			}
+			lvl.Layers = append(lvl.Layers, layer)
+		case "objectgroup":
+			layer := Layer{Name: name}
+			for _, o := range item.Objects {
+				obj := Object{
+					Layer:      name,
+					ID:         strconv.Itoa(o.ID),
+					Type:       o.Type,
+					Name:       o.Name,
+					X:          o.X,
+					Y:          o.Y,
+					Width:      o.Width,
+					Height:     o.Height,
+					Rotation:   o.Rotation,
+					Properties: make(map[string]interface{}, len(o.Properties)),
+				}
+				if obj.Type == "" {
+					obj.Type = o.Class
+				}
+				for _, p := range o.Properties {
+					v, err := p.value()
+					if err != nil {
+						return fmt.Errorf("level: object %d property %q: %w", o.ID, p.Name, err)
+					}
+					obj.Properties[p.Name] = v
+				}
+				layer.Objects = append(layer.Objects, obj)
+			}
+			lvl.Layers = append(lvl.Layers, layer)
+		}
+	}
+	return nil
+}
+
+// tmxGIDs decodes tile data, which without an encoding is a list of <tile>
+// elements.
+func tmxGIDs(encoding, compression, text string, tiles []tmxTile) ([]uint32, error) {
+	if encoding == "" {
+		gids := make([]uint32, len(tiles))
+		for i, t := range tiles {
+			gids[i] = t.GID
+		}
+		return gids, nil
+	}
+	return decodeGIDs(encoding, compression, text)
+}
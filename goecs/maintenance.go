@@ -0,0 +1,235 @@
+package goecs
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+)
+
+// --- Budgeted maintenance ---
+// Long sessions leave storages fragmented: arrays keep the capacity of the
+// busiest moment, and components emplaced one by one over time sit scattered
+// across the heap. A Maintenance worker tidies up in small steps at frame
+// boundaries, never spending more than its per-frame budget, so the work
+// never shows up as a hitch. It runs on the goroutine that calls Run, after
+// the scheduler's last stage (see Scheduler.SetMaintenance) or at the end of
+// World.Update, when no system touches the registry.
+//
+// A pass visits every storage in type name order, then the spatially sorted
+// groups, then the tasks added with AddTask, and the next pass starts over.
+// Only sparse set storages need tidying; archetype registries keep their
+// components packed and only run the tasks.
+//
+// Compacting storages moves their components, which would invalidate the
+// pointers EmplaceComponent and GetComponent returned behind the caller's
+// back, so Run never does it. Compact is called explicitly instead, at points
+// where the game holds no component pointers.
+
+// compactChunk is how many components one compaction step moves.
+const compactChunk = 256
+
+// MaintenanceOptions selects the work a Maintenance worker does.
+type MaintenanceOptions struct {
+	// Budget is the time spent per Run. At least one step runs per Run, and
+	// steps are small, so a Run overshoots by a step at most.
+	Budget time.Duration
+	// Shrink releases array capacity well beyond what a storage holds.
+	Shrink bool
+	// SpatialSort re-sorts the groups that have SortSpatial enabled, in
+	// addition to their own period.
+	SpatialSort bool
+}
+
+// storageMaintainer is implemented by every SparseSet, for the maintenance
+// worker.
+type storageMaintainer interface {
+	shrink()
+	compact(from, n int) int
+}
+
+// Maintenance performs storage upkeep within a per-frame time budget.
+type Maintenance struct {
+	registry *Registry
+	opts     MaintenanceOptions
+	tasks    []func(deadline time.Time) bool
+	// steps is the rest of the current pass; each reports true when done
+	steps  []func(deadline time.Time) bool
+	passes int
+	// compacting is the rest of the current Compact pass, in steps as well
+	compacting []func(deadline time.Time) bool
+}
+
+// NewMaintenance creates a maintenance worker for the registry.
+func NewMaintenance(r *Registry, opts MaintenanceOptions) *Maintenance {
+	return &Maintenance{registry: r, opts: opts}
+}
+
+// AddTask adds an incremental job, such as rebuilding a spatial index, to
+// every pass. step does work until the deadline and reports true once it is
+// done for this pass; until then it is called again on later Runs.
+func (m *Maintenance) AddTask(step func(deadline time.Time) bool) {
+	m.tasks = append(m.tasks, step)
+}
+
+// Passes returns the number of passes completed so far.
+func (m *Maintenance) Passes() int {
+	return m.passes
+}
+
+// Run does maintenance steps until the budget is used up or the pass is
+// complete, so an idle registry costs little. It does nothing if the budget
+// isn't positive.
+func (m *Maintenance) Run() {
+	if m.opts.Budget <= 0 {
+		return
+	}
+	deadline := time.Now().Add(m.opts.Budget)
+	for {
+		if len(m.steps) == 0 {
+			m.plan()
+			if len(m.steps) == 0 {
+				return
+			}
+		}
+		if m.steps[0](deadline) {
+			m.steps = m.steps[1:]
+			if len(m.steps) == 0 {
+				m.passes++
+				return
+			}
+		}
+		if !time.Now().Before(deadline) {
+			return
+		}
+	}
+}
+
+// plan queues the steps of the next pass.
+func (m *Maintenance) plan() {
+	r := m.registry
+	if m.opts.Shrink {
+		for _, s := range m.maintainers() {
+			m.steps = append(m.steps, func(time.Time) bool {
+				s.shrink()
+				return true
+			})
+		}
+	}
+	if m.opts.SpatialSort {
+		for _, g := range r.groups {
+			m.steps = append(m.steps, func(time.Time) bool {
+				if g.position != nil {
+					g.sortSpatial()
+				}
+				return true
+			})
+		}
+	}
+	m.steps = append(m.steps, m.tasks...)
+}
+
+// maintainers returns the sparse set storages in type name order, none for
+// archetype registries.
+func (m *Maintenance) maintainers() []storageMaintainer {
+	r := m.registry
+	if r.archetypes != nil {
+		return nil
+	}
+	types := make([]reflect.Type, 0, len(r.storages))
+	for t := range r.storages {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].String() < types[j].String() })
+	var out []storageMaintainer
+	for _, t := range types {
+		if s, ok := r.storages[t].(storageMaintainer); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Compact copies the components of each storage into contiguous blocks in
+// dense order, within the budget per call, and reports true once every
+// storage is done; the call after that starts a new pass. Without a
+// positive budget the pass finishes in one call. The components move, so
+// every pointer EmplaceComponent, GetComponent or an iteration handed out
+// before is stale afterwards and must not be used.
+func (m *Maintenance) Compact() bool {
+	m.registry.assertWritable()
+	if len(m.compacting) == 0 {
+		for _, s := range m.maintainers() {
+			next := 0
+			m.compacting = append(m.compacting, func(time.Time) bool {
+				next = s.compact(next, compactChunk)
+				return next < 0
+			})
+		}
+	}
+	deadline := time.Now().Add(m.opts.Budget)
+	for len(m.compacting) > 0 {
+		if m.compacting[0](deadline) {
+			m.compacting = m.compacting[1:]
+		}
+		if m.opts.Budget > 0 && !time.Now().Before(deadline) {
+			break
+		}
+	}
+	return len(m.compacting) == 0
+}
+
+// SetMaintenance attaches a maintenance worker that runs after the last
+// stage of every Run, nil detaches it.
+func (s *Scheduler) SetMaintenance(m *Maintenance) {
+	s.maintenance = m
+}
+
+// shrink reallocates the arrays when their capacity is more than twice what
+// the storage needs, never going below the policy's initial capacity.
+func (ss *SparseSet[T]) shrink() {
+	keep := max(ss.policy.InitialCapacity, len(ss.dense))
+	if cap(ss.dense) > 2*keep {
+		ss.dense = reallocated(ss.dense, keep)
+		ss.ticks = reallocated(ss.ticks, keep)
+		if ss.tag == nil {
+			ss.components = reallocated(ss.components, keep)
+		}
+	}
+	if ss.index != nil {
+		return
+	}
+	high := 0
+	for _, entity := range ss.dense {
+		high = max(high, int(entity.Index())+1)
+	}
+	high = max(high, ss.policy.InitialCapacity)
+	if len(ss.sparse) > 2*high {
+		ss.sparse = slices.Clone(ss.sparse[:high])
+	}
+}
+
+// compact copies up to n components from dense slot from on into one new
+// block and returns the slot to continue at, or -1 once the end is reached.
+func (ss *SparseSet[T]) compact(from, n int) int {
+	if ss.tag != nil || from >= len(ss.components) {
+		return -1
+	}
+	end := min(from+n, len(ss.components))
+	block := make([]T, end-from)
+	for i := from; i < end; i++ {
+		block[i-from] = *ss.components[i]
+		ss.components[i] = &block[i-from]
+	}
+	if end == len(ss.components) {
+		return -1
+	}
+	return end
+}
+
+// reallocated copies s into a new array of capacity c.
+func reallocated[E any](s []E, c int) []E {
+	out := make([]E, len(s), c)
+	copy(out, s)
+	return out
+}
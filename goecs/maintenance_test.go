@@ -0,0 +1,85 @@
+package goecs
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+)
+
+type maintProbe struct {
+	V int
+}
+
+func TestMaintenanceRunKeepsPointers(t *testing.T) {
+	w := NewWorld()
+	w.Maintenance = NewMaintenance(w.Registry, MaintenanceOptions{Budget: time.Second, Shrink: true, SpatialSort: true})
+	entities := w.Registry.CreateEntities(1000)
+	ptrs := make([]*maintProbe, len(entities))
+	for i, e := range entities {
+		ptrs[i] = EmplaceComponent(w.Registry, e, maintProbe{V: i})
+	}
+	for i := 0; i < 5; i++ {
+		w.Update(1)
+	}
+	if w.Maintenance.Passes() == 0 {
+		t.Fatal("no maintenance pass completed")
+	}
+	for i, e := range entities {
+		if c, _ := GetComponent[maintProbe](w.Registry, e); c != ptrs[i] {
+			t.Fatalf("entity %d moved from %p to %p during Update", e, ptrs[i], c)
+		}
+	}
+}
+
+func TestMaintenanceShrink(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(5000)
+	for _, e := range entities {
+		EmplaceComponent(r, e, maintProbe{})
+	}
+	r.DestroyEntities(entities[10:])
+	m := NewMaintenance(r, MaintenanceOptions{Budget: time.Second, Shrink: true})
+	m.Run()
+	if s := getStorage[maintProbe](r); cap(s.dense) >= 5000 || len(s.dense) != 10 {
+		t.Errorf("storage holds %d with capacity %d after shrinking", len(s.dense), cap(s.dense))
+	}
+}
+
+func TestMaintenanceCompact(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget time.Duration
+	}{
+		{"unbudgeted", 0},
+		{"budgeted", time.Nanosecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			entities := r.CreateEntities(3 * compactChunk)
+			for i, e := range entities {
+				EmplaceComponent(r, e, maintProbe{V: i})
+			}
+			m := NewMaintenance(r, MaintenanceOptions{Budget: tt.budget})
+			calls := 1
+			for !m.Compact() {
+				calls++
+				if calls > 100 {
+					t.Fatal("compaction never finished")
+				}
+			}
+			if tt.budget == 0 && calls != 1 {
+				t.Errorf("unbudgeted compaction took %d calls", calls)
+			}
+			s := getStorage[maintProbe](r)
+			for i := range s.components {
+				if s.components[i].V != int(s.dense[i].Index()) {
+					t.Fatalf("slot %d holds %d for entity %d", i, s.components[i].V, s.dense[i])
+				}
+				if i%compactChunk != 0 && uintptr(unsafe.Pointer(s.components[i])) != uintptr(unsafe.Pointer(s.components[i-1]))+unsafe.Sizeof(maintProbe{}) {
+					t.Fatalf("slot %d isn't next to slot %d after compaction", i, i-1)
+				}
+			}
+		})
+	}
+}
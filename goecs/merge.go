@@ -0,0 +1,58 @@
+package goecs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// --- Registry merge ---
+
+// MergePolicy decides how Merge treats the entity IDs of the source.
+type MergePolicy int
+
+const (
+	// MergeRemap gives every merged entity a fresh ID, like CopyEntities.
+	MergeRemap MergePolicy = iota
+	// MergeKeepIDs keeps the source IDs and fails if any of them is taken in
+	// the destination, for sub-levels built with disjoint ID ranges.
+	MergeKeepIDs
+)
+
+// ErrIDConflict is returned by Merge with MergeKeepIDs when a source ID is
+// in use in the destination, or was recycled there past its generation.
+var ErrIDConflict = errors.New("goecs: merged entity ID is taken")
+
+// Merge copies every entity with components from src into r, along with
+// their aliases and the relationship edges between them, and returns the ID
+// each source entity got. Goent references inside the components are
+// rewritten when IDs change. ID conflicts with MergeKeepIDs, and aliases or
+// names of entities or component types already taken in r (ErrKeyConflict),
+// are checked first, so a failed merge changes nothing.
+func (r *Registry) Merge(src *Registry, policy MergePolicy) (map[Goent]Goent, error) {
+	r.assertWritable()
+	entities := src.FilteredEntities(SnapshotFilter{})
+	switch policy {
+	case MergeRemap:
+		return CopyEntities(r, src, entities)
+	case MergeKeepIDs:
+		free := make(map[uint32]struct{}, len(r.entities.free))
+		for _, index := range r.entities.free {
+			free[index] = struct{}{}
+		}
+		for _, entity := range entities {
+			index := entity.Index()
+			if !r.entities.known(entity) {
+				continue
+			}
+			if _, ok := free[index]; !ok || r.entities.generations[index] > entity.Generation() {
+				return nil, fmt.Errorf("%w: %d", ErrIDConflict, entity)
+			}
+		}
+		return transferEntities(r, src, entities, func(entity Goent) Goent {
+			r.entities.claim(entity)
+			return entity
+		})
+	default:
+		return nil, fmt.Errorf("goecs: unknown merge policy %d", policy)
+	}
+}
@@ -0,0 +1,72 @@
+package goecs
+
+import (
+	"errors"
+	"testing"
+)
+
+type mergeLink struct {
+	To Goent
+}
+
+func TestMerge(t *testing.T) {
+	newSource := func() (*Registry, []Goent) {
+		src := NewRegistry()
+		entities := src.CreateEntities(5)
+		// only the upper range carries components
+		EmplaceComponent(src, entities[3], cloneProbe{V: 3})
+		EmplaceComponent(src, entities[4], mergeLink{To: entities[3]})
+		src.SetAlias(entities[3], "gate")
+		return src, entities
+	}
+
+	t.Run("remap", func(t *testing.T) {
+		src, entities := newSource()
+		dst := NewRegistry()
+		dst.CreateEntities(5)
+		ids, err := dst.Merge(src, MergeRemap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != 2 || ids[entities[3]] == entities[3] {
+			t.Fatalf("Merge = %v, want two fresh IDs", ids)
+		}
+		if l, _ := GetComponent[mergeLink](dst, ids[entities[4]]); l == nil || l.To != ids[entities[3]] {
+			t.Errorf("merged link = %v, want %d", l, ids[entities[3]])
+		}
+		if e, _ := dst.LookupAlias("gate"); e != ids[entities[3]] {
+			t.Errorf("alias resolves to %d", e)
+		}
+	})
+
+	t.Run("keep IDs", func(t *testing.T) {
+		src, entities := newSource()
+		dst := NewRegistry()
+		dst.CreateEntities(3)
+		ids, err := dst.Merge(src, MergeKeepIDs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids[entities[3]] != entities[3] || ids[entities[4]] != entities[4] {
+			t.Errorf("Merge = %v, want the source IDs", ids)
+		}
+		if l, _ := GetComponent[mergeLink](dst, entities[4]); l == nil || l.To != entities[3] {
+			t.Errorf("merged link = %v", l)
+		}
+		if e := dst.CreateEntity(); e == entities[3] || e == entities[4] {
+			t.Errorf("merged ID %d handed out again", e)
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		src, _ := newSource()
+		dst := NewRegistry()
+		taken := dst.CreateEntities(4)[3]
+		if _, err := dst.Merge(src, MergeKeepIDs); !errors.Is(err, ErrIDConflict) {
+			t.Errorf("Merge = %v, want ErrIDConflict", err)
+		}
+		if dst.EntityCount() != 4 || HasComponent[cloneProbe](dst, taken) {
+			t.Error("failed merge changed the destination")
+		}
+	})
+}
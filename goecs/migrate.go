@@ -0,0 +1,108 @@
+package goecs
+
+import (
+	"reflect"
+)
+
+// --- Component migrations ---
+// When a component definition changes at runtime (live-edited or scripted
+// components), the instances already stored still have the old layout. A
+// migration converts them to the new schema so the data isn't orphaned:
+// typed components move from the old type to the new one, and blob-backed
+// script components are rewritten in place.
+
+// migration is a registered conversion waiting for Migrate.
+type migration struct {
+	from reflect.Type
+	run  func(r *Registry) int
+}
+
+// RegisterMigration registers a hook converting stored From components into
+// To components. It runs on the next call to Migrate, typically issued by the
+// hot-reload tooling once the new definition is in place.
+func RegisterMigration[From any, To any](r *Registry, convert func(entity Goent, old *From) To) {
+	r.migrations = append(r.migrations, migration{
+		from: typeKeyFor[From](),
+		run: func(r *Registry) int {
+			return MigrateComponent(r, convert)
+		},
+	})
+}
+
+// Migrate runs the registered migrations in registration order and forgets
+// them. It returns the number of converted components per source type.
+func (r *Registry) Migrate() map[reflect.Type]int {
+	converted := make(map[reflect.Type]int)
+	pending := r.migrations
+	r.migrations = nil
+	for _, m := range pending {
+		converted[m.from] += m.run(r)
+	}
+	return converted
+}
+
+// MigrateComponent converts every stored From component into a To component
+// on the same entity and removes the From component. When From and To are
+// the same type the value is replaced in place. It returns the number of
+// converted components.
+func MigrateComponent[From any, To any](r *Registry, convert func(entity Goent, old *From) To) int {
+	r.assertWritable()
+
+	// collect first, emplacing while iterating would shift the dense arrays
+	var entities []Goent
+	Iterate1(r, func(entity Goent, c *From) {
+		entities = append(entities, entity)
+	}, IncludeDisabled())
+
+	inPlace := typeKeyFor[From]() == typeKeyFor[To]()
+	for _, entity := range entities {
+		old, _ := GetComponent[From](r, entity)
+		next := convert(entity, old)
+		if inPlace {
+			// same type, so To is From and the assertion always holds
+			*old = interface{}(next).(From)
+			continue
+		}
+		RemoveComponent[From](r, entity)
+		EmplaceComponent(r, entity, next)
+	}
+	return len(entities)
+}
+
+// ConvertComponent replaces every stored TOld component with convert of it,
+// for refactoring a component type live or loading legacy content. Unlike
+// MigrateComponent it works in passes over the whole storage: every value is
+// converted first, then the TOld storage is cleared, firing OnRemove, and the
+// new components are emplaced as one batch, firing OnAdd. Converting a type
+// to itself emplaces the new values over the old ones, firing OnUpdate and
+// stamping their change ticks. It returns the number of converted
+// components.
+func ConvertComponent[TOld any, TNew any](r *Registry, convert func(old TOld) TNew) int {
+	r.assertWritable()
+	var entities []Goent
+	var comps []TNew
+	Iterate1(r, func(entity Goent, c *TOld) {
+		entities = append(entities, entity)
+		comps = append(comps, convert(*c))
+	}, IncludeDisabled())
+
+	if typeKeyFor[TOld]() != typeKeyFor[TNew]() {
+		ClearComponent[TOld](r)
+	}
+	EmplaceBatch(r, entities, comps)
+	return len(entities)
+}
+
+// MigrateBlobs rewrites every blob with the given tag through convert, for
+// script components stored as blobs. convert may change both the tag and the
+// data. It returns the number of rewritten blobs.
+func (r *Registry) MigrateBlobs(tag string, convert func(entity Goent, b *Blob)) int {
+	count := 0
+	Iterate1(r, func(entity Goent, b *Blob) {
+		if b.Tag == tag {
+			convert(entity, b)
+			count++
+		}
+	}, IncludeDisabled())
+	return count
+}
@@ -0,0 +1,127 @@
+package goecs
+
+import (
+	"slices"
+	"testing"
+)
+
+type healthV1 struct {
+	HP int
+}
+
+type healthV2 struct {
+	Current, Max int
+}
+
+func TestMigrate(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(3)
+	for i, e := range entities {
+		EmplaceComponent(r, e, healthV1{HP: 10 * (i + 1)})
+	}
+	r.Disable(entities[1])
+	RegisterMigration(r, func(e Goent, old *healthV1) healthV2 {
+		return healthV2{Current: old.HP, Max: 100}
+	})
+	RegisterMigration(r, func(e Goent, old *healthV2) healthV2 {
+		return healthV2{Current: old.Current, Max: old.Max * 2}
+	})
+
+	converted := r.Migrate()
+	if converted[ComponentType[healthV1]()] != 3 || converted[ComponentType[healthV2]()] != 3 {
+		t.Errorf("Migrate converted %v", converted)
+	}
+	for i, e := range entities {
+		h, ok := GetComponent[healthV2](r, e)
+		if !ok || *h != (healthV2{Current: 10 * (i + 1), Max: 200}) || HasComponent[healthV1](r, e) {
+			t.Errorf("entity %d holds %v, %v after migrating", i, h, ok)
+		}
+	}
+	if again := r.Migrate(); len(again) != 0 {
+		t.Errorf("migrations ran twice: %v", again)
+	}
+}
+
+func TestMigrateBlobs(t *testing.T) {
+	r := NewRegistry()
+	a, b := r.CreateEntity(), r.CreateEntity()
+	r.SetBlob(a, "script/v1", []byte("old"))
+	r.SetBlob(b, "other", []byte("keep"))
+	n := r.MigrateBlobs("script/v1", func(e Goent, blob *Blob) {
+		blob.Tag = "script/v2"
+		blob.Data = append(blob.Data, '!')
+	})
+	if n != 1 {
+		t.Errorf("MigrateBlobs rewrote %d blobs, want 1", n)
+	}
+	if blob, _ := r.GetBlob(a); blob.Tag != "script/v2" || string(blob.Data) != "old!" {
+		t.Errorf("migrated blob = %+v", blob)
+	}
+	if blob, _ := r.GetBlob(b); blob.Tag != "other" || string(blob.Data) != "keep" {
+		t.Errorf("other blob = %+v", blob)
+	}
+}
+
+func TestConvertComponent(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(4)
+	for i, e := range entities[:3] {
+		EmplaceComponent(r, e, healthV1{HP: 10 * (i + 1)})
+	}
+	r.Disable(entities[1])
+	var log []string
+	OnRemove(r, func(e Goent, old *healthV1) { log = append(log, "remove") })
+	OnAdd(r, func(e Goent, c *healthV2) {
+		if HasComponent[healthV1](r, e) {
+			t.Errorf("entity %d got healthV2 while it still had healthV1", e)
+		}
+		log = append(log, "add")
+	})
+
+	n := ConvertComponent(r, func(old healthV1) healthV2 { return healthV2{Current: old.HP, Max: 100} })
+	if n != 3 || Count[healthV1](r) != 0 || Count[healthV2](r) != 3 {
+		t.Errorf("converted %d, left %d healthV1 and %d healthV2", n, Count[healthV1](r), Count[healthV2](r))
+	}
+	for i, e := range entities[:3] {
+		if h, ok := GetComponent[healthV2](r, e); !ok || *h != (healthV2{Current: 10 * (i + 1), Max: 100}) {
+			t.Errorf("entity %d has %v, %v", e, h, ok)
+		}
+	}
+	if HasComponent[healthV2](r, entities[3]) {
+		t.Error("entity without healthV1 got a healthV2")
+	}
+	if want := []string{"remove", "remove", "remove", "add", "add", "add"}; !slices.Equal(log, want) {
+		t.Errorf("hooks fired %v, want %v", log, want)
+	}
+
+	// converting a type to itself updates in place
+	tick := r.AdvanceTick()
+	updated := 0
+	OnUpdate(r, func(Goent, *healthV2) { updated++ })
+	ConvertComponent(r, func(old healthV2) healthV2 { return healthV2{Current: old.Current, Max: old.Max * 2} })
+	if h, _ := GetComponent[healthV2](r, entities[0]); h.Max != 200 || updated != 3 {
+		t.Errorf("self conversion gave %v and fired OnUpdate %d times", h, updated)
+	}
+	if changed, _ := ChangeTick[healthV2](r, entities[2]); changed != tick {
+		t.Errorf("change tick = %d, want %d", changed, tick)
+	}
+}
+
+func TestConvertComponentUpgrade(t *testing.T) {
+	r := NewRegistry()
+	s := RegisterComponentWithPolicy[healthV2](r, RareComponentPolicy(2))
+	entities := r.CreateEntities(10)
+	for i, e := range entities {
+		EmplaceComponent(r, e, healthV1{HP: i})
+	}
+
+	n := ConvertComponent(r, func(old healthV1) healthV2 { return healthV2{Current: old.HP, Max: 10} })
+	if n != 10 || s.index != nil {
+		t.Fatalf("converted %d, map index left as %v", n, s.index)
+	}
+	for i, e := range entities {
+		if h, ok := GetComponent[healthV2](r, e); !ok || *h != (healthV2{Current: i, Max: 10}) {
+			t.Errorf("entity %d has %v, %v", e, h, ok)
+		}
+	}
+}
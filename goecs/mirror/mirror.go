@@ -0,0 +1,205 @@
+// Package mirror runs a game's registry operations against the sparse set
+// and the archetype backend at once and cross-checks them, to validate a
+// backend change or a migration on real workloads. Every operation goes to
+// both registries; queries compare the entities each backend matches, and
+// EndFrame compares entity counts and every component value:
+//
+//	m := mirror.New()
+//	e := m.CreateEntity()
+//	mirror.Emplace(m, e, Transform{})
+//	for _, e := range mirror.Query2[Transform, Velocity](m) {
+//		mirror.Update(m, e, func(t *Transform) { t.X++ })
+//	}
+//	if err := m.EndFrame(); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// Component pointers from one backend must not be written through, since
+// the write would reach only that backend; use Update instead. The harness
+// doubles the cost of everything and is meant for debug builds and tests.
+package mirror
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+// Registry is a pair of registries kept in lockstep.
+type Registry struct {
+	// Sparse and Archetype are the mirrored registries. Read them freely,
+	// but change them only through the harness.
+	Sparse, Archetype *goecs.Registry
+	// checks compares the components of each type seen so far, in the
+	// order the types were first emplaced
+	checks []func(m *Registry) error
+	seen   map[reflect.Type]bool
+	// errs collects the mismatches found since the last EndFrame
+	errs  []error
+	frame int
+}
+
+// New creates a harness over a fresh registry of each backend.
+func New() *Registry {
+	return &Registry{
+		Sparse:    goecs.NewRegistryWithOptions(goecs.RegistryOptions{Storage: goecs.SparseSetStorage}),
+		Archetype: goecs.NewRegistryWithOptions(goecs.RegistryOptions{Storage: goecs.ArchetypeStorage}),
+		seen:      make(map[reflect.Type]bool),
+	}
+}
+
+// Frame returns the number of frames ended so far.
+func (m *Registry) Frame() int {
+	return m.frame
+}
+
+func (m *Registry) mismatch(format string, args ...interface{}) {
+	m.errs = append(m.errs, fmt.Errorf("frame %d: "+format, append([]interface{}{m.frame}, args...)...))
+}
+
+// CreateEntity creates an entity in both registries. Both allocate IDs the
+// same way, so they must hand out the same one.
+func (m *Registry) CreateEntity() goecs.Goent {
+	e, twin := m.Sparse.CreateEntity(), m.Archetype.CreateEntity()
+	if e != twin {
+		m.mismatch("created entity %d, archetype backend %d", e, twin)
+	}
+	return e
+}
+
+// CreateEntities creates n entities in both registries.
+func (m *Registry) CreateEntities(n int) []goecs.Goent {
+	entities, twins := m.Sparse.CreateEntities(n), m.Archetype.CreateEntities(n)
+	if !slices.Equal(entities, twins) {
+		m.mismatch("created entities %v, archetype backend %v", entities, twins)
+	}
+	return entities
+}
+
+// DestroyEntity destroys the entity in both registries.
+func (m *Registry) DestroyEntity(e goecs.Goent) {
+	m.Sparse.DestroyEntity(e)
+	m.Archetype.DestroyEntity(e)
+}
+
+// Emplace adds or replaces the entity's T component in both registries.
+func Emplace[T any](m *Registry, e goecs.Goent, c T) {
+	watch[T](m)
+	goecs.EmplaceComponent(m.Sparse, e, c)
+	goecs.EmplaceComponent(m.Archetype, e, c)
+}
+
+// Remove removes the entity's T component from both registries.
+func Remove[T any](m *Registry, e goecs.Goent) {
+	goecs.RemoveComponent[T](m.Sparse, e)
+	goecs.RemoveComponent[T](m.Archetype, e)
+}
+
+// Update calls fn on the entity's T component in both registries, and
+// records a mismatch if only one of them has it.
+func Update[T any](m *Registry, e goecs.Goent, fn func(c *T)) {
+	c, ok := goecs.GetComponent[T](m.Sparse, e)
+	twin, twinOK := goecs.GetComponent[T](m.Archetype, e)
+	if ok != twinOK {
+		m.mismatch("entity %d has %s on one backend only", e, goecs.ComponentType[T]())
+	}
+	if ok {
+		fn(c)
+	}
+	if twinOK {
+		fn(twin)
+	}
+}
+
+// Get returns the entity's T component from the sparse set registry, for
+// reading.
+func Get[T any](m *Registry, e goecs.Goent) (*T, bool) {
+	return goecs.GetComponent[T](m.Sparse, e)
+}
+
+// watch registers the value check of T on its first emplace.
+func watch[T any](m *Registry) {
+	t := goecs.ComponentType[T]()
+	if m.seen[t] {
+		return
+	}
+	m.seen[t] = true
+	m.checks = append(m.checks, func(m *Registry) error {
+		if n, twin := goecs.Count[T](m.Sparse), goecs.Count[T](m.Archetype); n != twin {
+			return fmt.Errorf("%d %s components, archetype backend %d", n, t, twin)
+		}
+		var err error
+		goecs.Iterate1(m.Sparse, func(e goecs.Goent, c *T) {
+			if err != nil {
+				return
+			}
+			twin, ok := goecs.GetComponent[T](m.Archetype, e)
+			switch {
+			case !ok:
+				err = fmt.Errorf("entity %d lacks %s on the archetype backend", e, t)
+			case !reflect.DeepEqual(*c, *twin):
+				err = fmt.Errorf("entity %d %s is %+v, archetype backend %+v", e, t, *c, *twin)
+			}
+		}, goecs.IncludeDisabled())
+		return err
+	})
+}
+
+// compare records a mismatch unless both backends matched the same
+// entities, and returns them sorted.
+func (m *Registry) compare(query string, entities, twins []goecs.Goent) []goecs.Goent {
+	slices.Sort(entities)
+	slices.Sort(twins)
+	if !slices.Equal(entities, twins) {
+		m.mismatch("%s matched %v, archetype backend %v", query, entities, twins)
+	}
+	return entities
+}
+
+// Query1 returns the entities with a T component, sorted, after checking
+// that both backends match the same ones.
+func Query1[T any](m *Registry, filters ...goecs.Filter) []goecs.Goent {
+	var entities, twins []goecs.Goent
+	goecs.Iterate1(m.Sparse, func(e goecs.Goent, _ *T) { entities = append(entities, e) }, filters...)
+	goecs.Iterate1(m.Archetype, func(e goecs.Goent, _ *T) { twins = append(twins, e) }, filters...)
+	return m.compare(fmt.Sprintf("Query1[%s]", goecs.ComponentType[T]()), entities, twins)
+}
+
+// Query2 returns the entities with T1 and T2 components, see Query1.
+func Query2[T1 any, T2 any](m *Registry, filters ...goecs.Filter) []goecs.Goent {
+	var entities, twins []goecs.Goent
+	goecs.Iterate2(m.Sparse, func(e goecs.Goent, _ *T1, _ *T2) { entities = append(entities, e) }, filters...)
+	goecs.Iterate2(m.Archetype, func(e goecs.Goent, _ *T1, _ *T2) { twins = append(twins, e) }, filters...)
+	return m.compare(fmt.Sprintf("Query2[%s, %s]", goecs.ComponentType[T1](), goecs.ComponentType[T2]()), entities, twins)
+}
+
+// Query3 returns the entities with T1, T2 and T3 components, see Query1.
+func Query3[T1 any, T2 any, T3 any](m *Registry, filters ...goecs.Filter) []goecs.Goent {
+	var entities, twins []goecs.Goent
+	goecs.Iterate3(m.Sparse, func(e goecs.Goent, _ *T1, _ *T2, _ *T3) { entities = append(entities, e) }, filters...)
+	goecs.Iterate3(m.Archetype, func(e goecs.Goent, _ *T1, _ *T2, _ *T3) { twins = append(twins, e) }, filters...)
+	return m.compare(fmt.Sprintf("Query3[%s, %s, %s]", goecs.ComponentType[T1](), goecs.ComponentType[T2](), goecs.ComponentType[T3]()), entities, twins)
+}
+
+// EndFrame cross-checks the entity counts and every component value of the
+// types emplaced through the harness, advances both registries' ticks and
+// returns the mismatches found during the frame, joined, or nil.
+func (m *Registry) EndFrame() error {
+	if n, twin := m.Sparse.EntityCount(), m.Archetype.EntityCount(); n != twin {
+		m.mismatch("%d entities, archetype backend %d", n, twin)
+	}
+	for _, check := range m.checks {
+		if err := check(m); err != nil {
+			m.mismatch("%w", err)
+		}
+	}
+	m.Sparse.AdvanceTick()
+	m.Archetype.AdvanceTick()
+	m.frame++
+	err := errors.Join(m.errs...)
+	m.errs = nil
+	return err
+}
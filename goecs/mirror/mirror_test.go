@@ -0,0 +1,104 @@
+package mirror
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+type mirrorPos struct {
+	X int
+}
+
+type mirrorVel struct {
+	DX int
+}
+
+type mirrorTag struct{}
+
+func TestLockstep(t *testing.T) {
+	m := New()
+	entities := m.CreateEntities(6)
+	for i, e := range entities {
+		Emplace(m, e, mirrorPos{X: i})
+		if i%2 == 0 {
+			Emplace(m, e, mirrorVel{DX: 1})
+		}
+	}
+	Emplace(m, entities[3], mirrorTag{})
+	m.DestroyEntity(entities[4])
+	Remove[mirrorVel](m, entities[2])
+
+	for range 3 {
+		for _, e := range Query2[mirrorPos, mirrorVel](m) {
+			Update(m, e, func(p *mirrorPos) { p.X += 10 })
+		}
+		if err := m.EndFrame(); err != nil {
+			t.Fatalf("frame %d of a clean workload: %v", m.Frame(), err)
+		}
+	}
+	if got := Query1[mirrorPos](m, goecs.Without[mirrorTag]()); !slices.Equal(got, []goecs.Goent{entities[0], entities[1], entities[2], entities[5]}) {
+		t.Errorf("Query1 = %v", got)
+	}
+	if p, _ := Get[mirrorPos](m, entities[0]); p.X != 30 {
+		t.Errorf("entity 0 at %d after 3 frames, want 30", p.X)
+	}
+	if m.Frame() != 3 || m.Sparse.Tick() != m.Archetype.Tick() {
+		t.Errorf("Frame() = %d with ticks %d and %d", m.Frame(), m.Sparse.Tick(), m.Archetype.Tick())
+	}
+}
+
+func TestMismatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		diverge func(m *Registry, e goecs.Goent)
+		want    string
+	}{
+		{"value", func(m *Registry, e goecs.Goent) {
+			p, _ := goecs.GetComponent[mirrorPos](m.Archetype, e)
+			p.X = 99
+		}, "archetype backend {X:99}"},
+		{"query", func(m *Registry, e goecs.Goent) {
+			goecs.RemoveComponent[mirrorPos](m.Sparse, e)
+			Query1[mirrorPos](m)
+		}, "Query1"},
+		{"update", func(m *Registry, e goecs.Goent) {
+			goecs.EmplaceComponent(m.Sparse, e, mirrorTag{})
+			Update(m, e, func(*mirrorTag) {})
+		}, "on one backend only"},
+		{"entities", func(m *Registry, e goecs.Goent) {
+			m.Archetype.CreateEntity()
+		}, "entities, archetype backend"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+			e := m.CreateEntity()
+			Emplace(m, e, mirrorPos{X: 1})
+			tt.diverge(m, e)
+			err := m.EndFrame()
+			if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.HasPrefix(err.Error(), "frame 0: ") {
+				t.Errorf("EndFrame = %v, want a frame 0 error about %q", err, tt.want)
+			}
+		})
+	}
+
+	// reported mismatches don't carry over, but lasting ones are found again
+	m := New()
+	e := m.CreateEntity()
+	Emplace(m, e, mirrorPos{})
+	Update(m, e, func(*mirrorVel) {})
+	goecs.EmplaceComponent(m.Sparse, e, mirrorVel{})
+	if err := m.EndFrame(); err != nil {
+		t.Errorf("type unknown to the harness was checked: %v", err)
+	}
+	p, _ := goecs.GetComponent[mirrorPos](m.Archetype, e)
+	p.X = 5
+	for frame := 1; frame <= 2; frame++ {
+		if err := m.EndFrame(); err == nil || strings.Count(err.Error(), "frame") != 1 {
+			t.Errorf("frame %d reported %v", frame, err)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Package nav moves agents along paths found on a navigation mesh. Games
+// give an entity a NavAgent and a physics.Transform, set a destination, and
+// the System plans the path on a time budget and steers the agent along it,
+// through its RigidBody velocity when it has one so the physics integration
+// keeps control of the motion.
+package nav
+
+import (
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+	"github.com/Swedeachu/go_ecs/goecs/physics"
+	"github.com/Swedeachu/go_ecs/goecs/spatial"
+)
+
+// Status is where an agent is in getting to its destination.
+type Status int
+
+const (
+	// Idle agents have nowhere to go.
+	Idle Status = iota
+	// Pending agents wait for their path to be planned.
+	Pending
+	// Moving agents follow their path.
+	Moving
+	// Arrived agents reached their destination.
+	Arrived
+	// Failed agents have no path to their destination.
+	Failed
+)
+
+// NavAgent is an entity that finds its own way around.
+type NavAgent struct {
+	// Speed is in world units per second.
+	Speed float64
+	// ArriveDistance is how close to a waypoint counts as reaching it.
+	ArriveDistance float64
+	Destination    spatial.Vec2
+	Status         Status
+	// Path holds the planned waypoints, Next the one being approached.
+	Path []spatial.Vec2
+	Next int
+}
+
+// SetDestination sends the agent to dest, planning a new path.
+func SetDestination(r *goecs.Registry, e goecs.Goent, dest spatial.Vec2) {
+	if a, ok := goecs.GetComponent[NavAgent](r, e); ok {
+		a.Destination = dest
+		a.Status = Pending
+		a.Path = a.Path[:0]
+		a.Next = 0
+	}
+}
+
+// NavMesh is what a pathfinding backend implements.
+type NavMesh interface {
+	// FindPath returns the waypoints from from to to, ending at to, or false
+	// if to can't be reached.
+	FindPath(from, to spatial.Vec2) ([]spatial.Vec2, bool)
+}
+
+// System plans and follows agent paths. Planning is time-sliced: each Step
+// spends at most Budget on path requests, resuming where the last Step
+// stopped, so a crowd ordered to move at once doesn't stall a frame.
+type System struct {
+	Mesh   NavMesh
+	Budget time.Duration
+	cursor goecs.BudgetCursor
+}
+
+// NewSystem creates a system planning on mesh for at most budget per step.
+func NewSystem(mesh NavMesh, budget time.Duration) *System {
+	return &System{Mesh: mesh, Budget: budget}
+}
+
+// Access is what Step touches, for goecs.Scheduler.Add.
+func (s *System) Access() goecs.SystemAccess {
+	return goecs.SystemAccess{Writes: []reflect.Type{
+		goecs.ComponentType[NavAgent](),
+		goecs.ComponentType[physics.Transform](),
+		goecs.ComponentType[physics.RigidBody](),
+	}}
+}
+
+// Plan finds paths for pending agents until the budget is spent.
+func (s *System) Plan(r *goecs.Registry) {
+	deadline := time.Now().Add(s.Budget)
+	goecs.IterateBudget2(r, &s.cursor, deadline, func(e goecs.Goent, a *NavAgent, t *physics.Transform) {
+		// the cursor only checks the clock now and then, planning is too
+		// expensive to keep going until it does
+		if a.Status != Pending || time.Now().After(deadline) {
+			return
+		}
+		path, ok := s.Mesh.FindPath(t.Position, a.Destination)
+		if !ok {
+			a.Status = Failed
+			return
+		}
+		a.Path, a.Next, a.Status = path, 0, Moving
+	})
+}
+
+// Follow steers moving agents toward their next waypoint. Agents with a
+// RigidBody get its velocity set, others are moved directly.
+func (s *System) Follow(r *goecs.Registry, dt float64) {
+	goecs.Iterate2(r, func(e goecs.Goent, a *NavAgent, t *physics.Transform) {
+		if a.Status != Moving {
+			return
+		}
+		body, hasBody := goecs.GetComponent[physics.RigidBody](r, e)
+		for a.Next < len(a.Path) && distance(t.Position, a.Path[a.Next]) <= a.ArriveDistance {
+			a.Next++
+		}
+		if a.Next == len(a.Path) {
+			a.Status = Arrived
+			if hasBody {
+				body.LinearVelocity = spatial.Vec2{}
+				goecs.MarkChanged[physics.RigidBody](r, e)
+			}
+			return
+		}
+
+		to := a.Path[a.Next].Sub(t.Position)
+		dist := math.Hypot(to.X, to.Y)
+		if hasBody {
+			body.LinearVelocity = to.Scale(a.Speed / dist)
+			goecs.MarkChanged[physics.RigidBody](r, e)
+			return
+		}
+		step := math.Min(a.Speed*dt, dist)
+		t.Position = t.Position.Add(to.Scale(step / dist))
+		goecs.MarkChanged[physics.Transform](r, e)
+	})
+}
+
+// Step plans, then follows.
+func (s *System) Step(r *goecs.Registry, dt float64) {
+	s.Plan(r)
+	s.Follow(r, dt)
+}
+
+// Update implements goecs.System.
+func (s *System) Update(w *goecs.World, dt float64) {
+	s.Step(w.Registry, dt)
+}
+
+func distance(a, b spatial.Vec2) float64 {
+	d := b.Sub(a)
+	return math.Hypot(d.X, d.Y)
+}
@@ -0,0 +1,78 @@
+package nav
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+	"github.com/Swedeachu/go_ecs/goecs/physics"
+	"github.com/Swedeachu/go_ecs/goecs/spatial"
+)
+
+// gridMesh walks along x first, then y, and can't reach negative x.
+type gridMesh struct {
+	requests int
+}
+
+func (m *gridMesh) FindPath(from, to spatial.Vec2) ([]spatial.Vec2, bool) {
+	m.requests++
+	if to.X < 0 {
+		return nil, false
+	}
+	return []spatial.Vec2{{X: to.X, Y: from.Y}, to}, true
+}
+
+func TestSystem(t *testing.T) {
+	r := goecs.NewRegistry()
+	walker, rider, lost := r.CreateEntity(), r.CreateEntity(), r.CreateEntity()
+	for _, e := range []goecs.Goent{walker, rider, lost} {
+		goecs.EmplaceComponent(r, e, NavAgent{Speed: 1, ArriveDistance: 0.01})
+		goecs.EmplaceComponent(r, e, physics.Transform{})
+	}
+	goecs.EmplaceComponent(r, rider, physics.RigidBody{Type: physics.Dynamic})
+	SetDestination(r, walker, spatial.Vec2{X: 1, Y: 1})
+	SetDestination(r, rider, spatial.Vec2{Y: 2})
+	SetDestination(r, lost, spatial.Vec2{X: -1})
+
+	mesh := &gridMesh{}
+	s := NewSystem(mesh, 0)
+	s.Step(r, 0.5)
+	if mesh.requests != 0 {
+		t.Fatalf("a spent budget still planned %d paths", mesh.requests)
+	}
+
+	s.Budget = time.Second
+	for i := 0; i < 3; i++ {
+		s.Step(r, 0.5)
+	}
+	agent := func(e goecs.Goent) *NavAgent {
+		a, _ := goecs.GetComponent[NavAgent](r, e)
+		return a
+	}
+	if mesh.requests != 3 {
+		t.Errorf("planned %d paths, want one per agent", mesh.requests)
+	}
+	if tr, _ := goecs.GetComponent[physics.Transform](r, walker); agent(walker).Status != Moving || tr.Position != (spatial.Vec2{X: 1, Y: 0.5}) {
+		t.Errorf("walker %v at %v, want moving at {1 0.5}", agent(walker).Status, tr.Position)
+	}
+	if agent(lost).Status != Failed {
+		t.Errorf("unreachable destination left the agent %v", agent(lost).Status)
+	}
+	// the rider is steered through its body and never moves by itself
+	body, _ := goecs.GetComponent[physics.RigidBody](r, rider)
+	if body.LinearVelocity != (spatial.Vec2{Y: 1}) {
+		t.Errorf("rider velocity = %v, want {0 1}", body.LinearVelocity)
+	}
+
+	s.Step(r, 0.5)
+	s.Step(r, 0.5)
+	if agent(walker).Status != Arrived {
+		t.Errorf("walker is %v at the end of its path", agent(walker).Status)
+	}
+	tr, _ := goecs.GetComponent[physics.Transform](r, rider)
+	tr.Position = spatial.Vec2{Y: 2}
+	s.Step(r, 0.5)
+	if agent(rider).Status != Arrived || body.LinearVelocity != (spatial.Vec2{}) {
+		t.Errorf("rider %v with velocity %v, want stopped on arrival", agent(rider).Status, body.LinearVelocity)
+	}
+}
@@ -0,0 +1,86 @@
+package goecs
+
+import (
+	"sort"
+)
+
+// --- Deterministic iteration order ---
+// Storages normally add at the end and remove by moving the last entry into
+// the hole, so the order iteration sees depends on the history of adds and
+// removes. A registry created with RegistryOptions.Deterministic keeps every
+// storage ordered by entity index instead: queries visit entities in the same
+// order on every machine and after every reload, as lockstep multiplayer and
+// replays need. Adding or removing a component then shifts the entries after
+// it, which costs O(n) instead of O(1); appending entities newer than all
+// others stays cheap.
+//
+// Owning groups and SortStorage reorder storages for their own purposes and
+// are rejected on deterministic registries. The archetype backend doesn't
+// support the mode.
+//
+// PreserveOrder applies the same shifting removal to a single storage,
+// keeping its components in insertion order rather than index order.
+
+// settle moves the entry just appended at dense slot i back to its place in
+// index order.
+func (ss *SparseSet[T]) settle(i int) {
+	index := ss.dense[i].Index()
+	j := sort.Search(i, func(k int) bool { return ss.dense[k].Index() > index })
+	if j == i {
+		return
+	}
+	entity, tick := ss.dense[i], ss.ticks[i]
+	copy(ss.dense[j+1:i+1], ss.dense[j:i])
+	copy(ss.ticks[j+1:i+1], ss.ticks[j:i])
+	ss.dense[j], ss.ticks[j] = entity, tick
+	if ss.tag == nil {
+		c := ss.components[i]
+		copy(ss.components[j+1:i+1], ss.components[j:i])
+		ss.components[j] = c
+	}
+	for k := j; k <= i; k++ {
+		ss.setSlot(ss.dense[k].Index(), k)
+	}
+}
+
+// PreserveOrder makes removals from the T storage keep the order of the
+// remaining components, so iteration visits them in insertion order, as UI
+// layers or queued events stored as components need. Removal shifts the
+// entries after the removed one, costing O(n) instead of O(1). It panics on
+// archetype registries, whose rows are shared by all types of an entity, and
+// if a group owns the storage.
+func PreserveOrder[T any](r *Registry) {
+	if r.archetypes != nil {
+		panic("goecs: PreserveOrder needs sparse set storage")
+	}
+	ensureStorage[T](r).SetPreserveOrder(true)
+}
+
+// SetPreserveOrder switches the storage between order preserving and
+// swap-with-last removal, see PreserveOrder.
+func (ss *SparseSet[T]) SetPreserveOrder(on bool) {
+	if on && ss.group != nil {
+		panic("goecs: a storage owned by a group can't preserve its order")
+	}
+	ss.stable = on
+}
+
+// removeOrdered takes out the entry at dense slot i, shifting the later
+// entries down to keep the order.
+func (ss *SparseSet[T]) removeOrdered(i int) {
+	entity := ss.dense[i]
+	last := len(ss.dense) - 1
+	copy(ss.dense[i:], ss.dense[i+1:])
+	copy(ss.ticks[i:], ss.ticks[i+1:])
+	ss.dense = ss.dense[:last]
+	ss.ticks = ss.ticks[:last]
+	if ss.tag == nil {
+		copy(ss.components[i:], ss.components[i+1:])
+		ss.components[last] = nil
+		ss.components = ss.components[:last]
+	}
+	for k := i; k < last; k++ {
+		ss.setSlot(ss.dense[k].Index(), k)
+	}
+	ss.setSlot(entity.Index(), invalidIndex)
+}
@@ -0,0 +1,130 @@
+package goecs
+
+import (
+	"slices"
+	"testing"
+)
+
+type orderedProbe struct {
+	V int
+}
+
+// visitOrder lists the entity indices in iteration order.
+func visitOrder(r *Registry) []uint32 {
+	var order []uint32
+	Iterate1(r, func(e Goent, _ *orderedProbe) { order = append(order, e.Index()) })
+	return order
+}
+
+func TestDeterministic(t *testing.T) {
+	newDeterministic := func() (*Registry, []Goent) {
+		r := NewRegistryWithOptions(RegistryOptions{Deterministic: true})
+		return r, r.CreateEntities(10)
+	}
+	// two unrelated histories both iterate in index order
+	a, ea := newDeterministic()
+	for _, i := range []int{7, 2, 9, 0, 5, 3, 8} {
+		EmplaceComponent(a, ea[i], orderedProbe{V: i})
+	}
+	RemoveComponent[orderedProbe](a, ea[5])
+	a.DestroyEntities([]Goent{ea[8], ea[0]})
+	reused := a.CreateEntity()
+	EmplaceComponent(a, reused, orderedProbe{})
+
+	b, eb := newDeterministic()
+	EmplaceBatch(b, []Goent{eb[3], eb[9], eb[2], eb[4]}, []orderedProbe{{}, {}, {}, {}})
+	EmplaceComponent(b, eb[7], orderedProbe{})
+	b.DestroyEntity(eb[4])
+	EmplaceComponent(b, b.CreateEntity(), orderedProbe{})
+
+	// the reused index takes the place of a destroyed entity
+	want := []uint32{2, 3, 7, 9, reused.Index()}
+	slices.Sort(want)
+	if got := visitOrder(a); !slices.Equal(got, want) {
+		t.Errorf("first history iterates %v, want %v", got, want)
+	}
+	if got := visitOrder(b); len(got) != 5 || !slices.IsSorted(got) {
+		t.Errorf("second history iterates %v, want index order", got)
+	}
+	// lookups still find the right components after the shifting
+	for _, i := range []int{2, 3, 7, 9} {
+		if c, ok := GetComponent[orderedProbe](a, ea[i]); !ok || c.V != i {
+			t.Errorf("entity %d has %v, %v", ea[i], c, ok)
+		}
+	}
+
+	tests := []struct {
+		name string
+		run  func()
+	}{
+		{"archetypes", func() { NewRegistryWithOptions(RegistryOptions{Storage: ArchetypeStorage, Deterministic: true}) }},
+		{"owning group", func() { NewGroup2[orderedProbe, entityProbe](a) }},
+		{"SortStorage", func() { SortStorage(a, func(x, y *orderedProbe) bool { return x.V < y.V }) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("didn't panic")
+				}
+			}()
+			tt.run()
+		})
+	}
+}
+
+func TestPreserveOrder(t *testing.T) {
+	r := NewRegistry()
+	PreserveOrder[orderedProbe](r)
+	entities := r.CreateEntities(8)
+	// inserted out of index order
+	for _, i := range []int{5, 1, 7, 0, 3, 6, 2, 4} {
+		EmplaceComponent(r, entities[i], orderedProbe{V: i})
+	}
+	RemoveComponent[orderedProbe](r, entities[7])
+	r.DestroyEntity(entities[5])
+	r.DestroyEntities([]Goent{entities[3], entities[6], entities[0]})
+	EmplaceComponent(r, entities[7], orderedProbe{V: 7})
+
+	var got []int
+	Iterate1(r, func(e Goent, c *orderedProbe) {
+		if c.V != int(e.Index()) {
+			t.Errorf("entity %d reads the component of entity %d", e, c.V)
+		}
+		got = append(got, c.V)
+	})
+	if want := []int{1, 2, 4, 7}; !slices.Equal(got, want) {
+		t.Errorf("iteration order = %v, want %v", got, want)
+	}
+
+	// switching it off goes back to swap removal
+	getStorage[orderedProbe](r).SetPreserveOrder(false)
+	RemoveComponent[orderedProbe](r, entities[1])
+	if got := visitOrder(r); !slices.Equal(got, []uint32{7, 2, 4}) {
+		t.Errorf("after swap removal, order = %v", got)
+	}
+
+	tests := []struct {
+		name string
+		run  func()
+	}{
+		{"archetypes", func() {
+			PreserveOrder[orderedProbe](NewRegistryWithOptions(RegistryOptions{Storage: ArchetypeStorage}))
+		}},
+		{"group owned", func() {
+			r := NewRegistry()
+			NewGroup2[orderedProbe, entityProbe](r)
+			PreserveOrder[orderedProbe](r)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("PreserveOrder didn't panic")
+				}
+			}()
+			tt.run()
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package physics is the glue between the ECS and a 2D physics engine. Games
+// describe bodies with Transform, RigidBody and Collider components; any
+// engine is slotted in by implementing World, and Sync keeps both sides in
+// step: components are pushed into the engine before it steps and the
+// simulated poses and velocities are read back after.
+package physics
+
+import (
+	"github.com/Swedeachu/go_ecs/goecs"
+	"github.com/Swedeachu/go_ecs/goecs/spatial"
+)
+
+// Transform is the pose of an entity in world space.
+type Transform struct {
+	Position spatial.Vec2
+	// Rotation is counter-clockwise, in radians.
+	Rotation float64
+}
+
+// BodyType says how the engine moves a body.
+type BodyType int
+
+const (
+	// Static bodies never move.
+	Static BodyType = iota
+	// Kinematic bodies move by their velocity only, the game drives them.
+	Kinematic
+	// Dynamic bodies are moved by the simulation.
+	Dynamic
+)
+
+// RigidBody makes an entity with a Transform part of the simulation.
+type RigidBody struct {
+	Type            BodyType
+	Mass            float64
+	LinearVelocity  spatial.Vec2
+	AngularVelocity float64
+	// GravityScale multiplies the world's gravity for this body.
+	GravityScale  float64
+	FixedRotation bool
+}
+
+// ShapeKind selects the collider shape.
+type ShapeKind int
+
+const (
+	Box ShapeKind = iota
+	Circle
+)
+
+// Collider gives a body a shape to collide with.
+type Collider struct {
+	Shape ShapeKind
+	// HalfExtents sizes a Box, Radius a Circle.
+	HalfExtents spatial.Vec2
+	Radius      float64
+	// Offset moves the shape away from the body's origin.
+	Offset      spatial.Vec2
+	Density     float64
+	Friction    float64
+	Restitution float64
+	// Sensors report overlaps without colliding.
+	Sensor bool
+	// Layer is the collision category, Mask the categories it collides with.
+	Layer, Mask uint32
+}
+
+// BodyID identifies a body inside the engine.
+type BodyID uint64
+
+// World is what an engine adapter implements. Sync calls it from the game
+// loop only.
+type World interface {
+	// CreateBody adds a body for the entity. collider is nil if it has none.
+	CreateBody(e goecs.Goent, t Transform, body RigidBody, collider *Collider) BodyID
+	DestroyBody(id BodyID)
+	// SetCollider replaces the body's shape, nil removes it.
+	SetCollider(id BodyID, collider *Collider)
+	SetTransform(id BodyID, t Transform)
+	SetVelocity(id BodyID, linear spatial.Vec2, angular float64)
+	Transform(id BodyID) Transform
+	Velocity(id BodyID) (linear spatial.Vec2, angular float64)
+	Step(dt float64)
+}
+
+// Sync mirrors the physics components of one registry into a World.
+//
+// Game code changes a body by emplacing its components or calling
+// goecs.MarkChanged after editing them in place; Sync pushes what changed
+// since its last step. Kinematic bodies are pushed every step. Sync writes
+// the simulated state back through pointers, which doesn't count as a change.
+type Sync struct {
+	world  World
+	bodies map[goecs.Goent]BodyID
+	// synced is the tick of the last push, see PreStep
+	synced uint64
+}
+
+// NewSync connects r to the world. Bodies are destroyed as soon as their
+// entity loses its Transform or RigidBody.
+func NewSync(r *goecs.Registry, world World) *Sync {
+	s := &Sync{world: world, bodies: make(map[goecs.Goent]BodyID)}
+	goecs.OnRemove(r, func(e goecs.Goent, _ *RigidBody) { s.destroy(e) })
+	goecs.OnRemove(r, func(e goecs.Goent, _ *Transform) { s.destroy(e) })
+	goecs.OnRemove(r, func(e goecs.Goent, _ *Collider) {
+		if id, ok := s.bodies[e]; ok {
+			s.world.SetCollider(id, nil)
+		}
+	})
+	return s
+}
+
+func (s *Sync) destroy(e goecs.Goent) {
+	if id, ok := s.bodies[e]; ok {
+		s.world.DestroyBody(id)
+		delete(s.bodies, e)
+	}
+}
+
+// Body returns the engine body of the entity.
+func (s *Sync) Body(e goecs.Goent) (BodyID, bool) {
+	id, ok := s.bodies[e]
+	return id, ok
+}
+
+// changed reports whether T of e changed since the last push. The tick of
+// that push is included: the write back after it left the component equal to
+// the engine state, so pushing it again is harmless.
+func changed[T any](r *goecs.Registry, e goecs.Goent, since uint64) bool {
+	tick, ok := goecs.ChangeTick[T](r, e)
+	return ok && tick >= since
+}
+
+// PreStep creates the bodies of new entities and pushes changed components
+// into the engine.
+func (s *Sync) PreStep(r *goecs.Registry) {
+	goecs.Iterate2(r, func(e goecs.Goent, t *Transform, body *RigidBody) {
+		collider, hasCollider := goecs.GetComponent[Collider](r, e)
+		id, ok := s.bodies[e]
+		if !ok {
+			s.bodies[e] = s.world.CreateBody(e, *t, *body, collider)
+			return
+		}
+		if body.Type == Kinematic || changed[Transform](r, e, s.synced) {
+			s.world.SetTransform(id, *t)
+		}
+		if body.Type == Kinematic || changed[RigidBody](r, e, s.synced) {
+			s.world.SetVelocity(id, body.LinearVelocity, body.AngularVelocity)
+		}
+		if hasCollider && changed[Collider](r, e, s.synced) {
+			s.world.SetCollider(id, collider)
+		}
+	})
+	s.synced = r.Tick()
+}
+
+// PostStep copies the simulated pose and velocity of dynamic bodies back
+// into their components.
+func (s *Sync) PostStep(r *goecs.Registry) {
+	goecs.Iterate2(r, func(e goecs.Goent, t *Transform, body *RigidBody) {
+		id, ok := s.bodies[e]
+		if !ok || body.Type != Dynamic {
+			return
+		}
+		*t = s.world.Transform(id)
+		body.LinearVelocity, body.AngularVelocity = s.world.Velocity(id)
+	})
+}
+
+// Step runs PreStep, steps the engine by dt and runs PostStep.
+func (s *Sync) Step(r *goecs.Registry, dt float64) {
+	s.PreStep(r)
+	s.world.Step(dt)
+	s.PostStep(r)
+}
+
+// Update implements goecs.System, so a Sync can be added to a goecs.World.
+func (s *Sync) Update(w *goecs.World, dt float64) {
+	s.Step(w.Registry, dt)
+}
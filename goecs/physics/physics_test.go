@@ -0,0 +1,124 @@
+package physics
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+	"github.com/Swedeachu/go_ecs/goecs/spatial"
+)
+
+type fakeBody struct {
+	t        Transform
+	body     RigidBody
+	collider *Collider
+}
+
+// fakeWorld moves dynamic bodies by their velocity and logs every call that
+// changes a body.
+type fakeWorld struct {
+	bodies map[BodyID]*fakeBody
+	next   BodyID
+	log    []string
+}
+
+func newFakeWorld() *fakeWorld {
+	return &fakeWorld{bodies: make(map[BodyID]*fakeBody)}
+}
+
+func (w *fakeWorld) CreateBody(e goecs.Goent, t Transform, body RigidBody, collider *Collider) BodyID {
+	w.next++
+	var c *Collider
+	if collider != nil {
+		copied := *collider
+		c = &copied
+	}
+	w.bodies[w.next] = &fakeBody{t: t, body: body, collider: c}
+	w.log = append(w.log, fmt.Sprint("create ", w.next))
+	return w.next
+}
+
+func (w *fakeWorld) DestroyBody(id BodyID) {
+	delete(w.bodies, id)
+	w.log = append(w.log, fmt.Sprint("destroy ", id))
+}
+
+func (w *fakeWorld) SetCollider(id BodyID, collider *Collider) {
+	w.bodies[id].collider = collider
+	w.log = append(w.log, fmt.Sprint("collider ", id, " ", collider != nil))
+}
+
+func (w *fakeWorld) SetTransform(id BodyID, t Transform) {
+	w.bodies[id].t = t
+	w.log = append(w.log, fmt.Sprint("transform ", id))
+}
+
+func (w *fakeWorld) SetVelocity(id BodyID, linear spatial.Vec2, angular float64) {
+	w.bodies[id].body.LinearVelocity, w.bodies[id].body.AngularVelocity = linear, angular
+	w.log = append(w.log, fmt.Sprint("velocity ", id))
+}
+
+func (w *fakeWorld) Transform(id BodyID) Transform {
+	return w.bodies[id].t
+}
+
+func (w *fakeWorld) Velocity(id BodyID) (spatial.Vec2, float64) {
+	b := w.bodies[id].body
+	return b.LinearVelocity, b.AngularVelocity
+}
+
+func (w *fakeWorld) Step(dt float64) {
+	for _, b := range w.bodies {
+		if b.body.Type != Static {
+			b.t.Position.X += b.body.LinearVelocity.X * dt
+			b.t.Position.Y += b.body.LinearVelocity.Y * dt
+		}
+	}
+}
+
+func TestSync(t *testing.T) {
+	r := goecs.NewRegistry()
+	world := newFakeWorld()
+	s := NewSync(r, world)
+	ground, ball := r.CreateEntity(), r.CreateEntity()
+	goecs.EmplaceComponent(r, ground, Transform{})
+	goecs.EmplaceComponent(r, ground, RigidBody{Type: Static})
+	goecs.EmplaceComponent(r, ground, Collider{Shape: Box, HalfExtents: spatial.Vec2{X: 10, Y: 1}})
+	goecs.EmplaceComponent(r, ball, Transform{Position: spatial.Vec2{Y: 5}})
+	goecs.EmplaceComponent(r, ball, RigidBody{Type: Dynamic, LinearVelocity: spatial.Vec2{X: 2}})
+
+	s.Step(r, 0.5)
+	r.AdvanceTick()
+	if tr, _ := goecs.GetComponent[Transform](r, ball); tr.Position != (spatial.Vec2{X: 1, Y: 5}) {
+		t.Errorf("ball at %v after a step, want {1 5}", tr.Position)
+	}
+	id, ok := s.Body(ground)
+	if !ok || world.bodies[id].collider == nil {
+		t.Fatalf("ground body %d, %v has no collider", id, ok)
+	}
+
+	// unchanged bodies aren't pushed again, changed ones are
+	s.Step(r, 0.5)
+	r.AdvanceTick()
+	world.log = nil
+	goecs.EmplaceComponent(r, ground, Transform{Position: spatial.Vec2{Y: -1}})
+	s.Step(r, 0.5)
+	if want := []string{fmt.Sprint("transform ", id)}; !slices.Equal(world.log, want) {
+		t.Errorf("engine calls = %v, want %v", world.log, want)
+	}
+	if tr, _ := goecs.GetComponent[Transform](r, ball); tr.Position.X != 3 {
+		t.Errorf("ball at %v after three steps, want x 3", tr.Position)
+	}
+
+	world.log = nil
+	goecs.RemoveComponent[Collider](r, ground)
+	goecs.RemoveComponent[RigidBody](r, ball)
+	want := []string{fmt.Sprint("collider ", id, " false"), "destroy 2"}
+	if !slices.Equal(world.log, want) {
+		t.Errorf("engine calls = %v, want %v", world.log, want)
+	}
+	if _, ok := s.Body(ball); ok {
+		t.Error("ball still has a body")
+	}
+}
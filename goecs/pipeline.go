@@ -0,0 +1,299 @@
+package goecs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// --- Pipeline configuration ---
+// Instead of calling Scheduler.Add in code, systems can be registered by name
+// in a SystemCatalog and laid out by a pipeline file, so a shipped build can
+// reorder or switch off systems without recompiling:
+//
+//	{
+//	  "stages": [
+//	    {"name": "input", "systems": [{"name": "ReadInput"}]},
+//	    {"name": "simulate", "systems": [
+//	      {"name": "Move"},
+//	      {"name": "Collide", "after": ["Move"]},
+//	      {"name": "DrawColliders"}
+//	    ]}
+//	  ],
+//	  "sets": {"debug": ["DrawColliders"]},
+//	  "enabled": ["debug"],
+//	  "disabled": ["ReadInput"]
+//	}
+//
+// Every system of a stage runs after every system of the stages before it.
+// Within a stage the scheduler still parallelizes by declared access, and
+// "after"/"before" add ordering between systems that don't conflict.
+// Systems that belong to sets only run while one of their sets is enabled;
+// "disabled" switches single systems off. Constraints naming a system that
+// is switched off or not in the pipeline are ignored.
+//
+// Mods extend a pipeline with PipelineSpec.Extend.
+
+// PipelineSystem places one system in a stage.
+type PipelineSystem struct {
+	Name   string   `json:"name"`
+	After  []string `json:"after,omitempty"`
+	Before []string `json:"before,omitempty"`
+}
+
+// PipelineStage is a named group of systems.
+type PipelineStage struct {
+	Name string `json:"name"`
+	// Before places a stage added by Extend in front of an existing stage
+	// instead of at the end.
+	Before  string           `json:"before,omitempty"`
+	Systems []PipelineSystem `json:"systems"`
+}
+
+// PipelineSpec is a pipeline as it appears in a config file.
+type PipelineSpec struct {
+	Stages   []PipelineStage     `json:"stages"`
+	Sets     map[string][]string `json:"sets,omitempty"`
+	Enabled  []string            `json:"enabled,omitempty"`
+	Disabled []string            `json:"disabled,omitempty"`
+}
+
+// LoadPipeline parses a JSON pipeline spec.
+func LoadPipeline(data []byte) (PipelineSpec, error) {
+	var spec PipelineSpec
+	err := json.Unmarshal(data, &spec)
+	return spec, err
+}
+
+// LoadPipelineFile parses the JSON pipeline spec at path.
+func LoadPipelineFile(path string) (PipelineSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PipelineSpec{}, err
+	}
+	return LoadPipeline(data)
+}
+
+// Extend merges a mod's spec into p. Systems of a stage p already has are
+// appended to it; new stages are inserted before the stage they name in
+// Before, or appended. Sets are merged, and the enabled and disabled lists
+// are added to.
+func (p *PipelineSpec) Extend(mod PipelineSpec) error {
+	for _, stage := range mod.Stages {
+		if i := p.stageIndex(stage.Name); i >= 0 {
+			p.Stages[i].Systems = append(p.Stages[i].Systems, stage.Systems...)
+			continue
+		}
+		if stage.Before == "" {
+			p.Stages = append(p.Stages, stage)
+			continue
+		}
+		i := p.stageIndex(stage.Before)
+		if i < 0 {
+			return fmt.Errorf("goecs: pipeline stage %q goes before unknown stage %q", stage.Name, stage.Before)
+		}
+		p.Stages = append(p.Stages[:i], append([]PipelineStage{stage}, p.Stages[i:]...)...)
+	}
+	if len(mod.Sets) > 0 && p.Sets == nil {
+		p.Sets = make(map[string][]string, len(mod.Sets))
+	}
+	for set, names := range mod.Sets {
+		p.Sets[set] = append(p.Sets[set], names...)
+	}
+	p.Enabled = append(p.Enabled, mod.Enabled...)
+	p.Disabled = append(p.Disabled, mod.Disabled...)
+	return nil
+}
+
+func (p *PipelineSpec) stageIndex(name string) int {
+	for i, stage := range p.Stages {
+		if stage.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// active returns the predicate telling whether a system is switched on.
+func (p *PipelineSpec) active() (func(name string) bool, error) {
+	enabled := make(map[string]bool, len(p.Enabled))
+	for _, set := range p.Enabled {
+		if _, ok := p.Sets[set]; !ok {
+			return nil, fmt.Errorf("goecs: pipeline enables unknown set %q", set)
+		}
+		enabled[set] = true
+	}
+	// on holds, for systems in at least one set, whether any of them is enabled
+	on := make(map[string]bool)
+	for set, names := range p.Sets {
+		for _, name := range names {
+			on[name] = on[name] || enabled[set]
+		}
+	}
+	for _, name := range p.Disabled {
+		on[name] = false
+	}
+	return func(name string) bool {
+		active, listed := on[name]
+		return !listed || active
+	}, nil
+}
+
+type catalogEntry struct {
+	access SystemAccess
+	run    func(r *Registry, q *SystemQueue)
+}
+
+// SystemCatalog holds the systems a pipeline spec can refer to by name.
+type SystemCatalog struct {
+	entries map[string]catalogEntry
+}
+
+// NewSystemCatalog creates an empty catalog.
+func NewSystemCatalog() *SystemCatalog {
+	return &SystemCatalog{entries: make(map[string]catalogEntry)}
+}
+
+// Register makes a system available under name, see Scheduler.Add.
+func (c *SystemCatalog) Register(name string, access SystemAccess, run func(r *Registry)) {
+	c.RegisterQueued(name, access, func(r *Registry, q *SystemQueue) { run(r) })
+}
+
+// RegisterQueued makes a queued system available under name, see
+// Scheduler.AddQueued. Registering a name again replaces the system.
+func (c *SystemCatalog) RegisterQueued(name string, access SystemAccess, run func(r *Registry, q *SystemQueue)) {
+	c.entries[name] = catalogEntry{access: access, run: run}
+}
+
+// Build adds the active systems of the spec to the scheduler in pipeline
+// order. It fails, leaving the scheduler untouched, on systems missing from
+// the catalog, systems listed twice, constraints that contradict the stage
+// order and ordering cycles.
+func (c *SystemCatalog) Build(s *Scheduler, spec PipelineSpec) error {
+	active, err := spec.active()
+	if err != nil {
+		return err
+	}
+	stageOf := make(map[string]int)
+	for i, stage := range spec.Stages {
+		for _, sys := range stage.Systems {
+			if _, ok := c.entries[sys.Name]; !ok {
+				return fmt.Errorf("goecs: pipeline stage %q names unknown system %q", stage.Name, sys.Name)
+			}
+			if _, dup := stageOf[sys.Name]; dup {
+				return fmt.Errorf("goecs: pipeline lists system %q twice", sys.Name)
+			}
+			stageOf[sys.Name] = i
+		}
+	}
+
+	orders := make([][]string, len(spec.Stages))
+	deps := make(map[string][]string)
+	for i, stage := range spec.Stages {
+		orders[i], err = c.orderStage(stage, stageOf, active, deps)
+		if err != nil {
+			return err
+		}
+	}
+
+	// barrier holds the systems of the last non-empty stage, which everything
+	// in the next stage follows
+	var barrier []int
+	ids := make(map[string]int)
+	for _, order := range orders {
+		if len(order) == 0 {
+			continue
+		}
+		var current []int
+		for _, name := range order {
+			after := append([]int(nil), barrier...)
+			for _, dep := range deps[name] {
+				after = append(after, ids[dep])
+			}
+			entry := c.entries[name]
+			ids[name] = s.add(name, entry.access, entry.run, after)
+			current = append(current, ids[name])
+		}
+		barrier = current
+	}
+	return nil
+}
+
+// orderStage sorts the active systems of a stage so each comes after the
+// ones it must follow, keeping the listed order otherwise. The same-stage
+// predecessors of each system are recorded in deps.
+func (c *SystemCatalog) orderStage(stage PipelineStage, stageOf map[string]int, active func(string) bool, deps map[string][]string) ([]string, error) {
+	var names []string
+	for _, sys := range stage.Systems {
+		if active(sys.Name) {
+			names = append(names, sys.Name)
+		}
+	}
+
+	// edge records that a must run before b, checking it against the stages
+	edge := func(a, b string) error {
+		for _, name := range []string{a, b} {
+			if _, ok := c.entries[name]; !ok {
+				return fmt.Errorf("goecs: pipeline orders unknown system %q", name)
+			}
+		}
+		stageA, inA := stageOf[a]
+		stageB, inB := stageOf[b]
+		if !inA || !inB || !active(a) || !active(b) {
+			return nil
+		}
+		if stageA > stageB {
+			return fmt.Errorf("goecs: pipeline wants %q before %q, but it is in a later stage", a, b)
+		}
+		if stageA == stageB {
+			deps[b] = append(deps[b], a)
+		}
+		return nil
+	}
+	for _, sys := range stage.Systems {
+		for _, after := range sys.After {
+			if err := edge(after, sys.Name); err != nil {
+				return nil, err
+			}
+		}
+		for _, before := range sys.Before {
+			if err := edge(sys.Name, before); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	placed := make(map[string]bool, len(names))
+	order := make([]string, 0, len(names))
+	for len(order) < len(names) {
+		progress := false
+		for _, name := range names {
+			if placed[name] || !allPlaced(deps[name], placed) {
+				continue
+			}
+			placed[name] = true
+			order = append(order, name)
+			progress = true
+		}
+		if !progress {
+			var stuck []string
+			for _, name := range names {
+				if !placed[name] {
+					stuck = append(stuck, name)
+				}
+			}
+			return nil, fmt.Errorf("goecs: pipeline stage %q has an ordering cycle among %s", stage.Name, strings.Join(stuck, ", "))
+		}
+	}
+	return order, nil
+}
+
+func allPlaced(names []string, placed map[string]bool) bool {
+	for _, name := range names {
+		if !placed[name] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package goecs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testPipeline = `{
+	"stages": [
+		{"name": "input", "systems": [{"name": "ReadInput"}]},
+		{"name": "simulate", "systems": [
+			{"name": "Collide", "after": ["Move"]},
+			{"name": "Move"},
+			{"name": "DrawColliders"},
+			{"name": "Profile"}
+		]}
+	],
+	"sets": {"debug": ["DrawColliders"], "dev": ["Profile"]},
+	"enabled": ["debug"],
+	"disabled": ["ReadInput"]
+}`
+
+func newTestCatalog(names ...string) *SystemCatalog {
+	c := NewSystemCatalog()
+	for _, name := range names {
+		c.Register(name, SystemAccess{}, func(r *Registry) {})
+	}
+	return c
+}
+
+func TestPipelineBuild(t *testing.T) {
+	spec, err := LoadPipeline([]byte(testPipeline))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = spec.Extend(PipelineSpec{Stages: []PipelineStage{
+		{Name: "simulate", Systems: []PipelineSystem{{Name: "Wind", Before: []string{"Move"}}}},
+		{Name: "network", Before: "simulate", Systems: []PipelineSystem{{Name: "Receive"}}},
+		{Name: "render", Systems: []PipelineSystem{{Name: "Draw"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestCatalog("ReadInput", "Move", "Collide", "DrawColliders", "Profile", "Wind", "Receive", "Draw")
+	s := NewScheduler(1)
+	defer s.Close()
+	if err := c.Build(s, spec); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"Receive"}, {"DrawColliders", "Wind"}, {"Move"}, {"Collide"}, {"Draw"}}
+	if got := s.Plan(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Plan() = %v, want %v", got, want)
+	}
+}
+
+func TestPipelineErrors(t *testing.T) {
+	tests := []struct {
+		name, spec, want string
+	}{
+		{"unknown system", `{"stages": [{"name": "a", "systems": [{"name": "Missing"}]}]}`, "unknown system"},
+		{"listed twice", `{"stages": [{"name": "a", "systems": [{"name": "Move"}]}, {"name": "b", "systems": [{"name": "Move"}]}]}`, "twice"},
+		{"unknown set", `{"stages": [], "enabled": ["debug"]}`, "unknown set"},
+		{"against stage order", `{"stages": [
+			{"name": "a", "systems": [{"name": "Move", "after": ["Collide"]}]},
+			{"name": "b", "systems": [{"name": "Collide"}]}]}`, "later stage"},
+		{"cycle", `{"stages": [{"name": "a", "systems": [
+			{"name": "Move", "after": ["Collide"]},
+			{"name": "Collide", "after": ["Move"]}]}]}`, "cycle"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := LoadPipeline([]byte(tt.spec))
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := NewScheduler(1)
+			defer s.Close()
+			err = newTestCatalog("Move", "Collide").Build(s, spec)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Build = %v, want an error mentioning %q", err, tt.want)
+			}
+			if plan := s.Plan(); len(plan) != 0 {
+				t.Errorf("failed Build added %v", plan)
+			}
+		})
+	}
+}
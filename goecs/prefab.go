@@ -0,0 +1,345 @@
+package goecs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// --- Prefabs ---
+// A prefab is a named set of component values that can be spawned as many
+// times as needed. Prefabs are loaded from data, where one can extend
+// another and override or add single component fields:
+//
+//	[
+//	  {"name": "Goblin", "components": {"game.Health": {"HP": 30, "Armor": 2}}},
+//	  {"name": "GoblinArcher", "extends": "Goblin",
+//	   "components": {"game.Health": {"HP": 20}, "game.Bow": {"Range": 12}}}
+//	]
+//
+// GoblinArcher ends up with Health{HP: 20, Armor: 2} and a Bow. Inheritance
+// is resolved once at load time, so spawning never walks the hierarchy.
+// Component names are the ones given to RegisterNamedComponent.
+//
+// Prefabs can also be built in code from component values:
+//
+//	goblin := NewPrefab(Health{HP: 30, Armor: 2}, Sprite{Name: "goblin"})
+//	goblin.Spawn(r, Health{HP: 10})
+//
+// Values passed to Spawn override the prefab's for that entity only. Every
+// spawn gets deep copies, so entities never share slices or maps with the
+// prefab or each other. Spawning into a registry that has never stored a
+// type needs the type captured with PrefabValue, unless some other registry
+// stored it before:
+//
+//	goblin := NewPrefab(PrefabValue(Health{HP: 30}), PrefabValue(Sprite{Name: "goblin"}))
+
+// PrefabDef is a prefab as it appears in data.
+type PrefabDef struct {
+	Name       string                     `json:"name"`
+	Extends    string                     `json:"extends,omitempty"`
+	Components map[string]json.RawMessage `json:"components"`
+}
+
+type prefabComponent struct {
+	typ   reflect.Type
+	value reflect.Value
+	// note records typ in a registry, nil until some registry stored it
+	note func(r *Registry) *componentInfo
+}
+
+// PrefabComponent is a component value captured with its type by
+// PrefabValue.
+type PrefabComponent struct {
+	value reflect.Value
+	note  func(r *Registry) *componentInfo
+}
+
+// PrefabValue captures comp for NewPrefab and With together with its type,
+// so the prefab spawns into registries that have never stored T.
+func PrefabValue[T any](comp T) PrefabComponent {
+	return PrefabComponent{value: reflect.ValueOf(comp), note: noteComponent[T]}
+}
+
+// Prefab is a set of component values ready to spawn, built in code with
+// NewPrefab or loaded from data.
+type Prefab struct {
+	Name       string
+	components []prefabComponent
+}
+
+// NewPrefab creates a prefab from component values, as in
+// NewPrefab(Transform{...}, Mesh{...}), or values wrapped with PrefabValue.
+// Later values replace earlier ones of the same type.
+func NewPrefab(components ...interface{}) *Prefab {
+	return (&Prefab{}).With(components...)
+}
+
+// With returns a copy of the prefab with the given values replacing the
+// components of the same type and adding the others.
+func (p *Prefab) With(overrides ...interface{}) *Prefab {
+	out := &Prefab{Name: p.Name, components: append([]prefabComponent(nil), p.components...)}
+	for _, comp := range overrides {
+		c, ok := comp.(PrefabComponent)
+		if !ok {
+			c.value = reflect.ValueOf(comp)
+			c.note, _ = noteFor(c.value.Type())
+		}
+		out.set(prefabComponent{typ: c.value.Type(), value: c.value, note: c.note})
+	}
+	return out
+}
+
+func (p *Prefab) set(c prefabComponent) {
+	for i := range p.components {
+		if p.components[i].typ == c.typ {
+			if c.note == nil {
+				c.note = p.components[i].note
+			}
+			p.components[i] = c
+			return
+		}
+	}
+	p.components = append(p.components, c)
+}
+
+// info returns the registry's record of the component type, recording it
+// first if the registry has never stored the type.
+func (c prefabComponent) info(r *Registry) (*componentInfo, bool) {
+	if info, ok := r.componentTypes[c.typ]; ok {
+		return info, true
+	}
+	note := c.note
+	if note == nil {
+		var ok bool
+		if note, ok = noteFor(c.typ); !ok {
+			return nil, false
+		}
+	}
+	return note(r), true
+}
+
+// Spawn creates an entity carrying deep copies of the prefab's components,
+// with the overrides replacing or adding components for this entity only.
+// It panics on a type no registry has stored that wasn't captured with
+// PrefabValue. Components are emplaced in restore order (see RestoreAfter).
+func (p *Prefab) Spawn(r *Registry, overrides ...interface{}) Goent {
+	comps := p.components
+	if len(overrides) > 0 {
+		comps = p.With(overrides...).components
+	}
+	infos := make([]*componentInfo, len(comps))
+	for i, c := range comps {
+		info, ok := c.info(r)
+		if !ok {
+			panic(fmt.Sprintf("goecs: prefab %q uses %s, which no registry has stored, wrap it with PrefabValue", p.Name, c.typ))
+		}
+		infos[i] = info
+	}
+	ranks := r.restoreRanks()
+	order := make([]int, len(comps))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return ranks[infos[order[i]]] < ranks[infos[order[j]]] })
+
+	entity := r.CreateEntity()
+	for _, i := range order {
+		infos[i].emplace(r, entity, deepCopy(comps[i].value))
+	}
+	return entity
+}
+
+// PrefabLibrary holds prefabs by name.
+type PrefabLibrary struct {
+	prefabs map[string]*Prefab
+}
+
+// NewPrefabLibrary creates an empty library, for prefabs built in code.
+func NewPrefabLibrary() *PrefabLibrary {
+	return &PrefabLibrary{prefabs: make(map[string]*Prefab)}
+}
+
+// Add stores the prefab under its name, replacing any prefab of that name.
+func (lib *PrefabLibrary) Add(p *Prefab) {
+	lib.prefabs[p.Name] = p
+}
+
+// Get returns the prefab with the given name.
+func (lib *PrefabLibrary) Get(name string) (*Prefab, bool) {
+	p, ok := lib.prefabs[name]
+	return p, ok
+}
+
+// Spawn spawns the named prefab with the given overrides, reporting false if
+// there is none.
+func (lib *PrefabLibrary) Spawn(r *Registry, name string, overrides ...interface{}) (Goent, bool) {
+	p, ok := lib.prefabs[name]
+	if !ok {
+		return 0, false
+	}
+	return p.Spawn(r, overrides...), true
+}
+
+// SpawnData spawns the named prefab with overrides from scene data, keyed by
+// component name like PrefabDef.Components. Overrides merge field by field
+// into the prefab's values, so {"game.Health": {"HP": 5}} keeps the armor.
+func (lib *PrefabLibrary) SpawnData(r *Registry, name string, overrides map[string]json.RawMessage) (Goent, error) {
+	p, ok := lib.prefabs[name]
+	if !ok {
+		return 0, fmt.Errorf("goecs: unknown prefab %q", name)
+	}
+	values := make([]interface{}, 0, len(overrides))
+	for compName, raw := range overrides {
+		info, ok := r.namedComponent(compName)
+		if !ok {
+			return 0, fmt.Errorf("%w: prefab %q override uses %q", ErrTypeNotRegistered, name, compName)
+		}
+		var overlay interface{}
+		if err := json.Unmarshal(raw, &overlay); err != nil {
+			return 0, fmt.Errorf("goecs: prefab %q override %s: %w", name, compName, err)
+		}
+		for _, c := range p.components {
+			if c.typ != info.typ {
+				continue
+			}
+			base, err := toJSONValue(c.value.Interface())
+			if err != nil {
+				return 0, fmt.Errorf("goecs: prefab %q, %s: %w", name, compName, err)
+			}
+			overlay = mergeJSON(base, overlay)
+		}
+		merged, err := json.Marshal(overlay)
+		if err != nil {
+			return 0, err
+		}
+		value, err := info.decodeJSON(merged)
+		if err != nil {
+			return 0, fmt.Errorf("goecs: prefab %q override %s: %w", name, compName, err)
+		}
+		values = append(values, value.Interface())
+	}
+	return p.Spawn(r, values...), nil
+}
+
+// toJSONValue converts v to its generic decoded JSON form.
+func toJSONValue(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	err = json.Unmarshal(raw, &out)
+	return out, err
+}
+
+// LoadPrefabs parses a JSON array of PrefabDefs and resolves them against
+// the component names of r. It fails on unknown names or parents,
+// duplicate prefab names and inheritance cycles.
+func LoadPrefabs(r *Registry, data []byte) (*PrefabLibrary, error) {
+	var defs []PrefabDef
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, err
+	}
+	return ResolvePrefabs(r, defs)
+}
+
+// ResolvePrefabs resolves already parsed definitions, see LoadPrefabs.
+func ResolvePrefabs(r *Registry, defs []PrefabDef) (*PrefabLibrary, error) {
+	byName := make(map[string]PrefabDef, len(defs))
+	for _, def := range defs {
+		if _, dup := byName[def.Name]; dup {
+			return nil, fmt.Errorf("goecs: prefab %q is defined twice", def.Name)
+		}
+		byName[def.Name] = def
+	}
+
+	// merged holds each prefab's components with its ancestors' folded in
+	merged := make(map[string]map[string]interface{}, len(defs))
+	var resolve func(name string, chain []string) (map[string]interface{}, error)
+	resolve = func(name string, chain []string) (map[string]interface{}, error) {
+		if comps, done := merged[name]; done {
+			return comps, nil
+		}
+		for i, seen := range chain {
+			if seen == name {
+				return nil, fmt.Errorf("goecs: prefab inheritance cycle %s", strings.Join(append(chain[i:], name), " -> "))
+			}
+		}
+		def, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("goecs: prefab %q extends unknown prefab %q", chain[len(chain)-1], name)
+		}
+
+		comps := make(map[string]interface{})
+		if def.Extends != "" {
+			parent, err := resolve(def.Extends, append(chain, name))
+			if err != nil {
+				return nil, err
+			}
+			for compName, value := range parent {
+				comps[compName] = value
+			}
+		}
+		for compName, raw := range def.Components {
+			var value interface{}
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, fmt.Errorf("goecs: prefab %q, %s: %w", name, compName, err)
+			}
+			comps[compName] = mergeJSON(comps[compName], value)
+		}
+		merged[name] = comps
+		return comps, nil
+	}
+
+	lib := &PrefabLibrary{prefabs: make(map[string]*Prefab, len(defs))}
+	for _, def := range defs {
+		comps, err := resolve(def.Name, nil)
+		if err != nil {
+			return nil, err
+		}
+		for compName := range comps {
+			if _, known := r.namedComponent(compName); !known {
+				return nil, fmt.Errorf("%w: prefab %q uses %q", ErrTypeNotRegistered, def.Name, compName)
+			}
+		}
+		p := &Prefab{Name: def.Name}
+		for _, info := range r.restoreOrder() {
+			value, ok := comps[info.name]
+			if !info.named || !ok {
+				continue
+			}
+			raw, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			decoded, err := info.decodeJSON(raw)
+			if err != nil {
+				return nil, fmt.Errorf("goecs: prefab %q, %s: %w", def.Name, info.name, err)
+			}
+			p.components = append(p.components, prefabComponent{typ: info.typ, value: decoded})
+		}
+		lib.prefabs[def.Name] = p
+	}
+	return lib, nil
+}
+
+// mergeJSON overlays a decoded JSON value onto a base one. Objects merge key
+// by key, recursively; anything else replaces the base.
+func mergeJSON(base, overlay interface{}) interface{} {
+	baseObj, ok1 := base.(map[string]interface{})
+	overObj, ok2 := overlay.(map[string]interface{})
+	if !ok1 || !ok2 {
+		return overlay
+	}
+	out := make(map[string]interface{}, len(baseObj)+len(overObj))
+	for k, v := range baseObj {
+		out[k] = v
+	}
+	for k, v := range overObj {
+		out[k] = mergeJSON(out[k], v)
+	}
+	return out
+}
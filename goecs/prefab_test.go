@@ -0,0 +1,170 @@
+package goecs
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type prefabHealth struct {
+	HP, Armor int
+}
+
+type prefabBow struct {
+	Range int
+}
+
+func newPrefabRegistry() *Registry {
+	r := NewRegistry()
+	RegisterNamedComponent[prefabHealth](r, "test.Health")
+	RegisterNamedComponent[prefabBow](r, "test.Bow")
+	return r
+}
+
+func TestPrefabInheritance(t *testing.T) {
+	r := newPrefabRegistry()
+	lib, err := LoadPrefabs(r, []byte(`[
+		{"name": "GoblinArcherChief", "extends": "GoblinArcher", "components": {"test.Health": {"Armor": 5}}},
+		{"name": "Goblin", "components": {"test.Health": {"HP": 30, "Armor": 2}}},
+		{"name": "GoblinArcher", "extends": "Goblin",
+		 "components": {"test.Health": {"HP": 20}, "test.Bow": {"Range": 12}}}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		prefab string
+		health prefabHealth
+		bow    *prefabBow
+	}{
+		{"Goblin", prefabHealth{HP: 30, Armor: 2}, nil},
+		{"GoblinArcher", prefabHealth{HP: 20, Armor: 2}, &prefabBow{Range: 12}},
+		{"GoblinArcherChief", prefabHealth{HP: 20, Armor: 5}, &prefabBow{Range: 12}},
+	}
+	for _, tt := range tests {
+		e, ok := lib.Spawn(r, tt.prefab)
+		if !ok {
+			t.Fatalf("prefab %s is missing", tt.prefab)
+		}
+		if h, _ := GetComponent[prefabHealth](r, e); h == nil || *h != tt.health {
+			t.Errorf("%s health = %v, want %v", tt.prefab, h, tt.health)
+		}
+		bow, _ := GetComponent[prefabBow](r, e)
+		if (bow == nil) != (tt.bow == nil) || bow != nil && *bow != *tt.bow {
+			t.Errorf("%s bow = %v, want %v", tt.prefab, bow, tt.bow)
+		}
+	}
+}
+
+func TestLoadPrefabsErrors(t *testing.T) {
+	r := newPrefabRegistry()
+	tests := []struct {
+		name, doc, want string
+	}{
+		{"cycle", `[{"name": "A", "extends": "B"}, {"name": "B", "extends": "A"}]`, "cycle"},
+		{"unknown parent", `[{"name": "A", "extends": "Missing"}]`, "unknown prefab"},
+		{"duplicate", `[{"name": "A"}, {"name": "A"}]`, "defined twice"},
+		{"unknown component", `[{"name": "A", "components": {"test.Missing": {}}}]`, "test.Missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadPrefabs(r, []byte(tt.doc)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadPrefabs = %v, want an error mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefabSpawn(t *testing.T) {
+	r := newPrefabRegistry()
+	goblin := NewPrefab(prefabHealth{HP: 30, Armor: 2}, prefabBow{Range: 5}, prefabHealth{HP: 40, Armor: 2})
+	goblin.Name = "Goblin"
+
+	plain := goblin.Spawn(r)
+	wounded := goblin.Spawn(r, prefabHealth{HP: 10})
+	tests := []struct {
+		name   string
+		entity Goent
+		want   prefabHealth
+	}{
+		{"later value wins", plain, prefabHealth{HP: 40, Armor: 2}},
+		{"override", wounded, prefabHealth{HP: 10}},
+	}
+	for _, tt := range tests {
+		if h, _ := GetComponent[prefabHealth](r, tt.entity); h == nil || *h != tt.want {
+			t.Errorf("%s: health = %v, want %v", tt.name, h, tt.want)
+		}
+		if b, _ := GetComponent[prefabBow](r, tt.entity); b == nil || b.Range != 5 {
+			t.Errorf("%s: bow = %v", tt.name, b)
+		}
+	}
+	// spawns get copies
+	h, _ := GetComponent[prefabHealth](r, plain)
+	h.HP = 1
+	if h, _ := GetComponent[prefabHealth](r, goblin.Spawn(r)); h.HP != 40 {
+		t.Errorf("spawned entities share state, HP %d", h.HP)
+	}
+
+	lib := NewPrefabLibrary()
+	lib.Add(goblin)
+	e, err := lib.SpawnData(r, "Goblin", map[string]json.RawMessage{"test.Health": json.RawMessage(`{"HP": 5}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h, _ := GetComponent[prefabHealth](r, e); h == nil || *h != (prefabHealth{HP: 5, Armor: 2}) {
+		t.Errorf("SpawnData health = %v, want the armor kept", h)
+	}
+	if _, err := lib.SpawnData(r, "Orc", nil); err == nil {
+		t.Error("SpawnData of an unknown prefab succeeded")
+	}
+	if _, err := lib.SpawnData(r, "Goblin", map[string]json.RawMessage{"test.Missing": nil}); !errors.Is(err, ErrTypeNotRegistered) {
+		t.Errorf("SpawnData with an unknown component = %v, want ErrTypeNotRegistered", err)
+	}
+	if _, ok := lib.Spawn(r, "Orc"); ok {
+		t.Error("Spawn of an unknown prefab succeeded")
+	}
+}
+
+type prefabInventory struct {
+	Items []int
+}
+
+func TestPrefabSpawnDeepCopies(t *testing.T) {
+	r := NewRegistry()
+	RegisterComponent[prefabInventory](r)
+	chest := NewPrefab(prefabInventory{Items: []int{1, 2}})
+	a, b := chest.Spawn(r), chest.Spawn(r)
+	inv, _ := GetComponent[prefabInventory](r, a)
+	inv.Items[0] = 99
+	if other, _ := GetComponent[prefabInventory](r, b); other == nil || other.Items[0] != 1 {
+		t.Errorf("spawned entities share items: %v", other)
+	}
+	if again, _ := GetComponent[prefabInventory](r, chest.Spawn(r)); again.Items[0] != 1 {
+		t.Errorf("prefab changed through a spawn: %v", again.Items)
+	}
+}
+
+func TestPrefabSpawnUnknownType(t *testing.T) {
+	type local struct{ V int }
+	p := NewPrefab(PrefabValue(local{V: 1})).With(local{V: 2})
+	r := NewRegistry()
+	e := p.Spawn(r)
+	if c, ok := GetComponent[local](r, e); !ok || c.V != 2 {
+		t.Errorf("spawned %v, %v into a fresh registry", c, ok)
+	}
+
+	// a plain value is enough once any registry stored the type
+	fresh := NewRegistry()
+	if c, ok := GetComponent[local](fresh, NewPrefab(local{V: 3}).Spawn(fresh)); !ok || c.V != 3 {
+		t.Errorf("plain value spawned %v, %v", c, ok)
+	}
+
+	type unseen struct{ V int }
+	defer func() {
+		if recover() == nil {
+			t.Error("spawning a type no registry stored didn't panic")
+		}
+	}()
+	NewPrefab(unseen{}).Spawn(NewRegistry())
+}
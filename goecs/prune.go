@@ -0,0 +1,125 @@
+package goecs
+
+import (
+	"reflect"
+)
+
+// --- Garbage component pruning ---
+
+// PruneReport describes what a call to PruneComponents cleaned up.
+type PruneReport struct {
+	// Removed counts the pruned components per component type.
+	Removed map[reflect.Type]int
+	// Entities lists every entity that lost at least one component.
+	Entities []Goent
+}
+
+// Total returns the number of components removed across all types.
+func (pr PruneReport) Total() int {
+	total := 0
+	for _, n := range pr.Removed {
+		total += n
+	}
+	return total
+}
+
+// MarkIdentity marks T as an identity component. Once any identity types are
+// marked, an entity is only considered to exist while it has at least one of
+// them, anything else it still carries after losing them all is garbage for
+// PruneComponents.
+func MarkIdentity[T any](r *Registry) {
+	if r.archetypes == nil {
+		ensureStorage[T](r)
+	}
+	r.identities[typeKeyFor[T]()] = struct{}{}
+}
+
+// entityExists reports whether the entity is not stale and, when identity
+// types are marked, still present in any identity storage.
+func (r *Registry) entityExists(entity Goent) bool {
+	if r.isStale(entity) {
+		return false
+	}
+	if len(r.identities) == 0 {
+		return true
+	}
+	for key := range r.identities {
+		if _, ok := r.componentOf(entity, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// PruneComponents is an optional sweep that removes every component whose
+// entity no longer exists, either because its generation is stale or because
+// it lost all of its identity components, protecting against leaks when user
+// code forgets to remove components. It works on both storage backends.
+func (r *Registry) PruneComponents() PruneReport {
+	r.assertWritable()
+	report := PruneReport{Removed: make(map[reflect.Type]int)}
+	if r.archetypes != nil {
+		r.pruneArchetypes(&report)
+		return report
+	}
+
+	seen := make(map[Goent]struct{})
+	var garbage []Goent
+	for key, storage := range r.storages {
+		if _, isIdentity := r.identities[key]; isIdentity {
+			continue
+		}
+
+		// Collect first, removing while walking the dense array would skip entries
+		garbage = garbage[:0]
+		for _, entity := range storage.GetDense() {
+			if !r.entityExists(entity) {
+				garbage = append(garbage, entity)
+			}
+		}
+		if len(garbage) == 0 {
+			continue
+		}
+
+		for _, entity := range garbage {
+			r.fireRemoveHook(entity, key)
+			storage.Remove(entity)
+			if _, ok := seen[entity]; !ok {
+				seen[entity] = struct{}{}
+				report.Entities = append(report.Entities, entity)
+			}
+		}
+		report.Removed[key] = len(garbage)
+	}
+	return report
+}
+
+// pruneArchetypes is PruneComponents for the archetype backend, where
+// destroying an entity drops its whole row, so only entities that lost
+// their identity components leave garbage behind.
+func (r *Registry) pruneArchetypes(report *PruneReport) {
+	type garbage struct {
+		entity Goent
+		types  []reflect.Type
+	}
+	// Collect first, removing moves entities between archetypes
+	var found []garbage
+	for _, a := range r.archetypes.list {
+		for _, entity := range a.entities {
+			if !r.entityExists(entity) {
+				found = append(found, garbage{entity: entity, types: a.types})
+			}
+		}
+	}
+	for _, g := range found {
+		for _, key := range g.types {
+			if _, isIdentity := r.identities[key]; isIdentity {
+				continue
+			}
+			r.fireRemoveHook(g.entity, key)
+			r.archetypes.remove(g.entity, key)
+			report.Removed[key]++
+		}
+		report.Entities = append(report.Entities, g.entity)
+	}
+}
@@ -0,0 +1,108 @@
+package goecs
+
+import (
+	"slices"
+	"testing"
+)
+
+type pruneBody struct {
+	ID int
+}
+
+type pruneSprite struct {
+	Frame int
+}
+
+type pruneSound struct {
+	Volume float64
+}
+
+func TestPruneComponents(t *testing.T) {
+	r := NewRegistry()
+	MarkIdentity[pruneBody](r)
+	entities := r.CreateEntities(6)
+	for i, e := range entities {
+		EmplaceComponent(r, e, pruneSprite{Frame: i})
+		if i%2 == 0 {
+			EmplaceComponent(r, e, pruneBody{ID: i})
+			EmplaceComponent(r, e, pruneSound{})
+		}
+	}
+	// a component left behind under a handle whose entity is gone
+	stale := r.CreateEntity()
+	r.DestroyEntity(stale)
+	getStorage[pruneSound](r).Emplace(stale, pruneSound{})
+
+	var removed []Goent
+	OnRemove(r, func(e Goent, _ *pruneSprite) { removed = append(removed, e) })
+	report := r.PruneComponents()
+
+	want := []Goent{entities[1], entities[3], entities[5]}
+	if got := report.Removed[ComponentType[pruneSprite]()]; got != len(want) {
+		t.Errorf("pruned %d sprites, want %d", got, len(want))
+	}
+	if got := report.Removed[ComponentType[pruneSound]()]; got != 1 {
+		t.Errorf("pruned %d sounds, want 1", got)
+	}
+	if report.Total() != len(want)+1 {
+		t.Errorf("Total() = %d, want %d", report.Total(), len(want)+1)
+	}
+	entitiesWant := append(slices.Clone(want), stale)
+	slices.Sort(report.Entities)
+	slices.Sort(entitiesWant)
+	if !slices.Equal(report.Entities, entitiesWant) {
+		t.Errorf("report lists %v, want %v", report.Entities, entitiesWant)
+	}
+	slices.Sort(removed)
+	if !slices.Equal(removed, want) {
+		t.Errorf("OnRemove fired for %v, want %v", removed, want)
+	}
+	for i, e := range entities {
+		if HasComponent[pruneSprite](r, e) != (i%2 == 0) {
+			t.Errorf("entity %d has sprite %v", i, HasComponent[pruneSprite](r, e))
+		}
+	}
+	if again := r.PruneComponents(); again.Total() != 0 {
+		t.Errorf("second sweep pruned %d", again.Total())
+	}
+}
+
+func TestPruneComponentsWithoutIdentities(t *testing.T) {
+	r := NewRegistry()
+	e := r.CreateEntity()
+	EmplaceComponent(r, e, pruneSprite{})
+	if report := r.PruneComponents(); report.Total() != 0 || !HasComponent[pruneSprite](r, e) {
+		t.Errorf("pruned %d components of a live entity", report.Total())
+	}
+}
+
+func TestPruneComponentsArchetypes(t *testing.T) {
+	r := NewRegistryWithOptions(RegistryOptions{Storage: ArchetypeStorage})
+	MarkIdentity[pruneBody](r)
+	entities := r.CreateEntities(4)
+	for i, e := range entities {
+		EmplaceComponent(r, e, pruneSprite{Frame: i})
+		EmplaceComponent(r, e, pruneSound{})
+		if i%2 == 0 {
+			EmplaceComponent(r, e, pruneBody{ID: i})
+		}
+	}
+	var removed []Goent
+	OnRemove(r, func(e Goent, _ *pruneSprite) { removed = append(removed, e) })
+	report := r.PruneComponents()
+
+	want := []Goent{entities[1], entities[3]}
+	slices.Sort(report.Entities)
+	if !slices.Equal(report.Entities, want) || report.Total() != 4 {
+		t.Errorf("pruned %d components of %v, want 4 of %v", report.Total(), report.Entities, want)
+	}
+	slices.Sort(removed)
+	if !slices.Equal(removed, want) {
+		t.Errorf("OnRemove fired for %v, want %v", removed, want)
+	}
+	for i, e := range entities {
+		if HasComponent[pruneSprite](r, e) != (i%2 == 0) || HasComponent[pruneSound](r, e) != (i%2 == 0) {
+			t.Errorf("entity %d kept the wrong components", i)
+		}
+	}
+}
@@ -0,0 +1,462 @@
+package goecs
+
+import (
+	"iter"
+	"reflect"
+	"slices"
+)
+
+// --- Range-over-func queries ---
+// The Query functions return iterators over the same entities as the
+// Iterate functions, for use with range, so break, continue and return work
+// as in any other loop:
+//
+//	for e, row := range Query2[Transform, RigidBody](r) {
+//		if row.C2.Sleeping {
+//			continue
+//		}
+//		row.C1.X += row.C2.VX * dt
+//	}
+//
+// A range loop has at most two variables, so the components of queries over
+// several types come as a row struct. Filters are resolved every time the
+// iterator is ranged over, so one iterator can be kept and reused across
+// frames. The same rules as for Iterate apply to changing the registry
+// inside the loop.
+
+// Query1 returns an iterator over the entities with a T component.
+func Query1[T any](r *Registry, filters ...Filter) iter.Seq2[Goent, *T] {
+	return func(yield func(Goent, *T) bool) {
+		fs := r.resolveFilters(filters)
+		if r.archetypes != nil {
+			k := typeKeyFor[T]()
+			for _, a := range r.archetypes.list {
+				if len(a.entities) == 0 || !a.matches(&fs, []reflect.Type{k}) {
+					continue
+				}
+				col := archColumnOf[T](a, k)
+				for row, entity := range a.entities {
+					if !fs.skip(entity) && !yield(entity, col.at(row)) {
+						return
+					}
+				}
+			}
+			return
+		}
+		s := getStorage[T](r)
+		if s == nil {
+			return
+		}
+		for i, entity := range s.dense {
+			if !fs.skip(entity) && !yield(entity, s.at(i)) {
+				return
+			}
+		}
+	}
+}
+
+// Row2 holds the components of an entity visited by Query2.
+type Row2[T1 any, T2 any] struct {
+	C1 *T1
+	C2 *T2
+}
+
+// Query2 returns an iterator over the entities with T1 and T2
+// components.
+func Query2[T1 any, T2 any](r *Registry, filters ...Filter) iter.Seq2[Goent, Row2[T1, T2]] {
+	return func(yield func(Goent, Row2[T1, T2]) bool) {
+		fs := r.resolveFilters(filters)
+		if r.archetypes != nil {
+			k1, k2 := typeKeyFor[T1](), typeKeyFor[T2]()
+			types := []reflect.Type{k1, k2}
+			for _, a := range r.archetypes.list {
+				if len(a.entities) == 0 || !a.matches(&fs, types) {
+					continue
+				}
+				col1 := archColumnOf[T1](a, k1)
+				col2 := archColumnOf[T2](a, k2)
+				for row, entity := range a.entities {
+					if !fs.skip(entity) && !yield(entity, Row2[T1, T2]{col1.at(row), col2.at(row)}) {
+						return
+					}
+				}
+			}
+			return
+		}
+		c1, ok1 := newColumn[T1](r, &fs)
+		c2, ok2 := newColumn[T2](r, &fs)
+		if !ok1 || !ok2 {
+			return
+		}
+		for _, entity := range driverDense(c1, c2) {
+			if fs.skip(entity) {
+				continue
+			}
+			p1, ok1 := c1.get(entity)
+			p2, ok2 := c2.get(entity)
+			if ok1 && ok2 && !yield(entity, Row2[T1, T2]{p1, p2}) {
+				return
+			}
+		}
+	}
+}
+
+// Row3 holds the components of an entity visited by Query3.
+type Row3[T1 any, T2 any, T3 any] struct {
+	C1 *T1
+	C2 *T2
+	C3 *T3
+}
+
+// Query3 returns an iterator over the entities with T1, T2 and T3
+// components.
+func Query3[T1 any, T2 any, T3 any](r *Registry, filters ...Filter) iter.Seq2[Goent, Row3[T1, T2, T3]] {
+	return func(yield func(Goent, Row3[T1, T2, T3]) bool) {
+		fs := r.resolveFilters(filters)
+		if r.archetypes != nil {
+			k1, k2, k3 := typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3]()
+			types := []reflect.Type{k1, k2, k3}
+			for _, a := range r.archetypes.list {
+				if len(a.entities) == 0 || !a.matches(&fs, types) {
+					continue
+				}
+				col1 := archColumnOf[T1](a, k1)
+				col2 := archColumnOf[T2](a, k2)
+				col3 := archColumnOf[T3](a, k3)
+				for row, entity := range a.entities {
+					if !fs.skip(entity) && !yield(entity, Row3[T1, T2, T3]{col1.at(row), col2.at(row), col3.at(row)}) {
+						return
+					}
+				}
+			}
+			return
+		}
+		c1, ok1 := newColumn[T1](r, &fs)
+		c2, ok2 := newColumn[T2](r, &fs)
+		c3, ok3 := newColumn[T3](r, &fs)
+		if !ok1 || !ok2 || !ok3 {
+			return
+		}
+		for _, entity := range driverDense(c1, c2, c3) {
+			if fs.skip(entity) {
+				continue
+			}
+			p1, ok1 := c1.get(entity)
+			p2, ok2 := c2.get(entity)
+			p3, ok3 := c3.get(entity)
+			if ok1 && ok2 && ok3 && !yield(entity, Row3[T1, T2, T3]{p1, p2, p3}) {
+				return
+			}
+		}
+	}
+}
+
+// Row4 holds the components of an entity visited by Query4.
+type Row4[T1 any, T2 any, T3 any, T4 any] struct {
+	C1 *T1
+	C2 *T2
+	C3 *T3
+	C4 *T4
+}
+
+// Query4 returns an iterator over the entities with T1, T2, T3 and T4
+// components.
+func Query4[T1 any, T2 any, T3 any, T4 any](r *Registry, filters ...Filter) iter.Seq2[Goent, Row4[T1, T2, T3, T4]] {
+	return func(yield func(Goent, Row4[T1, T2, T3, T4]) bool) {
+		fs := r.resolveFilters(filters)
+		if r.archetypes != nil {
+			k1, k2, k3, k4 := typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3](), typeKeyFor[T4]()
+			types := []reflect.Type{k1, k2, k3, k4}
+			for _, a := range r.archetypes.list {
+				if len(a.entities) == 0 || !a.matches(&fs, types) {
+					continue
+				}
+				col1 := archColumnOf[T1](a, k1)
+				col2 := archColumnOf[T2](a, k2)
+				col3 := archColumnOf[T3](a, k3)
+				col4 := archColumnOf[T4](a, k4)
+				for row, entity := range a.entities {
+					if !fs.skip(entity) && !yield(entity, Row4[T1, T2, T3, T4]{col1.at(row), col2.at(row), col3.at(row), col4.at(row)}) {
+						return
+					}
+				}
+			}
+			return
+		}
+		c1, ok1 := newColumn[T1](r, &fs)
+		c2, ok2 := newColumn[T2](r, &fs)
+		c3, ok3 := newColumn[T3](r, &fs)
+		c4, ok4 := newColumn[T4](r, &fs)
+		if !ok1 || !ok2 || !ok3 || !ok4 {
+			return
+		}
+		for _, entity := range driverDense(c1, c2, c3, c4) {
+			if fs.skip(entity) {
+				continue
+			}
+			p1, ok1 := c1.get(entity)
+			p2, ok2 := c2.get(entity)
+			p3, ok3 := c3.get(entity)
+			p4, ok4 := c4.get(entity)
+			if ok1 && ok2 && ok3 && ok4 && !yield(entity, Row4[T1, T2, T3, T4]{p1, p2, p3, p4}) {
+				return
+			}
+		}
+	}
+}
+
+// --- Pull iterators ---
+// A QueryIter advances a query one result at a time, for consumers that
+// can't hand a callback or loop body to the query, such as coroutines or
+// work spread over several frames:
+//
+//	it := NewQueryIter2[Transform, Path](r)
+//	for it.Next() {
+//		e, t, p := it.Item()
+//		...
+//	}
+//
+// A pass takes a snapshot of the candidate entities when it starts and checks
+// each one again when Next reaches it, so entities destroyed or stripped of a
+// component since are skipped and the components are always current.
+// Entities that start matching during a pass are picked up by the next one.
+
+// queryGet fetches the entity's T for a pull iterator. A missing optional
+// component yields nil while still reporting ok.
+func queryGet[T any](r *Registry, fs *filterSet, entity Goent) (*T, bool) {
+	if c, ok := GetComponent[T](r, entity); ok {
+		return c, true
+	}
+	return nil, fs.isOptional(typeKeyFor[T]())
+}
+
+// queryCursor is the state shared by the pull iterators.
+type queryCursor struct {
+	registry *Registry
+	filters  []Filter
+	fs       filterSet
+	// candidates is the snapshot of the current pass, nil before it starts
+	candidates []Goent
+	pos        int
+	entity     Goent
+}
+
+// advance moves to the next candidate, starting a pass with collect if none
+// is running. It reports false once the pass is exhausted.
+func (q *queryCursor) advance(collect func() []Goent) bool {
+	if q.candidates == nil {
+		q.fs = q.registry.resolveFilters(q.filters)
+		q.candidates = collect()
+		if q.candidates == nil {
+			q.candidates = []Goent{}
+		}
+		q.pos = 0
+	}
+	for q.pos < len(q.candidates) {
+		entity := q.candidates[q.pos]
+		q.pos++
+		if !q.fs.skip(entity) {
+			q.entity = entity
+			return true
+		}
+	}
+	return false
+}
+
+// Reset drops the current pass; the next call to Next starts a new one.
+func (q *queryCursor) Reset() {
+	q.candidates = nil
+	q.pos = 0
+	q.entity = 0
+}
+
+// collect returns a copy of the dense array driving a sparse set query,
+// or of the entities of the matching archetypes.
+func (q *queryCursor) collect(types []reflect.Type, dense func() []Goent) []Goent {
+	if q.registry.archetypes != nil {
+		var out []Goent
+		q.registry.archetypes.each(&q.fs, types, func(a *archetype) {
+			out = append(out, a.entities...)
+		})
+		return out
+	}
+	return slices.Clone(dense())
+}
+
+// QueryIter1 is a pull iterator over the entities with T
+// component.
+type QueryIter1[T any] struct {
+	queryCursor
+	c1 *T
+}
+
+// NewQueryIter1 creates a pull iterator over the entities with T
+// component. Its first pass starts with the first call to Next.
+func NewQueryIter1[T any](r *Registry, filters ...Filter) *QueryIter1[T] {
+	return &QueryIter1[T]{queryCursor: queryCursor{registry: r, filters: filters}}
+}
+
+// Next advances to the next matching entity, reporting false once the pass
+// is done. Call Reset to start another pass.
+func (it *QueryIter1[T]) Next() bool {
+	collect := func() []Goent {
+		return it.collect([]reflect.Type{typeKeyFor[T]()}, func() []Goent {
+			s := getStorage[T](it.registry)
+			if s == nil {
+				return nil
+			}
+			return s.dense
+		})
+	}
+	for it.advance(collect) {
+		c1, ok1 := queryGet[T](it.registry, &it.fs, it.entity)
+		if ok1 {
+			it.c1 = c1
+			return true
+		}
+	}
+	return false
+}
+
+// Item returns the entity Next advanced to and its component.
+func (it *QueryIter1[T]) Item() (Goent, *T) {
+	return it.entity, it.c1
+}
+
+// QueryIter2 is a pull iterator over the entities with T1 and T2
+// components.
+type QueryIter2[T1 any, T2 any] struct {
+	queryCursor
+	c1 *T1
+	c2 *T2
+}
+
+// NewQueryIter2 creates a pull iterator over the entities with T1 and T2
+// components. Its first pass starts with the first call to Next.
+func NewQueryIter2[T1 any, T2 any](r *Registry, filters ...Filter) *QueryIter2[T1, T2] {
+	return &QueryIter2[T1, T2]{queryCursor: queryCursor{registry: r, filters: filters}}
+}
+
+// Next advances to the next matching entity, reporting false once the pass
+// is done. Call Reset to start another pass.
+func (it *QueryIter2[T1, T2]) Next() bool {
+	collect := func() []Goent {
+		return it.collect([]reflect.Type{typeKeyFor[T1](), typeKeyFor[T2]()}, func() []Goent {
+			c1, ok1 := newColumn[T1](it.registry, &it.fs)
+			c2, ok2 := newColumn[T2](it.registry, &it.fs)
+			if !ok1 || !ok2 {
+				return nil
+			}
+			return driverDense(c1, c2)
+		})
+	}
+	for it.advance(collect) {
+		c1, ok1 := queryGet[T1](it.registry, &it.fs, it.entity)
+		c2, ok2 := queryGet[T2](it.registry, &it.fs, it.entity)
+		if ok1 && ok2 {
+			it.c1, it.c2 = c1, c2
+			return true
+		}
+	}
+	return false
+}
+
+// Item returns the entity Next advanced to and its components.
+func (it *QueryIter2[T1, T2]) Item() (Goent, *T1, *T2) {
+	return it.entity, it.c1, it.c2
+}
+
+// QueryIter3 is a pull iterator over the entities with T1, T2 and T3
+// components.
+type QueryIter3[T1 any, T2 any, T3 any] struct {
+	queryCursor
+	c1 *T1
+	c2 *T2
+	c3 *T3
+}
+
+// NewQueryIter3 creates a pull iterator over the entities with T1, T2 and T3
+// components. Its first pass starts with the first call to Next.
+func NewQueryIter3[T1 any, T2 any, T3 any](r *Registry, filters ...Filter) *QueryIter3[T1, T2, T3] {
+	return &QueryIter3[T1, T2, T3]{queryCursor: queryCursor{registry: r, filters: filters}}
+}
+
+// Next advances to the next matching entity, reporting false once the pass
+// is done. Call Reset to start another pass.
+func (it *QueryIter3[T1, T2, T3]) Next() bool {
+	collect := func() []Goent {
+		return it.collect([]reflect.Type{typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3]()}, func() []Goent {
+			c1, ok1 := newColumn[T1](it.registry, &it.fs)
+			c2, ok2 := newColumn[T2](it.registry, &it.fs)
+			c3, ok3 := newColumn[T3](it.registry, &it.fs)
+			if !ok1 || !ok2 || !ok3 {
+				return nil
+			}
+			return driverDense(c1, c2, c3)
+		})
+	}
+	for it.advance(collect) {
+		c1, ok1 := queryGet[T1](it.registry, &it.fs, it.entity)
+		c2, ok2 := queryGet[T2](it.registry, &it.fs, it.entity)
+		c3, ok3 := queryGet[T3](it.registry, &it.fs, it.entity)
+		if ok1 && ok2 && ok3 {
+			it.c1, it.c2, it.c3 = c1, c2, c3
+			return true
+		}
+	}
+	return false
+}
+
+// Item returns the entity Next advanced to and its components.
+func (it *QueryIter3[T1, T2, T3]) Item() (Goent, *T1, *T2, *T3) {
+	return it.entity, it.c1, it.c2, it.c3
+}
+
+// QueryIter4 is a pull iterator over the entities with T1, T2, T3 and T4
+// components.
+type QueryIter4[T1 any, T2 any, T3 any, T4 any] struct {
+	queryCursor
+	c1 *T1
+	c2 *T2
+	c3 *T3
+	c4 *T4
+}
+
+// NewQueryIter4 creates a pull iterator over the entities with T1, T2, T3 and T4
+// components. Its first pass starts with the first call to Next.
+func NewQueryIter4[T1 any, T2 any, T3 any, T4 any](r *Registry, filters ...Filter) *QueryIter4[T1, T2, T3, T4] {
+	return &QueryIter4[T1, T2, T3, T4]{queryCursor: queryCursor{registry: r, filters: filters}}
+}
+
+// Next advances to the next matching entity, reporting false once the pass
+// is done. Call Reset to start another pass.
+func (it *QueryIter4[T1, T2, T3, T4]) Next() bool {
+	collect := func() []Goent {
+		return it.collect([]reflect.Type{typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3](), typeKeyFor[T4]()}, func() []Goent {
+			c1, ok1 := newColumn[T1](it.registry, &it.fs)
+			c2, ok2 := newColumn[T2](it.registry, &it.fs)
+			c3, ok3 := newColumn[T3](it.registry, &it.fs)
+			c4, ok4 := newColumn[T4](it.registry, &it.fs)
+			if !ok1 || !ok2 || !ok3 || !ok4 {
+				return nil
+			}
+			return driverDense(c1, c2, c3, c4)
+		})
+	}
+	for it.advance(collect) {
+		c1, ok1 := queryGet[T1](it.registry, &it.fs, it.entity)
+		c2, ok2 := queryGet[T2](it.registry, &it.fs, it.entity)
+		c3, ok3 := queryGet[T3](it.registry, &it.fs, it.entity)
+		c4, ok4 := queryGet[T4](it.registry, &it.fs, it.entity)
+		if ok1 && ok2 && ok3 && ok4 {
+			it.c1, it.c2, it.c3, it.c4 = c1, c2, c3, c4
+			return true
+		}
+	}
+	return false
+}
+
+// Item returns the entity Next advanced to and its components.
+func (it *QueryIter4[T1, T2, T3, T4]) Item() (Goent, *T1, *T2, *T3, *T4) {
+	return it.entity, it.c1, it.c2, it.c3, it.c4
+}
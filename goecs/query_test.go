@@ -0,0 +1,155 @@
+package goecs
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			entities := newIterWorld(r, 30)
+			r.Disable(entities[9])
+
+			tests := []struct {
+				name string
+				seq  func() []int
+				want []int
+			}{
+				{"Query1", func() (got []int) {
+					for e, c := range Query1[iterC](r) {
+						if c.V != int(e.Index()) {
+							t.Errorf("entity %d got component %d", e, c.V)
+						}
+						got = append(got, c.V)
+					}
+					return got
+				}, []int{0, 3, 6, 12, 15, 18, 21, 24, 27}},
+				{"Query2", func() (got []int) {
+					for _, row := range Query2[iterA, iterC](r, IncludeDisabled()) {
+						got = append(got, row.C1.V)
+					}
+					return got
+				}, multiples(30, 3)},
+				{"Query3", func() (got []int) {
+					for _, row := range Query3[iterA, iterB, iterC](r, Without[iterE]()) {
+						got = append(got, row.C3.V)
+					}
+					return got
+				}, []int{6, 12, 18, 24}},
+				{"Query4", func() (got []int) {
+					for _, row := range Query4[iterA, iterB, iterC, iterF](r) {
+						got = append(got, row.C4.V)
+					}
+					return got
+				}, []int{0, 6, 12, 18, 24}},
+			}
+			for _, tt := range tests {
+				if got := sortedValues(tt.seq()); !slices.Equal(got, tt.want) {
+					t.Errorf("%s visited %v, want %v", tt.name, got, tt.want)
+				}
+			}
+
+			// break stops the iteration
+			seq := Query1[iterB](r)
+			n := 0
+			for range seq {
+				n++
+				if n == 3 {
+					break
+				}
+			}
+			if n != 3 {
+				t.Errorf("loop ran %d times, want 3", n)
+			}
+			// writes go to the stored components
+			for _, row := range Query2[iterA, iterB](r) {
+				row.C2.V = -row.C1.V
+			}
+			if c, _ := GetComponent[iterB](r, entities[4]); c.V != -4 {
+				t.Errorf("iterB of entity 4 = %d after the loop, want -4", c.V)
+			}
+			// a kept iterator sees later changes
+			e := r.CreateEntity()
+			EmplaceComponent(r, e, iterB{V: 100})
+			found := false
+			for got := range seq {
+				found = found || got == e
+			}
+			if !found {
+				t.Error("reused iterator missed the new entity")
+			}
+		})
+	}
+}
+
+func TestQueryIter(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			entities := newIterWorld(r, 12)
+			it := NewQueryIter2[iterA, iterB](r)
+
+			// advance a few items, then change the world mid-pass
+			var got []int
+			for range 2 {
+				if !it.Next() {
+					t.Fatal("Next ran out early")
+				}
+				_, a, _ := it.Item()
+				got = append(got, a.V)
+			}
+			seen := map[int]bool{got[0]: true, got[1]: true}
+			var doomed Goent
+			for _, e := range entities {
+				if a, _ := GetComponent[iterA](r, e); a.V%2 == 0 && !seen[a.V] {
+					doomed = e
+					break
+				}
+			}
+			r.DestroyEntity(doomed)
+			late := r.CreateEntity()
+			EmplaceComponent(r, late, iterA{V: 100})
+			EmplaceComponent(r, late, iterB{V: 100})
+			for it.Next() {
+				e, a, c := it.Item()
+				if a.V != c.V || int(e.Index()) != a.V {
+					t.Errorf("entity %d has iterA %d and iterB %d", e, a.V, c.V)
+				}
+				got = append(got, a.V)
+			}
+			want := []int{0, 2, 4, 6, 8, 10}
+			want = slices.DeleteFunc(want, func(v int) bool { return v == int(doomed.Index()) })
+			if got := sortedValues(got); !slices.Equal(got, want) {
+				t.Errorf("first pass visited %v, want %v", got, want)
+			}
+			if it.Next() {
+				t.Error("Next advanced past the end of the pass")
+			}
+
+			// the next pass picks up the entity created during the first
+			it.Reset()
+			n := 0
+			for it.Next() {
+				n++
+			}
+			if n != len(want)+1 {
+				t.Errorf("second pass visited %d entities, want %d", n, len(want)+1)
+			}
+
+			// optional components come as nil
+			opt := NewQueryIter2[iterA, iterC](r, Optional[iterC]())
+			visited, withC := 0, 0
+			for opt.Next() {
+				visited++
+				if _, _, c := opt.Item(); c != nil {
+					withC++
+				}
+			}
+			if visited != Count[iterA](r) || withC != Count[iterC](r) {
+				t.Errorf("optional query visited %d with %d iterC, want %d with %d", visited, withC, Count[iterA](r), Count[iterC](r))
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package goecs
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// --- Query cost assertions ---
+// A change to storage or query planning can make a query scan far more
+// entities or allocate where it didn't, without anything failing. Measuring
+// a query on a reference workload in a test and checking the cost against
+// limits turns such a regression into a test failure:
+//
+//	goecs.AssertQueryCost(t, r, goecs.QueryLimits{MaxCandidates: 100, MaxAllocs: 8}, func() {
+//		goecs.Iterate2(r, move)
+//	})
+//
+// Durations depend on the machine running the test, so MaxDuration should
+// leave a wide margin or be left out on shared CI runners.
+
+// QueryCost is what running a query cost.
+type QueryCost struct {
+	// Candidates is the number of entities the iterations examined, matching
+	// or not.
+	Candidates int64
+	Allocs     uint64
+	Bytes      uint64
+	Duration   time.Duration
+}
+
+// QueryLimits are the largest costs a query may have. Zero values aren't
+// checked, except MaxAllocs, which is checked when NoAllocs is set or it is
+// above zero.
+type QueryLimits struct {
+	MaxCandidates int64
+	MaxAllocs     uint64
+	// NoAllocs requires the query not to allocate at all.
+	NoAllocs    bool
+	MaxDuration time.Duration
+}
+
+// MeasureQuery runs the query and returns its cost. Every iteration of the
+// registry run counts towards Candidates, so run should do nothing else with
+// the registry. Allocations are counted for the whole process, so nothing
+// else should be running either; that makes MeasureQuery a tool for tests
+// and benchmarks, not for a running game.
+func (r *Registry) MeasureQuery(run func()) QueryCost {
+	var cost QueryCost
+	var before, after runtime.MemStats
+	r.probe = &cost
+	defer func() { r.probe = nil }()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	run()
+	cost.Duration = time.Since(start)
+	runtime.ReadMemStats(&after)
+	cost.Allocs = after.Mallocs - before.Mallocs
+	cost.Bytes = after.TotalAlloc - before.TotalAlloc
+	return cost
+}
+
+// Check returns an error listing every limit the cost exceeds, or nil.
+func (c QueryCost) Check(limits QueryLimits) error {
+	var over []string
+	if limits.MaxCandidates > 0 && c.Candidates > limits.MaxCandidates {
+		over = append(over, fmt.Sprintf("%d candidates scanned, limit %d", c.Candidates, limits.MaxCandidates))
+	}
+	if (limits.NoAllocs || limits.MaxAllocs > 0) && c.Allocs > limits.MaxAllocs {
+		over = append(over, fmt.Sprintf("%d allocations, limit %d", c.Allocs, limits.MaxAllocs))
+	}
+	if limits.MaxDuration > 0 && c.Duration > limits.MaxDuration {
+		over = append(over, fmt.Sprintf("took %v, limit %v", c.Duration, limits.MaxDuration))
+	}
+	if len(over) == 0 {
+		return nil
+	}
+	return fmt.Errorf("goecs: query too expensive: %s", strings.Join(over, "; "))
+}
+
+// TestingT is the part of testing.TB AssertQueryCost uses, so this package
+// doesn't depend on testing.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertQueryCost measures the query with MeasureQuery and fails the test if
+// it exceeds the limits. It returns the cost for further checks.
+func AssertQueryCost(t TestingT, r *Registry, limits QueryLimits, run func()) QueryCost {
+	t.Helper()
+	cost := r.MeasureQuery(run)
+	if err := cost.Check(limits); err != nil {
+		t.Errorf("%v", err)
+	}
+	return cost
+}
@@ -0,0 +1,78 @@
+package goecs
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordT collects the failures AssertQueryCost reports.
+type recordT struct {
+	errors []string
+}
+
+func (t *recordT) Helper() {}
+
+func (t *recordT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMeasureQuery(t *testing.T) {
+	r := NewRegistry()
+	newIterWorld(r, 80)
+	tests := []struct {
+		name string
+		run  func()
+		want int64
+	}{
+		{"one type", func() { Iterate1(r, func(Goent, *iterA) {}) }, 80},
+		{"driven by the smaller storage", func() { Iterate2(r, func(Goent, *iterA, *iterH) {}) }, 10},
+		{"no iteration", func() { GetComponent[iterA](r, 0) }, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cost := r.MeasureQuery(tt.run); cost.Candidates != tt.want {
+				t.Errorf("Candidates = %d, want %d", cost.Candidates, tt.want)
+			}
+		})
+	}
+
+	rec := &recordT{}
+	AssertQueryCost(rec, r, QueryLimits{MaxCandidates: 20}, func() { Iterate1(r, func(Goent, *iterA) {}) })
+	if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "80 candidates") {
+		t.Errorf("AssertQueryCost reported %v", rec.errors)
+	}
+	if r.probe != nil {
+		t.Error("the registry is still measured after the query")
+	}
+}
+
+func TestQueryCostCheck(t *testing.T) {
+	cost := QueryCost{Candidates: 50, Allocs: 2, Duration: time.Second}
+	tests := []struct {
+		name   string
+		limits QueryLimits
+		want   []string
+	}{
+		{"unlimited", QueryLimits{}, nil},
+		{"within", QueryLimits{MaxCandidates: 50, MaxAllocs: 2, MaxDuration: time.Minute}, nil},
+		{"candidates", QueryLimits{MaxCandidates: 49}, []string{"50 candidates scanned"}},
+		{"no allocs", QueryLimits{NoAllocs: true}, []string{"2 allocations, limit 0"}},
+		{"everything", QueryLimits{MaxCandidates: 1, MaxAllocs: 1, MaxDuration: time.Millisecond},
+			[]string{"candidates", "allocations", "took 1s"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cost.Check(tt.limits)
+			if (err == nil) != (tt.want == nil) {
+				t.Fatalf("Check = %v, want failures %v", err, tt.want)
+			}
+			for _, part := range tt.want {
+				if !strings.Contains(err.Error(), part) {
+					t.Errorf("Check = %v, missing %q", err, part)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package goecs
+
+import (
+	"sort"
+)
+
+// --- Reactive queries ---
+// A reactive query reports the entities that started or stopped matching a
+// component combination since it was last drained, so systems pick up new
+// work without re-scanning the world. It is driven by the lifecycle hooks of
+// its component types: gaining the last missing type enters an entity,
+// losing any of them (or being destroyed) exits it. An entity that enters
+// and exits between two drains shows up in neither list. Unlike iteration,
+// reactive queries track component membership only: disabled entities
+// match, and Disable or Enable doesn't make an entity exit or enter.
+//
+// Consumers that want to hear about changes as they happen, rather than
+// polling Drain, Subscribe callbacks instead. Several consumers (minimap,
+// audio, AI director) can share one query this way.
+
+// ReactiveQuery tracks entered and exited entities of one combination.
+type ReactiveQuery struct {
+	has     func(entity Goent) bool
+	matched map[Goent]struct{}
+	// pending is true for entered entities and false for exited ones
+	pending map[Goent]bool
+	closed  bool
+	// subscribers are called on every enter and exit, see Subscribe
+	subscribers []reactiveSubscriber
+}
+
+type reactiveSubscriber struct {
+	onEnter, onExit func(entity Goent)
+}
+
+func newReactiveQuery(has func(entity Goent) bool) *ReactiveQuery {
+	return &ReactiveQuery{
+		has:     has,
+		matched: make(map[Goent]struct{}),
+		pending: make(map[Goent]bool),
+	}
+}
+
+// added is hooked to OnAdd of every type in the combination.
+func (q *ReactiveQuery) added(entity Goent) {
+	if q.closed || !q.has(entity) {
+		return
+	}
+	if _, ok := q.matched[entity]; ok {
+		return
+	}
+	q.matched[entity] = struct{}{}
+	if entered, ok := q.pending[entity]; ok && !entered {
+		delete(q.pending, entity)
+	} else {
+		q.pending[entity] = true
+	}
+	for _, s := range q.subscribers {
+		if s.onEnter != nil {
+			s.onEnter(entity)
+		}
+	}
+}
+
+// removed is hooked to OnRemove, which runs while the component is attached.
+func (q *ReactiveQuery) removed(entity Goent) {
+	if q.closed {
+		return
+	}
+	if _, ok := q.matched[entity]; !ok {
+		return
+	}
+	delete(q.matched, entity)
+	if entered, ok := q.pending[entity]; ok && entered {
+		delete(q.pending, entity)
+	} else {
+		q.pending[entity] = false
+	}
+	for _, s := range q.subscribers {
+		if s.onExit != nil {
+			s.onExit(entity)
+		}
+	}
+}
+
+// Subscribe registers callbacks for entities entering and leaving the query,
+// either may be nil. onEnter is called right away for the entities already
+// matching, sorted by handle. The callbacks run from the lifecycle hooks of
+// the change that caused them, exits while the component is still attached.
+func (q *ReactiveQuery) Subscribe(onEnter, onExit func(entity Goent)) {
+	if q.closed {
+		return
+	}
+	q.subscribers = append(q.subscribers, reactiveSubscriber{onEnter: onEnter, onExit: onExit})
+	if onEnter == nil {
+		return
+	}
+	for _, entity := range q.Matched() {
+		onEnter(entity)
+	}
+}
+
+// Matched returns the entities currently matching, sorted by handle.
+func (q *ReactiveQuery) Matched() []Goent {
+	entities := make([]Goent, 0, len(q.matched))
+	for entity := range q.matched {
+		entities = append(entities, entity)
+	}
+	sortEntities(entities)
+	return entities
+}
+
+// Drain returns the entities that entered and exited since the last drain,
+// each sorted by handle, and starts a new period.
+func (q *ReactiveQuery) Drain() (entered, exited []Goent) {
+	for entity, in := range q.pending {
+		if in {
+			entered = append(entered, entity)
+		} else {
+			exited = append(exited, entity)
+		}
+	}
+	clear(q.pending)
+	sort.Slice(entered, func(i, j int) bool { return entered[i] < entered[j] })
+	sort.Slice(exited, func(i, j int) bool { return exited[i] < exited[j] })
+	return entered, exited
+}
+
+// Len returns the number of entities currently matching.
+func (q *ReactiveQuery) Len() int {
+	return len(q.matched)
+}
+
+// Close stops tracking. Hooks can't be unregistered, so the query's hooks
+// stay installed but do nothing.
+func (q *ReactiveQuery) Close() {
+	q.closed = true
+	q.matched = nil
+	q.pending = nil
+	q.subscribers = nil
+}
+
+// watchReactive hooks q up to the lifecycle of T. The constructors then seed
+// it with the current matches, which the first Drain reports as entered.
+func watchReactive[T any](r *Registry, q *ReactiveQuery) {
+	OnAdd(r, func(entity Goent, c *T) { q.added(entity) })
+	OnRemove(r, func(entity Goent, c *T) { q.removed(entity) })
+}
+
+// NewReactive1 tracks entities gaining and losing T.
+func NewReactive1[T any](r *Registry) *ReactiveQuery {
+	q := newReactiveQuery(func(entity Goent) bool {
+		return HasComponent[T](r, entity)
+	})
+	watchReactive[T](r, q)
+	Iterate1(r, func(entity Goent, c *T) { q.added(entity) }, IncludeDisabled())
+	return q
+}
+
+// NewReactive2 tracks entities starting and stopping to have both T1 and T2.
+func NewReactive2[T1 any, T2 any](r *Registry) *ReactiveQuery {
+	q := newReactiveQuery(func(entity Goent) bool {
+		return HasComponent[T1](r, entity) && HasComponent[T2](r, entity)
+	})
+	watchReactive[T1](r, q)
+	watchReactive[T2](r, q)
+	Iterate2(r, func(entity Goent, c1 *T1, c2 *T2) { q.added(entity) }, IncludeDisabled())
+	return q
+}
+
+// NewReactive3 tracks entities starting and stopping to have T1, T2 and T3.
+func NewReactive3[T1 any, T2 any, T3 any](r *Registry) *ReactiveQuery {
+	q := newReactiveQuery(func(entity Goent) bool {
+		return HasComponent[T1](r, entity) && HasComponent[T2](r, entity) && HasComponent[T3](r, entity)
+	})
+	watchReactive[T1](r, q)
+	watchReactive[T2](r, q)
+	watchReactive[T3](r, q)
+	Iterate3(r, func(entity Goent, c1 *T1, c2 *T2, c3 *T3) { q.added(entity) }, IncludeDisabled())
+	return q
+}
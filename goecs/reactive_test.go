@@ -0,0 +1,76 @@
+package goecs
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+type reactPos struct {
+	X int
+}
+
+type reactSprite struct {
+	Frame int
+}
+
+func TestReactiveDrain(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(5)
+	EmplaceComponent(r, entities[0], reactPos{})
+	EmplaceComponent(r, entities[0], reactSprite{})
+	q := NewReactive2[reactPos, reactSprite](r)
+
+	entered, exited := q.Drain()
+	if !slices.Equal(entered, entities[:1]) || exited != nil {
+		t.Fatalf("first Drain = %v, %v, want the existing match", entered, exited)
+	}
+
+	// gains the last missing type
+	EmplaceComponent(r, entities[1], reactPos{})
+	EmplaceComponent(r, entities[1], reactSprite{})
+	// replacing a component neither enters nor exits
+	EmplaceComponent(r, entities[0], reactPos{X: 1})
+	// enters and exits within one period
+	EmplaceComponent(r, entities[2], reactPos{})
+	EmplaceComponent(r, entities[2], reactSprite{})
+	RemoveComponent[reactSprite](r, entities[2])
+	// only half the combination
+	EmplaceComponent(r, entities[3], reactPos{})
+	r.DestroyEntity(entities[0])
+
+	entered, exited = q.Drain()
+	if !slices.Equal(entered, []Goent{entities[1]}) || !slices.Equal(exited, []Goent{entities[0]}) {
+		t.Errorf("Drain = %v, %v, want [%d] and [%d]", entered, exited, entities[1], entities[0])
+	}
+	if q.Len() != 1 || !slices.Equal(q.Matched(), []Goent{entities[1]}) {
+		t.Errorf("Matched() = %v", q.Matched())
+	}
+
+	q.Close()
+	EmplaceComponent(r, entities[3], reactSprite{})
+	if entered, exited := q.Drain(); entered != nil || exited != nil || q.Len() != 0 {
+		t.Errorf("closed query reported %v, %v", entered, exited)
+	}
+}
+
+func TestReactiveSubscribe(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(3)
+	EmplaceComponent(r, entities[1], reactPos{})
+	q := NewReactive1[reactPos](r)
+
+	var log []string
+	q.Subscribe(func(e Goent) { log = append(log, fmt.Sprint("enter ", e)) }, func(e Goent) {
+		if !HasComponent[reactPos](r, e) {
+			t.Errorf("exit of %d reported after the component was removed", e)
+		}
+		log = append(log, fmt.Sprint("exit ", e))
+	})
+	EmplaceComponent(r, entities[0], reactPos{})
+	RemoveComponent[reactPos](r, entities[1])
+	want := []string{"enter 1", "enter 0", "exit 1"}
+	if !slices.Equal(log, want) {
+		t.Errorf("subscriber saw %v, want %v", log, want)
+	}
+}
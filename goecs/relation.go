@@ -0,0 +1,225 @@
+package goecs
+
+import "reflect"
+
+// --- Entity relationships ---
+// A relationship is a typed edge from a source entity to a target entity,
+// carrying a value of its type, like flecs pairs:
+//
+//	type Targets struct{ Since float64 }
+//	Relate(r, turret, enemy, Targets{Since: now})
+//	for _, turret := range SourcesOf[Targets](r, enemy) { ... }
+//
+// An entity can relate to many targets with the same type and be the target
+// of many sources. Both directions are indexed, and every edge touching an
+// entity is dropped when it is destroyed. Parent/child is kept separately,
+// see SetParent.
+//
+// Edges live beside the component storages. CloneEntity, CopyEntities and
+// Merge carry them over; snapshots (Save, WriteDelta, MarshalJSON) don't
+// include them, so a loaded world has none.
+
+// relationSet is the type-erased side of relationStore.
+type relationSet interface {
+	forget(entity Goent)
+	clear()
+	// edges calls f for every edge, in no particular order
+	edges(f func(source, target Goent))
+	// copyEdges relates the images of the edges into dst, see copyRelations
+	copyEdges(dst *Registry, images map[Goent]Goent, keepOthers bool)
+}
+
+type relationStore[R any] struct {
+	// out maps source to target to value, in the reverse direction only the
+	// sources are kept
+	out map[Goent]map[Goent]*R
+	in  map[Goent]map[Goent]struct{}
+}
+
+func relationsFor[R any](r *Registry, create bool) *relationStore[R] {
+	key := typeKeyFor[R]()
+	if s, ok := r.relations[key]; ok {
+		return s.(*relationStore[R])
+	}
+	if !create {
+		return nil
+	}
+	s := &relationStore[R]{
+		out: make(map[Goent]map[Goent]*R),
+		in:  make(map[Goent]map[Goent]struct{}),
+	}
+	r.relations[key] = s
+	return s
+}
+
+func (s *relationStore[R]) unlink(source, target Goent) {
+	delete(s.out[source], target)
+	if len(s.out[source]) == 0 {
+		delete(s.out, source)
+	}
+	delete(s.in[target], source)
+	if len(s.in[target]) == 0 {
+		delete(s.in, target)
+	}
+}
+
+func (s *relationStore[R]) forget(entity Goent) {
+	for target := range s.out[entity] {
+		s.unlink(entity, target)
+	}
+	for source := range s.in[entity] {
+		s.unlink(source, entity)
+	}
+}
+
+func (s *relationStore[R]) clear() {
+	clear(s.out)
+	clear(s.in)
+}
+
+func (s *relationStore[R]) edges(f func(source, target Goent)) {
+	for source, targets := range s.out {
+		for target := range targets {
+			f(source, target)
+		}
+	}
+}
+
+func (s *relationStore[R]) copyEdges(dst *Registry, images map[Goent]Goent, keepOthers bool) {
+	type edge struct {
+		source, target Goent
+		value          reflect.Value
+	}
+	var copies []edge
+	for source, targets := range s.out {
+		for target, value := range targets {
+			newSource, sourceOK := images[source]
+			newTarget, targetOK := images[target]
+			if !sourceOK && !targetOK || (!sourceOK || !targetOK) && !keepOthers {
+				continue
+			}
+			if !sourceOK {
+				newSource = source
+			}
+			if !targetOK {
+				newTarget = target
+			}
+			copies = append(copies, edge{source: newSource, target: newTarget, value: deepCopy(reflect.ValueOf(value).Elem())})
+		}
+	}
+	for _, e := range copies {
+		remapValue(e.value, images)
+		Relate(dst, e.source, e.target, e.value.Interface().(R))
+	}
+}
+
+// copyRelations copies the edges of r touching the entities of images into
+// dst, with each endpoint replaced by its image and deep copied values
+// remapped the same way. Edges with one endpoint outside images are skipped,
+// or kept pointing at that endpoint when keepOthers is set, for copies
+// within the same registry.
+func (r *Registry) copyRelations(dst *Registry, images map[Goent]Goent, keepOthers bool) {
+	for _, s := range r.relations {
+		s.copyEdges(dst, images, keepOthers)
+	}
+}
+
+// forgetRelations drops every edge touching a destroyed entity.
+func (r *Registry) forgetRelations(entity Goent) {
+	for _, s := range r.relations {
+		s.forget(entity)
+	}
+}
+
+// Relate adds an R edge from source to target, or replaces its value.
+func Relate[R any](r *Registry, source, target Goent, value R) {
+	r.assertWritable()
+	if r.isStale(source) || r.isStale(target) {
+		return
+	}
+	s := relationsFor[R](r, true)
+	targets, ok := s.out[source]
+	if !ok {
+		targets = make(map[Goent]*R)
+		s.out[source] = targets
+	}
+	targets[target] = &value
+	sources, ok := s.in[target]
+	if !ok {
+		sources = make(map[Goent]struct{})
+		s.in[target] = sources
+	}
+	sources[source] = struct{}{}
+}
+
+// Unrelate removes the R edge from source to target.
+func Unrelate[R any](r *Registry, source, target Goent) {
+	r.assertWritable()
+	if s := relationsFor[R](r, false); s != nil {
+		s.unlink(source, target)
+	}
+}
+
+// GetRelation returns the value of the R edge from source to target.
+func GetRelation[R any](r *Registry, source, target Goent) (*R, bool) {
+	s := relationsFor[R](r, false)
+	if s == nil {
+		return nil, false
+	}
+	value, ok := s.out[source][target]
+	return value, ok
+}
+
+// HasRelation reports whether there is an R edge from source to target.
+func HasRelation[R any](r *Registry, source, target Goent) bool {
+	_, ok := GetRelation[R](r, source, target)
+	return ok
+}
+
+// TargetsOf returns the targets of the R edges leaving source, ordered by ID.
+func TargetsOf[R any](r *Registry, source Goent) []Goent {
+	s := relationsFor[R](r, false)
+	if s == nil {
+		return nil
+	}
+	targets := make([]Goent, 0, len(s.out[source]))
+	for target := range s.out[source] {
+		targets = append(targets, target)
+	}
+	sortEntities(targets)
+	return targets
+}
+
+// SourcesOf returns the sources of the R edges pointing at target, ordered
+// by ID: every entity that Targets X, say.
+func SourcesOf[R any](r *Registry, target Goent) []Goent {
+	s := relationsFor[R](r, false)
+	if s == nil {
+		return nil
+	}
+	sources := make([]Goent, 0, len(s.in[target]))
+	for source := range s.in[target] {
+		sources = append(sources, source)
+	}
+	sortEntities(sources)
+	return sources
+}
+
+// EachRelation calls f for every R edge, ordered by source, then target. f
+// may change the edge's value but not add or remove edges.
+func EachRelation[R any](r *Registry, f func(source, target Goent, value *R)) {
+	s := relationsFor[R](r, false)
+	if s == nil {
+		return
+	}
+	sources := make([]Goent, 0, len(s.out))
+	for source := range s.out {
+		sources = append(sources, source)
+	}
+	sortEntities(sources)
+	for _, source := range sources {
+		for _, target := range TargetsOf[R](r, source) {
+			f(source, target, s.out[source][target])
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package goecs
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+type relTargets struct {
+	Since int
+}
+
+type relOwnedBy struct{}
+
+type relLink struct {
+	Via []Goent
+}
+
+func TestRelations(t *testing.T) {
+	r := NewRegistry()
+	turrets := r.CreateEntities(3)
+	enemy, other := r.CreateEntity(), r.CreateEntity()
+	for i, turret := range turrets {
+		Relate(r, turret, enemy, relTargets{Since: i})
+	}
+	Relate(r, turrets[0], other, relTargets{})
+	Relate(r, turrets[0], enemy, relTargets{Since: 10})
+	Relate(r, other, turrets[0], relOwnedBy{})
+
+	if got := SourcesOf[relTargets](r, enemy); !slices.Equal(got, turrets) {
+		t.Errorf("SourcesOf(enemy) = %v, want %v", got, turrets)
+	}
+	if got := TargetsOf[relTargets](r, turrets[0]); !slices.Equal(got, []Goent{enemy, other}) {
+		t.Errorf("TargetsOf = %v", got)
+	}
+	if v, ok := GetRelation[relTargets](r, turrets[0], enemy); !ok || v.Since != 10 {
+		t.Errorf("replaced relation = %v, %v", v, ok)
+	}
+	// relation types are independent
+	if HasRelation[relOwnedBy](r, turrets[0], other) || !HasRelation[relOwnedBy](r, other, turrets[0]) {
+		t.Error("relation types leak into each other")
+	}
+
+	Unrelate[relTargets](r, turrets[1], enemy)
+	var edges []string
+	EachRelation(r, func(source, target Goent, v *relTargets) {
+		edges = append(edges, fmt.Sprint(source, "->", target, " ", v.Since))
+	})
+	want := []string{
+		fmt.Sprint(turrets[0], "->", enemy, " 10"),
+		fmt.Sprint(turrets[0], "->", other, " 0"),
+		fmt.Sprint(turrets[2], "->", enemy, " 2"),
+	}
+	if !slices.Equal(edges, want) {
+		t.Errorf("EachRelation = %v, want %v", edges, want)
+	}
+
+	// destroying either endpoint drops the edge
+	r.DestroyEntity(enemy)
+	r.DestroyEntity(turrets[0])
+	if got := TargetsOf[relTargets](r, turrets[2]); len(got) != 0 {
+		t.Errorf("edge to a destroyed target survived: %v", got)
+	}
+	if got := SourcesOf[relTargets](r, other); len(got) != 0 {
+		t.Errorf("edge from a destroyed source survived: %v", got)
+	}
+	if HasRelation[relOwnedBy](r, other, turrets[0]) {
+		t.Error("OwnedBy edge to a destroyed entity survived")
+	}
+	Relate(r, turrets[2], enemy, relTargets{})
+	if HasRelation[relTargets](r, turrets[2], enemy) {
+		t.Error("Relate accepted a destroyed target")
+	}
+}
+
+func TestCloneEntityRelations(t *testing.T) {
+	r := NewRegistry()
+	turret, enemy, base := r.CreateEntity(), r.CreateEntity(), r.CreateEntity()
+	Relate(r, turret, enemy, relLink{Via: []Goent{turret}})
+	Relate(r, base, turret, relOwnedBy{})
+
+	clone := r.CloneEntity(turret)
+	v, ok := GetRelation[relLink](r, clone, enemy)
+	if !ok || !slices.Equal(v.Via, []Goent{clone}) {
+		t.Errorf("cloned edge = %v, %v, want one pointing through the clone", v, ok)
+	}
+	if !HasRelation[relOwnedBy](r, base, clone) {
+		t.Error("clone lost the incoming edge")
+	}
+	v.Via[0] = enemy
+	if orig, _ := GetRelation[relLink](r, turret, enemy); orig.Via[0] != turret {
+		t.Error("clone shares the edge value with the original")
+	}
+}
+
+func TestCopyEntitiesRelations(t *testing.T) {
+	src := NewRegistry()
+	a, b, outsider := src.CreateEntity(), src.CreateEntity(), src.CreateEntity()
+	for _, e := range []Goent{a, b, outsider} {
+		EmplaceComponent(src, e, relTargets{})
+	}
+	Relate(src, a, b, relLink{Via: []Goent{a, outsider}})
+	Relate(src, a, outsider, relOwnedBy{})
+
+	dst := NewRegistry()
+	dst.CreateEntities(2)
+	remap, err := CopyEntities(dst, src, []Goent{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, ok := GetRelation[relLink](dst, remap[a], remap[b])
+	if !ok || !slices.Equal(v.Via, []Goent{remap[a], outsider}) {
+		t.Errorf("copied edge = %v, %v", v, ok)
+	}
+	if got := TargetsOf[relOwnedBy](dst, remap[a]); len(got) != 0 {
+		t.Errorf("edge to an entity left behind was copied: %v", got)
+	}
+
+	merged := NewRegistry()
+	ids, err := merged.Merge(src, MergeKeepIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !HasRelation[relLink](merged, ids[a], ids[b]) || !HasRelation[relOwnedBy](merged, ids[a], ids[outsider]) {
+		t.Error("Merge dropped relation edges")
+	}
+}
+
+func TestSaveDropsRelations(t *testing.T) {
+	src := NewRegistry()
+	a, b := src.CreateEntity(), src.CreateEntity()
+	Relate(src, a, b, relOwnedBy{})
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dst := NewRegistry()
+	if err := dst.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !dst.IsAlive(a) || !dst.IsAlive(b) || HasRelation[relOwnedBy](dst, a, b) {
+		t.Error("snapshot carried a relation edge")
+	}
+}
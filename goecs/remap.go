@@ -0,0 +1,104 @@
+package goecs
+
+import (
+	"reflect"
+	"sync"
+)
+
+// --- Entity reference remapping ---
+// Components may reference other entities through Goent fields (a target, a
+// parent, an inventory list). When entities are recreated under new IDs, as
+// LoadRemapped and ImportJSON do, those references are rewritten through the
+// old-to-new table. Goents are found in struct fields, arrays, slices, map
+// keys and values and behind pointers; unexported fields are left alone.
+// References to entities missing from the table are kept as they are.
+
+var goentType = reflect.TypeOf(Goent(0))
+
+// refTypes caches per type whether values of it can hold a Goent.
+var refTypes sync.Map
+
+// holdsEntities reports whether values of t can contain a Goent.
+func holdsEntities(t reflect.Type) bool {
+	if cached, ok := refTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	holds := scanEntities(t, make(map[reflect.Type]bool))
+	refTypes.Store(t, holds)
+	return holds
+}
+
+func scanEntities(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if t == goentType {
+		return true
+	}
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() && scanEntities(f.Type, visiting) {
+				return true
+			}
+		}
+	case reflect.Array, reflect.Slice, reflect.Pointer:
+		return scanEntities(t.Elem(), visiting)
+	case reflect.Map:
+		return scanEntities(t.Key(), visiting) || scanEntities(t.Elem(), visiting)
+	}
+	return false
+}
+
+// RemapEntities rewrites the Goent references inside comp through remap.
+func RemapEntities[T any](comp *T, remap map[Goent]Goent) {
+	remapValue(reflect.ValueOf(comp).Elem(), remap)
+}
+
+// remapValue rewrites the Goents inside v, which must be settable.
+func remapValue(v reflect.Value, remap map[Goent]Goent) {
+	if !holdsEntities(v.Type()) {
+		return
+	}
+	if v.Type() == goentType {
+		if mapped, ok := remap[Goent(v.Uint())]; ok {
+			v.SetUint(uint64(mapped))
+		}
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				remapValue(v.Field(i), remap)
+			}
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			remapValue(v.Index(i), remap)
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			remapValue(v.Elem(), remap)
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		// keys can't be changed in place, so the map is rebuilt
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := reflect.New(v.Type().Key()).Elem()
+			key.Set(iter.Key())
+			remapValue(key, remap)
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(iter.Value())
+			remapValue(value, remap)
+			out.SetMapIndex(key, value)
+		}
+		v.Set(out)
+	}
+}
@@ -0,0 +1,30 @@
+package goecs
+
+import (
+	"reflect"
+	"testing"
+)
+
+type remapInventory struct {
+	Owner   *Goent
+	Slots   [2]Goent
+	Threat  map[Goent]int
+	private Goent
+}
+
+func TestRemapEntities(t *testing.T) {
+	a, b, c := makeGoent(1, 0), makeGoent(2, 0), makeGoent(3, 0)
+	remap := map[Goent]Goent{a: makeGoent(10, 0), b: makeGoent(20, 0)}
+	owner := a
+	inv := remapInventory{Owner: &owner, Slots: [2]Goent{b, c}, Threat: map[Goent]int{a: 1, c: 2}, private: a}
+	RemapEntities(&inv, remap)
+
+	want := remapInventory{Slots: [2]Goent{remap[b], c}, Threat: map[Goent]int{remap[a]: 1, c: 2}, private: a}
+	if owner != remap[a] {
+		t.Errorf("pointed to entity = %d, want %d", owner, remap[a])
+	}
+	inv.Owner = nil
+	if !reflect.DeepEqual(inv, want) {
+		t.Errorf("RemapEntities = %+v, want %+v", inv, want)
+	}
+}
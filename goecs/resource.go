@@ -0,0 +1,58 @@
+package goecs
+
+import "reflect"
+
+// --- Resources ---
+// Resources are singletons stored in the registry, one per type, for the
+// world-global state that doesn't belong to any entity: input, the active
+// camera, the RNG, the physics bridge.
+//
+//	SetResource(r, Input{})
+//	in, _ := GetResource[Input](r)
+//	in.Jump = true
+//
+// Like components, resources are handed out as pointers that may be changed
+// in place. They aren't part of snapshots, saves or clones.
+
+// resourceKey is the key of resource type T. Unlike typeKeyFor it also works
+// for interface types, so a resource can be e.g. a rand.Source.
+func resourceKey[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// SetResource stores value as the T resource, replacing the previous one.
+func SetResource[T any](r *Registry, value T) {
+	r.assertWritable()
+	r.resources[resourceKey[T]()] = &value
+}
+
+// GetResource returns the T resource.
+func GetResource[T any](r *Registry) (*T, bool) {
+	res, ok := r.resources[resourceKey[T]()]
+	if !ok {
+		return nil, false
+	}
+	return res.(*T), true
+}
+
+// MustResource returns the T resource and panics if there is none, for
+// systems that can't run without it.
+func MustResource[T any](r *Registry) *T {
+	res, ok := GetResource[T](r)
+	if !ok {
+		panic("goecs: no resource of type " + resourceKey[T]().String())
+	}
+	return res
+}
+
+// HasResource reports whether a T resource is set.
+func HasResource[T any](r *Registry) bool {
+	_, ok := r.resources[resourceKey[T]()]
+	return ok
+}
+
+// RemoveResource drops the T resource.
+func RemoveResource[T any](r *Registry) {
+	r.assertWritable()
+	delete(r.resources, resourceKey[T]())
+}
@@ -0,0 +1,43 @@
+package goecs
+
+import (
+	"fmt"
+	"testing"
+)
+
+type resInput struct {
+	Jump bool
+}
+
+func TestResources(t *testing.T) {
+	r := NewRegistry()
+	if _, ok := GetResource[resInput](r); ok || HasResource[resInput](r) {
+		t.Fatal("fresh registry has a resource")
+	}
+	SetResource(r, resInput{})
+	in, ok := GetResource[resInput](r)
+	if !ok {
+		t.Fatal("GetResource after SetResource failed")
+	}
+	in.Jump = true
+	if !MustResource[resInput](r).Jump {
+		t.Error("write through the resource pointer was lost")
+	}
+
+	// interface types are keyed by the interface, not the dynamic type
+	SetResource[fmt.Stringer](r, ComponentType[resInput]())
+	if s, ok := GetResource[fmt.Stringer](r); !ok || (*s).String() != "goecs.resInput" {
+		t.Errorf("interface resource = %v, %v", s, ok)
+	}
+
+	RemoveResource[resInput](r)
+	if HasResource[resInput](r) {
+		t.Error("resource survived RemoveResource")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("MustResource of a missing resource didn't panic")
+		}
+	}()
+	MustResource[resInput](r)
+}
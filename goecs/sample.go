@@ -0,0 +1,132 @@
+package goecs
+
+import (
+	"math/rand/v2"
+	"reflect"
+)
+
+// --- Random sampling ---
+// Ambient systems often want a few random matching entities per frame, such
+// as five NPCs to bark a line. Sampling draws random slots of the smallest
+// storage a query drives from and keeps the matching ones, so it touches
+// about n/p candidates where p is the fraction that match, rather than
+// iterating everything. Each matching entity is equally likely to be picked
+// and none is picked twice.
+
+// sampleLists draws up to n distinct entries passing match from the
+// concatenation of lists, uniformly. It walks a lazily shuffled permutation
+// of the positions: swapped holds the positions the partial Fisher-Yates
+// shuffle moved so far.
+func sampleLists(lists [][]Goent, n int, rng *rand.Rand, match func(entity Goent) bool) []Goent {
+	total := 0
+	for _, list := range lists {
+		total += len(list)
+	}
+	if n <= 0 || total == 0 {
+		return nil
+	}
+	at := func(pos int) Goent {
+		for _, list := range lists {
+			if pos < len(list) {
+				return list[pos]
+			}
+			pos -= len(list)
+		}
+		panic("unreachable")
+	}
+	swapped := make(map[int]int)
+	lookup := func(pos int) int {
+		if moved, ok := swapped[pos]; ok {
+			return moved
+		}
+		return pos
+	}
+	out := make([]Goent, 0, min(n, total))
+	for i := 0; i < total && len(out) < n; i++ {
+		j := i + rng.IntN(total-i)
+		pick := lookup(j)
+		swapped[j] = lookup(i)
+		if entity := at(pick); match(entity) {
+			out = append(out, entity)
+		}
+	}
+	return out
+}
+
+// archetypeLists returns the entity lists of the archetypes matching the
+// types, for sampling on the archetype backend.
+func (r *Registry) archetypeLists(fs *filterSet, types ...reflect.Type) [][]Goent {
+	var lists [][]Goent
+	r.archetypes.each(fs, types, func(a *archetype) {
+		lists = append(lists, a.entities)
+	})
+	return lists
+}
+
+// SampleEntities1 returns up to n distinct entities with a T component,
+// picked uniformly at random with rng. It returns fewer when fewer match.
+func SampleEntities1[T any](r *Registry, n int, rng *rand.Rand, filters ...Filter) []Goent {
+	fs := r.resolveFilters(filters)
+	if r.archetypes != nil {
+		return sampleLists(r.archetypeLists(&fs, typeKeyFor[T]()), n, rng, func(entity Goent) bool {
+			return !fs.skip(entity)
+		})
+	}
+	s := getStorage[T](r)
+	if s == nil {
+		return nil
+	}
+	return sampleLists([][]Goent{s.dense}, n, rng, func(entity Goent) bool {
+		return !fs.skip(entity)
+	})
+}
+
+// SampleEntities2 returns up to n distinct entities with T1 and T2
+// components, see SampleEntities1.
+func SampleEntities2[T1 any, T2 any](r *Registry, n int, rng *rand.Rand, filters ...Filter) []Goent {
+	fs := r.resolveFilters(filters)
+	if r.archetypes != nil {
+		return sampleLists(r.archetypeLists(&fs, typeKeyFor[T1](), typeKeyFor[T2]()), n, rng, func(entity Goent) bool {
+			return !fs.skip(entity)
+		})
+	}
+	c1, ok1 := newColumn[T1](r, &fs)
+	c2, ok2 := newColumn[T2](r, &fs)
+	if !ok1 || !ok2 {
+		return nil
+	}
+	return sampleLists([][]Goent{driverDense(c1, c2)}, n, rng, func(entity Goent) bool {
+		if fs.skip(entity) {
+			return false
+		}
+		_, ok1 := c1.get(entity)
+		_, ok2 := c2.get(entity)
+		return ok1 && ok2
+	})
+}
+
+// SampleEntities3 returns up to n distinct entities with T1, T2 and T3
+// components, see SampleEntities1.
+func SampleEntities3[T1 any, T2 any, T3 any](r *Registry, n int, rng *rand.Rand, filters ...Filter) []Goent {
+	fs := r.resolveFilters(filters)
+	if r.archetypes != nil {
+		return sampleLists(r.archetypeLists(&fs, typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3]()), n, rng, func(entity Goent) bool {
+			return !fs.skip(entity)
+		})
+	}
+	c1, ok1 := newColumn[T1](r, &fs)
+	c2, ok2 := newColumn[T2](r, &fs)
+	c3, ok3 := newColumn[T3](r, &fs)
+	if !ok1 || !ok2 || !ok3 {
+		return nil
+	}
+	return sampleLists([][]Goent{driverDense(c1, c2, c3)}, n, rng, func(entity Goent) bool {
+		if fs.skip(entity) {
+			return false
+		}
+		_, ok1 := c1.get(entity)
+		_, ok2 := c2.get(entity)
+		_, ok3 := c3.get(entity)
+		return ok1 && ok2 && ok3
+	})
+}
@@ -0,0 +1,58 @@
+package goecs
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestSampleEntities(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			entities := newIterWorld(r, 30)
+			r.Disable(entities[27])
+			rng := rand.New(rand.NewPCG(1, 2))
+
+			// entities with iterA and iterC are the multiples of 3 but 27
+			counts := map[Goent]int{}
+			for range 1000 {
+				got := SampleEntities2[iterA, iterC](r, 3, rng)
+				if len(got) != 3 {
+					t.Fatalf("sampled %v, want 3 entities", got)
+				}
+				for i, e := range got {
+					if e.Index()%3 != 0 || e == entities[27] || slices.Contains(got[:i], e) {
+						t.Fatalf("sampled %v", got)
+					}
+					counts[e]++
+				}
+			}
+			// each of the 9 matches is picked about a third of the time
+			for e, n := range counts {
+				if n < 250 || n > 420 {
+					t.Errorf("entity %d sampled %d times in 1000 draws", e, n)
+				}
+			}
+			if len(counts) != 9 {
+				t.Errorf("sampled %d distinct entities, want 9", len(counts))
+			}
+
+			tests := []struct {
+				name string
+				got  []Goent
+				want int
+			}{
+				{"more than match", SampleEntities3[iterA, iterB, iterC](r, 10, rng), 5},
+				{"disabled included", SampleEntities1[iterC](r, 20, rng, IncludeDisabled()), 10},
+				{"none", SampleEntities1[iterA](r, 0, rng), 0},
+				{"unused type", SampleEntities1[entityProbe](r, 4, rng), 0},
+			}
+			for _, tt := range tests {
+				if len(tt.got) != tt.want {
+					t.Errorf("%s: sampled %d entities, want %d", tt.name, len(tt.got), tt.want)
+				}
+			}
+		})
+	}
+}
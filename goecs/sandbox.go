@@ -0,0 +1,142 @@
+package goecs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// --- Script sandboxes ---
+// Mods and user scripts get at components by name, but must not be able to
+// corrupt the components the engine keeps for itself. A Sandbox is a handle
+// on a registry that only reaches the component types it was given, each
+// read-only or read-write. Every access goes through the permission check,
+// and components cross the boundary as deep copies, so a script can't keep a
+// pointer into a storage and write through it later.
+//
+//	sb := r.NewSandbox(map[string]goecs.Permission{
+//		"health": goecs.ReadWrite,
+//		"transform": goecs.ReadOnly,
+//	})
+//	hp, err := sb.Get(e, "health")
+
+// Permission is what a sandbox may do with a component type.
+type Permission int
+
+const (
+	// NoAccess hides the type, as if it wasn't listed.
+	NoAccess Permission = iota
+	// ReadOnly allows Has and Get.
+	ReadOnly
+	// ReadWrite also allows Set and Remove.
+	ReadWrite
+)
+
+// ErrAccessDenied is returned, wrapped, for an access the sandbox doesn't
+// permit.
+var ErrAccessDenied = errors.New("goecs: sandbox access denied")
+
+// Sandbox is a restricted handle on a registry. Its permissions are fixed at
+// creation, so handing it to a script can't widen them.
+type Sandbox struct {
+	r     *Registry
+	perms map[string]Permission
+}
+
+// NewSandbox creates a sandbox allowing the given component type names, see
+// RegisterNamedComponent.
+func (r *Registry) NewSandbox(perms map[string]Permission) *Sandbox {
+	s := &Sandbox{r: r, perms: make(map[string]Permission, len(perms))}
+	for name, p := range perms {
+		if p > NoAccess {
+			s.perms[name] = p
+		}
+	}
+	return s
+}
+
+// Names returns the component type names the sandbox can reach, sorted.
+func (s *Sandbox) Names() []string {
+	names := make([]string, 0, len(s.perms))
+	for name := range s.perms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Permission returns what the sandbox may do with the named type.
+func (s *Sandbox) Permission(name string) Permission {
+	return s.perms[name]
+}
+
+// check resolves the named type if the sandbox holds at least the permission.
+func (s *Sandbox) check(name string, want Permission) (*componentInfo, error) {
+	if s.perms[name] < want {
+		verb := "read"
+		if want == ReadWrite {
+			verb = "write"
+		}
+		return nil, fmt.Errorf("%w: %s %q", ErrAccessDenied, verb, name)
+	}
+	info, ok := s.r.namedComponent(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrTypeNotRegistered, name)
+	}
+	return info, nil
+}
+
+// Has reports whether the entity has the named component.
+func (s *Sandbox) Has(entity Goent, name string) (bool, error) {
+	info, err := s.check(name, ReadOnly)
+	if err != nil {
+		return false, err
+	}
+	_, ok := s.r.componentOf(entity, info.typ)
+	return ok, nil
+}
+
+// Get returns a copy of the entity's named component, or nil if it has none.
+func (s *Sandbox) Get(entity Goent, name string) (interface{}, error) {
+	info, err := s.check(name, ReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	comp, ok := s.r.componentOf(entity, info.typ)
+	if !ok {
+		return nil, nil
+	}
+	return deepCopy(reflect.ValueOf(comp).Elem()).Interface(), nil
+}
+
+// Set emplaces a copy of value as the entity's named component. value must
+// be of the component type or a pointer to it.
+func (s *Sandbox) Set(entity Goent, name string, value interface{}) error {
+	info, err := s.check(name, ReadWrite)
+	if err != nil {
+		return err
+	}
+	if !s.r.IsAlive(entity) {
+		return fmt.Errorf("%w: setting %q on entity %d", ErrEntityNotAlive, name, entity)
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Pointer && v.Type().Elem() == info.typ && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Type() != info.typ {
+		return fmt.Errorf("goecs: setting %q needs a %s, got %T", name, info.typ, value)
+	}
+	info.emplace(s.r, entity, deepCopy(v))
+	return nil
+}
+
+// Remove removes the entity's named component.
+func (s *Sandbox) Remove(entity Goent, name string) error {
+	info, err := s.check(name, ReadWrite)
+	if err != nil {
+		return err
+	}
+	info.remove(s.r, entity)
+	return nil
+}
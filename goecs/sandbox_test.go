@@ -0,0 +1,87 @@
+package goecs
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+type sandboxHealth struct {
+	HP    int
+	Buffs []string
+}
+
+type sandboxTransform struct {
+	X float64
+}
+
+func TestSandbox(t *testing.T) {
+	r := NewRegistry()
+	RegisterNamedComponent[sandboxHealth](r, "health")
+	RegisterNamedComponent[sandboxTransform](r, "transform")
+	e := r.CreateEntity()
+	EmplaceComponent(r, e, sandboxHealth{HP: 10, Buffs: []string{"haste"}})
+	EmplaceComponent(r, e, sandboxTransform{X: 1})
+
+	sb := r.NewSandbox(map[string]Permission{
+		"health":    ReadWrite,
+		"transform": ReadOnly,
+		"hidden":    NoAccess,
+	})
+	if names := sb.Names(); !slices.Equal(names, []string{"health", "transform"}) {
+		t.Errorf("Names() = %v", names)
+	}
+
+	// Get hands out a copy the script can't write through
+	got, err := sb.Get(e, "health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hp := got.(sandboxHealth)
+	hp.Buffs[0] = "curse"
+	if h, _ := GetComponent[sandboxHealth](r, e); h.Buffs[0] != "haste" {
+		t.Error("writing to a Get result changed the stored component")
+	}
+
+	if err := sb.Set(e, "health", &sandboxHealth{HP: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if h, _ := GetComponent[sandboxHealth](r, e); h.HP != 3 {
+		t.Errorf("HP = %d after Set, want 3", h.HP)
+	}
+
+	tests := []struct {
+		name   string
+		access func() error
+		want   error
+	}{
+		{"read read-only", func() error { _, err := sb.Get(e, "transform"); return err }, nil},
+		{"write read-only", func() error { return sb.Set(e, "transform", sandboxTransform{}) }, ErrAccessDenied},
+		{"remove read-only", func() error { return sb.Remove(e, "transform") }, ErrAccessDenied},
+		{"read unlisted", func() error { _, err := sb.Has(e, "hidden"); return err }, ErrAccessDenied},
+		{"write dead entity", func() error { return sb.Set(makeGoent(99, 0), "health", sandboxHealth{}) }, ErrEntityNotAlive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.access(); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if err := sb.Set(e, "health", sandboxTransform{}); err == nil {
+		t.Error("Set accepted a value of the wrong type")
+	}
+	if x, _ := GetComponent[sandboxTransform](r, e); x.X != 1 {
+		t.Error("a denied write changed the component")
+	}
+
+	if err := sb.Remove(e, "health"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := sb.Has(e, "health"); ok || err != nil {
+		t.Errorf("Has after Remove = %v, %v", ok, err)
+	}
+	if got, err := sb.Get(e, "health"); got != nil || err != nil {
+		t.Errorf("Get of a missing component = %v, %v", got, err)
+	}
+}
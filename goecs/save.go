@@ -0,0 +1,355 @@
+package goecs
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// --- Binary save/load ---
+// Save writes a gob stream: a header with the entity allocator state, the
+// alias tables and the build info (see BuildInfo), then the entities grouped
+// by the set of component types they have. Each group is a record naming its
+// types and listing its entities, followed by one slice of values per type,
+// so values of a type are encoded back to back and the entity list is written
+// once per group instead of once per type. An empty record marks the end.
+// Component types are identified by name (see RegisterNamedComponent), so
+// Load only restores types the target registry knows under the same name.
+// Components must be gob encodable; types without any fields (tags) are
+// named by the group but carry no values.
+
+// snapshotVersion is bumped whenever the stream layout changes.
+const snapshotVersion = 2
+
+// ErrRegistryNotEmpty is returned by Load when the target registry has
+// already allocated entities, whose IDs the snapshot would collide with.
+var ErrRegistryNotEmpty = errors.New("goecs: load into a registry that already has entities")
+
+type snapshotHeader struct {
+	Version     int
+	Generations []uint32
+	Free        []uint32
+	// Entities lists the saved entities, others are restored as destroyed
+	Entities      []Goent
+	StringAliases map[Goent]string
+	IDAliases     map[Goent]uint64
+	Names         map[Goent]string
+	Annotations   map[Goent]map[string]string
+	Build         BuildInfo
+}
+
+// snapshotRecord is a group of entities with the same component types.
+type snapshotRecord struct {
+	Names []string
+	// Entities is empty in the record ending the stream
+	Entities []Goent
+}
+
+// saveGroup collects the entities of one record while saving.
+type saveGroup struct {
+	types    []*componentInfo
+	entities []Goent
+	// columns holds a component pointer per entity for every type
+	columns [][]interface{}
+}
+
+// isFieldless reports whether values of t carry no data gob could encode.
+func isFieldless(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.NumField() == 0
+}
+
+// Save writes every entity and component of the registry to w. Relationship
+// edges (see Relate) live outside the components and aren't saved.
+func (r *Registry) Save(w io.Writer) error {
+	return r.SaveFiltered(w, SnapshotFilter{})
+}
+
+// SaveFiltered writes the entities passing the filter to w, including live
+// entities without components. Entities left out are restored as destroyed,
+// so their IDs stay unused.
+func (r *Registry) SaveFiltered(w io.Writer, f SnapshotFilter) error {
+	entities := r.savedEntities(f)
+	header := snapshotHeader{
+		Version:       snapshotVersion,
+		Generations:   r.entities.generations,
+		Free:          r.entities.free,
+		Entities:      entities,
+		StringAliases: make(map[Goent]string),
+		IDAliases:     make(map[Goent]uint64),
+		Names:         make(map[Goent]string),
+		Annotations:   make(map[Goent]map[string]string),
+		Build:         r.BuildInfo(),
+	}
+	for _, entity := range entities {
+		if key, ok := r.stringAliases.keyOf(entity); ok {
+			header.StringAliases[entity] = key
+		}
+		if key, ok := r.idAliases.keyOf(entity); ok {
+			header.IDAliases[entity] = key
+		}
+		if name, ok := r.names.keyOf(entity); ok {
+			header.Names[entity] = name
+		}
+		if notes, ok := r.annotations[entity]; ok {
+			header.Annotations[entity] = notes
+		}
+	}
+
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(header); err != nil {
+		return err
+	}
+	for _, group := range r.groupBySignature(entities) {
+		record := snapshotRecord{Entities: group.entities}
+		for _, info := range group.types {
+			name, err := r.serialName(info)
+			if err != nil {
+				return err
+			}
+			record.Names = append(record.Names, name)
+		}
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+		for i, info := range group.types {
+			if isFieldless(info.typ) {
+				continue
+			}
+			values := reflect.MakeSlice(reflect.SliceOf(info.typ), len(group.entities), len(group.entities))
+			for j, comp := range group.columns[i] {
+				values.Index(j).Set(reflect.ValueOf(comp).Elem())
+			}
+			if err := enc.EncodeValue(values); err != nil {
+				return fmt.Errorf("goecs: saving %s: %w", info.name, err)
+			}
+		}
+	}
+	return enc.Encode(snapshotRecord{})
+}
+
+// savedEntities returns every live entity passing the filter, whether it
+// has components or not, ordered by ID.
+func (r *Registry) savedEntities(f SnapshotFilter) []Goent {
+	var entities []Goent
+	for _, entity := range r.entities.live() {
+		if f.Matches(r, entity) {
+			entities = append(entities, entity)
+		}
+	}
+	sortEntities(entities)
+	return entities
+}
+
+// groupBySignature splits the entities into groups with the same component
+// types, in the order each group's first entity appears. Entities without
+// components are left out, the header's entity list restores them.
+func (r *Registry) groupBySignature(entities []Goent) []*saveGroup {
+	types := r.sortedComponentTypes()
+	bySignature := make(map[string]*saveGroup)
+	var groups []*saveGroup
+	signature := make([]byte, len(types))
+	comps := make([]interface{}, len(types))
+	for _, entity := range entities {
+		empty := true
+		for i, info := range types {
+			comp, ok := r.componentOf(entity, info.typ)
+			signature[i], comps[i] = 0, comp
+			if ok {
+				signature[i], empty = 1, false
+			}
+		}
+		if empty {
+			continue
+		}
+		group, ok := bySignature[string(signature)]
+		if !ok {
+			group = &saveGroup{}
+			for i, info := range types {
+				if signature[i] == 1 {
+					group.types = append(group.types, info)
+				}
+			}
+			group.columns = make([][]interface{}, len(group.types))
+			bySignature[string(signature)] = group
+			groups = append(groups, group)
+		}
+		group.entities = append(group.entities, entity)
+		column := 0
+		for i := range types {
+			if signature[i] == 1 {
+				group.columns[column] = append(group.columns[column], comps[i])
+				column++
+			}
+		}
+	}
+	return groups
+}
+
+// Load restores a snapshot written by Save into an empty registry, keeping
+// every entity ID, the tick and the recorded seeds. The loaded world has no
+// relationship edges, snapshots don't hold them. Lifecycle hooks fire as
+// the components are emplaced, type by type in restore order (see
+// RestoreAfter).
+func (r *Registry) Load(rd io.Reader) error {
+	r.assertWritable()
+	if len(r.entities.generations) != 0 {
+		return ErrRegistryNotEmpty
+	}
+	header, records, err := r.readSnapshot(rd)
+	if err != nil {
+		return err
+	}
+	r.restoreAllocator(header)
+	if header.Build.Tick > r.tick {
+		r.tick = header.Build.Tick
+	}
+	for name, seed := range header.Build.Seeds {
+		r.SetSeed(name, seed)
+	}
+	for _, record := range records {
+		for i, entity := range record.entities {
+			record.info.emplace(r, entity, record.values.Index(i))
+		}
+	}
+	for entity, key := range header.StringAliases {
+		r.stringAliases.set(entity, key)
+	}
+	for entity, key := range header.IDAliases {
+		r.idAliases.set(entity, key)
+	}
+	for entity, name := range header.Names {
+		r.names.set(entity, name)
+	}
+	for entity, notes := range header.Annotations {
+		r.annotations[entity] = notes
+	}
+	return nil
+}
+
+// LoadRemapped adds the entities of a snapshot written by Save to a registry
+// that may already have entities, giving each a fresh ID. Goent references
+// inside the loaded components are rewritten to the new IDs (see
+// RemapEntities), and the returned table maps every saved ID to its new one.
+// Aliases and names already bound in the registry move to the loaded
+// entities.
+func (r *Registry) LoadRemapped(rd io.Reader) (map[Goent]Goent, error) {
+	r.assertWritable()
+	header, records, err := r.readSnapshot(rd)
+	if err != nil {
+		return nil, err
+	}
+	remap := make(map[Goent]Goent, len(header.Entities))
+	for _, entity := range header.Entities {
+		remap[entity] = r.CreateEntity()
+	}
+	for _, record := range records {
+		for i, entity := range record.entities {
+			value := record.values.Index(i)
+			remapValue(value, remap)
+			record.info.emplace(r, remap[entity], value)
+		}
+	}
+	for entity, key := range header.StringAliases {
+		r.stringAliases.set(remap[entity], key)
+	}
+	for entity, key := range header.IDAliases {
+		r.idAliases.set(remap[entity], key)
+	}
+	for entity, name := range header.Names {
+		r.names.set(remap[entity], name)
+	}
+	for entity, notes := range header.Annotations {
+		r.annotations[remap[entity]] = notes
+	}
+	return remap, nil
+}
+
+// loadedRecord is one decoded component record of a snapshot.
+type loadedRecord struct {
+	info     *componentInfo
+	entities []Goent
+	// values is a slice of the component type, parallel to entities
+	values reflect.Value
+	// removed lists the entities losing the component, in deltas
+	removed []Goent
+}
+
+// sortRecords puts records in restore order, see RestoreAfter.
+func (r *Registry) sortRecords(records []loadedRecord) {
+	ranks := r.restoreRanks()
+	sort.Slice(records, func(i, j int) bool { return ranks[records[i].info] < ranks[records[j].info] })
+}
+
+// readSnapshot decodes a whole snapshot before anything is applied, so a
+// corrupt or incompatible stream leaves the registry untouched. The records
+// come back in restore order.
+func (r *Registry) readSnapshot(rd io.Reader) (snapshotHeader, []loadedRecord, error) {
+	dec := gob.NewDecoder(rd)
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return header, nil, err
+	}
+	if header.Version != snapshotVersion {
+		return header, nil, fmt.Errorf("goecs: unsupported snapshot version %d", header.Version)
+	}
+
+	// the groups are merged back into one record per type
+	byType := make(map[*componentInfo]int)
+	var records []loadedRecord
+	for {
+		var record snapshotRecord
+		if err := dec.Decode(&record); err != nil {
+			return header, nil, err
+		}
+		if len(record.Entities) == 0 {
+			r.sortRecords(records)
+			return header, records, nil
+		}
+		for _, name := range record.Names {
+			info, ok := r.componentNames[name]
+			if !ok {
+				return header, nil, fmt.Errorf("%w: snapshot has unknown component type %q", ErrTypeNotRegistered, name)
+			}
+			values := reflect.New(reflect.SliceOf(info.typ)).Elem()
+			if isFieldless(info.typ) {
+				values = reflect.MakeSlice(values.Type(), len(record.Entities), len(record.Entities))
+			} else if err := dec.DecodeValue(values.Addr()); err != nil {
+				return header, nil, fmt.Errorf("goecs: loading %s: %w", name, err)
+			}
+			if values.Len() != len(record.Entities) {
+				return header, nil, fmt.Errorf("goecs: snapshot record %s is corrupt", name)
+			}
+			i, seen := byType[info]
+			if !seen {
+				byType[info] = len(records)
+				records = append(records, loadedRecord{info: info, values: reflect.MakeSlice(values.Type(), 0, 0)})
+				i = len(records) - 1
+			}
+			records[i].entities = append(records[i].entities, record.Entities...)
+			records[i].values = reflect.AppendSlice(records[i].values, values)
+		}
+	}
+}
+
+// restoreAllocator takes over the saved allocator state, releasing every
+// index that was alive but left out of the snapshot.
+func (r *Registry) restoreAllocator(header snapshotHeader) {
+	r.entities.generations = append([]uint32(nil), header.Generations...)
+	r.entities.free = append([]uint32(nil), header.Free...)
+
+	kept := make(map[uint32]struct{}, len(header.Entities)+len(header.Free))
+	for _, entity := range header.Entities {
+		kept[entity.Index()] = struct{}{}
+	}
+	for _, index := range header.Free {
+		kept[index] = struct{}{}
+	}
+	for index, gen := range r.entities.generations {
+		if _, ok := kept[uint32(index)]; !ok {
+			r.entities.release(makeGoent(uint32(index), gen))
+		}
+	}
+}
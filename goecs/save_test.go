@@ -0,0 +1,336 @@
+package goecs
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type savePos struct {
+	X, Y int
+}
+
+type saveVFX struct{}
+
+type saveTarget struct {
+	Of    Goent
+	Trail []Goent
+}
+
+// newSaveTarget returns an empty registry knowing the save test types.
+func newSaveTarget() *Registry {
+	r := NewRegistry()
+	RegisterComponent[savePos](r)
+	RegisterComponent[saveVFX](r)
+	RegisterComponent[saveTarget](r)
+	return r
+}
+
+func TestSaveFiltered(t *testing.T) {
+	src := newSaveTarget()
+	entities := src.CreateEntities(6)
+	for i, e := range entities {
+		EmplaceComponent(src, e, savePos{X: i})
+		if i%2 == 1 {
+			EmplaceComponent(src, e, saveVFX{})
+		}
+	}
+	f := SnapshotFilter{
+		Exclude:   []reflect.Type{ComponentType[saveVFX]()},
+		Predicate: func(r *Registry, e Goent) bool { return e != entities[4] },
+	}
+	want := []Goent{entities[0], entities[2]}
+	if got := src.FilteredEntities(f); !slices.Equal(got, want) {
+		t.Fatalf("FilteredEntities = %v, want %v", got, want)
+	}
+	include := SnapshotFilter{Include: []reflect.Type{ComponentType[savePos](), ComponentType[saveVFX]()}}
+	if got := src.FilteredEntities(include); len(got) != 3 || !include.Matches(src, entities[1]) || include.Matches(src, entities[0]) {
+		t.Errorf("Include filter matched %v", got)
+	}
+
+	var buf bytes.Buffer
+	if err := src.SaveFiltered(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	dst := newSaveTarget()
+	if err := dst.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range entities {
+		kept := slices.Contains(want, e)
+		if dst.IsAlive(e) != kept || HasComponent[savePos](dst, e) != kept {
+			t.Errorf("entity %d alive %v after a filtered load, want %v", i, dst.IsAlive(e), kept)
+		}
+	}
+	// the filtered out IDs stay unused
+	if e := dst.CreateEntity(); slices.Contains(entities, e) {
+		t.Errorf("new entity %d reuses a saved ID", e)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	src := newSaveTarget()
+	entities := src.CreateEntities(5)
+	for i, e := range entities {
+		EmplaceComponent(src, e, savePos{X: i, Y: -i})
+		if i%2 == 0 {
+			EmplaceComponent(src, e, saveVFX{})
+		}
+	}
+	src.DestroyEntity(entities[3])
+	src.SetName(entities[0], "player")
+	src.SetAlias(entities[1], "db:1")
+	src.SetAliasID(entities[2], 7)
+	src.AdvanceTick()
+
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dst := newSaveTarget()
+	if err := dst.Load(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, e := range entities {
+		if i == 3 {
+			if dst.IsAlive(e) {
+				t.Error("destroyed entity came back")
+			}
+			continue
+		}
+		p, ok := GetComponent[savePos](dst, e)
+		if !ok || *p != (savePos{X: i, Y: -i}) || HasComponent[saveVFX](dst, e) != (i%2 == 0) {
+			t.Errorf("entity %d loaded as %v, %v", i, p, ok)
+		}
+	}
+	if dst.EntityCount() != src.EntityCount() {
+		t.Errorf("loaded %d entities, want %d", dst.EntityCount(), src.EntityCount())
+	}
+	if e, _ := dst.FindByName("player"); e != entities[0] {
+		t.Errorf("name resolves to %d", e)
+	}
+	if e, _ := dst.LookupAlias("db:1"); e != entities[1] {
+		t.Errorf("alias resolves to %d", e)
+	}
+	if e, _ := dst.LookupAliasID(7); e != entities[2] {
+		t.Errorf("alias ID resolves to %d", e)
+	}
+	if dst.Tick() < src.Tick() {
+		t.Errorf("loaded tick %d is behind the saved %d", dst.Tick(), src.Tick())
+	}
+	if next := dst.CreateEntity(); slices.Contains(entities, next) {
+		t.Errorf("new entity %d collides with a saved one", next)
+	}
+
+	if err := dst.Load(bytes.NewReader(buf.Bytes())); !errors.Is(err, ErrRegistryNotEmpty) {
+		t.Errorf("Load into a used registry = %v, want ErrRegistryNotEmpty", err)
+	}
+	if err := newSaveTarget().Load(bytes.NewReader(buf.Bytes()[:buf.Len()/2])); err == nil {
+		t.Error("Load of a truncated snapshot succeeded")
+	}
+}
+
+func TestSaveLoadBareEntity(t *testing.T) {
+	src := newSaveTarget()
+	bare, disabled := src.CreateEntity(), src.CreateEntity()
+	src.SetName(bare, "spawner")
+	EmplaceComponent(src, disabled, savePos{X: 3})
+	src.Disable(disabled)
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := NewRegistry()
+	RegisterComponent[savePos](dst)
+	if err := dst.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !dst.IsAlive(bare) || dst.EntityCount() != 2 {
+		t.Errorf("loaded %d entities, bare entity alive %v", dst.EntityCount(), dst.IsAlive(bare))
+	}
+	if e, ok := dst.FindByName("spawner"); !ok || e != bare {
+		t.Errorf("name resolves to %d, %v", e, ok)
+	}
+	if !HasComponent[Disabled](dst, disabled) {
+		t.Error("entity came back enabled")
+	}
+	n := 0
+	Iterate1(dst, func(Goent, *savePos) { n++ })
+	if p, _ := GetComponent[savePos](dst, disabled); p == nil || p.X != 3 || n != 0 {
+		t.Errorf("disabled entity loaded as %v, iterated %d times", p, n)
+	}
+}
+
+func TestLoadRemapped(t *testing.T) {
+	src := newSaveTarget()
+	entities := src.CreateEntities(3)
+	for i, e := range entities {
+		EmplaceComponent(src, e, savePos{X: i})
+	}
+	outsider := makeGoent(99, 0)
+	EmplaceComponent(src, entities[2], saveTarget{Of: entities[0], Trail: []Goent{entities[1], outsider}})
+	src.SetName(entities[0], "boss")
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := newSaveTarget()
+	existing := dst.CreateEntities(3)
+	EmplaceComponent(dst, existing[0], savePos{X: 100})
+	dst.SetName(existing[1], "boss")
+	remap, err := dst.LoadRemapped(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remap) != len(entities) {
+		t.Fatalf("remap has %d entries, want %d", len(remap), len(entities))
+	}
+	for i, e := range entities {
+		if slices.Contains(existing, remap[e]) {
+			t.Errorf("entity %d was loaded over existing entity %d", i, remap[e])
+		}
+		if p, _ := GetComponent[savePos](dst, remap[e]); p == nil || p.X != i {
+			t.Errorf("entity %d loaded as %v", i, p)
+		}
+	}
+	if p, _ := GetComponent[savePos](dst, existing[0]); p == nil || p.X != 100 {
+		t.Errorf("existing entity changed to %v", p)
+	}
+	want := saveTarget{Of: remap[entities[0]], Trail: []Goent{remap[entities[1]], outsider}}
+	if got, _ := GetComponent[saveTarget](dst, remap[entities[2]]); got == nil || !reflect.DeepEqual(*got, want) {
+		t.Errorf("references loaded as %v, want %v", got, want)
+	}
+	if e, _ := dst.FindByName("boss"); e != remap[entities[0]] {
+		t.Errorf("name resolves to %d, want the loaded entity %d", e, remap[entities[0]])
+	}
+}
+
+func TestSaveGroupsBySignature(t *testing.T) {
+	src := newSaveTarget()
+	entities := src.CreateEntities(9)
+	// four signatures, one of them tag only, each held by two entities, and
+	// one entity without components
+	for i, e := range entities[:8] {
+		switch i % 4 {
+		case 0:
+			EmplaceComponent(src, e, savePos{X: i})
+		case 1:
+			EmplaceComponent(src, e, savePos{X: i})
+			EmplaceComponent(src, e, saveVFX{})
+		case 2:
+			EmplaceComponent(src, e, saveVFX{})
+		case 3:
+			EmplaceComponent(src, e, savePos{X: i})
+			EmplaceComponent(src, e, saveTarget{Of: entities[i-3]})
+		}
+	}
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// walk the raw stream: one record per signature
+	dec := gob.NewDecoder(bytes.NewReader(buf.Bytes()))
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		t.Fatal(err)
+	}
+	// the entity without components is only in the header
+	if !slices.Contains(header.Entities, entities[8]) {
+		t.Errorf("header entities %v miss the bare entity %d", header.Entities, entities[8])
+	}
+	seen := make(map[string]int)
+	for {
+		var record snapshotRecord
+		if err := dec.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		if len(record.Entities) == 0 {
+			break
+		}
+		if slices.Contains(record.Entities, entities[8]) {
+			t.Errorf("bare entity written in the %v record", record.Names)
+		}
+		signature := strings.Join(record.Names, ",")
+		if _, dup := seen[signature]; dup {
+			t.Errorf("signature %s written twice", signature)
+		}
+		seen[signature] = len(record.Entities)
+		for _, name := range record.Names {
+			typ := src.componentNames[name].typ
+			if isFieldless(typ) {
+				continue
+			}
+			values := reflect.New(reflect.SliceOf(typ))
+			if err := dec.DecodeValue(values); err != nil {
+				t.Fatalf("decoding %s: %v", name, err)
+			}
+			if n := values.Elem().Len(); n != len(record.Entities) {
+				t.Errorf("%s of %s holds %d values for %d entities", name, signature, n, len(record.Entities))
+			}
+		}
+	}
+	if len(seen) != 4 {
+		t.Errorf("stream holds signatures %v, want 4", seen)
+	}
+	for signature, n := range seen {
+		if n != 2 {
+			t.Errorf("signature %s has %d entities, want 2", signature, n)
+		}
+	}
+
+	dst := newSaveTarget()
+	if err := dst.Load(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range entities[:8] {
+		p, hasPos := GetComponent[savePos](dst, e)
+		if hasPos != (i%4 != 2) || (hasPos && p.X != i) {
+			t.Errorf("entity %d loaded with position %v, %v", i, p, hasPos)
+		}
+		if HasComponent[saveVFX](dst, e) != (i%4 == 1 || i%4 == 2) {
+			t.Errorf("entity %d loaded with the wrong tag", i)
+		}
+		target, ok := GetComponent[saveTarget](dst, e)
+		if ok != (i%4 == 3) || (ok && target.Of != entities[i-3]) {
+			t.Errorf("entity %d loaded with target %v, %v", i, target, ok)
+		}
+	}
+	if !dst.IsAlive(entities[8]) || dst.EntityCount() != src.EntityCount() {
+		t.Errorf("loaded %d entities, want %d", dst.EntityCount(), src.EntityCount())
+	}
+}
+
+func TestLoadRejectsVersion1(t *testing.T) {
+	src := NewRegistry()
+	e := src.CreateEntity()
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	header := snapshotHeader{Version: 1, Generations: []uint32{0}, Entities: []Goent{e}}
+	// version 1 wrote one record per type, named by a single string
+	record := struct {
+		Name     string
+		Entities []Goent
+	}{Name: "goecs.saveVFX", Entities: []Goent{e}}
+	for _, v := range []interface{}{header, record, struct{ Name string }{}} {
+		if err := enc.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := newSaveTarget()
+	err := dst.Load(&buf)
+	if err == nil || !strings.Contains(err.Error(), "unsupported snapshot version 1") {
+		t.Fatalf("Load of a version 1 stream = %v", err)
+	}
+	if n := dst.EntityCount(); n != 0 {
+		t.Errorf("rejected load left %d entities", n)
+	}
+}
@@ -0,0 +1,340 @@
+package goecs
+
+import (
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// --- System scheduler ---
+// Systems declare the component types they read and write, and the scheduler
+// runs systems whose declarations don't conflict at the same time. Two systems
+// conflict when one writes a type the other reads or writes. The plan keeps
+// registration order between conflicting systems: every system lands in the
+// first stage after all earlier systems it conflicts with.
+//
+// Stages holding several systems run on the worker pool under the same guard
+// as ParallelRead, so structural changes from inside them panic. A system
+// that needs to create, destroy or restructure entities declares Exclusive
+// access and gets a stage to itself, where it may change anything. Systems
+// added with AddQueued can instead defer their changes to a SystemQueue,
+// which the scheduler applies once the stage is done.
+//
+// Every system owns its queue and is its only producer, so recording a
+// command is a plain append without locks or atomics, however many workers
+// run. The stage's WaitGroup orders those appends before the merge, which
+// happens on the goroutine calling Run. Queues keep their capacity between
+// runs, so steady-state frames don't allocate for them.
+//
+// By default queues are merged in the order their systems finish, which
+// varies between runs. Deterministic mode keeps the same parallel plan but
+// merges by system ID (registration order), then entity, so servers running
+// in parallel stay replay and lockstep compatible.
+//
+// Run checkpoints the registry's change tick after every stage, as
+// World.Update does after every system, and hands each system the tick its
+// previous run ended at through SystemQueue.LastRun, for Changed filters.
+// Systems of one stage share that checkpoint.
+
+// SystemAccess declares what a system touches.
+type SystemAccess struct {
+	Reads  []reflect.Type
+	Writes []reflect.Type
+	// Exclusive systems conflict with every other system.
+	Exclusive bool
+}
+
+func (a SystemAccess) conflicts(b SystemAccess) bool {
+	if a.Exclusive || b.Exclusive {
+		return true
+	}
+	return overlaps(a.Writes, b.Reads) || overlaps(a.Writes, b.Writes) || overlaps(b.Writes, a.Reads)
+}
+
+func overlaps(a, b []reflect.Type) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// QueuedEvent is an event a system emitted through its SystemQueue.
+type QueuedEvent struct {
+	System  string
+	Entity  Goent
+	Payload interface{}
+}
+
+// SystemQueue collects the deferred work of one system run: the commands of
+// its embedded CommandBuffer are applied after its stage, and events are
+// handed to later stages. Only the owning system may write to it; a system
+// fanning out to goroutines gives each its own CommandBuffer and Appends them
+// to the queue before returning.
+type SystemQueue struct {
+	CommandBuffer
+	name   string
+	events []QueuedEvent
+	// since is the tick the system's previous run ended at, see LastRun
+	since uint64
+}
+
+// LastRun returns the tick the system's previous run ended at, 0 on its
+// first run, for its Changed filters:
+//
+//	Iterate1(r, sync, Changed[Transform](q.LastRun()))
+func (q *SystemQueue) LastRun() uint64 {
+	return q.since
+}
+
+// Emit queues an event concerning the entity. Systems in later stages see it
+// through Scheduler.Events.
+func (q *SystemQueue) Emit(entity Goent, payload interface{}) {
+	q.events = append(q.events, QueuedEvent{System: q.name, Entity: entity, Payload: payload})
+}
+
+// sortByEntity orders the queue by entity, keeping the order of entries
+// concerning the same entity.
+func (q *SystemQueue) sortByEntity() {
+	sort.SliceStable(q.commands, func(i, j int) bool { return q.commands[i].entity < q.commands[j].entity })
+	sort.SliceStable(q.events, func(i, j int) bool { return q.events[i].Entity < q.events[j].Entity })
+}
+
+type scheduledSystem struct {
+	name   string
+	access SystemAccess
+	run    func(r *Registry, q *SystemQueue)
+	queue  SystemQueue
+	// after lists earlier systems this one must follow even without a
+	// conflict, see SystemCatalog
+	after []int
+
+	// governor bookkeeping, see Governor
+	deferrable bool
+	lastCost   time.Duration
+	cost       time.Duration
+	deferrals  int
+}
+
+// Scheduler runs registered systems once per Run, in parallel where their
+// declared access allows it.
+type Scheduler struct {
+	systems []*scheduledSystem
+	// stages holds system indices per stage, rebuilt after Add
+	stages [][]int
+	jobs   chan func()
+	closed bool
+
+	deterministic bool
+	// events emitted so far in the current (or last) Run
+	events []QueuedEvent
+
+	governor    *Governor
+	maintenance *Maintenance
+}
+
+// NewScheduler creates a scheduler with a pool of the given number of
+// workers, or GOMAXPROCS workers if workers < 1. Close stops the pool.
+func NewScheduler(workers int) *Scheduler {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	s := &Scheduler{jobs: make(chan func())}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range s.jobs {
+				job()
+			}
+		}()
+	}
+	return s
+}
+
+// Add registers a system. Systems registered later run after the earlier
+// systems they conflict with. A system using Changed filters needs its
+// LastRun and is added with AddQueued.
+func (s *Scheduler) Add(name string, access SystemAccess, run func(r *Registry)) {
+	s.AddQueued(name, access, func(r *Registry, q *SystemQueue) { run(r) })
+}
+
+// AddQueued registers a system that defers structural changes and events to
+// its queue, so it can run in parallel with others without Exclusive access.
+func (s *Scheduler) AddQueued(name string, access SystemAccess, run func(r *Registry, q *SystemQueue)) {
+	s.add(name, access, run, nil)
+}
+
+// add registers a system that runs after the given earlier systems.
+func (s *Scheduler) add(name string, access SystemAccess, run func(r *Registry, q *SystemQueue), after []int) int {
+	s.systems = append(s.systems, &scheduledSystem{
+		name:   name,
+		access: access,
+		run:    run,
+		queue:  SystemQueue{name: name},
+		after:  after,
+	})
+	s.stages = nil
+	return len(s.systems) - 1
+}
+
+// SetDeterministic switches between merging system queues in finishing
+// order and in system ID, then entity order.
+func (s *Scheduler) SetDeterministic(on bool) {
+	s.deterministic = on
+}
+
+// Events returns the events emitted during the current or last Run, in merge
+// order. The slice is reused by the next Run.
+func (s *Scheduler) Events() []QueuedEvent {
+	return s.events
+}
+
+// build assigns each system the stage after its last conflicting or required
+// predecessor.
+func (s *Scheduler) build() [][]int {
+	stageOf := make([]int, len(s.systems))
+	var stages [][]int
+	for i, sys := range s.systems {
+		stage := 0
+		for j := 0; j < i; j++ {
+			if stageOf[j] >= stage && (sys.access.conflicts(s.systems[j].access) || sys.follows(j)) {
+				stage = stageOf[j] + 1
+			}
+		}
+		stageOf[i] = stage
+		if stage == len(stages) {
+			stages = append(stages, nil)
+		}
+		stages[stage] = append(stages[stage], i)
+	}
+	return stages
+}
+
+func (sys *scheduledSystem) follows(id int) bool {
+	for _, after := range sys.after {
+		if after == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Plan returns the system names per stage, in execution order.
+func (s *Scheduler) Plan() [][]string {
+	if s.stages == nil {
+		s.stages = s.build()
+	}
+	plan := make([][]string, len(s.stages))
+	for i, stage := range s.stages {
+		for _, id := range stage {
+			plan[i] = append(plan[i], s.systems[id].name)
+		}
+	}
+	return plan
+}
+
+// Run executes every system once, stage by stage.
+func (s *Scheduler) Run(r *Registry) {
+	if s.closed {
+		panic("goecs: Run on a closed scheduler")
+	}
+	if s.stages == nil {
+		s.stages = s.build()
+	}
+	s.events = s.events[:0]
+	if s.governor != nil {
+		s.governor.beginFrame()
+	}
+	for _, stage := range s.stages {
+		if s.governor != nil {
+			stage = s.governor.admit(s.systems, stage)
+		}
+		switch len(stage) {
+		case 0:
+			continue
+		case 1:
+			s.runSystem(r, s.systems[stage[0]])
+			s.merge(r, stage)
+		default:
+			s.runParallel(r, stage)
+		}
+		tick := r.Checkpoint()
+		for _, id := range stage {
+			s.systems[id].queue.since = tick
+		}
+		if s.governor != nil {
+			s.governor.record(s.systems, stage)
+		}
+	}
+	if s.governor != nil {
+		s.governor.endFrame()
+	}
+	if s.maintenance != nil {
+		s.maintenance.Run()
+	}
+}
+
+// runParallel runs a stage of several systems on the worker pool.
+func (s *Scheduler) runParallel(r *Registry, stage []int) {
+	// finishing systems claim the next slot of finished with an atomic add,
+	// so recording the merge order takes no lock either
+	finished := make([]int, len(stage))
+	var done int32
+	atomic.AddInt32(&r.readers, 1)
+	var wg sync.WaitGroup
+	wg.Add(len(stage))
+	for _, id := range stage {
+		sys := s.systems[id]
+		s.jobs <- func() {
+			defer wg.Done()
+			s.runSystem(r, sys)
+			finished[atomic.AddInt32(&done, 1)-1] = id
+		}
+	}
+	wg.Wait()
+	atomic.AddInt32(&r.readers, -1)
+
+	if s.deterministic {
+		// stage lists systems in registration order already
+		s.merge(r, stage)
+	} else {
+		s.merge(r, finished)
+	}
+}
+
+// runSystem runs one system, timing it when a governor is set.
+func (s *Scheduler) runSystem(r *Registry, sys *scheduledSystem) {
+	if s.governor == nil {
+		sys.run(r, &sys.queue)
+		return
+	}
+	start := time.Now()
+	sys.run(r, &sys.queue)
+	sys.lastCost = time.Since(start)
+}
+
+// merge applies and drains the queues of the given systems, in that order.
+func (s *Scheduler) merge(r *Registry, order []int) {
+	for _, id := range order {
+		q := &s.systems[id].queue
+		if s.deterministic {
+			q.sortByEntity()
+		}
+		q.Flush(r)
+		s.events = append(s.events, q.events...)
+		q.events = q.events[:0]
+	}
+}
+
+// Close stops the worker pool. The scheduler can't run afterwards.
+func (s *Scheduler) Close() {
+	if !s.closed {
+		s.closed = true
+		close(s.jobs)
+	}
+}
@@ -0,0 +1,227 @@
+package goecs
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+type schedPos struct {
+	X int
+}
+
+type schedVel struct {
+	X int
+}
+
+type schedHealth struct {
+	HP int
+}
+
+func TestSchedulerPlan(t *testing.T) {
+	pos, vel, hp := ComponentType[schedPos](), ComponentType[schedVel](), ComponentType[schedHealth]()
+	types := func(ts ...reflect.Type) []reflect.Type { return ts }
+	type system struct {
+		name   string
+		access SystemAccess
+	}
+	tests := []struct {
+		name    string
+		systems []system
+		want    [][]string
+	}{
+		{"shared reads", []system{
+			{"a", SystemAccess{Reads: types(pos)}},
+			{"b", SystemAccess{Reads: types(pos, vel)}},
+		}, [][]string{{"a", "b"}}},
+		{"write after read", []system{
+			{"read", SystemAccess{Reads: types(pos)}},
+			{"write", SystemAccess{Writes: types(pos)}},
+			{"other", SystemAccess{Writes: types(hp)}},
+		}, [][]string{{"read", "other"}, {"write"}}},
+		{"write after write", []system{
+			{"a", SystemAccess{Writes: types(vel)}},
+			{"b", SystemAccess{Reads: types(pos), Writes: types(vel)}},
+			{"c", SystemAccess{Reads: types(vel)}},
+		}, [][]string{{"a"}, {"b"}, {"c"}}},
+		{"exclusive", []system{
+			{"a", SystemAccess{Reads: types(pos)}},
+			{"spawn", SystemAccess{Exclusive: true}},
+			{"b", SystemAccess{Reads: types(pos)}},
+		}, [][]string{{"a"}, {"spawn"}, {"b"}}},
+		{"late system joins an early stage", []system{
+			{"a", SystemAccess{Writes: types(pos)}},
+			{"b", SystemAccess{Writes: types(pos)}},
+			{"c", SystemAccess{Writes: types(hp)}},
+		}, [][]string{{"a", "c"}, {"b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler(2)
+			defer s.Close()
+			for _, sys := range tt.systems {
+				s.Add(sys.name, sys.access, func(r *Registry) {})
+			}
+			if got := s.Plan(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Plan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedulerExclusiveStage(t *testing.T) {
+	r := NewRegistry()
+	s := NewScheduler(2)
+	defer s.Close()
+	var spawned Goent
+	s.Add("spawn", SystemAccess{Exclusive: true}, func(r *Registry) {
+		spawned = r.CreateEntity()
+		EmplaceComponent(r, spawned, schedPos{X: 1})
+	})
+	s.Add("move", SystemAccess{Writes: []reflect.Type{ComponentType[schedPos]()}}, func(r *Registry) {
+		Iterate1(r, func(e Goent, p *schedPos) { p.X++ })
+	})
+	s.Run(r)
+	if p, ok := GetComponent[schedPos](r, spawned); !ok || p.X != 2 {
+		t.Errorf("spawned entity has %v, %v, want X 2", p, ok)
+	}
+}
+
+func TestSchedulerDeterministic(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(6)
+	for _, e := range entities {
+		EmplaceComponent(r, e, schedPos{})
+	}
+	s := NewScheduler(3)
+	defer s.Close()
+	s.SetDeterministic(true)
+	pos := []reflect.Type{ComponentType[schedPos]()}
+	// both systems queue in descending entity order and the second finishes first
+	gate := make(chan struct{})
+	s.AddQueued("first", SystemAccess{Reads: pos}, func(r *Registry, q *SystemQueue) {
+		<-gate
+		for i := len(entities) - 1; i >= 0; i-- {
+			q.Emit(entities[i], "first")
+			DeferEmplace(&q.CommandBuffer, entities[i], schedHealth{HP: 1})
+		}
+	})
+	s.AddQueued("second", SystemAccess{Reads: pos}, func(r *Registry, q *SystemQueue) {
+		for i := len(entities) - 1; i >= 0; i -= 2 {
+			q.Emit(entities[i], "second")
+			DeferEmplace(&q.CommandBuffer, entities[i], schedHealth{HP: 2})
+		}
+		close(gate)
+	})
+	s.Run(r)
+
+	var got []string
+	for _, ev := range s.Events() {
+		got = append(got, ev.Payload.(string))
+	}
+	var want []string
+	for range entities {
+		want = append(want, "first")
+	}
+	for i := 1; i < len(entities); i += 2 {
+		want = append(want, "second")
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	for i, ev := range s.Events()[:len(entities)] {
+		if ev.Entity != entities[i] {
+			t.Errorf("event %d concerns %d, want %d", i, ev.Entity, entities[i])
+		}
+	}
+	// the second system's commands are applied last
+	for i, e := range entities {
+		if h, _ := GetComponent[schedHealth](r, e); h == nil || h.HP != 1+i%2 {
+			t.Errorf("entity %d has health %v, want %d", i, h, 1+i%2)
+		}
+	}
+}
+
+func TestSystemQueues(t *testing.T) {
+	r := NewRegistry()
+	const producers, perRun = 4, 100
+	entities := r.CreateEntities(producers * perRun)
+	s := NewScheduler(producers)
+	defer s.Close()
+	pos := []reflect.Type{ComponentType[schedPos]()}
+	for p := 0; p < producers; p++ {
+		s.AddQueued(fmt.Sprint("produce", p), SystemAccess{Reads: pos}, func(r *Registry, q *SystemQueue) {
+			for _, e := range entities[p*perRun : (p+1)*perRun] {
+				DeferEmplace(&q.CommandBuffer, e, schedHealth{HP: p})
+				q.Emit(e, p)
+			}
+		})
+	}
+	// a later stage sees the events of the stages before it
+	var seen []int
+	s.Add("count", SystemAccess{Writes: pos}, func(r *Registry) { seen = append(seen, len(s.Events())) })
+
+	for run := 0; run < 2; run++ {
+		s.Run(r)
+		if n := Count[schedHealth](r); n != len(entities) {
+			t.Errorf("run %d: %d entities have health, want %d", run, n, len(entities))
+		}
+		perSystem := map[string]int{}
+		for _, ev := range s.Events() {
+			perSystem[ev.System]++
+			if h, _ := GetComponent[schedHealth](r, ev.Entity); h == nil || fmt.Sprint("produce", h.HP) != ev.System {
+				t.Errorf("event of %s concerns an entity with %v", ev.System, h)
+			}
+		}
+		if len(perSystem) != producers {
+			t.Errorf("run %d: events came from %v", run, perSystem)
+		}
+		for name, n := range perSystem {
+			if n != perRun {
+				t.Errorf("run %d: %s emitted %d events, want %d", run, name, n, perRun)
+			}
+		}
+	}
+	if !slices.Equal(seen, []int{len(entities), len(entities)}) {
+		t.Errorf("later stage saw %v events", seen)
+	}
+}
+
+func TestSchedulerChangedSince(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(3)
+	for _, e := range entities {
+		EmplaceComponent(r, e, schedPos{})
+	}
+	pos := ComponentType[schedPos]()
+	s := NewScheduler(2)
+	defer s.Close()
+	var move Goent
+	s.Add("move", SystemAccess{Writes: []reflect.Type{pos}}, func(r *Registry) {
+		if move != 0 {
+			p, _ := GetComponent[schedPos](r, move)
+			p.X++
+			MarkChanged[schedPos](r, move)
+		}
+	})
+	var seen []Goent
+	s.AddQueued("sync", SystemAccess{Reads: []reflect.Type{pos}}, func(r *Registry, q *SystemQueue) {
+		seen = seen[:0]
+		Iterate1(r, func(e Goent, _ *schedPos) { seen = append(seen, e) }, Changed[schedPos](q.LastRun()))
+	})
+
+	s.Run(r)
+	if len(seen) != 3 {
+		t.Fatalf("first run saw %v, want every entity", seen)
+	}
+	s.Run(r)
+	if len(seen) != 0 {
+		t.Errorf("run without changes saw %v", seen)
+	}
+	move = entities[1]
+	s.Run(r)
+	if !slices.Equal(seen, []Goent{entities[1]}) {
+		t.Errorf("saw %v, want only the moved entity", seen)
+	}
+}
@@ -0,0 +1,54 @@
+package goecs
+
+import (
+	"reflect"
+	"sort"
+)
+
+// --- Snapshot entity selection ---
+
+// ComponentType returns the reflect.Type the registry keys T's storage by,
+// for building component type sets such as snapshot filters.
+func ComponentType[T any]() reflect.Type {
+	return typeKeyFor[T]()
+}
+
+// SnapshotFilter selects which entities a snapshot contains, so saves hold
+// only persistent world state and skip transient things like VFX or
+// projectiles. The zero value matches every entity.
+type SnapshotFilter struct {
+	// Include lists component types an entity must all have to be saved.
+	Include []reflect.Type
+	// Exclude lists component types that keep an entity out when any is present.
+	Exclude []reflect.Type
+	// Predicate, when set, runs last on entities that passed the type checks.
+	Predicate func(r *Registry, entity Goent) bool
+}
+
+// Matches reports whether the entity passes the filter.
+func (f SnapshotFilter) Matches(r *Registry, entity Goent) bool {
+	for _, t := range f.Include {
+		if _, ok := r.componentOf(entity, t); !ok {
+			return false
+		}
+	}
+	for _, t := range f.Exclude {
+		if _, ok := r.componentOf(entity, t); ok {
+			return false
+		}
+	}
+	return f.Predicate == nil || f.Predicate(r, entity)
+}
+
+// FilteredEntities returns every entity carrying at least one component that
+// passes the filter, ordered by ID so snapshots come out deterministic.
+func (r *Registry) FilteredEntities(f SnapshotFilter) []Goent {
+	var entities []Goent
+	r.eachEntity(func(entity Goent) {
+		if f.Matches(r, entity) {
+			entities = append(entities, entity)
+		}
+	})
+	sort.Slice(entities, func(i, j int) bool { return entities[i] < entities[j] })
+	return entities
+}
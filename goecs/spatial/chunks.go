@@ -0,0 +1,220 @@
+package spatial
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+// --- Cell partitioned chunk files ---
+//
+// A chunk file stores one record per spatial cell behind an index header, so
+// loading a region only reads the records of the cells it covers instead of
+// parsing the entire world file. Layout, all little endian:
+//
+//	magic "GECH" | version u32 | cell size f64 | cell count u32
+//	cell count * (x i32 | y i32 | offset u64 | length u64)
+//	records: entity count u32, then per entity: id u64 | size u32 | payload
+//
+// Offsets are relative to the start of the file. How an entity is turned into
+// a payload is up to the caller, which keeps the layout independent of any
+// particular component serialization.
+
+var chunkMagic = [4]byte{'G', 'E', 'C', 'H'}
+
+const chunkVersion = 1
+
+// ErrBadChunkFile is returned when a chunk file has the wrong magic or version.
+var ErrBadChunkFile = errors.New("spatial: not a chunk file")
+
+// EntityEncoder turns one entity into the payload stored in its cell record.
+type EntityEncoder func(e goecs.Goent) ([]byte, error)
+
+// EntityDecoder receives every entity stored in a cell record with its payload.
+type EntityDecoder func(e goecs.Goent, payload []byte) error
+
+type chunkIndexEntry struct {
+	Cell   Cell
+	Offset uint64
+	Length uint64
+}
+
+// WriteChunks writes every entity in the index to w, partitioned by the cell
+// containing the center of its bounds, so each entity is stored exactly once.
+func WriteChunks(w io.Writer, ix *Index, encode EntityEncoder) error {
+	byCell := make(map[Cell][]goecs.Goent)
+	seen := make(map[goecs.Goent]struct{})
+	for _, entries := range ix.cells {
+		for _, en := range entries {
+			if _, dup := seen[en.entity]; dup {
+				continue
+			}
+			seen[en.entity] = struct{}{}
+			c := ix.CellAt(en.bounds.Center())
+			byCell[c] = append(byCell[c], en.entity)
+		}
+	}
+
+	// Sort cells and entities so the same world always produces the same file
+	cells := make([]Cell, 0, len(byCell))
+	for c := range byCell {
+		cells = append(cells, c)
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Y != cells[j].Y {
+			return cells[i].Y < cells[j].Y
+		}
+		return cells[i].X < cells[j].X
+	})
+
+	headerSize := uint64(4 + 4 + 8 + 4 + len(cells)*(4+4+8+8))
+	index := make([]chunkIndexEntry, len(cells))
+	var records bytes.Buffer
+	for i, c := range cells {
+		entities := byCell[c]
+		sort.Slice(entities, func(a, b int) bool { return entities[a] < entities[b] })
+
+		start := records.Len()
+		binary.Write(&records, binary.LittleEndian, uint32(len(entities)))
+		for _, e := range entities {
+			payload, err := encode(e)
+			if err != nil {
+				return fmt.Errorf("spatial: encoding entity %d: %w", e, err)
+			}
+			binary.Write(&records, binary.LittleEndian, uint64(e))
+			binary.Write(&records, binary.LittleEndian, uint32(len(payload)))
+			records.Write(payload)
+		}
+		index[i] = chunkIndexEntry{
+			Cell:   c,
+			Offset: headerSize + uint64(start),
+			Length: uint64(records.Len() - start),
+		}
+	}
+
+	var header bytes.Buffer
+	header.Write(chunkMagic[:])
+	binary.Write(&header, binary.LittleEndian, uint32(chunkVersion))
+	binary.Write(&header, binary.LittleEndian, ix.cellSize)
+	binary.Write(&header, binary.LittleEndian, uint32(len(index)))
+	for _, entry := range index {
+		binary.Write(&header, binary.LittleEndian, int32(entry.Cell.X))
+		binary.Write(&header, binary.LittleEndian, int32(entry.Cell.Y))
+		binary.Write(&header, binary.LittleEndian, entry.Offset)
+		binary.Write(&header, binary.LittleEndian, entry.Length)
+	}
+
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return err
+	}
+	_, err := w.Write(records.Bytes())
+	return err
+}
+
+// ChunkFile gives random access to the cell records of a chunk file.
+type ChunkFile struct {
+	src      io.ReaderAt
+	cellSize float64
+	index    map[Cell]chunkIndexEntry
+}
+
+// OpenChunks reads only the header of a chunk file.
+func OpenChunks(src io.ReaderAt) (*ChunkFile, error) {
+	fixed := make([]byte, 4+4+8+4)
+	if _, err := src.ReadAt(fixed, 0); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(fixed[:4], chunkMagic[:]) || binary.LittleEndian.Uint32(fixed[4:8]) != chunkVersion {
+		return nil, ErrBadChunkFile
+	}
+
+	var cellSize float64
+	binary.Read(bytes.NewReader(fixed[8:16]), binary.LittleEndian, &cellSize)
+	count := binary.LittleEndian.Uint32(fixed[16:20])
+
+	raw := make([]byte, int(count)*(4+4+8+8))
+	if _, err := src.ReadAt(raw, int64(len(fixed))); err != nil {
+		return nil, err
+	}
+	cf := &ChunkFile{src: src, cellSize: cellSize, index: make(map[Cell]chunkIndexEntry, count)}
+	for i := 0; i < int(count); i++ {
+		b := raw[i*24:]
+		c := Cell{int(int32(binary.LittleEndian.Uint32(b[0:4]))), int(int32(binary.LittleEndian.Uint32(b[4:8])))}
+		cf.index[c] = chunkIndexEntry{
+			Cell:   c,
+			Offset: binary.LittleEndian.Uint64(b[8:16]),
+			Length: binary.LittleEndian.Uint64(b[16:24]),
+		}
+	}
+	return cf, nil
+}
+
+// CellSize returns the cell size the file was partitioned with.
+func (cf *ChunkFile) CellSize() float64 {
+	return cf.cellSize
+}
+
+// Cells returns every cell that has a record in the file.
+func (cf *ChunkFile) Cells() []Cell {
+	cells := make([]Cell, 0, len(cf.index))
+	for c := range cf.index {
+		cells = append(cells, c)
+	}
+	return cells
+}
+
+// ReadCell decodes the record of one cell. A cell without a record is empty
+// and not an error.
+func (cf *ChunkFile) ReadCell(c Cell, decode EntityDecoder) error {
+	entry, ok := cf.index[c]
+	if !ok {
+		return nil
+	}
+	record := make([]byte, entry.Length)
+	if _, err := cf.src.ReadAt(record, int64(entry.Offset)); err != nil {
+		return err
+	}
+
+	if len(record) < 4 {
+		return ErrBadChunkFile
+	}
+	count := binary.LittleEndian.Uint32(record)
+	record = record[4:]
+	for i := uint32(0); i < count; i++ {
+		if len(record) < 12 {
+			return ErrBadChunkFile
+		}
+		e := goecs.Goent(binary.LittleEndian.Uint64(record))
+		size := binary.LittleEndian.Uint32(record[8:])
+		record = record[12:]
+		if uint32(len(record)) < size {
+			return ErrBadChunkFile
+		}
+		if err := decode(e, record[:size]); err != nil {
+			return err
+		}
+		record = record[size:]
+	}
+	return nil
+}
+
+// ReadRegion decodes the records of every cell overlapping the area. Entities
+// are filed under the cell of their center, so a large entity reaching into
+// the area from an uncovered cell is not included.
+func (cf *ChunkFile) ReadRegion(area Rect, decode EntityDecoder) error {
+	ix := Index{cellSize: cf.cellSize}
+	lo, hi := ix.CellAt(area.Min), ix.CellAt(area.Max)
+	for y := lo.Y; y <= hi.Y; y++ {
+		for x := lo.X; x <= hi.X; x++ {
+			if err := cf.ReadCell(Cell{x, y}, decode); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package spatial
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+func TestChunks(t *testing.T) {
+	r := goecs.NewRegistry()
+	a, b, c := r.CreateEntity(), r.CreateEntity(), r.CreateEntity()
+	goecs.EmplaceComponent(r, a, box(1, 1, 1))
+	// overlaps four cells, stored once under the cell of its center
+	goecs.EmplaceComponent(r, b, box(3, 3, 2))
+	goecs.EmplaceComponent(r, c, box(-9, 12, 1))
+	ix := NewIndex(4)
+	ix.Rebuild(r)
+
+	var buf bytes.Buffer
+	encode := func(e goecs.Goent) ([]byte, error) { return []byte(fmt.Sprint("entity ", e)), nil }
+	if err := WriteChunks(&buf, ix, encode); err != nil {
+		t.Fatal(err)
+	}
+	var again bytes.Buffer
+	WriteChunks(&again, ix, encode)
+	if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Error("the same world wrote two different files")
+	}
+
+	cf, err := OpenChunks(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cf.CellSize() != 4 || len(cf.Cells()) != 3 {
+		t.Errorf("file has cell size %v and cells %v", cf.CellSize(), cf.Cells())
+	}
+	read := func(area Rect) []goecs.Goent {
+		var got []goecs.Goent
+		err := cf.ReadRegion(area, func(e goecs.Goent, payload []byte) error {
+			if string(payload) != fmt.Sprint("entity ", e) {
+				t.Errorf("entity %d has payload %q", e, payload)
+			}
+			got = append(got, e)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices.Sort(got)
+		return got
+	}
+	if got := read(Rect{Max: Vec2{X: 3, Y: 3}}); !slices.Equal(got, []goecs.Goent{a}) {
+		t.Errorf("origin cell holds %v, want %v", got, []goecs.Goent{a})
+	}
+	if got := read(Rect{Min: Vec2{X: -10, Y: -10}, Max: Vec2{X: 10, Y: 20}}); !slices.Equal(got, []goecs.Goent{a, b, c}) {
+		t.Errorf("whole world holds %v", got)
+	}
+	if got := read(Rect{Min: Vec2{X: 50, Y: 50}, Max: Vec2{X: 51, Y: 51}}); got != nil {
+		t.Errorf("empty region holds %v", got)
+	}
+
+	stop := errors.New("stop")
+	if err := cf.ReadCell(ix.CellAt(Vec2{X: 1, Y: 1}), func(goecs.Goent, []byte) error { return stop }); err != stop {
+		t.Errorf("ReadCell returned %v, want the decoder's error", err)
+	}
+}
+
+func TestOpenChunksRejectsOtherFiles(t *testing.T) {
+	data := []byte("GECX\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x40\x00\x00\x00\x00")
+	if _, err := OpenChunks(bytes.NewReader(data)); !errors.Is(err, ErrBadChunkFile) {
+		t.Errorf("OpenChunks = %v, want ErrBadChunkFile", err)
+	}
+}
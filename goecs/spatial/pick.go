@@ -0,0 +1,125 @@
+package spatial
+
+import (
+	"math"
+	"sort"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+// --- Picking and raycasts ---
+
+// Hit is one entity found by a pick or raycast.
+type Hit struct {
+	Entity goecs.Goent
+	// Point is where the entity was hit, for raycasts the entry point into its bounds.
+	Point Vec2
+	// Distance is how far Point is from the pick position or ray origin.
+	Distance float64
+}
+
+// Ray is a half line starting at Origin. Dir doesn't need to be normalized.
+type Ray struct {
+	Origin Vec2
+	Dir    Vec2
+}
+
+// currentBounds returns the entity's Bounds as stored in the registry right
+// now, so hits are never reported against a stale index entry.
+func currentBounds(r *goecs.Registry, e goecs.Goent) (Rect, bool) {
+	b, ok := goecs.GetComponent[Bounds](r, e)
+	if !ok {
+		return Rect{}, false
+	}
+	return b.Rect, true
+}
+
+func sortHits(hits []Hit) {
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Distance < hits[j].Distance })
+}
+
+// PickPoint returns every entity whose bounds contain the point (x, y),
+// nearest bounds center first. Editor selection uses the first hit.
+func PickPoint(r *goecs.Registry, ix *Index, x, y float64) []Hit {
+	p := Vec2{x, y}
+	var hits []Hit
+	ix.Query(Rect{Min: p, Max: p}, func(e goecs.Goent, _ Rect) {
+		bounds, ok := currentBounds(r, e)
+		if !ok || !bounds.Contains(p) {
+			return
+		}
+		c := bounds.Center()
+		hits = append(hits, Hit{Entity: e, Point: p, Distance: math.Hypot(c.X-p.X, c.Y-p.Y)})
+	})
+	sortHits(hits)
+	return hits
+}
+
+// intersectRay runs a slab test and returns the ray parameter at which the
+// ray enters the rectangle, 0 if it starts inside.
+func intersectRay(ray Ray, rect Rect) (float64, bool) {
+	tMin, tMax := 0.0, math.Inf(1)
+	origins := [2]float64{ray.Origin.X, ray.Origin.Y}
+	dirs := [2]float64{ray.Dir.X, ray.Dir.Y}
+	lo := [2]float64{rect.Min.X, rect.Min.Y}
+	hi := [2]float64{rect.Max.X, rect.Max.Y}
+	for i := range origins {
+		origin, dir := origins[i], dirs[i]
+		if dir == 0 {
+			if origin < lo[i] || origin > hi[i] {
+				return 0, false
+			}
+			continue
+		}
+		t1, t2 := (lo[i]-origin)/dir, (hi[i]-origin)/dir
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tMin, tMax = math.Max(tMin, t1), math.Min(tMax, t2)
+		if tMin > tMax {
+			return 0, false
+		}
+	}
+	return tMin, true
+}
+
+// RaycastAll returns every entity whose bounds the ray crosses within
+// maxDistance world units, nearest first.
+func RaycastAll(r *goecs.Registry, ix *Index, ray Ray, maxDistance float64) []Hit {
+	length := math.Hypot(ray.Dir.X, ray.Dir.Y)
+	if length == 0 {
+		return nil
+	}
+	dir := ray.Dir.Scale(1 / length)
+	ray = Ray{Origin: ray.Origin, Dir: dir}
+
+	end := ray.Origin.Add(dir.Scale(maxDistance))
+	area := Rect{
+		Min: Vec2{math.Min(ray.Origin.X, end.X), math.Min(ray.Origin.Y, end.Y)},
+		Max: Vec2{math.Max(ray.Origin.X, end.X), math.Max(ray.Origin.Y, end.Y)},
+	}
+
+	var hits []Hit
+	ix.Query(area, func(e goecs.Goent, _ Rect) {
+		bounds, ok := currentBounds(r, e)
+		if !ok {
+			return
+		}
+		t, ok := intersectRay(ray, bounds)
+		if !ok || t > maxDistance {
+			return
+		}
+		hits = append(hits, Hit{Entity: e, Point: ray.Origin.Add(dir.Scale(t)), Distance: t})
+	})
+	sortHits(hits)
+	return hits
+}
+
+// RaycastFirst returns the nearest entity the ray crosses within maxDistance.
+func RaycastFirst(r *goecs.Registry, ix *Index, ray Ray, maxDistance float64) (Hit, bool) {
+	hits := RaycastAll(r, ix, ray, maxDistance)
+	if len(hits) == 0 {
+		return Hit{}, false
+	}
+	return hits[0], true
+}
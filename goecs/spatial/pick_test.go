@@ -0,0 +1,75 @@
+package spatial
+
+import (
+	"testing"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+func TestPickPoint(t *testing.T) {
+	r := goecs.NewRegistry()
+	outer, inner, other := r.CreateEntity(), r.CreateEntity(), r.CreateEntity()
+	goecs.EmplaceComponent(r, outer, box(0, 0, 10))
+	goecs.EmplaceComponent(r, inner, box(2, 2, 2))
+	goecs.EmplaceComponent(r, other, box(20, 0, 2))
+	ix := NewIndex(4)
+	ix.Rebuild(r)
+
+	hits := PickPoint(r, ix, 3, 3)
+	if len(hits) != 2 || hits[0].Entity != inner || hits[1].Entity != outer {
+		t.Fatalf("PickPoint = %v, want inner then outer", hits)
+	}
+	if hits[0].Distance != 0 || hits[0].Point != (Vec2{X: 3, Y: 3}) {
+		t.Errorf("inner hit = %+v", hits[0])
+	}
+	// hits use the current bounds, not the stale index entry
+	goecs.RemoveComponent[Bounds](r, inner)
+	if hits := PickPoint(r, ix, 3, 3); len(hits) != 1 || hits[0].Entity != outer {
+		t.Errorf("PickPoint after removing bounds = %v", hits)
+	}
+}
+
+func TestRaycast(t *testing.T) {
+	r := goecs.NewRegistry()
+	near, far, off := r.CreateEntity(), r.CreateEntity(), r.CreateEntity()
+	goecs.EmplaceComponent(r, near, box(5, -1, 2))
+	goecs.EmplaceComponent(r, far, box(15, -1, 2))
+	goecs.EmplaceComponent(r, off, box(10, 5, 2))
+	ix := NewIndex(4)
+	ix.Rebuild(r)
+	ray := Ray{Dir: Vec2{X: 2}}
+
+	tests := []struct {
+		name string
+		max  float64
+		want []goecs.Goent
+	}{
+		{"both", 100, []goecs.Goent{near, far}},
+		{"short", 10, []goecs.Goent{near}},
+		{"too short", 4, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := RaycastAll(r, ix, ray, tt.max)
+			if len(hits) != len(tt.want) {
+				t.Fatalf("RaycastAll = %v, want %v", hits, tt.want)
+			}
+			for i, h := range hits {
+				if h.Entity != tt.want[i] {
+					t.Errorf("hit %d = %d, want %d", i, h.Entity, tt.want[i])
+				}
+			}
+		})
+	}
+	hit, ok := RaycastFirst(r, ix, ray, 100)
+	if !ok || hit.Entity != near || hit.Distance != 5 || hit.Point != (Vec2{X: 5}) {
+		t.Errorf("RaycastFirst = %+v, %v, want near at distance 5", hit, ok)
+	}
+	if hits := RaycastAll(r, ix, Ray{Origin: Vec2{X: 6}}, 100); hits != nil {
+		t.Errorf("zero direction ray hit %v", hits)
+	}
+	// a ray starting inside hits at its origin
+	if hit, ok := RaycastFirst(r, ix, Ray{Origin: Vec2{X: 6}, Dir: Vec2{Y: -1}}, 100); !ok || hit.Entity != near || hit.Distance != 0 {
+		t.Errorf("ray from inside = %+v, %v", hit, ok)
+	}
+}
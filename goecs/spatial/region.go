@@ -0,0 +1,114 @@
+package spatial
+
+import (
+	"sort"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+// --- Region subscriptions ---
+// A region subscription tells a consumer (minimap, audio, AI director) which
+// entities entered and left an area. It is kept up to date by Index.Rebuild,
+// which only looks at the grid cells the area covers, so consumers don't
+// each scan the world every frame.
+
+// Subscription tracks the entities whose bounds intersect an area.
+type Subscription struct {
+	ix      *Index
+	area    Rect
+	inside  map[goecs.Goent]struct{}
+	onEnter func(e goecs.Goent)
+	onExit  func(e goecs.Goent)
+}
+
+// Subscribe starts tracking the area. After every Rebuild onExit is called
+// for the entities that left it, then onEnter for those that entered, each
+// sorted by handle; either may be nil. An entity that lost its Bounds or was
+// destroyed leaves the area. The first Rebuild reports everything inside as
+// entered.
+func (ix *Index) Subscribe(area Rect, onEnter, onExit func(e goecs.Goent)) *Subscription {
+	s := &Subscription{
+		ix:      ix,
+		area:    area,
+		inside:  make(map[goecs.Goent]struct{}),
+		onEnter: onEnter,
+		onExit:  onExit,
+	}
+	ix.subs = append(ix.subs, s)
+	return s
+}
+
+// Area returns the tracked area.
+func (s *Subscription) Area() Rect {
+	return s.area
+}
+
+// SetArea moves the tracked area, e.g. with the camera. The difference is
+// reported by the next Rebuild.
+func (s *Subscription) SetArea(area Rect) {
+	s.area = area
+}
+
+// Inside returns the entities in the area as of the last Rebuild, sorted by
+// handle.
+func (s *Subscription) Inside() []goecs.Goent {
+	entities := make([]goecs.Goent, 0, len(s.inside))
+	for e := range s.inside {
+		entities = append(entities, e)
+	}
+	sortHandles(entities)
+	return entities
+}
+
+// Contains reports whether the entity was in the area at the last Rebuild.
+func (s *Subscription) Contains(e goecs.Goent) bool {
+	_, ok := s.inside[e]
+	return ok
+}
+
+// Close stops tracking the area.
+func (s *Subscription) Close() {
+	for i, sub := range s.ix.subs {
+		if sub == s {
+			s.ix.subs = append(s.ix.subs[:i], s.ix.subs[i+1:]...)
+			break
+		}
+	}
+	s.inside = nil
+}
+
+// update diffs the area's current contents against the last ones.
+func (s *Subscription) update() {
+	now := make(map[goecs.Goent]struct{}, len(s.inside))
+	var entered []goecs.Goent
+	s.ix.Query(s.area, func(e goecs.Goent, _ Rect) {
+		now[e] = struct{}{}
+		if _, ok := s.inside[e]; !ok {
+			entered = append(entered, e)
+		}
+	})
+	var exited []goecs.Goent
+	for e := range s.inside {
+		if _, ok := now[e]; !ok {
+			exited = append(exited, e)
+		}
+	}
+	s.inside = now
+
+	sortHandles(exited)
+	sortHandles(entered)
+	if s.onExit != nil {
+		for _, e := range exited {
+			s.onExit(e)
+		}
+	}
+	if s.onEnter != nil {
+		for _, e := range entered {
+			s.onEnter(e)
+		}
+	}
+}
+
+func sortHandles(entities []goecs.Goent) {
+	sort.Slice(entities, func(i, j int) bool { return entities[i] < entities[j] })
+}
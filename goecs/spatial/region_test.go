@@ -0,0 +1,49 @@
+package spatial
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+func TestSubscription(t *testing.T) {
+	r := goecs.NewRegistry()
+	a, b, c := r.CreateEntity(), r.CreateEntity(), r.CreateEntity()
+	goecs.EmplaceComponent(r, a, box(1, 1, 1))
+	goecs.EmplaceComponent(r, b, box(5, 5, 1))
+	goecs.EmplaceComponent(r, c, box(50, 50, 1))
+	ix := NewIndex(4)
+	var log []string
+	sub := ix.Subscribe(Rect{Max: Vec2{X: 10, Y: 10}},
+		func(e goecs.Goent) { log = append(log, fmt.Sprint("enter ", e)) },
+		func(e goecs.Goent) { log = append(log, fmt.Sprint("exit ", e)) })
+
+	rebuild := func(want ...string) {
+		t.Helper()
+		log = nil
+		ix.Rebuild(r)
+		if !slices.Equal(log, want) {
+			t.Errorf("Rebuild reported %v, want %v", log, want)
+		}
+	}
+	rebuild(fmt.Sprint("enter ", a), fmt.Sprint("enter ", b))
+	rebuild()
+
+	goecs.EmplaceComponent(r, b, box(30, 30, 1))
+	goecs.EmplaceComponent(r, c, box(2, 2, 1))
+	r.DestroyEntity(a)
+	rebuild(fmt.Sprint("exit ", a), fmt.Sprint("exit ", b), fmt.Sprint("enter ", c))
+	if !slices.Equal(sub.Inside(), []goecs.Goent{c}) || !sub.Contains(c) {
+		t.Errorf("Inside = %v", sub.Inside())
+	}
+
+	// moving the area reports the difference
+	sub.SetArea(Rect{Min: Vec2{X: 25, Y: 25}, Max: Vec2{X: 35, Y: 35}})
+	rebuild(fmt.Sprint("exit ", c), fmt.Sprint("enter ", b))
+
+	sub.Close()
+	goecs.RemoveComponent[Bounds](r, b)
+	rebuild()
+}
@@ -0,0 +1,146 @@
+// Package spatial provides 2D bounds components and a uniform grid index over
+// them, shared by culling, picking and any other system that asks "what is
+// around here" without scanning every entity.
+package spatial
+
+import (
+	"math"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+// --- Geometry ---
+
+// Vec2 is a 2D point or direction in world space.
+type Vec2 struct {
+	X, Y float64
+}
+
+// Add returns v + o.
+func (v Vec2) Add(o Vec2) Vec2 {
+	return Vec2{v.X + o.X, v.Y + o.Y}
+}
+
+// Sub returns v - o.
+func (v Vec2) Sub(o Vec2) Vec2 {
+	return Vec2{v.X - o.X, v.Y - o.Y}
+}
+
+// Scale returns v * s.
+func (v Vec2) Scale(s float64) Vec2 {
+	return Vec2{v.X * s, v.Y * s}
+}
+
+// Rect is an axis aligned rectangle, Min holding the smallest coordinates.
+type Rect struct {
+	Min, Max Vec2
+}
+
+// Contains reports whether the point lies inside the rectangle, edges included.
+func (r Rect) Contains(p Vec2) bool {
+	return p.X >= r.Min.X && p.X <= r.Max.X && p.Y >= r.Min.Y && p.Y <= r.Max.Y
+}
+
+// Intersects reports whether the two rectangles overlap, touching edges included.
+func (r Rect) Intersects(o Rect) bool {
+	return r.Min.X <= o.Max.X && r.Max.X >= o.Min.X && r.Min.Y <= o.Max.Y && r.Max.Y >= o.Min.Y
+}
+
+// Center returns the middle of the rectangle.
+func (r Rect) Center() Vec2 {
+	return Vec2{(r.Min.X + r.Max.X) / 2, (r.Min.Y + r.Max.Y) / 2}
+}
+
+// Bounds is the component that places an entity in the spatial index. It is
+// the world space box the entity occupies.
+type Bounds struct {
+	Rect
+}
+
+// --- Grid index ---
+
+// Cell addresses one square of the grid.
+type Cell struct {
+	X, Y int
+}
+
+// entry is an entity as seen by the index at the last rebuild.
+type entry struct {
+	entity goecs.Goent
+	bounds Rect
+}
+
+// Index is a uniform grid over Bounds components. Every entity is listed in
+// each cell its bounds overlap. It is rebuilt from the registry rather than
+// updated incrementally, so call Rebuild once per frame after movement.
+type Index struct {
+	cellSize float64
+	cells    map[Cell][]entry
+	// subs are updated after every Rebuild, see Subscribe
+	subs []*Subscription
+}
+
+// NewIndex creates an empty index with square cells of the given size in world units.
+func NewIndex(cellSize float64) *Index {
+	if cellSize <= 0 {
+		cellSize = 1
+	}
+	return &Index{cellSize: cellSize, cells: make(map[Cell][]entry)}
+}
+
+// CellSize returns the world size of one cell.
+func (ix *Index) CellSize() float64 {
+	return ix.cellSize
+}
+
+// CellAt returns the cell containing the point.
+func (ix *Index) CellAt(p Vec2) Cell {
+	return Cell{int(math.Floor(p.X / ix.cellSize)), int(math.Floor(p.Y / ix.cellSize))}
+}
+
+// CellBounds returns the world rectangle covered by a cell.
+func (ix *Index) CellBounds(c Cell) Rect {
+	origin := Vec2{float64(c.X) * ix.cellSize, float64(c.Y) * ix.cellSize}
+	return Rect{Min: origin, Max: origin.Add(Vec2{ix.cellSize, ix.cellSize})}
+}
+
+// Rebuild clears the index and reinserts every entity with a Bounds
+// component, then updates the region subscriptions.
+func (ix *Index) Rebuild(r *goecs.Registry) {
+	for c, entries := range ix.cells {
+		ix.cells[c] = entries[:0]
+	}
+	goecs.Iterate1(r, func(e goecs.Goent, b *Bounds) {
+		ix.insert(e, b.Rect)
+	})
+	for _, s := range ix.subs {
+		s.update()
+	}
+}
+
+func (ix *Index) insert(e goecs.Goent, bounds Rect) {
+	lo, hi := ix.CellAt(bounds.Min), ix.CellAt(bounds.Max)
+	for y := lo.Y; y <= hi.Y; y++ {
+		for x := lo.X; x <= hi.X; x++ {
+			c := Cell{x, y}
+			ix.cells[c] = append(ix.cells[c], entry{entity: e, bounds: bounds})
+		}
+	}
+}
+
+// Query calls f once for every indexed entity whose bounds intersect the area.
+func (ix *Index) Query(area Rect, f func(e goecs.Goent, bounds Rect)) {
+	lo, hi := ix.CellAt(area.Min), ix.CellAt(area.Max)
+	seen := make(map[goecs.Goent]struct{})
+	for y := lo.Y; y <= hi.Y; y++ {
+		for x := lo.X; x <= hi.X; x++ {
+			for _, en := range ix.cells[Cell{x, y}] {
+				if _, dup := seen[en.entity]; dup || !en.bounds.Intersects(area) {
+					continue
+				}
+				seen[en.entity] = struct{}{}
+				f(en.entity, en.bounds)
+			}
+		}
+	}
+}
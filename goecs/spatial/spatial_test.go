@@ -0,0 +1,69 @@
+package spatial
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+// box returns bounds of the given size with their minimum corner at x, y.
+func box(x, y, size float64) Bounds {
+	return Bounds{Rect: Rect{Min: Vec2{X: x, Y: y}, Max: Vec2{X: x + size, Y: y + size}}}
+}
+
+func TestIndexQuery(t *testing.T) {
+	r := goecs.NewRegistry()
+	small, large, far := r.CreateEntity(), r.CreateEntity(), r.CreateEntity()
+	goecs.EmplaceComponent(r, small, box(1, 1, 1))
+	// spans many cells but must be reported once
+	goecs.EmplaceComponent(r, large, box(-10, -10, 30))
+	goecs.EmplaceComponent(r, far, box(100, 100, 1))
+	ix := NewIndex(4)
+	ix.Rebuild(r)
+
+	tests := []struct {
+		name string
+		area Rect
+		want []goecs.Goent
+	}{
+		{"origin", Rect{Max: Vec2{X: 3, Y: 3}}, []goecs.Goent{small, large}},
+		{"same cell, no overlap", Rect{Min: Vec2{X: 2.5, Y: 2.5}, Max: Vec2{X: 3, Y: 3}}, []goecs.Goent{large}},
+		{"far", Rect{Min: Vec2{X: 99, Y: 99}, Max: Vec2{X: 200, Y: 200}}, []goecs.Goent{far}},
+		{"empty", Rect{Min: Vec2{X: 50, Y: 50}, Max: Vec2{X: 60, Y: 60}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []goecs.Goent
+			ix.Query(tt.area, func(e goecs.Goent, _ Rect) { got = append(got, e) })
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Query(%v) = %v, want %v", tt.area, got, tt.want)
+			}
+		})
+	}
+
+	// entities move between rebuilds
+	b, _ := goecs.GetComponent[Bounds](r, small)
+	*b = box(101, 101, 1)
+	ix.Rebuild(r)
+	var got []goecs.Goent
+	ix.Query(Rect{Min: Vec2{X: 99, Y: 99}, Max: Vec2{X: 200, Y: 200}}, func(e goecs.Goent, _ Rect) { got = append(got, e) })
+	slices.Sort(got)
+	if want := []goecs.Goent{small, far}; !slices.Equal(got, want) {
+		t.Errorf("after moving, Query = %v, want %v", got, want)
+	}
+}
+
+func TestCellAt(t *testing.T) {
+	ix := NewIndex(2)
+	if c := ix.CellAt(Vec2{X: -0.5, Y: 3}); c != (Cell{-1, 1}) {
+		t.Errorf("CellAt = %v, want {-1 1}", c)
+	}
+	if b := ix.CellBounds(Cell{-1, 1}); b != (Rect{Min: Vec2{X: -2, Y: 2}, Max: Vec2{X: 0, Y: 4}}) {
+		t.Errorf("CellBounds = %v", b)
+	}
+	if NewIndex(0).CellSize() != 1 {
+		t.Error("non-positive cell size not defaulted")
+	}
+}
@@ -0,0 +1,152 @@
+package goecs
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// --- Component statistics sampling ---
+// A Sampler periodically aggregates a numeric value across every component of
+// a type (Health.HP, the magnitude of a Velocity, ...) into mean, min, max
+// and a histogram, giving designers live balancing insight. The latest
+// results are kept per metric name and served as JSON by Handler.
+
+// FieldStats is the aggregate of one metric at one sample.
+type FieldStats struct {
+	Tick  uint64
+	Count int
+	Mean  float64
+	Min   float64
+	Max   float64
+	// Histogram counts values in equal width buckets from Min to Max.
+	Histogram   []int
+	BucketWidth float64
+}
+
+type sampleSpec struct {
+	name    string
+	buckets int
+	collect func(r *Registry, fn func(v float64))
+}
+
+// Sampler collects FieldStats every N ticks. Sampling runs on the game loop,
+// while Stats and Handler are safe to use from other goroutines.
+type Sampler struct {
+	every uint64
+	tick  uint64
+	specs []sampleSpec
+
+	mu     sync.RWMutex
+	latest map[string]FieldStats
+}
+
+// NewSampler creates a sampler sampling every `every` ticks.
+func NewSampler(every int) *Sampler {
+	if every < 1 {
+		every = 1
+	}
+	return &Sampler{every: uint64(every), latest: make(map[string]FieldStats)}
+}
+
+// SampleField registers a metric aggregating value over every T component
+// passing the filters, with a histogram of the given number of buckets.
+func SampleField[T any](s *Sampler, name string, buckets int, value func(entity Goent, c *T) float64, filters ...Filter) {
+	if buckets < 1 {
+		buckets = 1
+	}
+	s.specs = append(s.specs, sampleSpec{
+		name:    name,
+		buckets: buckets,
+		collect: func(r *Registry, fn func(v float64)) {
+			Iterate1(r, func(entity Goent, c *T) {
+				fn(value(entity, c))
+			}, filters...)
+		},
+	})
+}
+
+// Tick advances the sampler by one tick, sampling the registry when due.
+func (s *Sampler) Tick(r *Registry) {
+	if s.tick%s.every == 0 {
+		s.Sample(r)
+	}
+	s.tick++
+}
+
+// Sample aggregates every registered metric right away.
+func (s *Sampler) Sample(r *Registry) {
+	var values []float64
+	results := make(map[string]FieldStats, len(s.specs))
+	for _, spec := range s.specs {
+		values = values[:0]
+		spec.collect(r, func(v float64) {
+			values = append(values, v)
+		})
+		results[spec.name] = aggregate(values, spec.buckets, s.tick)
+	}
+
+	s.mu.Lock()
+	for name, stats := range results {
+		s.latest[name] = stats
+	}
+	s.mu.Unlock()
+}
+
+func aggregate(values []float64, buckets int, tick uint64) FieldStats {
+	stats := FieldStats{Tick: tick, Count: len(values), Histogram: make([]int, buckets)}
+	if len(values) == 0 {
+		return stats
+	}
+	stats.Min, stats.Max = math.Inf(1), math.Inf(-1)
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+		stats.Min = math.Min(stats.Min, v)
+		stats.Max = math.Max(stats.Max, v)
+	}
+	stats.Mean = sum / float64(len(values))
+
+	stats.BucketWidth = (stats.Max - stats.Min) / float64(buckets)
+	for _, v := range values {
+		b := buckets - 1
+		if stats.BucketWidth > 0 {
+			b = min(int((v-stats.Min)/stats.BucketWidth), buckets-1)
+		}
+		stats.Histogram[b]++
+	}
+	return stats
+}
+
+// Stats returns a copy of the latest results per metric name.
+func (s *Sampler) Stats() map[string]FieldStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]FieldStats, len(s.latest))
+	for name, stats := range s.latest {
+		out[name] = stats
+	}
+	return out
+}
+
+// Handler serves the latest results as JSON:
+//
+//	/            every metric, keyed by name
+//	/names       the metric names, sorted
+func (s *Sampler) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(w, s.Stats())
+	})
+	mux.HandleFunc("/names", func(w http.ResponseWriter, req *http.Request) {
+		stats := s.Stats()
+		names := make([]string, 0, len(stats))
+		for name := range stats {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		writeJSON(w, names)
+	})
+	return mux
+}
@@ -0,0 +1,58 @@
+package goecs
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+type statsHealth struct {
+	HP float64
+}
+
+type statsBoss struct{}
+
+func TestSampler(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(5)
+	for i, e := range entities {
+		EmplaceComponent(r, e, statsHealth{HP: float64(i * 10)})
+	}
+	EmplaceComponent(r, entities[4], statsBoss{})
+
+	s := NewSampler(2)
+	SampleField(s, "hp", 4, func(e Goent, h *statsHealth) float64 { return h.HP })
+	SampleField(s, "minion_hp", 1, func(e Goent, h *statsHealth) float64 { return h.HP }, Without[statsBoss]())
+	s.Tick(r)
+
+	hp := s.Stats()["hp"]
+	want := FieldStats{Count: 5, Mean: 20, Min: 0, Max: 40, Histogram: []int{1, 1, 1, 2}, BucketWidth: 10}
+	if hp.Count != want.Count || hp.Mean != want.Mean || hp.Min != want.Min || hp.Max != want.Max ||
+		hp.BucketWidth != want.BucketWidth || !slices.Equal(hp.Histogram, want.Histogram) {
+		t.Errorf("hp = %+v, want %+v", hp, want)
+	}
+	if minion := s.Stats()["minion_hp"]; minion.Count != 4 || minion.Max != 30 || !slices.Equal(minion.Histogram, []int{4}) {
+		t.Errorf("minion_hp = %+v", minion)
+	}
+
+	// only every second tick samples
+	for _, e := range entities {
+		r.DestroyEntity(e)
+	}
+	s.Tick(r)
+	if hp := s.Stats()["hp"]; hp.Count != 5 {
+		t.Errorf("off tick resampled: %+v", hp)
+	}
+	s.Tick(r)
+	if hp := s.Stats()["hp"]; hp.Count != 0 || hp.Tick != 2 {
+		t.Errorf("empty sample = %+v", hp)
+	}
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/names", nil))
+	var names []string
+	if err := json.NewDecoder(rec.Body).Decode(&names); err != nil || !slices.Equal(names, []string{"hp", "minion_hp"}) {
+		t.Errorf("/names = %v, %v", names, err)
+	}
+}
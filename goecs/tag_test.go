@@ -0,0 +1,50 @@
+package goecs
+
+import (
+	"slices"
+	"testing"
+)
+
+type tagDead struct{}
+
+type tagHealth struct {
+	HP int
+}
+
+func TestTagStorage(t *testing.T) {
+	r := NewRegistry()
+	entities := r.CreateEntities(6)
+	for i, e := range entities {
+		EmplaceComponent(r, e, tagHealth{HP: i})
+		if i%2 == 0 {
+			EmplaceComponent(r, e, tagDead{})
+		}
+	}
+	s := getStorage[tagDead](r)
+	if s.tag == nil || s.components != nil {
+		t.Fatal("zero-size component got a components slice")
+	}
+	EmplaceComponent(r, entities[0], tagDead{})
+	RemoveComponent[tagDead](r, entities[2])
+
+	if c, ok := GetComponent[tagDead](r, entities[4]); !ok || c == nil {
+		t.Errorf("GetComponent of a tag = %v, %v", c, ok)
+	}
+	if HasComponent[tagDead](r, entities[2]) || HasComponent[tagDead](r, entities[1]) {
+		t.Error("tag found on an entity without it")
+	}
+	var dead []int
+	Iterate2(r, func(e Goent, _ *tagDead, h *tagHealth) { dead = append(dead, h.HP) })
+	slices.Sort(dead)
+	if !slices.Equal(dead, []int{0, 4}) || Count[tagDead](r) != 2 {
+		t.Errorf("tagged entities = %v", dead)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		RemoveComponent[tagDead](r, entities[1])
+		EmplaceComponent(r, entities[1], tagDead{})
+	})
+	if allocs != 0 {
+		t.Errorf("tagging an entity allocates %v times", allocs)
+	}
+}
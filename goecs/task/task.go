@@ -0,0 +1,259 @@
+// Package task runs coroutines owned by entities. Cutscenes, ability
+// sequences and other scripted behaviour are written as straight-line code
+// that waits for time to pass or conditions to hold:
+//
+//	runner.Start(r, boss, func(c *task.Co) {
+//		goecs.EmplaceComponent(c.Registry(), boss, Shielded{})
+//		c.Wait(3)
+//		goecs.RemoveComponent[Shielded](c.Registry(), boss)
+//	})
+//
+// A Runner resumes its tasks once per frame, one at a time, on the goroutine
+// calling Tick, so tasks may touch the registry freely while they run. A
+// task whose entity is destroyed is cancelled the next time it would be
+// resumed; its deferred calls still run, so it can undo what it started.
+package task
+
+import (
+	"runtime"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+// Func is the body of a task.
+type Func func(c *Co)
+
+// ID identifies a started task.
+type ID uint64
+
+// Co is a running task, handed to its Func to wait with.
+type Co struct {
+	id     ID
+	entity goecs.Goent
+	r      *goecs.Registry
+	dt     float64
+	// resume hands control to the task, yield hands it back
+	resume    chan struct{}
+	yield     chan struct{}
+	cancelled bool
+	done      bool
+	panicked  interface{}
+}
+
+// ID returns the task's ID.
+func (c *Co) ID() ID {
+	return c.id
+}
+
+// Entity returns the entity owning the task.
+func (c *Co) Entity() goecs.Goent {
+	return c.entity
+}
+
+// Registry returns the registry the task is running against.
+func (c *Co) Registry() *goecs.Registry {
+	return c.r
+}
+
+// DT returns the time elapsed in the current frame.
+func (c *Co) DT() float64 {
+	return c.dt
+}
+
+// Yield suspends the task until the next frame.
+func (c *Co) Yield() {
+	c.yield <- struct{}{}
+	<-c.resume
+	if c.cancelled {
+		runtime.Goexit()
+	}
+}
+
+// Wait suspends the task until seconds have passed.
+func (c *Co) Wait(seconds float64) {
+	for seconds > 0 {
+		c.Yield()
+		seconds -= c.dt
+	}
+}
+
+// WaitFrames suspends the task for n frames.
+func (c *Co) WaitFrames(n int) {
+	for ; n > 0; n-- {
+		c.Yield()
+	}
+}
+
+// WaitUntil suspends the task until cond holds, checking it once per frame.
+// It returns right away if cond already holds.
+func (c *Co) WaitUntil(cond func() bool) {
+	for !cond() {
+		c.Yield()
+	}
+}
+
+// run is the task's goroutine. It waits for the first resume so nothing of
+// the task runs before the first Tick after Start.
+func (c *Co) run(f Func) {
+	defer func() {
+		c.panicked = recover()
+		c.done = true
+		c.yield <- struct{}{}
+	}()
+	<-c.resume
+	if c.cancelled {
+		return
+	}
+	f(c)
+}
+
+// step resumes the task until it yields or finishes.
+func (c *Co) step() {
+	c.resume <- struct{}{}
+	<-c.yield
+}
+
+// Runner ticks the tasks of one registry.
+type Runner struct {
+	tasks []*Co
+	next  ID
+	// running is the task resumed by Tick right now
+	running *Co
+}
+
+// NewRunner creates an empty runner.
+func NewRunner() *Runner {
+	return &Runner{}
+}
+
+// Start starts f as a task owned by the entity. It first runs on the next
+// Tick, so a task started from another task waits for the next frame. Tasks
+// run in the order they were started.
+func (rn *Runner) Start(r *goecs.Registry, entity goecs.Goent, f Func) ID {
+	rn.next++
+	c := &Co{
+		id:     rn.next,
+		entity: entity,
+		r:      r,
+		resume: make(chan struct{}),
+		yield:  make(chan struct{}),
+	}
+	go c.run(f)
+	rn.tasks = append(rn.tasks, c)
+	return c.id
+}
+
+// Running reports whether the task has neither finished nor been cancelled.
+func (rn *Runner) Running(id ID) bool {
+	for _, c := range rn.tasks {
+		if c.id == id {
+			return !c.done
+		}
+	}
+	return false
+}
+
+// Len returns the number of running tasks.
+func (rn *Runner) Len() int {
+	n := 0
+	for _, c := range rn.tasks {
+		if !c.done {
+			n++
+		}
+	}
+	return n
+}
+
+// cancel unwinds the task, running its deferred calls. The running task
+// can't be resumed from inside itself, it unwinds at its next wait instead.
+func (rn *Runner) cancel(c *Co) {
+	if c.done {
+		return
+	}
+	c.cancelled = true
+	if c == rn.running {
+		return
+	}
+	c.step()
+	if c.panicked != nil {
+		panic(c.panicked)
+	}
+}
+
+// Cancel stops the task.
+func (rn *Runner) Cancel(id ID) {
+	for _, c := range rn.tasks {
+		if c.id == id {
+			rn.cancel(c)
+			return
+		}
+	}
+}
+
+// CancelEntity stops every task owned by the entity.
+func (rn *Runner) CancelEntity(entity goecs.Goent) {
+	for _, c := range rn.tasks {
+		if c.entity == entity {
+			rn.cancel(c)
+		}
+	}
+}
+
+// Tick resumes every task once, cancelling those whose entity was destroyed.
+// A panic inside a task is raised again from Tick.
+func (rn *Runner) Tick(r *goecs.Registry, dt float64) {
+	n := len(rn.tasks)
+	for i := 0; i < n; i++ {
+		c := rn.tasks[i]
+		if c.done {
+			continue
+		}
+		if !r.IsAlive(c.entity) {
+			rn.cancel(c)
+			continue
+		}
+		c.r, c.dt = r, dt
+		rn.running = c
+		c.step()
+		rn.running = nil
+		if c.panicked != nil {
+			rn.compact()
+			panic(c.panicked)
+		}
+	}
+	rn.compact()
+}
+
+// compact drops finished tasks, keeping the start order.
+func (rn *Runner) compact() {
+	kept := rn.tasks[:0]
+	for _, c := range rn.tasks {
+		if !c.done {
+			kept = append(kept, c)
+		}
+	}
+	for i := len(kept); i < len(rn.tasks); i++ {
+		rn.tasks[i] = nil
+	}
+	rn.tasks = kept
+}
+
+// Close cancels every task. A runner that is dropped without Close leaks the
+// goroutines of its unfinished tasks.
+func (rn *Runner) Close() {
+	for _, c := range rn.tasks {
+		rn.cancel(c)
+	}
+	rn.tasks = nil
+}
+
+// Access declares the runner exclusive for goecs.Scheduler.Add, since tasks
+// may touch anything.
+func (rn *Runner) Access() goecs.SystemAccess {
+	return goecs.SystemAccess{Exclusive: true}
+}
+
+// Update implements goecs.System.
+func (rn *Runner) Update(w *goecs.World, dt float64) {
+	rn.Tick(w.Registry, dt)
+}
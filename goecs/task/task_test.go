@@ -0,0 +1,104 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+func TestRunner(t *testing.T) {
+	r := goecs.NewRegistry()
+	boss, minion := r.CreateEntity(), r.CreateEntity()
+	rn := NewRunner()
+	defer rn.Close()
+
+	var log []string
+	rn.Start(r, boss, func(c *Co) {
+		log = append(log, "shield up")
+		c.Wait(1)
+		log = append(log, "shield down")
+	})
+	flag := false
+	rn.Start(r, boss, func(c *Co) {
+		c.WaitUntil(func() bool { return flag })
+		log = append(log, "flagged")
+	})
+	cancelled := rn.Start(r, minion, func(c *Co) {
+		defer func() { log = append(log, "minion cleanup") }()
+		for i := 0; ; i++ {
+			log = append(log, fmt.Sprint("minion ", i))
+			c.WaitFrames(2)
+		}
+	})
+	if len(log) != 0 {
+		t.Fatalf("tasks ran before the first Tick: %v", log)
+	}
+
+	for frame := 0; frame < 3; frame++ {
+		rn.Tick(r, 0.4)
+	}
+	want := []string{"shield up", "minion 0", "minion 1"}
+	if !slices.Equal(log, want) {
+		t.Errorf("after three frames: %v, want %v", log, want)
+	}
+
+	// destroying the owner cancels its tasks, running their defers
+	log = nil
+	r.DestroyEntity(minion)
+	flag = true
+	rn.Tick(r, 0.4)
+	want = []string{"shield down", "flagged", "minion cleanup"}
+	if !slices.Equal(log, want) {
+		t.Errorf("fourth frame: %v, want %v", log, want)
+	}
+	if rn.Running(cancelled) || rn.Len() != 0 {
+		t.Errorf("%d tasks still running", rn.Len())
+	}
+}
+
+func TestRunnerCancel(t *testing.T) {
+	r := goecs.NewRegistry()
+	e := r.CreateEntity()
+	rn := NewRunner()
+	defer rn.Close()
+	cleaned := 0
+	body := func(c *Co) {
+		defer func() { cleaned++ }()
+		for {
+			c.Yield()
+		}
+	}
+	first := rn.Start(r, e, body)
+	rn.Start(r, e, body)
+	// a task can cancel itself from inside
+	self := rn.Start(r, e, func(c *Co) {
+		defer func() { cleaned++ }()
+		rn.Cancel(c.ID())
+		c.Yield()
+		t.Error("cancelled task resumed")
+	})
+	rn.Tick(r, 0.1)
+	rn.Cancel(first)
+	if cleaned != 1 || rn.Running(first) {
+		t.Errorf("after Cancel: %d cleaned up, want 1", cleaned)
+	}
+	// the self-cancelled task unwinds when it is resumed
+	rn.Tick(r, 0.1)
+	if cleaned != 2 || rn.Running(self) {
+		t.Errorf("after the next Tick: %d cleaned up, want 2", cleaned)
+	}
+	rn.CancelEntity(e)
+	if cleaned != 3 || rn.Len() != 0 {
+		t.Errorf("after CancelEntity: %d cleaned up, %d running", cleaned, rn.Len())
+	}
+
+	rn.Start(r, e, func(c *Co) { panic("boom") })
+	defer func() {
+		if recover() != "boom" {
+			t.Error("task panic wasn't raised from Tick")
+		}
+	}()
+	rn.Tick(r, 0.1)
+}
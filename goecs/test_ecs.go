@@ -77,7 +77,7 @@ func measureTime(name string, fn func()) {
 // TestEmplaceComponents creates entities and assigns components
 func TestEmplaceComponents(reg *Registry, numEntities int) {
 	for i := 0; i < numEntities; i++ {
-		id := CreateEntity()
+		id := reg.CreateEntity()
 		EmplaceComponent(reg, id, testTransform{
 			X: float64(i),
 			Y: float64(i) * 2,
@@ -0,0 +1,266 @@
+package goecs
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// --- Debug timeline ---
+
+// TimelineFrame is the state of every entity captured at one tick. Component
+// values are keyed by their Go type name and are shallow copies, so slices
+// and maps inside components still share memory with the live world.
+type TimelineFrame struct {
+	Tick     uint64
+	Entities map[Goent]map[string]interface{}
+	// Annotations are copies of the entities' annotations, see Annotate
+	Annotations map[Goent]map[string]string
+}
+
+// TimelineDiff describes what changed between two captured ticks.
+type TimelineDiff struct {
+	From, To uint64
+	Added    []Goent
+	Removed  []Goent
+	// Changed lists, per entity present in both ticks, the component types
+	// that were added, removed or hold a different value.
+	Changed map[Goent][]string
+}
+
+// Timeline is a debugging aid that captures the world every N ticks into a
+// ring buffer, so any entity can be inspected at a past tick and two ticks
+// can be diffed. It is served over HTTP by Handler.
+type Timeline struct {
+	every  uint64
+	tick   uint64
+	frames []*TimelineFrame
+	next   int
+}
+
+// NewTimeline creates a timeline capturing every `every` ticks and keeping
+// the last `capacity` captures.
+func NewTimeline(every, capacity int) *Timeline {
+	if every < 1 {
+		every = 1
+	}
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Timeline{every: uint64(every), frames: make([]*TimelineFrame, capacity)}
+}
+
+// Tick advances the timeline by one tick, capturing the registry when due.
+func (tl *Timeline) Tick(r *Registry) {
+	if tl.tick%tl.every == 0 {
+		tl.Capture(r)
+	}
+	tl.tick++
+}
+
+// Capture records the registry at the current tick right away.
+func (tl *Timeline) Capture(r *Registry) {
+	frame := &TimelineFrame{
+		Tick:        tl.tick,
+		Entities:    make(map[Goent]map[string]interface{}),
+		Annotations: make(map[Goent]map[string]string),
+	}
+	for key, storage := range r.storages {
+		name := key.String()
+		for _, entity := range storage.GetDense() {
+			comp, _ := storage.GetComponent(entity)
+			comps, ok := frame.Entities[entity]
+			if !ok {
+				comps = make(map[string]interface{})
+				frame.Entities[entity] = comps
+			}
+			// comp is a pointer to T, store a copy of the value it points at
+			comps[name] = reflect.ValueOf(comp).Elem().Interface()
+		}
+	}
+	for entity, notes := range r.annotations {
+		frame.Annotations[entity] = copyAnnotations(notes)
+	}
+	tl.frames[tl.next] = frame
+	tl.next = (tl.next + 1) % len(tl.frames)
+}
+
+// Ticks returns the ticks that are still held in the ring buffer, oldest first.
+func (tl *Timeline) Ticks() []uint64 {
+	var ticks []uint64
+	for _, frame := range tl.frames {
+		if frame != nil {
+			ticks = append(ticks, frame.Tick)
+		}
+	}
+	sort.Slice(ticks, func(i, j int) bool { return ticks[i] < ticks[j] })
+	return ticks
+}
+
+// Frame returns the capture taken at the tick.
+func (tl *Timeline) Frame(tick uint64) (*TimelineFrame, bool) {
+	for _, frame := range tl.frames {
+		if frame != nil && frame.Tick == tick {
+			return frame, true
+		}
+	}
+	return nil, false
+}
+
+// Inspect returns the components an entity had at the tick.
+func (tl *Timeline) Inspect(tick uint64, entity Goent) (map[string]interface{}, bool) {
+	frame, ok := tl.Frame(tick)
+	if !ok {
+		return nil, false
+	}
+	comps, ok := frame.Entities[entity]
+	return comps, ok
+}
+
+// visibleTo reports whether the viewer could see the entity when the frame
+// was captured, see Domains.
+func (f *TimelineFrame) visibleTo(entity Goent, viewer DomainMask) bool {
+	d, restricted := f.Entities[entity][typeKeyFor[Domains]().String()].(Domains)
+	return visible(d.Mask, restricted, viewer)
+}
+
+// InspectAs is Inspect for a viewer, which only sees the entities visible to
+// it at the tick.
+func (tl *Timeline) InspectAs(tick uint64, entity Goent, viewer DomainMask) (map[string]interface{}, bool) {
+	frame, ok := tl.Frame(tick)
+	if !ok || !frame.visibleTo(entity, viewer) {
+		return nil, false
+	}
+	return tl.Inspect(tick, entity)
+}
+
+// DiffAs is Diff for a viewer: entities it couldn't see at either tick are
+// left out, and one coming into or going out of view counts as added or
+// removed.
+func (tl *Timeline) DiffAs(from, to uint64, viewer DomainMask) (TimelineDiff, bool) {
+	diff, ok := tl.Diff(from, to)
+	if !ok || viewer == AllDomains {
+		return diff, ok
+	}
+	a, _ := tl.Frame(from)
+	b, _ := tl.Frame(to)
+	out := TimelineDiff{From: from, To: to, Changed: make(map[Goent][]string)}
+	for entity := range a.Entities {
+		if a.visibleTo(entity, viewer) && !b.visibleTo(entity, viewer) {
+			out.Removed = append(out.Removed, entity)
+		}
+	}
+	for entity := range b.Entities {
+		if b.visibleTo(entity, viewer) && !a.visibleTo(entity, viewer) {
+			out.Added = append(out.Added, entity)
+		}
+	}
+	for entity, changed := range diff.Changed {
+		if a.visibleTo(entity, viewer) && b.visibleTo(entity, viewer) {
+			out.Changed[entity] = changed
+		}
+	}
+	sortEntities(out.Added)
+	sortEntities(out.Removed)
+	return out, true
+}
+
+// Diff compares two captured ticks. It reports false if either isn't held anymore.
+func (tl *Timeline) Diff(from, to uint64) (TimelineDiff, bool) {
+	a, okA := tl.Frame(from)
+	b, okB := tl.Frame(to)
+	if !okA || !okB {
+		return TimelineDiff{}, false
+	}
+
+	diff := TimelineDiff{From: from, To: to, Changed: make(map[Goent][]string)}
+	for entity, before := range a.Entities {
+		after, exists := b.Entities[entity]
+		if !exists {
+			diff.Removed = append(diff.Removed, entity)
+			continue
+		}
+		var changed []string
+		for name, value := range before {
+			if other, ok := after[name]; !ok || !reflect.DeepEqual(value, other) {
+				changed = append(changed, name)
+			}
+		}
+		for name := range after {
+			if _, ok := before[name]; !ok {
+				changed = append(changed, name)
+			}
+		}
+		if len(changed) > 0 {
+			sort.Strings(changed)
+			diff.Changed[entity] = changed
+		}
+	}
+	for entity := range b.Entities {
+		if _, exists := a.Entities[entity]; !exists {
+			diff.Added = append(diff.Added, entity)
+		}
+	}
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i] < diff.Added[j] })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i] < diff.Removed[j] })
+	return diff, true
+}
+
+// Handler serves the timeline as JSON:
+//
+//	/ticks                      captured ticks, oldest first
+//	/entity?tick=T&id=E         components of entity E at tick T
+//	/diff?from=A&to=B           what changed between ticks A and B
+//
+// The timeline is not safe for concurrent use, so only serve it while the
+// game loop isn't ticking it, or guard both with the same lock.
+func (tl *Timeline) Handler() http.Handler {
+	return tl.HandlerFor(AllDomains)
+}
+
+// HandlerFor serves the timeline like Handler, showing only what the viewer
+// may see, see InspectAs and DiffAs.
+func (tl *Timeline) HandlerFor(viewer DomainMask) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ticks", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(w, tl.Ticks())
+	})
+	mux.HandleFunc("/entity", func(w http.ResponseWriter, req *http.Request) {
+		tick, err1 := strconv.ParseUint(req.URL.Query().Get("tick"), 10, 64)
+		id, err2 := strconv.ParseUint(req.URL.Query().Get("id"), 10, 64)
+		if err1 != nil || err2 != nil {
+			http.Error(w, "tick and id must be unsigned integers", http.StatusBadRequest)
+			return
+		}
+		comps, ok := tl.InspectAs(tick, Goent(id), viewer)
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		writeJSON(w, comps)
+	})
+	mux.HandleFunc("/diff", func(w http.ResponseWriter, req *http.Request) {
+		from, err1 := strconv.ParseUint(req.URL.Query().Get("from"), 10, 64)
+		to, err2 := strconv.ParseUint(req.URL.Query().Get("to"), 10, 64)
+		if err1 != nil || err2 != nil {
+			http.Error(w, "from and to must be unsigned integers", http.StatusBadRequest)
+			return
+		}
+		diff, ok := tl.DiffAs(from, to, viewer)
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		writeJSON(w, diff)
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,95 @@
+package goecs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+type timelinePos struct {
+	X int
+}
+
+type timelineTag struct{}
+
+func TestTimeline(t *testing.T) {
+	r := NewRegistry()
+	a, b := r.CreateEntity(), r.CreateEntity()
+	pos := EmplaceComponent(r, a, timelinePos{X: 1})
+	EmplaceComponent(r, b, timelinePos{X: 2})
+
+	tl := NewTimeline(2, 2)
+	tl.Tick(r) // captures tick 0
+	pos.X = 5
+	tl.Tick(r)
+	EmplaceComponent(r, a, timelineTag{})
+	r.DestroyEntity(b)
+	c := r.CreateEntity()
+	EmplaceComponent(r, c, timelinePos{X: 3})
+	tl.Tick(r) // captures tick 2
+
+	if got := tl.Ticks(); !slices.Equal(got, []uint64{0, 2}) {
+		t.Fatalf("Ticks() = %v, want [0 2]", got)
+	}
+	comps, ok := tl.Inspect(0, a)
+	if !ok || comps["goecs.timelinePos"] != (timelinePos{X: 1}) {
+		t.Errorf("Inspect(0, a) = %v, %v, want X 1", comps, ok)
+	}
+	if _, ok := tl.Inspect(2, b); ok {
+		t.Error("destroyed entity shows up at tick 2")
+	}
+
+	diff, ok := tl.Diff(0, 2)
+	if !ok {
+		t.Fatal("Diff(0, 2) not available")
+	}
+	if !slices.Equal(diff.Added, []Goent{c}) || !slices.Equal(diff.Removed, []Goent{b}) {
+		t.Errorf("diff added %v and removed %v", diff.Added, diff.Removed)
+	}
+	if got := diff.Changed[a]; !slices.Equal(got, []string{"goecs.timelinePos", "goecs.timelineTag"}) {
+		t.Errorf("entity a changed %v", got)
+	}
+
+	// the ring buffer drops the oldest capture
+	tl.Tick(r)
+	tl.Tick(r)
+	if got := tl.Ticks(); !slices.Equal(got, []uint64{2, 4}) {
+		t.Errorf("Ticks() = %v after wrapping, want [2 4]", got)
+	}
+	if _, ok := tl.Diff(0, 2); ok {
+		t.Error("Diff against an evicted tick succeeded")
+	}
+}
+
+func TestTimelineHandler(t *testing.T) {
+	r := NewRegistry()
+	e := r.CreateEntity()
+	EmplaceComponent(r, e, timelinePos{X: 7})
+	tl := NewTimeline(1, 4)
+	tl.Tick(r)
+	srv := httptest.NewServer(tl.Handler())
+	defer srv.Close()
+
+	get := func(path string) (*http.Response, map[string]interface{}) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp, body
+	}
+	if resp, body := get("/entity?tick=0&id=0"); resp.StatusCode != http.StatusOK || body["goecs.timelinePos"] == nil {
+		t.Errorf("/entity answered %d with %v", resp.StatusCode, body)
+	}
+	if resp, _ := get("/entity?tick=9&id=0"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("/entity for a missing tick answered %d", resp.StatusCode)
+	}
+	if resp, _ := get("/diff?from=x&to=0"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("/diff with a bad tick answered %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,161 @@
+// Package timer provides named per-entity timers for cooldowns, buffs and
+// delayed actions. An entity carries all its timers in one Timers component,
+// and the System counts them down, reporting every expiry as an Expired
+// event:
+//
+//	timer.StartTimer(r, player, "dash", 1.5)
+//	...
+//	if timer.IsReady(r, player, "dash") { ... }
+package timer
+
+import (
+	"reflect"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+// Timer is one named countdown.
+type Timer struct {
+	Name      string
+	Remaining float64
+	// Interval restarts the timer after every expiry when above zero.
+	Interval float64
+}
+
+// Timers holds the running timers of an entity in the order they were
+// started.
+type Timers struct {
+	Entries []Timer
+}
+
+// Expired is the event of a timer running out.
+type Expired struct {
+	Entity goecs.Goent
+	Name   string
+}
+
+func (t *Timers) find(name string) int {
+	for i := range t.Entries {
+		if t.Entries[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func start(r *goecs.Registry, e goecs.Goent, tm Timer) {
+	t, ok := goecs.GetComponent[Timers](r, e)
+	if !ok {
+		goecs.EmplaceComponent(r, e, Timers{Entries: []Timer{tm}})
+		return
+	}
+	if i := t.find(tm.Name); i >= 0 {
+		t.Entries[i] = tm
+	} else {
+		t.Entries = append(t.Entries, tm)
+	}
+}
+
+// StartTimer starts a one-shot timer, restarting it if it is running. The
+// first start adds the entity's Timers, so it must not be called from inside
+// an iteration over an entity without one.
+func StartTimer(r *goecs.Registry, e goecs.Goent, name string, seconds float64) {
+	start(r, e, Timer{Name: name, Remaining: seconds})
+}
+
+// StartRepeating starts a timer expiring every interval seconds until it is
+// stopped.
+func StartRepeating(r *goecs.Registry, e goecs.Goent, name string, interval float64) {
+	start(r, e, Timer{Name: name, Remaining: interval, Interval: interval})
+}
+
+// StopTimer stops the timer without it expiring.
+func StopTimer(r *goecs.Registry, e goecs.Goent, name string) {
+	if t, ok := goecs.GetComponent[Timers](r, e); ok {
+		if i := t.find(name); i >= 0 {
+			t.Entries = append(t.Entries[:i], t.Entries[i+1:]...)
+		}
+	}
+}
+
+// Remaining returns the time left on the timer, or false if it isn't
+// running.
+func Remaining(r *goecs.Registry, e goecs.Goent, name string) (float64, bool) {
+	t, ok := goecs.GetComponent[Timers](r, e)
+	if !ok {
+		return 0, false
+	}
+	i := t.find(name)
+	if i < 0 {
+		return 0, false
+	}
+	return t.Entries[i].Remaining, true
+}
+
+// IsReady reports whether the timer isn't running, i.e. the cooldown it
+// tracks is over.
+func IsReady(r *goecs.Registry, e goecs.Goent, name string) bool {
+	_, running := Remaining(r, e, name)
+	return !running
+}
+
+// System counts the timers down. Added to a goecs.World it advances them by
+// the frame time; scheduled with AddQueued it advances them by the fixed
+// Step every run and emits the expiries to the queue.
+type System struct {
+	Step    float64
+	expired []Expired
+}
+
+// NewSystem creates a system advancing scheduled runs by step seconds.
+func NewSystem(step float64) *System {
+	return &System{Step: step}
+}
+
+// Advance counts every timer down by dt and returns the expiries, in
+// iteration order, then start order per entity. A repeating timer expires
+// once per interval that passed. The slice is reused by the next Advance.
+func (s *System) Advance(r *goecs.Registry, dt float64) []Expired {
+	s.expired = s.expired[:0]
+	goecs.Iterate1(r, func(e goecs.Goent, t *Timers) {
+		kept := t.Entries[:0]
+		for _, tm := range t.Entries {
+			tm.Remaining -= dt
+			for tm.Remaining <= 0 {
+				s.expired = append(s.expired, Expired{Entity: e, Name: tm.Name})
+				if tm.Interval <= 0 {
+					break
+				}
+				tm.Remaining += tm.Interval
+			}
+			if tm.Remaining > 0 {
+				kept = append(kept, tm)
+			}
+		}
+		t.Entries = kept
+	})
+	return s.expired
+}
+
+// Expired returns the expiries of the last Advance or Update.
+func (s *System) Expired() []Expired {
+	return s.expired
+}
+
+// Run advances the timers by Step and emits an Expired event per expiry, for
+// goecs.Scheduler.AddQueued.
+func (s *System) Run(r *goecs.Registry, q *goecs.SystemQueue) {
+	for _, ev := range s.Advance(r, s.Step) {
+		q.Emit(ev.Entity, ev)
+	}
+}
+
+// Access is what Run touches, for goecs.Scheduler.AddQueued.
+func (s *System) Access() goecs.SystemAccess {
+	return goecs.SystemAccess{Writes: []reflect.Type{goecs.ComponentType[Timers]()}}
+}
+
+// Update implements goecs.System.
+func (s *System) Update(w *goecs.World, dt float64) {
+	s.Advance(w.Registry, dt)
+}
@@ -0,0 +1,58 @@
+package timer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Swedeachu/go_ecs/goecs"
+)
+
+func TestTimers(t *testing.T) {
+	r := goecs.NewRegistry()
+	player := r.CreateEntity()
+	StartTimer(r, player, "dash", 1)
+	StartRepeating(r, player, "regen", 0.5)
+	StartTimer(r, player, "bomb", 0.25)
+	StopTimer(r, player, "bomb")
+	if IsReady(r, player, "dash") || !IsReady(r, player, "bomb") {
+		t.Fatal("IsReady doesn't track running timers")
+	}
+
+	s := NewSystem(0)
+	if got := s.Advance(r, 0.75); !slices.Equal(got, []Expired{{player, "regen"}}) {
+		t.Errorf("first Advance = %v", got)
+	}
+	if left, _ := Remaining(r, player, "dash"); left != 0.25 {
+		t.Errorf("dash has %v left, want 0.25", left)
+	}
+	// restarting replaces the running timer
+	StartTimer(r, player, "dash", 2)
+	// a long frame fires a repeating timer once per interval
+	want := []Expired{{player, "regen"}, {player, "regen"}, {player, "regen"}}
+	if got := s.Advance(r, 1.5); !slices.Equal(got, want) {
+		t.Errorf("second Advance = %v, want %v", got, want)
+	}
+	if got := s.Advance(r, 0.5); !slices.Equal(got, []Expired{{player, "dash"}, {player, "regen"}}) || !IsReady(r, player, "dash") {
+		t.Errorf("third Advance = %v", got)
+	}
+}
+
+func TestTimersScheduled(t *testing.T) {
+	r := goecs.NewRegistry()
+	e := r.CreateEntity()
+	StartTimer(r, e, "fuse", 0.5)
+	s := NewSystem(0.25)
+	sched := goecs.NewScheduler(1)
+	defer sched.Close()
+	sched.AddQueued("timers", s.Access(), s.Run)
+
+	sched.Run(r)
+	if len(sched.Events()) != 0 {
+		t.Errorf("fuse expired early: %v", sched.Events())
+	}
+	sched.Run(r)
+	events := sched.Events()
+	if len(events) != 1 || events[0].Entity != e || events[0].Payload != (Expired{e, "fuse"}) {
+		t.Errorf("events = %v, want the fuse expiring", events)
+	}
+}
@@ -0,0 +1,275 @@
+package goecs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// --- Component type table ---
+// The registry remembers every component type it has stored, together with
+// the type-erased operations that features working without compile-time
+// types (serialization, tooling) need to recreate components.
+//
+// Serialized data identifies types by name. reflect.Type identities aren't
+// stable across builds and Go type names change with refactors, so types
+// meant to be persisted get a stable name with RegisterNamedComponent. Other
+// types fall back to their Go type name. Names map one to one to types; a
+// type whose Go type name is already taken, such as a second function-local
+// type of the same name, stays out of the name table and can be stored but
+// not serialized until it is given a name.
+
+// componentInfo describes one component type the registry has seen.
+type componentInfo struct {
+	typ reflect.Type
+	// name identifies the type in serialized data
+	name string
+	// named is set once the name was given with RegisterNamedComponent
+	named bool
+	// after lists the types restored before this one, see RestoreAfter
+	after []*componentInfo
+
+	emplace    func(r *Registry, entity Goent, value reflect.Value)
+	remove     func(r *Registry, entity Goent)
+	decodeJSON func(raw json.RawMessage) (reflect.Value, error)
+	// registerNamed is RegisterNamedComponent for this type
+	registerNamed func(r *Registry, name string)
+}
+
+// componentNotes maps every type any registry has recorded to its
+// noteComponent, so code holding only a reflect.Type, such as prefabs built
+// from plain values, can record it in another registry.
+var componentNotes sync.Map
+
+// noteFor returns the noteComponent of a type some registry has recorded.
+func noteFor(t reflect.Type) (func(r *Registry) *componentInfo, bool) {
+	note, ok := componentNotes.Load(t)
+	if !ok {
+		return nil, false
+	}
+	return note.(func(r *Registry) *componentInfo), true
+}
+
+// noteComponent records T in the type table if it isn't there yet.
+func noteComponent[T any](r *Registry) *componentInfo {
+	key := typeKeyFor[T]()
+	if info, ok := r.componentTypes[key]; ok {
+		return info
+	}
+	info := &componentInfo{
+		typ:  key,
+		name: key.String(),
+		emplace: func(r *Registry, entity Goent, value reflect.Value) {
+			EmplaceComponent(r, entity, value.Interface().(T))
+		},
+		remove: func(r *Registry, entity Goent) {
+			RemoveComponent[T](r, entity)
+		},
+		registerNamed: func(r *Registry, name string) {
+			RegisterNamedComponent[T](r, name)
+		},
+		decodeJSON: func(raw json.RawMessage) (reflect.Value, error) {
+			var comp T
+			err := json.Unmarshal(raw, &comp)
+			return reflect.ValueOf(comp), err
+		},
+	}
+	r.componentTypes[key] = info
+	componentNotes.LoadOrStore(key, noteComponent[T])
+	if _, taken := r.componentNames[info.name]; !taken {
+		r.componentNames[info.name] = info
+	}
+	r.ranks = nil
+	if _, ok := r.dirty[key]; !ok {
+		r.dirty[key] = make(map[Goent]DirtyMask)
+	}
+	return info
+}
+
+// noteBuiltins records the component types the package defines itself, so
+// snapshots holding them load into a fresh registry.
+func (r *Registry) noteBuiltins() {
+	noteComponent[Disabled](r)
+	noteComponent[Blob](r)
+	noteComponent[Parent](r)
+	noteComponent[Children](r)
+}
+
+// RegisterNamedComponent gives T a stable name used by every serialization
+// feature (Save/Load, JSON, prefabs) instead of its Go type name. It panics
+// if the name belongs to another type or T was already given another name.
+func RegisterNamedComponent[T any](r *Registry, name string) {
+	info := noteComponent[T](r)
+	if info.named {
+		if info.name != name {
+			panic(fmt.Sprintf("goecs: %s is already registered as %q", info.typ, info.name))
+		}
+		return
+	}
+	if other, taken := r.componentNames[name]; taken && other != info {
+		panic(fmt.Sprintf("goecs: component name %q is already used by %s", name, other.typ))
+	}
+	old := info.name
+	info.name = name
+	info.named = true
+	if r.componentNames[old] == info {
+		r.releaseName(old)
+	}
+	r.componentNames[name] = info
+	r.ranks = nil
+	r.prewarm(info)
+}
+
+// releaseName frees a Go type name, handing it to another unnamed type that
+// was left out of the name table for sharing it.
+func (r *Registry) releaseName(name string) {
+	delete(r.componentNames, name)
+	for _, info := range r.sortedComponentTypes() {
+		if !info.named && info.name == name {
+			r.componentNames[name] = info
+			return
+		}
+	}
+}
+
+// serialName returns the name info is serialized under, or an error if the
+// type was left out of the name table for sharing its Go type name.
+func (r *Registry) serialName(info *componentInfo) (string, error) {
+	if other := r.componentNames[info.name]; other != info {
+		return "", fmt.Errorf("goecs: %s shares the name %q with %s, give it a name with RegisterNamedComponent", info.typ, info.name, other.typ)
+	}
+	return info.name, nil
+}
+
+// RestoreAfter declares that T needs Dep in place when it is restored, like
+// transforms needing the hierarchy or references needing a GUID table. Load,
+// LoadRemapped, ReadDelta, ImportJSON and prefab spawns emplace every Dep
+// component before any T, so hooks on T see their prerequisites. It panics
+// if the declaration closes a cycle.
+func RestoreAfter[T, Dep any](r *Registry) {
+	info, dep := noteComponent[T](r), noteComponent[Dep](r)
+	if dep == info || dep.restoresAfter(info) {
+		panic(fmt.Sprintf("goecs: restoring %s after %s would be circular", info.typ, dep.typ))
+	}
+	info.after = append(info.after, dep)
+	r.ranks = nil
+}
+
+// restoresAfter reports whether info depends on other, directly or not.
+func (info *componentInfo) restoresAfter(other *componentInfo) bool {
+	for _, dep := range info.after {
+		if dep == other || dep.restoresAfter(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreRanks numbers the known types in restore order: dependencies first,
+// by name otherwise. The result is cached and must not be modified.
+func (r *Registry) restoreRanks() map[*componentInfo]int {
+	if r.ranks != nil {
+		return r.ranks
+	}
+	ranks := make(map[*componentInfo]int, len(r.componentTypes))
+	var place func(info *componentInfo)
+	place = func(info *componentInfo) {
+		if _, done := ranks[info]; done {
+			return
+		}
+		for _, dep := range info.after {
+			place(dep)
+		}
+		ranks[info] = len(ranks)
+	}
+	for _, info := range r.sortedComponentTypes() {
+		place(info)
+	}
+	r.ranks = ranks
+	return ranks
+}
+
+// restoreOrder returns the known types in restore order.
+func (r *Registry) restoreOrder() []*componentInfo {
+	ranks := r.restoreRanks()
+	infos := make([]*componentInfo, len(ranks))
+	for info, rank := range ranks {
+		infos[rank] = info
+	}
+	return infos
+}
+
+// ComponentName returns the name the registry serializes a type under.
+func (r *Registry) ComponentName(t reflect.Type) (string, bool) {
+	info, ok := r.componentTypes[t]
+	if !ok {
+		return "", false
+	}
+	return info.name, true
+}
+
+// ComponentTypeByName returns the type serialized under a name.
+func (r *Registry) ComponentTypeByName(name string) (reflect.Type, bool) {
+	info, ok := r.componentNames[name]
+	if !ok {
+		return nil, false
+	}
+	return info.typ, true
+}
+
+// namedComponent returns the type registered under name with
+// RegisterNamedComponent.
+func (r *Registry) namedComponent(name string) (*componentInfo, bool) {
+	info, ok := r.componentNames[name]
+	return info, ok && info.named
+}
+
+// sortedComponentTypes returns the known types ordered by name, so anything
+// encoded from them comes out deterministic.
+func (r *Registry) sortedComponentTypes() []*componentInfo {
+	infos := make([]*componentInfo, 0, len(r.componentTypes))
+	for _, info := range r.componentTypes {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].name < infos[j].name })
+	return infos
+}
+
+// eachEntity calls fn once for every entity that has at least one component,
+// on either backend, in no particular order.
+func (r *Registry) eachEntity(fn func(entity Goent)) {
+	if r.archetypes != nil {
+		for _, a := range r.archetypes.list {
+			for _, entity := range a.entities {
+				fn(entity)
+			}
+		}
+		return
+	}
+	seen := make(map[Goent]struct{})
+	for _, storage := range r.storages {
+		for _, entity := range storage.GetDense() {
+			if _, ok := seen[entity]; !ok {
+				seen[entity] = struct{}{}
+				fn(entity)
+			}
+		}
+	}
+}
+
+// VisitEntity calls fn with every component of the entity and its type, in
+// type name order, for tooling that can't name the component types up front.
+// comp is a pointer to the stored component. fn must not add or remove
+// components.
+func (r *Registry) VisitEntity(entity Goent, fn func(t reflect.Type, comp interface{})) {
+	if r.isStale(entity) {
+		return
+	}
+	for _, info := range r.sortedComponentTypes() {
+		if comp, ok := r.componentOf(entity, info.typ); ok {
+			fn(info.typ, comp)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package goecs
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+type namedTransform struct {
+	X float64
+}
+
+type namedMesh struct {
+	ID int
+}
+
+func TestRegisterNamedComponent(t *testing.T) {
+	r := NewRegistry()
+	RegisterNamedComponent[namedTransform](r, "game.Transform")
+	RegisterNamedComponent[namedTransform](r, "game.Transform")
+	transform := ComponentType[namedTransform]()
+	if name, ok := r.ComponentName(transform); !ok || name != "game.Transform" {
+		t.Errorf("ComponentName = %q, %v", name, ok)
+	}
+	if typ, ok := r.ComponentTypeByName("game.Transform"); !ok || typ != transform {
+		t.Errorf("ComponentTypeByName = %v, %v", typ, ok)
+	}
+	// unnamed types go by their Go type name until they are named
+	EmplaceComponent(r, r.CreateEntity(), namedMesh{})
+	mesh := ComponentType[namedMesh]()
+	if name, _ := r.ComponentName(mesh); name != mesh.String() {
+		t.Errorf("unnamed type is called %q, want %q", name, mesh.String())
+	}
+	RegisterNamedComponent[namedMesh](r, "game.Mesh")
+	if _, ok := r.ComponentTypeByName(mesh.String()); ok {
+		t.Error("renamed type still answers to its Go type name")
+	}
+
+	tests := []struct {
+		name     string
+		register func()
+	}{
+		{"name taken", func() { RegisterNamedComponent[namedMesh](r, "game.Transform") }},
+		{"renamed", func() { RegisterNamedComponent[namedTransform](r, "game.Xform") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("RegisterNamedComponent didn't panic")
+				}
+			}()
+			tt.register()
+		})
+	}
+	if typ, _ := r.ComponentTypeByName("game.Transform"); typ != transform {
+		t.Error("a rejected registration changed the table")
+	}
+}
+
+func TestSharedTypeName(t *testing.T) {
+	r := NewRegistry()
+	e := r.CreateEntity()
+	first := func() reflect.Type {
+		type pos struct{ X int }
+		EmplaceComponent(r, e, pos{X: 1})
+		return ComponentType[pos]()
+	}()
+	second := func() reflect.Type {
+		type pos struct{ Y int }
+		EmplaceComponent(r, e, pos{Y: 2})
+		return ComponentType[pos]()
+	}()
+	if first.String() != second.String() {
+		t.Fatalf("test types are called %q and %q", first, second)
+	}
+	n := 0
+	r.VisitEntity(e, func(reflect.Type, interface{}) { n++ })
+	if n != 2 {
+		t.Fatalf("entity holds %d components, want 2", n)
+	}
+	if typ, _ := r.ComponentTypeByName(first.String()); typ != first {
+		t.Errorf("name resolves to %v, want the first type", typ)
+	}
+	if err := r.Save(&bytes.Buffer{}); err == nil {
+		t.Error("Save of a type without a name of its own succeeded")
+	}
+
+	// naming the first type hands the Go type name over to the second
+	r.componentTypes[first].registerNamed(r, "game.Pos")
+	if typ, _ := r.ComponentTypeByName(second.String()); typ != second {
+		t.Errorf("name resolves to %v, want the second type", typ)
+	}
+	if err := r.Save(&bytes.Buffer{}); err != nil {
+		t.Error(err)
+	}
+}
+
+type restoreHierarchy struct{ Depth int }
+type restoreTransform struct{ X int }
+type restoreZ struct{ Z int }
+
+func TestRestoreAfter(t *testing.T) {
+	newTarget := func() (*Registry, *[]string) {
+		r := NewRegistry()
+		RegisterComponent[restoreTransform](r)
+		RegisterComponent[restoreHierarchy](r)
+		RegisterComponent[restoreZ](r)
+		// by name restoreZ would come last
+		RestoreAfter[restoreTransform, restoreHierarchy](r)
+		RestoreAfter[restoreHierarchy, restoreZ](r)
+		var log []string
+		OnAdd(r, func(e Goent, _ *restoreTransform) {
+			if !HasComponent[restoreHierarchy](r, e) {
+				t.Errorf("transform of entity %d restored before its hierarchy", e)
+			}
+			log = append(log, "transform")
+		})
+		OnAdd(r, func(e Goent, _ *restoreHierarchy) {
+			if !HasComponent[restoreZ](r, e) {
+				t.Errorf("hierarchy of entity %d restored before its z", e)
+			}
+			log = append(log, "hierarchy")
+		})
+		return r, &log
+	}
+
+	src, _ := newTarget()
+	for _, e := range src.CreateEntities(3) {
+		EmplaceComponent(src, e, restoreZ{})
+		EmplaceComponent(src, e, restoreHierarchy{})
+		EmplaceComponent(src, e, restoreTransform{})
+	}
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dst, log := newTarget()
+	if err := dst.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if len(*log) != 6 {
+		t.Errorf("Load fired %v", *log)
+	}
+
+	// declarations closing a cycle are rejected
+	defer func() {
+		if recover() == nil {
+			t.Error("circular RestoreAfter didn't panic")
+		}
+	}()
+	RestoreAfter[restoreZ, restoreTransform](dst)
+}
+
+func TestVisitEntity(t *testing.T) {
+	for _, b := range iterBackends {
+		t.Run(b.name, func(t *testing.T) {
+			r := b.new()
+			entities := newIterWorld(r, 8)
+			RegisterNamedComponent[iterF](r, "a.first")
+
+			var names []string
+			r.VisitEntity(entities[6], func(typ reflect.Type, comp interface{}) {
+				name, _ := r.ComponentName(typ)
+				names = append(names, name)
+				if reflect.TypeOf(comp) != reflect.PointerTo(typ) {
+					t.Errorf("%s visited as %T", name, comp)
+				}
+				if a, ok := comp.(*iterA); ok {
+					a.V = 60
+				}
+			})
+			// entity 6 is divisible by 2, 3 and 6
+			want := []string{"a.first", "goecs.iterA", "goecs.iterB", "goecs.iterC"}
+			if !slices.Equal(names, want) {
+				t.Errorf("visited %v, want %v", names, want)
+			}
+			if a, _ := GetComponent[iterA](r, entities[6]); a.V != 60 {
+				t.Errorf("write through the visited pointer gave iterA %d", a.V)
+			}
+
+			r.DestroyEntity(entities[6])
+			r.VisitEntity(entities[6], func(typ reflect.Type, _ interface{}) {
+				t.Errorf("destroyed entity visited %v", typ)
+			})
+		})
+	}
+}
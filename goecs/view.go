@@ -0,0 +1,680 @@
+package goecs
+
+import (
+	"reflect"
+)
+
+// --- Cached views ---
+// Views resolve their storages once at creation instead of on every call.
+// A view keeps pointing at the storages it was created with, so registering
+// one of its types again with RegisterComponent requires a new view. On an
+// archetype registry views hold no storages and fall back to the archetype
+// queries.
+
+// viewStorage returns the storage a view caches for T, creating it if
+// needed. Archetype registries only get T noted, since a sparse set there
+// would sit empty next to the archetypes.
+func viewStorage[T any](r *Registry) *SparseSet[T] {
+	if r.archetypes != nil {
+		if _, known := r.componentTypes[typeKeyFor[T]()]; !known {
+			noteComponent[T](r)
+		}
+		return nil
+	}
+	return ensureStorage[T](r)
+}
+
+// View1 is a cached query over entities with a T1 component.
+type View1[T1 any] struct {
+	r       *Registry
+	filters []Filter
+	s1      *SparseSet[T1]
+}
+
+// NewView1 creates a view, registering any of the component types that
+// don't have a storage yet. The filters apply to every call on the view.
+func NewView1[T1 any](r *Registry, filters ...Filter) *View1[T1] {
+	return &View1[T1]{
+		r:       r,
+		filters: filters,
+		s1:      viewStorage[T1](r),
+	}
+}
+
+// Each calls f for every entity matching the view.
+func (v *View1[T1]) Each(f func(entity Goent, c1 *T1)) {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		archIterate1(v.r, &fs, f)
+		return
+	}
+	for i, entity := range v.s1.dense {
+		if fs.skip(entity) {
+			continue
+		}
+		f(entity, v.s1.at(i))
+	}
+}
+
+// Contains reports whether the entity currently matches the view.
+func (v *View1[T1]) Contains(entity Goent) bool {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		return v.r.archetypes.entityMatches(entity, &fs, []reflect.Type{typeKeyFor[T1]()})
+	}
+	if fs.skip(entity) {
+		return false
+	}
+	_, ok := v.s1.Get(entity)
+	return ok
+}
+
+// Len returns how many entities match the view. Without filters and with
+// no entity disabled it is the size of the storage and doesn't walk anything.
+func (v *View1[T1]) Len() int {
+	if len(v.filters) == 0 && v.r.archetypes == nil {
+		if disabled := getStorage[Disabled](v.r); disabled == nil || len(disabled.dense) == 0 {
+			return len(v.s1.dense)
+		}
+	}
+	count := 0
+	v.Each(func(Goent, *T1) {
+		count++
+	})
+	return count
+}
+
+// View2 is a cached query over entities with T1 and T2 components.
+type View2[T1 any, T2 any] struct {
+	r       *Registry
+	filters []Filter
+	s1      *SparseSet[T1]
+	s2      *SparseSet[T2]
+}
+
+// NewView2 creates a view, registering any of the component types that
+// don't have a storage yet. The filters apply to every call on the view.
+func NewView2[T1 any, T2 any](r *Registry, filters ...Filter) *View2[T1, T2] {
+	return &View2[T1, T2]{
+		r:       r,
+		filters: filters,
+		s1:      viewStorage[T1](r),
+		s2:      viewStorage[T2](r),
+	}
+}
+
+func (v *View2[T1, T2]) columns(fs *filterSet) (column[T1], column[T2]) {
+	return column[T1]{storage: v.s1, optional: fs.isOptional(typeKeyFor[T1]())},
+		column[T2]{storage: v.s2, optional: fs.isOptional(typeKeyFor[T2]())}
+}
+
+// Each calls f for every entity matching the view.
+func (v *View2[T1, T2]) Each(f func(entity Goent, c1 *T1, c2 *T2)) {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		archIterate2(v.r, &fs, f)
+		return
+	}
+	c1, c2 := v.columns(&fs)
+
+	iterateDense(driverDense(c1, c2), func(entity Goent) {
+		if fs.skip(entity) {
+			return
+		}
+		p1, ok1 := c1.get(entity)
+		p2, ok2 := c2.get(entity)
+		if ok1 && ok2 {
+			f(entity, p1, p2)
+		}
+	})
+}
+
+// Contains reports whether the entity currently matches the view.
+func (v *View2[T1, T2]) Contains(entity Goent) bool {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		return v.r.archetypes.entityMatches(entity, &fs, []reflect.Type{typeKeyFor[T1](), typeKeyFor[T2]()})
+	}
+	if fs.skip(entity) {
+		return false
+	}
+	c1, c2 := v.columns(&fs)
+	_, ok1 := c1.get(entity)
+	_, ok2 := c2.get(entity)
+	return ok1 && ok2
+}
+
+// Len returns how many entities match the view. It walks the smallest
+// storage checking membership but never calls back into user code.
+func (v *View2[T1, T2]) Len() int {
+	count := 0
+	v.Each(func(Goent, *T1, *T2) {
+		count++
+	})
+	return count
+}
+
+// View3 is a cached query over entities with T1, T2, and T3 components.
+type View3[T1 any, T2 any, T3 any] struct {
+	r       *Registry
+	filters []Filter
+	s1      *SparseSet[T1]
+	s2      *SparseSet[T2]
+	s3      *SparseSet[T3]
+}
+
+// NewView3 creates a view, registering any of the component types that
+// don't have a storage yet. The filters apply to every call on the view.
+func NewView3[T1 any, T2 any, T3 any](r *Registry, filters ...Filter) *View3[T1, T2, T3] {
+	return &View3[T1, T2, T3]{
+		r:       r,
+		filters: filters,
+		s1:      viewStorage[T1](r),
+		s2:      viewStorage[T2](r),
+		s3:      viewStorage[T3](r),
+	}
+}
+
+func (v *View3[T1, T2, T3]) columns(fs *filterSet) (column[T1], column[T2], column[T3]) {
+	return column[T1]{storage: v.s1, optional: fs.isOptional(typeKeyFor[T1]())},
+		column[T2]{storage: v.s2, optional: fs.isOptional(typeKeyFor[T2]())},
+		column[T3]{storage: v.s3, optional: fs.isOptional(typeKeyFor[T3]())}
+}
+
+// Each calls f for every entity matching the view.
+func (v *View3[T1, T2, T3]) Each(f func(entity Goent, c1 *T1, c2 *T2, c3 *T3)) {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		archIterate3(v.r, &fs, f)
+		return
+	}
+	c1, c2, c3 := v.columns(&fs)
+
+	iterateDense(driverDense(c1, c2, c3), func(entity Goent) {
+		if fs.skip(entity) {
+			return
+		}
+		p1, ok1 := c1.get(entity)
+		p2, ok2 := c2.get(entity)
+		p3, ok3 := c3.get(entity)
+		if ok1 && ok2 && ok3 {
+			f(entity, p1, p2, p3)
+		}
+	})
+}
+
+// Contains reports whether the entity currently matches the view.
+func (v *View3[T1, T2, T3]) Contains(entity Goent) bool {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		return v.r.archetypes.entityMatches(entity, &fs, []reflect.Type{typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3]()})
+	}
+	if fs.skip(entity) {
+		return false
+	}
+	c1, c2, c3 := v.columns(&fs)
+	_, ok1 := c1.get(entity)
+	_, ok2 := c2.get(entity)
+	_, ok3 := c3.get(entity)
+	return ok1 && ok2 && ok3
+}
+
+// Len returns how many entities match the view. It walks the smallest
+// storage checking membership but never calls back into user code.
+func (v *View3[T1, T2, T3]) Len() int {
+	count := 0
+	v.Each(func(Goent, *T1, *T2, *T3) {
+		count++
+	})
+	return count
+}
+
+// View4 is a cached query over entities with T1, T2, T3, and T4 components.
+type View4[T1 any, T2 any, T3 any, T4 any] struct {
+	r       *Registry
+	filters []Filter
+	s1      *SparseSet[T1]
+	s2      *SparseSet[T2]
+	s3      *SparseSet[T3]
+	s4      *SparseSet[T4]
+}
+
+// NewView4 creates a view, registering any of the component types that
+// don't have a storage yet. The filters apply to every call on the view.
+func NewView4[T1 any, T2 any, T3 any, T4 any](r *Registry, filters ...Filter) *View4[T1, T2, T3, T4] {
+	return &View4[T1, T2, T3, T4]{
+		r:       r,
+		filters: filters,
+		s1:      viewStorage[T1](r),
+		s2:      viewStorage[T2](r),
+		s3:      viewStorage[T3](r),
+		s4:      viewStorage[T4](r),
+	}
+}
+
+func (v *View4[T1, T2, T3, T4]) columns(fs *filterSet) (column[T1], column[T2], column[T3], column[T4]) {
+	return column[T1]{storage: v.s1, optional: fs.isOptional(typeKeyFor[T1]())},
+		column[T2]{storage: v.s2, optional: fs.isOptional(typeKeyFor[T2]())},
+		column[T3]{storage: v.s3, optional: fs.isOptional(typeKeyFor[T3]())},
+		column[T4]{storage: v.s4, optional: fs.isOptional(typeKeyFor[T4]())}
+}
+
+// Each calls f for every entity matching the view.
+func (v *View4[T1, T2, T3, T4]) Each(f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4)) {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		archIterate4(v.r, &fs, f)
+		return
+	}
+	c1, c2, c3, c4 := v.columns(&fs)
+
+	iterateDense(driverDense(c1, c2, c3, c4), func(entity Goent) {
+		if fs.skip(entity) {
+			return
+		}
+		p1, ok1 := c1.get(entity)
+		p2, ok2 := c2.get(entity)
+		p3, ok3 := c3.get(entity)
+		p4, ok4 := c4.get(entity)
+		if ok1 && ok2 && ok3 && ok4 {
+			f(entity, p1, p2, p3, p4)
+		}
+	})
+}
+
+// Contains reports whether the entity currently matches the view.
+func (v *View4[T1, T2, T3, T4]) Contains(entity Goent) bool {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		return v.r.archetypes.entityMatches(entity, &fs, []reflect.Type{typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3](), typeKeyFor[T4]()})
+	}
+	if fs.skip(entity) {
+		return false
+	}
+	c1, c2, c3, c4 := v.columns(&fs)
+	_, ok1 := c1.get(entity)
+	_, ok2 := c2.get(entity)
+	_, ok3 := c3.get(entity)
+	_, ok4 := c4.get(entity)
+	return ok1 && ok2 && ok3 && ok4
+}
+
+// Len returns how many entities match the view. It walks the smallest
+// storage checking membership but never calls back into user code.
+func (v *View4[T1, T2, T3, T4]) Len() int {
+	count := 0
+	v.Each(func(Goent, *T1, *T2, *T3, *T4) {
+		count++
+	})
+	return count
+}
+
+// View5 is a cached query over entities with T1, T2, T3, T4, and T5 components.
+type View5[T1 any, T2 any, T3 any, T4 any, T5 any] struct {
+	r       *Registry
+	filters []Filter
+	s1      *SparseSet[T1]
+	s2      *SparseSet[T2]
+	s3      *SparseSet[T3]
+	s4      *SparseSet[T4]
+	s5      *SparseSet[T5]
+}
+
+// NewView5 creates a view, registering any of the component types that
+// don't have a storage yet. The filters apply to every call on the view.
+func NewView5[T1 any, T2 any, T3 any, T4 any, T5 any](r *Registry, filters ...Filter) *View5[T1, T2, T3, T4, T5] {
+	return &View5[T1, T2, T3, T4, T5]{
+		r:       r,
+		filters: filters,
+		s1:      viewStorage[T1](r),
+		s2:      viewStorage[T2](r),
+		s3:      viewStorage[T3](r),
+		s4:      viewStorage[T4](r),
+		s5:      viewStorage[T5](r),
+	}
+}
+
+func (v *View5[T1, T2, T3, T4, T5]) columns(fs *filterSet) (column[T1], column[T2], column[T3], column[T4], column[T5]) {
+	return column[T1]{storage: v.s1, optional: fs.isOptional(typeKeyFor[T1]())},
+		column[T2]{storage: v.s2, optional: fs.isOptional(typeKeyFor[T2]())},
+		column[T3]{storage: v.s3, optional: fs.isOptional(typeKeyFor[T3]())},
+		column[T4]{storage: v.s4, optional: fs.isOptional(typeKeyFor[T4]())},
+		column[T5]{storage: v.s5, optional: fs.isOptional(typeKeyFor[T5]())}
+}
+
+// Each calls f for every entity matching the view.
+func (v *View5[T1, T2, T3, T4, T5]) Each(f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4, c5 *T5)) {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		archIterate5(v.r, &fs, f)
+		return
+	}
+	c1, c2, c3, c4, c5 := v.columns(&fs)
+
+	iterateDense(driverDense(c1, c2, c3, c4, c5), func(entity Goent) {
+		if fs.skip(entity) {
+			return
+		}
+		p1, ok1 := c1.get(entity)
+		p2, ok2 := c2.get(entity)
+		p3, ok3 := c3.get(entity)
+		p4, ok4 := c4.get(entity)
+		p5, ok5 := c5.get(entity)
+		if ok1 && ok2 && ok3 && ok4 && ok5 {
+			f(entity, p1, p2, p3, p4, p5)
+		}
+	})
+}
+
+// Contains reports whether the entity currently matches the view.
+func (v *View5[T1, T2, T3, T4, T5]) Contains(entity Goent) bool {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		return v.r.archetypes.entityMatches(entity, &fs, []reflect.Type{typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3](), typeKeyFor[T4](), typeKeyFor[T5]()})
+	}
+	if fs.skip(entity) {
+		return false
+	}
+	c1, c2, c3, c4, c5 := v.columns(&fs)
+	_, ok1 := c1.get(entity)
+	_, ok2 := c2.get(entity)
+	_, ok3 := c3.get(entity)
+	_, ok4 := c4.get(entity)
+	_, ok5 := c5.get(entity)
+	return ok1 && ok2 && ok3 && ok4 && ok5
+}
+
+// Len returns how many entities match the view. It walks the smallest
+// storage checking membership but never calls back into user code.
+func (v *View5[T1, T2, T3, T4, T5]) Len() int {
+	count := 0
+	v.Each(func(Goent, *T1, *T2, *T3, *T4, *T5) {
+		count++
+	})
+	return count
+}
+
+// View6 is a cached query over entities with T1, T2, T3, T4, T5, and T6 components.
+type View6[T1 any, T2 any, T3 any, T4 any, T5 any, T6 any] struct {
+	r       *Registry
+	filters []Filter
+	s1      *SparseSet[T1]
+	s2      *SparseSet[T2]
+	s3      *SparseSet[T3]
+	s4      *SparseSet[T4]
+	s5      *SparseSet[T5]
+	s6      *SparseSet[T6]
+}
+
+// NewView6 creates a view, registering any of the component types that
+// don't have a storage yet. The filters apply to every call on the view.
+func NewView6[T1 any, T2 any, T3 any, T4 any, T5 any, T6 any](r *Registry, filters ...Filter) *View6[T1, T2, T3, T4, T5, T6] {
+	return &View6[T1, T2, T3, T4, T5, T6]{
+		r:       r,
+		filters: filters,
+		s1:      viewStorage[T1](r),
+		s2:      viewStorage[T2](r),
+		s3:      viewStorage[T3](r),
+		s4:      viewStorage[T4](r),
+		s5:      viewStorage[T5](r),
+		s6:      viewStorage[T6](r),
+	}
+}
+
+func (v *View6[T1, T2, T3, T4, T5, T6]) columns(fs *filterSet) (column[T1], column[T2], column[T3], column[T4], column[T5], column[T6]) {
+	return column[T1]{storage: v.s1, optional: fs.isOptional(typeKeyFor[T1]())},
+		column[T2]{storage: v.s2, optional: fs.isOptional(typeKeyFor[T2]())},
+		column[T3]{storage: v.s3, optional: fs.isOptional(typeKeyFor[T3]())},
+		column[T4]{storage: v.s4, optional: fs.isOptional(typeKeyFor[T4]())},
+		column[T5]{storage: v.s5, optional: fs.isOptional(typeKeyFor[T5]())},
+		column[T6]{storage: v.s6, optional: fs.isOptional(typeKeyFor[T6]())}
+}
+
+// Each calls f for every entity matching the view.
+func (v *View6[T1, T2, T3, T4, T5, T6]) Each(f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4, c5 *T5, c6 *T6)) {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		archIterate6(v.r, &fs, f)
+		return
+	}
+	c1, c2, c3, c4, c5, c6 := v.columns(&fs)
+
+	iterateDense(driverDense(c1, c2, c3, c4, c5, c6), func(entity Goent) {
+		if fs.skip(entity) {
+			return
+		}
+		p1, ok1 := c1.get(entity)
+		p2, ok2 := c2.get(entity)
+		p3, ok3 := c3.get(entity)
+		p4, ok4 := c4.get(entity)
+		p5, ok5 := c5.get(entity)
+		p6, ok6 := c6.get(entity)
+		if ok1 && ok2 && ok3 && ok4 && ok5 && ok6 {
+			f(entity, p1, p2, p3, p4, p5, p6)
+		}
+	})
+}
+
+// Contains reports whether the entity currently matches the view.
+func (v *View6[T1, T2, T3, T4, T5, T6]) Contains(entity Goent) bool {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		return v.r.archetypes.entityMatches(entity, &fs, []reflect.Type{typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3](), typeKeyFor[T4](), typeKeyFor[T5](), typeKeyFor[T6]()})
+	}
+	if fs.skip(entity) {
+		return false
+	}
+	c1, c2, c3, c4, c5, c6 := v.columns(&fs)
+	_, ok1 := c1.get(entity)
+	_, ok2 := c2.get(entity)
+	_, ok3 := c3.get(entity)
+	_, ok4 := c4.get(entity)
+	_, ok5 := c5.get(entity)
+	_, ok6 := c6.get(entity)
+	return ok1 && ok2 && ok3 && ok4 && ok5 && ok6
+}
+
+// Len returns how many entities match the view. It walks the smallest
+// storage checking membership but never calls back into user code.
+func (v *View6[T1, T2, T3, T4, T5, T6]) Len() int {
+	count := 0
+	v.Each(func(Goent, *T1, *T2, *T3, *T4, *T5, *T6) {
+		count++
+	})
+	return count
+}
+
+// View7 is a cached query over entities with T1, T2, T3, T4, T5, T6, and T7 components.
+type View7[T1 any, T2 any, T3 any, T4 any, T5 any, T6 any, T7 any] struct {
+	r       *Registry
+	filters []Filter
+	s1      *SparseSet[T1]
+	s2      *SparseSet[T2]
+	s3      *SparseSet[T3]
+	s4      *SparseSet[T4]
+	s5      *SparseSet[T5]
+	s6      *SparseSet[T6]
+	s7      *SparseSet[T7]
+}
+
+// NewView7 creates a view, registering any of the component types that
+// don't have a storage yet. The filters apply to every call on the view.
+func NewView7[T1 any, T2 any, T3 any, T4 any, T5 any, T6 any, T7 any](r *Registry, filters ...Filter) *View7[T1, T2, T3, T4, T5, T6, T7] {
+	return &View7[T1, T2, T3, T4, T5, T6, T7]{
+		r:       r,
+		filters: filters,
+		s1:      viewStorage[T1](r),
+		s2:      viewStorage[T2](r),
+		s3:      viewStorage[T3](r),
+		s4:      viewStorage[T4](r),
+		s5:      viewStorage[T5](r),
+		s6:      viewStorage[T6](r),
+		s7:      viewStorage[T7](r),
+	}
+}
+
+func (v *View7[T1, T2, T3, T4, T5, T6, T7]) columns(fs *filterSet) (column[T1], column[T2], column[T3], column[T4], column[T5], column[T6], column[T7]) {
+	return column[T1]{storage: v.s1, optional: fs.isOptional(typeKeyFor[T1]())},
+		column[T2]{storage: v.s2, optional: fs.isOptional(typeKeyFor[T2]())},
+		column[T3]{storage: v.s3, optional: fs.isOptional(typeKeyFor[T3]())},
+		column[T4]{storage: v.s4, optional: fs.isOptional(typeKeyFor[T4]())},
+		column[T5]{storage: v.s5, optional: fs.isOptional(typeKeyFor[T5]())},
+		column[T6]{storage: v.s6, optional: fs.isOptional(typeKeyFor[T6]())},
+		column[T7]{storage: v.s7, optional: fs.isOptional(typeKeyFor[T7]())}
+}
+
+// Each calls f for every entity matching the view.
+func (v *View7[T1, T2, T3, T4, T5, T6, T7]) Each(f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4, c5 *T5, c6 *T6, c7 *T7)) {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		archIterate7(v.r, &fs, f)
+		return
+	}
+	c1, c2, c3, c4, c5, c6, c7 := v.columns(&fs)
+
+	iterateDense(driverDense(c1, c2, c3, c4, c5, c6, c7), func(entity Goent) {
+		if fs.skip(entity) {
+			return
+		}
+		p1, ok1 := c1.get(entity)
+		p2, ok2 := c2.get(entity)
+		p3, ok3 := c3.get(entity)
+		p4, ok4 := c4.get(entity)
+		p5, ok5 := c5.get(entity)
+		p6, ok6 := c6.get(entity)
+		p7, ok7 := c7.get(entity)
+		if ok1 && ok2 && ok3 && ok4 && ok5 && ok6 && ok7 {
+			f(entity, p1, p2, p3, p4, p5, p6, p7)
+		}
+	})
+}
+
+// Contains reports whether the entity currently matches the view.
+func (v *View7[T1, T2, T3, T4, T5, T6, T7]) Contains(entity Goent) bool {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		return v.r.archetypes.entityMatches(entity, &fs, []reflect.Type{typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3](), typeKeyFor[T4](), typeKeyFor[T5](), typeKeyFor[T6](), typeKeyFor[T7]()})
+	}
+	if fs.skip(entity) {
+		return false
+	}
+	c1, c2, c3, c4, c5, c6, c7 := v.columns(&fs)
+	_, ok1 := c1.get(entity)
+	_, ok2 := c2.get(entity)
+	_, ok3 := c3.get(entity)
+	_, ok4 := c4.get(entity)
+	_, ok5 := c5.get(entity)
+	_, ok6 := c6.get(entity)
+	_, ok7 := c7.get(entity)
+	return ok1 && ok2 && ok3 && ok4 && ok5 && ok6 && ok7
+}
+
+// Len returns how many entities match the view. It walks the smallest
+// storage checking membership but never calls back into user code.
+func (v *View7[T1, T2, T3, T4, T5, T6, T7]) Len() int {
+	count := 0
+	v.Each(func(Goent, *T1, *T2, *T3, *T4, *T5, *T6, *T7) {
+		count++
+	})
+	return count
+}
+
+// View8 is a cached query over entities with T1, T2, T3, T4, T5, T6, T7, and T8 components.
+type View8[T1 any, T2 any, T3 any, T4 any, T5 any, T6 any, T7 any, T8 any] struct {
+	r       *Registry
+	filters []Filter
+	s1      *SparseSet[T1]
+	s2      *SparseSet[T2]
+	s3      *SparseSet[T3]
+	s4      *SparseSet[T4]
+	s5      *SparseSet[T5]
+	s6      *SparseSet[T6]
+	s7      *SparseSet[T7]
+	s8      *SparseSet[T8]
+}
+
+// NewView8 creates a view, registering any of the component types that
+// don't have a storage yet. The filters apply to every call on the view.
+func NewView8[T1 any, T2 any, T3 any, T4 any, T5 any, T6 any, T7 any, T8 any](r *Registry, filters ...Filter) *View8[T1, T2, T3, T4, T5, T6, T7, T8] {
+	return &View8[T1, T2, T3, T4, T5, T6, T7, T8]{
+		r:       r,
+		filters: filters,
+		s1:      viewStorage[T1](r),
+		s2:      viewStorage[T2](r),
+		s3:      viewStorage[T3](r),
+		s4:      viewStorage[T4](r),
+		s5:      viewStorage[T5](r),
+		s6:      viewStorage[T6](r),
+		s7:      viewStorage[T7](r),
+		s8:      viewStorage[T8](r),
+	}
+}
+
+func (v *View8[T1, T2, T3, T4, T5, T6, T7, T8]) columns(fs *filterSet) (column[T1], column[T2], column[T3], column[T4], column[T5], column[T6], column[T7], column[T8]) {
+	return column[T1]{storage: v.s1, optional: fs.isOptional(typeKeyFor[T1]())},
+		column[T2]{storage: v.s2, optional: fs.isOptional(typeKeyFor[T2]())},
+		column[T3]{storage: v.s3, optional: fs.isOptional(typeKeyFor[T3]())},
+		column[T4]{storage: v.s4, optional: fs.isOptional(typeKeyFor[T4]())},
+		column[T5]{storage: v.s5, optional: fs.isOptional(typeKeyFor[T5]())},
+		column[T6]{storage: v.s6, optional: fs.isOptional(typeKeyFor[T6]())},
+		column[T7]{storage: v.s7, optional: fs.isOptional(typeKeyFor[T7]())},
+		column[T8]{storage: v.s8, optional: fs.isOptional(typeKeyFor[T8]())}
+}
+
+// Each calls f for every entity matching the view.
+func (v *View8[T1, T2, T3, T4, T5, T6, T7, T8]) Each(f func(entity Goent, c1 *T1, c2 *T2, c3 *T3, c4 *T4, c5 *T5, c6 *T6, c7 *T7, c8 *T8)) {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		archIterate8(v.r, &fs, f)
+		return
+	}
+	c1, c2, c3, c4, c5, c6, c7, c8 := v.columns(&fs)
+
+	iterateDense(driverDense(c1, c2, c3, c4, c5, c6, c7, c8), func(entity Goent) {
+		if fs.skip(entity) {
+			return
+		}
+		p1, ok1 := c1.get(entity)
+		p2, ok2 := c2.get(entity)
+		p3, ok3 := c3.get(entity)
+		p4, ok4 := c4.get(entity)
+		p5, ok5 := c5.get(entity)
+		p6, ok6 := c6.get(entity)
+		p7, ok7 := c7.get(entity)
+		p8, ok8 := c8.get(entity)
+		if ok1 && ok2 && ok3 && ok4 && ok5 && ok6 && ok7 && ok8 {
+			f(entity, p1, p2, p3, p4, p5, p6, p7, p8)
+		}
+	})
+}
+
+// Contains reports whether the entity currently matches the view.
+func (v *View8[T1, T2, T3, T4, T5, T6, T7, T8]) Contains(entity Goent) bool {
+	fs := v.r.resolveFilters(v.filters)
+	if v.r.archetypes != nil {
+		return v.r.archetypes.entityMatches(entity, &fs, []reflect.Type{typeKeyFor[T1](), typeKeyFor[T2](), typeKeyFor[T3](), typeKeyFor[T4](), typeKeyFor[T5](), typeKeyFor[T6](), typeKeyFor[T7](), typeKeyFor[T8]()})
+	}
+	if fs.skip(entity) {
+		return false
+	}
+	c1, c2, c3, c4, c5, c6, c7, c8 := v.columns(&fs)
+	_, ok1 := c1.get(entity)
+	_, ok2 := c2.get(entity)
+	_, ok3 := c3.get(entity)
+	_, ok4 := c4.get(entity)
+	_, ok5 := c5.get(entity)
+	_, ok6 := c6.get(entity)
+	_, ok7 := c7.get(entity)
+	_, ok8 := c8.get(entity)
+	return ok1 && ok2 && ok3 && ok4 && ok5 && ok6 && ok7 && ok8
+}
+
+// Len returns how many entities match the view. It walks the smallest
+// storage checking membership but never calls back into user code.
+func (v *View8[T1, T2, T3, T4, T5, T6, T7, T8]) Len() int {
+	count := 0
+	v.Each(func(Goent, *T1, *T2, *T3, *T4, *T5, *T6, *T7, *T8) {
+		count++
+	})
+	return count
+}
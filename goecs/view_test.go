@@ -0,0 +1,104 @@
+package goecs
+
+import (
+	"slices"
+	"testing"
+)
+
+type viewProbe struct {
+	Team int
+}
+
+func TestView1LenDisabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage StorageMode
+		filters []Filter
+		disable int
+		want    int
+	}{
+		{"none disabled", SparseSetStorage, nil, 0, 4},
+		{"some disabled", SparseSetStorage, nil, 2, 2},
+		{"all disabled", SparseSetStorage, nil, 4, 0},
+		{"include disabled", SparseSetStorage, []Filter{IncludeDisabled()}, 2, 4},
+		{"archetypes", ArchetypeStorage, nil, 1, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistryWithOptions(RegistryOptions{Storage: tt.storage})
+			for i, e := range r.CreateEntities(4) {
+				EmplaceComponent(r, e, viewProbe{})
+				if i < tt.disable {
+					r.Disable(e)
+				}
+			}
+			if got := NewView1[viewProbe](r, tt.filters...).Len(); got != tt.want {
+				t.Errorf("Len = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestView1LenEnabledAgain(t *testing.T) {
+	r := NewRegistry()
+	e := r.CreateEntity()
+	EmplaceComponent(r, e, viewProbe{})
+	v := NewView1[viewProbe](r)
+	r.Disable(e)
+	if got := v.Len(); got != 0 {
+		t.Errorf("Len after Disable = %d, want 0", got)
+	}
+	r.Enable(e)
+	if got := v.Len(); got != 1 {
+		t.Errorf("Len after Enable = %d, want 1", got)
+	}
+}
+
+func TestGroupByDisabled(t *testing.T) {
+	for _, storage := range []StorageMode{SparseSetStorage, ArchetypeStorage} {
+		r := NewRegistryWithOptions(RegistryOptions{Storage: storage})
+		entities := r.CreateEntities(3)
+		for i, e := range entities {
+			EmplaceComponent(r, e, viewProbe{Team: i % 2})
+		}
+		r.Disable(entities[2])
+		groups := GroupBy(r, func(p *viewProbe) int { return p.Team })
+		if !slices.Equal(groups[0], entities[:1]) || !slices.Equal(groups[1], entities[1:2]) {
+			t.Errorf("storage %v: groups = %v, want [%d] and [%d]", storage, groups, entities[0], entities[1])
+		}
+		ReleaseGroups(groups)
+	}
+}
+
+func TestReactiveIgnoresDisabled(t *testing.T) {
+	r := NewRegistry()
+	seeded, later := r.CreateEntity(), r.CreateEntity()
+	EmplaceComponent(r, seeded, viewProbe{})
+	r.Disable(seeded)
+	q := NewReactive1[viewProbe](r)
+	r.Disable(later)
+	EmplaceComponent(r, later, viewProbe{})
+	if got := q.Matched(); !slices.Equal(got, []Goent{seeded, later}) {
+		t.Errorf("Matched = %v, want [%d %d]", got, seeded, later)
+	}
+	q.Drain()
+	r.Enable(seeded)
+	if entered, exited := q.Drain(); len(entered) != 0 || len(exited) != 0 {
+		t.Errorf("Enable reported entered %v, exited %v, want nothing", entered, exited)
+	}
+}
+
+func TestViewArchetypeNoStorages(t *testing.T) {
+	r := NewRegistryWithOptions(RegistryOptions{Storage: ArchetypeStorage})
+	e := r.CreateEntity()
+	EmplaceComponent(r, e, viewProbe{Team: 1})
+	v := NewView2[viewProbe, Parent](r, Optional[Parent]())
+	if len(r.storages) != 0 {
+		t.Errorf("NewView2 created %d sparse storages on an archetype registry", len(r.storages))
+	}
+	var got []Goent
+	v.Each(func(entity Goent, p *viewProbe, _ *Parent) { got = append(got, entity) })
+	if !slices.Equal(got, []Goent{e}) || !v.Contains(e) || v.Len() != 1 {
+		t.Errorf("Each visited %v, Contains %v, Len %d, want [%d], true, 1", got, v.Contains(e), v.Len(), e)
+	}
+}
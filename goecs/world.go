@@ -0,0 +1,80 @@
+package goecs
+
+// --- World and game loop ---
+// A World bundles a registry with the systems that update it, giving games
+// the standard ECS frame structure instead of a hand-rolled update loop.
+// Systems queue structural changes on World.Commands, which is flushed after
+// each system returns, and read their tunables from World.Config.
+
+// System is one step of the frame, run by World.Update.
+type System interface {
+	Update(w *World, dt float64)
+}
+
+// SystemFunc adapts a plain function to the System interface.
+type SystemFunc func(w *World, dt float64)
+
+// Update calls f(w, dt).
+func (f SystemFunc) Update(w *World, dt float64) {
+	f(w, dt)
+}
+
+// World is a registry plus its ordered systems.
+type World struct {
+	Registry *Registry
+	Commands *CommandBuffer
+	Config   *Config
+	// Maintenance, when set, runs at the end of every Update
+	Maintenance *Maintenance
+	systems     []System
+	// runs holds the tick each system's last run ended at, see LastRun
+	runs []uint64
+	// since is the LastRun of the system being updated
+	since uint64
+}
+
+// NewWorld creates a world around a fresh registry.
+func NewWorld() *World {
+	return NewWorldWithRegistry(NewRegistry())
+}
+
+// NewWorldWithRegistry creates a world around an existing registry, e.g. one
+// using the archetype backend.
+func NewWorldWithRegistry(r *Registry) *World {
+	return &World{Registry: r, Commands: NewCommandBuffer(), Config: NewConfig()}
+}
+
+// AddSystem appends a system; systems run in the order they were added.
+func (w *World) AddSystem(s System) {
+	w.systems = append(w.systems, s)
+	w.runs = append(w.runs, 0)
+}
+
+// Systems returns the registered systems in run order.
+func (w *World) Systems() []System {
+	return w.systems
+}
+
+// LastRun returns the tick the running system's previous run ended at, 0 on
+// its first run, for its Changed filters:
+//
+//	Iterate1(w.Registry, sync, Changed[Transform](w.LastRun()))
+func (w *World) LastRun() uint64 {
+	return w.since
+}
+
+// Update runs one frame, calling every system with the elapsed time dt. The
+// registry's tick advances at the start of every frame, and is checkpointed
+// after every system so it sees the changes of the systems after it.
+func (w *World) Update(dt float64) {
+	w.Registry.AdvanceTick()
+	for i, s := range w.systems {
+		w.since = w.runs[i]
+		s.Update(w, dt)
+		w.Commands.Flush(w.Registry)
+		w.runs[i] = w.Registry.Checkpoint()
+	}
+	if w.Maintenance != nil {
+		w.Maintenance.Run()
+	}
+}
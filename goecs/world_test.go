@@ -0,0 +1,73 @@
+package goecs
+
+import (
+	"slices"
+	"testing"
+)
+
+type worldPos struct {
+	X float64
+}
+
+type worldVel struct {
+	X float64
+}
+
+func TestWorldUpdate(t *testing.T) {
+	w := NewWorld()
+	e := w.Registry.CreateEntity()
+	EmplaceComponent(w.Registry, e, worldPos{})
+	EmplaceComponent(w.Registry, e, worldVel{X: 2})
+
+	var order []string
+	var spawned []Goent
+	w.AddSystem(SystemFunc(func(w *World, dt float64) {
+		order = append(order, "move")
+		Iterate2(w.Registry, func(e Goent, p *worldPos, v *worldVel) { p.X += v.X * dt })
+	}))
+	w.AddSystem(SystemFunc(func(w *World, dt float64) {
+		order = append(order, "spawn")
+		spawned = append(spawned, w.Registry.CreateEntity())
+		DeferEmplace(w.Commands, spawned[len(spawned)-1], worldVel{X: 1})
+	}))
+	w.AddSystem(SystemFunc(func(w *World, dt float64) {
+		order = append(order, "count")
+		// the spawn system's commands were flushed before this one runs
+		if n := Count[worldVel](w.Registry); n != 1+len(spawned) {
+			t.Errorf("count system saw %d velocities, want %d", n, 1+len(spawned))
+		}
+	}))
+
+	const frames = 3
+	for i := 0; i < frames; i++ {
+		w.Update(0.5)
+	}
+	p, _ := GetComponent[worldPos](w.Registry, e)
+	if p.X != frames {
+		t.Errorf("position = %v after %d frames, want %d", p.X, frames, frames)
+	}
+	want := slices.Repeat([]string{"move", "spawn", "count"}, frames)
+	if !slices.Equal(order, want) {
+		t.Errorf("systems ran %v, want %v", order, want)
+	}
+	if w.Commands.Len() != 0 {
+		t.Errorf("%d commands left after Update", w.Commands.Len())
+	}
+	if got := len(w.Systems()); got != 3 {
+		t.Errorf("Systems() has %d systems, want 3", got)
+	}
+}
+
+func TestWorldUpdateAdvancesTick(t *testing.T) {
+	w := NewWorld()
+	var ticks []uint64
+	w.AddSystem(SystemFunc(func(w *World, dt float64) { ticks = append(ticks, w.Registry.Tick()) }))
+	w.AddSystem(SystemFunc(func(w *World, dt float64) { ticks = append(ticks, w.Registry.Tick()) }))
+	w.Update(1)
+	w.Update(1)
+	for i := 1; i < len(ticks); i++ {
+		if ticks[i] <= ticks[i-1] {
+			t.Fatalf("system ticks %v don't increase", ticks)
+		}
+	}
+}